@@ -1,13 +1,103 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"os"
+	"slices"
+	"strings"
+	"time"
 
 	"com.github.yveskaufmann/hue-lighter/internal/app"
+	"com.github.yveskaufmann/hue-lighter/internal/logging"
 )
 
 func main() {
-	appInstance := app.Bootstrap()
+	if len(os.Args) > 1 && os.Args[1] == "doctor" {
+		if !app.RunDoctor(context.Background(), os.Stdout) {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "validate-config" {
+		var path string
+		if len(os.Args) > 2 && !strings.HasPrefix(os.Args[2], "--") {
+			path = os.Args[2]
+		}
+		if err := app.ValidateConfig(path, time.Now(), os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid config: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if len(os.Args) > 2 && os.Args[1] == "config" && os.Args[2] == "dump" {
+		var path string
+		if len(os.Args) > 3 && !strings.HasPrefix(os.Args[3], "--") {
+			path = os.Args[3]
+		}
+		if err := app.DumpConfig(path, os.Stdout, slices.Contains(os.Args[3:], "--json")); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to dump config: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "identities" {
+		if len(os.Args) > 3 && os.Args[2] == "remove" {
+			if err := app.RemoveIdentity(os.Stdout, os.Args[3]); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to remove identity: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+		if err := app.PrintIdentities(os.Stdout, slices.Contains(os.Args[2:], "--json")); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to list identities: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	var bootstrapOptions []app.BootstrapOption
+	if isCommand(os.Args[1:]) {
+		// Commands fail fast instead of waiting for the bridge like the service
+		bootstrapOptions = append(bootstrapOptions, app.WithoutDiscoveryRetries())
+	}
+
+	appInstance, err := app.Bootstrap(bootstrapOptions...)
+	if err != nil {
+		logging.NewLogger().WithField("component", "app").Fatalf("Failed to start: %v", err)
+	}
+
+	jsonOutput := slices.Contains(os.Args[1:], "--json")
+
+	if len(os.Args) > 1 && os.Args[1] == "lights" {
+		if err := appInstance.PrintLights(os.Stdout, jsonOutput); err != nil {
+			appInstance.Logger().Fatalf("failed to list lights: %v", err)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "registrations" {
+		if len(os.Args) > 2 && os.Args[2] == "prune" {
+			if err := appInstance.PruneRegistrations(os.Stdout); err != nil {
+				appInstance.Logger().Fatalf("failed to prune registrations: %v", err)
+			}
+			return
+		}
+		if err := appInstance.PrintRegistrations(os.Stdout, jsonOutput); err != nil {
+			appInstance.Logger().Fatalf("failed to list registrations: %v", err)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		if err := appInstance.ExportConfig(os.Stdout); err != nil {
+			appInstance.Logger().Fatalf("failed to export config: %v", err)
+		}
+		return
+	}
 
 	for arg := range os.Args {
 		{
@@ -18,6 +108,36 @@ func main() {
 				}
 				return
 			}
+
+			if os.Args[arg] == "--reload-config" {
+				err := appInstance.ReloadConfig()
+				if err != nil {
+					appInstance.Logger().Fatalf("failed to reload config: %v", err)
+				}
+				return
+			}
+
+			if os.Args[arg] == "--pause" {
+				if err := appInstance.PauseAutomation(); err != nil {
+					appInstance.Logger().Fatalf("failed to pause automation: %v", err)
+				}
+				return
+			}
+
+			if os.Args[arg] == "--resume" {
+				if err := appInstance.ResumeAutomation(); err != nil {
+					appInstance.Logger().Fatalf("failed to resume automation: %v", err)
+				}
+				return
+			}
+
+			if os.Args[arg] == "--status" {
+				err := appInstance.PrintStatus(os.Stdout, jsonOutput)
+				if err != nil {
+					appInstance.Logger().Fatalf("failed to print status: %v", err)
+				}
+				return
+			}
 		}
 	}
 
@@ -27,3 +147,14 @@ func main() {
 		appInstance.Logger().Fatalf("Unhandled error: %v", err)
 	}
 }
+
+// isCommand reports whether the arguments run a command which exits once it is
+// done, instead of starting the service.
+func isCommand(args []string) bool {
+	if len(args) > 0 && slices.Contains([]string{"lights", "registrations", "export"}, args[0]) {
+		return true
+	}
+	return slices.ContainsFunc(args, func(arg string) bool {
+		return slices.Contains([]string{"--shutdown", "--reload-config", "--pause", "--resume", "--status"}, arg)
+	})
+}
@@ -1,10 +1,13 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
+	"time"
 
 	"com.github.yveskaufmann/hue-lighter/internal/config"
 	hueclient "com.github.yveskaufmann/hue-lighter/internal/hue_client"
@@ -20,8 +23,11 @@ type App struct {
 	lightService    *light_automation.Service
 	eventService    *events.ExternalEventService
 	client          *hueclient.Client
+	apiKeyStore     hueclient.APIKeyStore
 	config          *config.Config
-	StopChn         chan struct{}
+	// stopChn is closed once to ask Run to shut down, see RequestStop
+	stopChn  chan struct{}
+	stopOnce sync.Once
 }
 
 func (a *App) Logger() *log.Entry {
@@ -35,11 +41,22 @@ func (a *App) EventService() *events.ExternalEventService {
 func (a *App) Run() error {
 	a.logger.Info("Starting application")
 
-	err := a.registerService.RegisterDevice(a.client.DeviceName())
+	registerCtx, cancelRegister := a.stopContext()
+	err := a.registerService.RegisterDevice(registerCtx, a.client.DeviceName())
+	cancelRegister()
 	if err != nil {
 		return fmt.Errorf("failed to register device: %w", err)
 	}
 
+	// The automation switches lights by ID, the lights are read once the device is registered
+	if err := resolveLightNames(a.config, a.client.GetAllLights); err != nil {
+		return err
+	}
+
+	if maxDrift := a.config.Automation.MaxClockDrift; maxDrift > 0 {
+		checkClockDrift(a.client, time.Now(), maxDrift, a.logger)
+	}
+
 	if err := a.lightService.Start(); err != nil {
 		return fmt.Errorf("failed to start light automation service: %w", err)
 	}
@@ -48,34 +65,76 @@ func (a *App) Run() error {
 		return fmt.Errorf("failed to start event service: %w", err)
 	}
 
+	streamCtx, stopStream := context.WithCancel(context.Background())
+	defer stopStream()
+	if a.config.Automation.LightStateRefreshMode == config.LightStateRefreshStream {
+		a.logger.Info("Updating light states from the event stream of the bridge")
+		go streamLightStates(streamCtx, a.client.StreamEvents, a.lightService.ApplyStreamEvent, streamReconnectBackoff, a.logger)
+	}
+
 	signalChan := make(chan os.Signal, 1)
 	signal.Notify(signalChan, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signalChan)
 
-eventLoop:
-	for {
-		select {
-		case <-signalChan:
-			a.logger.Info("Received interrupt signal, shutting down...")
-			break eventLoop
-		case <-a.StopChn:
-			a.logger.Info("Received stop signal, shutting down...")
-			break eventLoop
-		}
-	}
-
-	close(signalChan)
-	close(a.StopChn)
+	a.waitForStop(signalChan)
 
+	stopStream()
 	a.Stop()
 
 	return nil
 }
 
+// RequestStop asks Run to shut down. It is safe to call concurrently and more than
+// once, e.g. by the event service while a termination signal arrives.
+func (a *App) RequestStop() {
+	a.stopOnce.Do(func() {
+		close(a.stopChn)
+	})
+}
+
+// stopContext returns a context which is done once a termination signal arrives or
+// a stop was requested, e.g. to abort the device registration waiting for the link button.
+func (a *App) stopContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-a.stopChn:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// waitForStop blocks until a signal is received or a stop was requested.
+func (a *App) waitForStop(signals <-chan os.Signal) {
+	select {
+	case <-signals:
+		a.logger.Info("Received interrupt signal, shutting down...")
+		a.RequestStop()
+	case <-a.stopChn:
+		a.logger.Info("Received stop signal, shutting down...")
+	}
+}
+
+// shutdownGracePeriod bounds the shutdown on top of the configured shutdown delay
+// and fade duration, which a shutdown event in progress may still wait for.
+const shutdownGracePeriod = 10 * time.Second
+
+// Stop shuts the services down in order: the event service stops accepting events
+// and waits until a shutdown event in progress turned off the lights, then the
+// light automation is stopped.
 func (a *App) Stop() error {
 	a.logger.Info("Stopping application")
 
+	timeout := a.config.Shutdown.Delay + a.config.Shutdown.FadeDuration + shutdownGracePeriod
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := a.eventService.Shutdown(ctx); err != nil {
+		a.logger.WithError(err).Warn("External Event Service did not shut down in time")
+	}
 	a.lightService.Stop()
-	a.eventService.Stop()
 
 	return nil
 }
@@ -83,7 +142,9 @@ func (a *App) Stop() error {
 func (a *App) SendShutdownEvent() error {
 
 	a.logger.Info("Starting application")
-	err := a.registerService.RegisterDevice(a.client.DeviceName())
+	registerCtx, cancelRegister := a.stopContext()
+	defer cancelRegister()
+	err := a.registerService.RegisterDevice(registerCtx, a.client.DeviceName())
 	if err != nil {
 		return fmt.Errorf("failed to register device: %w", err)
 	}
@@ -92,3 +153,32 @@ func (a *App) SendShutdownEvent() error {
 
 	return a.eventService.StopAndTurnOffLights()
 }
+
+func (a *App) ReloadConfig() error {
+	if err := a.eventService.ReloadConfig(); err != nil {
+		return err
+	}
+
+	a.logger.Info("Config reloaded by running service")
+	return nil
+}
+
+// PauseAutomation pauses the light automation of the running service.
+func (a *App) PauseAutomation() error {
+	if err := a.eventService.PauseAutomation(); err != nil {
+		return err
+	}
+
+	a.logger.Info("Light automation paused by running service")
+	return nil
+}
+
+// ResumeAutomation resumes the paused light automation of the running service.
+func (a *App) ResumeAutomation() error {
+	if err := a.eventService.ResumeAutomation(); err != nil {
+		return err
+	}
+
+	a.logger.Info("Light automation resumed by running service")
+	return nil
+}
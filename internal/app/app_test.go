@@ -0,0 +1,79 @@
+package app
+
+import (
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"com.github.yveskaufmann/hue-lighter/internal/logging"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestApp() *App {
+	return &App{
+		logger:  logging.NewDiscardLogger(),
+		stopChn: make(chan struct{}),
+	}
+}
+
+func TestApp_RequestStop(t *testing.T) {
+	t.Run("can be called more than once", func(t *testing.T) {
+		app := newTestApp()
+
+		assert.NotPanics(t, func() {
+			app.RequestStop()
+			app.RequestStop()
+		})
+	})
+
+	t.Run("signal and shutdown event at the same time", func(t *testing.T) {
+		for i := 0; i < 50; i++ {
+			app := newTestApp()
+			signals := make(chan os.Signal, 1)
+
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				app.waitForStop(signals)
+			}()
+
+			// The event service requests the stop from its own goroutine while
+			// the termination signal arrives.
+			var wg sync.WaitGroup
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				signals <- syscall.SIGTERM
+			}()
+			go func() {
+				defer wg.Done()
+				app.RequestStop()
+			}()
+			wg.Wait()
+
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatal("waitForStop did not return")
+			}
+			app.RequestStop()
+		}
+	})
+}
+
+func TestApp_StopContext(t *testing.T) {
+	app := newTestApp()
+	ctx, cancel := app.stopContext()
+	defer cancel()
+
+	assert.NoError(t, ctx.Err())
+	app.RequestStop()
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("stop context was not done after the stop request")
+	}
+}
@@ -1,59 +1,313 @@
 package app
 
 import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
 	"com.github.yveskaufmann/hue-lighter/internal/config"
+	"com.github.yveskaufmann/hue-lighter/internal/geolocation"
 	hueclient "com.github.yveskaufmann/hue-lighter/internal/hue_client"
 	"com.github.yveskaufmann/hue-lighter/internal/logging"
 	"com.github.yveskaufmann/hue-lighter/internal/services/device_registration"
 	"com.github.yveskaufmann/hue-lighter/internal/services/events"
 	"com.github.yveskaufmann/hue-lighter/internal/services/light_automation"
+	log "github.com/sirupsen/logrus"
 )
 
-func Bootstrap() *App {
-	logger := logging.NewLogger().WithField("component", "app")
+// BootstrapOption configures Bootstrap.
+type BootstrapOption func(*bootstrapOptions)
+
+type bootstrapOptions struct {
+	withoutDiscoveryRetries bool
+}
+
+// WithoutDiscoveryRetries fails at once if no bridge is found instead of retrying
+// the discovery like the service, e.g. for commands like --status or lights.
+func WithoutDiscoveryRetries() BootstrapOption {
+	return func(o *bootstrapOptions) {
+		o.withoutDiscoveryRetries = true
+	}
+}
+
+// discoveryRetries returns the configured discovery retries, none if they are disabled by the options.
+func (o *bootstrapOptions) discoveryRetries(cfg *config.Config) int {
+	if o.withoutDiscoveryRetries || cfg.Discovery.Retries == nil {
+		return 0
+	}
+	return *cfg.Discovery.Retries
+}
 
-	config, err := config.LoadConfigFromDefaultPath()
+// Bootstrap loads the config, discovers the bridge and wires the services of the
+// application. It returns an error instead of exiting, so that callers decide how to fail.
+func Bootstrap(opts ...BootstrapOption) (*App, error) {
+	options := &bootstrapOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	// Every API key read from or written to the store is redacted in the logs.
+	redactor := logging.NewRedactor()
+	logger := logging.NewLogger(logging.WithRedaction(redactor)).WithField("component", "app")
+
+	// Abort the location lookup and the discovery when the service is stopped while still starting.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	config, err := loadConfig(ctx, logger)
 	if err != nil {
-		logger.Fatalf("Failed to load config: %v", err)
+		return nil, err
 	}
 
-	store, err := hueclient.NewAPIKeyStore(logger)
+	store, err := hueclient.NewAPIKeyStore(logger, config.Paths.APIKeyStore)
 	if err != nil {
-		logger.Fatalf("Failed to create API key store: %v", err)
+		return nil, fmt.Errorf("failed to create API key store: %w", err)
 	}
+	store = hueclient.NewRedactingAPIKeyStore(store, redactor)
+
+	certPin, err := hueclient.ResolveCertificatePin(logger)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bridge certificate fingerprint: %w", err)
+	}
+	tlsOptions := []hueclient.TLSOption{hueclient.WithCertificatePin(certPin)}
 
 	// Verify CA bundle is present before attempting discovery or creating clients.
-	certPath, err := hueclient.ResolveCABundlePath()
+	certPath, err := hueclient.ResolveCABundlePath(config.Paths.CABundle)
 	if err != nil {
-		logger.Fatalf("CA bundle check failed: %v", err)
+		if !hueclient.InsecureTLSAllowed() {
+			return nil, fmt.Errorf("CA bundle check failed: %w", err)
+		}
+		logger.WithError(err).Warn("SECURITY WARNING: CA bundle is missing and HUE_ALLOW_INSECURE_TLS is set, " +
+			"the bridge certificate is NOT verified against the Philips CA. Only the bridge ID in the certificate is checked, " +
+			"so anyone on your network can impersonate the bridge. Install the CA bundle and unset HUE_ALLOW_INSECURE_TLS as soon as possible")
+		tlsOptions = append(tlsOptions, hueclient.WithInsecureSkipCAVerification())
+	} else {
+		logger.Infof("Using CA bundle: %s", certPath)
 	}
-	logger.Infof("Using CA bundle: %s", certPath)
 
-	discoveryService := hueclient.NewBridgeDiscoveryService(logger)
-	bridge, err := discoveryService.DiscoverFirstBridge(logger)
+	discoveryOptions, err := discoveryOptions(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid discovery config: %w", err)
+	}
+	discoveryService := hueclient.NewBridgeDiscoveryService(logger, discoveryOptions...)
+	bridge, err := discoverBridgeWithRetry(ctx, bridgeLocator(config, discoveryService), options.discoveryRetries(config), config.Discovery.RetryBackoff, logger)
 	if err != nil {
-		logger.Fatalf("Failed to discover Hue Bridge: %v", err)
+		return nil, fmt.Errorf("failed to discover Hue Bridge: %w", err)
 	}
 	logger.Infof("Discovered Hue Bridge at IP: %s", bridge.IP)
 
-	stopChn := make(chan struct{})
+	// Keep the registration when the bridge was replaced, e.g. by a newer model
+	migrated, err := hueclient.MigrateReplacedBridgeAPIKey(store, bridge, config.DeviceName())
+	if err != nil {
+		logger.WithError(err).Warn("Failed to migrate the API key of the replaced Hue Bridge, the device may need to register again")
+	} else if migrated {
+		logger.Infof("Hue Bridge %s replaced bridge %s, migrated the stored API key", bridge.ID, bridge.ReplacesBridgeID)
+	}
+
+	// A provided API key skips the registration with the link button
+	seeded, err := hueclient.SeedAPIKey(store, bridge.ID, config.DeviceName(), hueclient.ProvidedAPIKey(config.Bridge.APIKey))
+	if err != nil {
+		return nil, err
+	} else if seeded {
+		logger.Info("Stored the provided API key, skipping the device registration")
+	}
+
+	clientOptions := []hueclient.ClientOption{
+		hueclient.WithTLSOptions(tlsOptions...),
+		hueclient.WithAppName(config.Meta.AppName),
+		hueclient.WithLightAPI(config.Bridge.LightAPI),
+		hueclient.WithLogBodyLimit(config.Bridge.LogBodyLimit),
+		hueclient.WithMaxBrightness(maxBrightness(config)),
+	}
+	if config.Bridge.RediscoveryThreshold > 0 {
+		clientOptions = append(clientOptions, hueclient.WithRediscovery(discoveryService.LocateBridge, config.Bridge.RediscoveryThreshold))
+	}
+
+	// The registration service and the app need the client, they are created after the client.
+	var registerService *device_registration.Service
+	var app *App
+	if config.Bridge.ReregisterOnUnauthorized {
+		clientOptions = append(clientOptions, hueclient.WithReregistration(func() error {
+			ctx, cancel := app.stopContext()
+			defer cancel()
+			return registerService.RegisterDevice(ctx, config.DeviceName())
+		}))
+	}
 
-	client, err := hueclient.NewClient(config.Meta.Name, bridge.ID, bridge.IP, store, certPath, logger)
+	client, err := hueclient.NewClient(config.DeviceName(), bridge.ID, bridge.IP, store, certPath, logger, clientOptions...)
 	if err != nil {
-		logger.Fatalf("Failed to create Hue client: %v", err)
+		return nil, fmt.Errorf("failed to create Hue client: %w", err)
 	}
 
-	registerService := device_registration.NewService(client, store, logger)
+	registerService = device_registration.NewService(client, store, logger)
 	lightService := light_automation.NewService(client, config, logger)
-	eventService := events.NewExternalEventService(lightService, logger, stopChn)
 
-	return &App{
+	app = &App{
 		logger:          logger,
 		registerService: registerService,
 		client:          client,
-		eventService:    eventService,
+		apiKeyStore:     store,
 		lightService:    lightService,
 		config:          config,
-		StopChn:         stopChn,
+		stopChn:         make(chan struct{}),
+	}
+	app.eventService = events.NewExternalEventService(lightService, logger, app.RequestStop,
+		events.WithConfigLoader(reloadConfigLoader(configLoader(logger), client)),
+		events.WithReloadDebounce(config.Automation.ReloadDebounce))
+
+	return app, nil
+}
+
+// loadConfig loads the config file, or builds the config from the command line
+// flags if there is none, and resolves its location from the location source,
+// the location is read from the cache after the first lookup.
+func loadConfig(ctx context.Context, logger *log.Entry) (*config.Config, error) {
+	cfg, err := loadDefaultConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	for _, warning := range cfg.Warnings() {
+		logger.Warn(warning)
+	}
+
+	locator := geolocation.NewLocator(logger, geolocation.WithCachePath(cfg.Paths.LocationCache))
+	if err := resolveLocation(ctx, cfg, locator); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// loadDefaultConfig loads the config file at the default path (CONFIG_PATH), or
+// builds it from the flags of the command line and the environment variables
+// when running without a config file.
+func loadDefaultConfig() (*config.Config, error) {
+	return config.LoadConfigFromDefaultPathOrFlags(os.Args[1:])
+}
+
+// configLoader returns a loader for config reloads, which resolves the location as on startup.
+func configLoader(logger *log.Entry) func() (*config.Config, error) {
+	return func() (*config.Config, error) {
+		return loadConfig(context.Background(), logger)
+	}
+}
+
+// reloadConfigLoader wraps the config loader of the reloads, it resolves the names
+// of the lights configured without ID and lets the brightness cap of the client
+// follow the max_brightness of the reloaded config.
+func reloadConfigLoader(load func() (*config.Config, error), client *hueclient.Client) func() (*config.Config, error) {
+	return func() (*config.Config, error) {
+		cfg, err := load()
+		if err != nil {
+			return nil, err
+		}
+		if err := resolveLightNames(cfg, client.GetAllLights); err != nil {
+			return nil, err
+		}
+		client.SetMaxBrightness(maxBrightness(cfg))
+		return cfg, nil
+	}
+}
+
+// maxBrightness returns the max_brightness of the config, zero if not set.
+func maxBrightness(cfg *config.Config) float32 {
+	if cfg.Automation.MaxBrightness == nil {
+		return 0
 	}
+	return *cfg.Automation.MaxBrightness
+}
+
+// locationResolver resolves the location of a location source.
+type locationResolver interface {
+	Resolve(ctx context.Context, source geolocation.Source) (geolocation.Coordinates, error)
+}
+
+// resolveLocation populates the location of the config from its source if no
+// coordinates are configured.
+func resolveLocation(ctx context.Context, cfg *config.Config, resolver locationResolver) error {
+	if !cfg.LocationFromSource() {
+		return nil
+	}
+
+	coordinates, err := resolver.Resolve(ctx, cfg.Location.Source)
+	if err != nil {
+		return fmt.Errorf("failed to resolve location, configure latitude and longitude instead: %w", err)
+	}
+
+	cfg.Location.Latitude = coordinates.Latitude
+	cfg.Location.Longitude = coordinates.Longitude
+	return nil
+}
+
+// maxDiscoveryBackoff caps the doubling wait between the discovery retries.
+const maxDiscoveryBackoff = time.Minute
+
+// discoverBridgeWithRetry discovers the bridge and retries up to retries times if
+// the discovery fails, a negative value retries until the bridge is found. The wait
+// before a retry starts at backoff and doubles up to maxDiscoveryBackoff. It gives
+// up when ctx is done, e.g. because the service is stopped while waiting.
+func discoverBridgeWithRetry(ctx context.Context, discover func(ctx context.Context) (*hueclient.DiscoveredBridge, error), retries int, backoff time.Duration, logger *log.Entry) (*hueclient.DiscoveredBridge, error) {
+	for attempt := 0; ; attempt++ {
+		bridge, err := discover(ctx)
+		if err == nil {
+			return bridge, nil
+		}
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("%w: %w", err, ctx.Err())
+		}
+		if retries >= 0 && attempt >= retries {
+			return nil, err
+		}
+
+		logger.WithError(err).Warnf("No Hue Bridge found, retrying in %s", backoff)
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("%w: %w", err, ctx.Err())
+		}
+		backoff = min(2*backoff, maxDiscoveryBackoff)
+	}
+}
+
+// bridgeLocator returns the function which finds the bridge at startup, a configured
+// bridge IP skips the discovery.
+func bridgeLocator(cfg *config.Config, discoveryService *hueclient.BridgeDiscoveryService) func(ctx context.Context) (*hueclient.DiscoveredBridge, error) {
+	if cfg.Bridge.IP == "" {
+		return discoveryService.DiscoverFirstBridgeCtx
+	}
+	return func(ctx context.Context) (*hueclient.DiscoveredBridge, error) {
+		return discoveryService.BridgeAtIP(ctx, cfg.Bridge.IP)
+	}
+}
+
+// discoveryOptions returns the bridge discovery options of the config, the
+// configured network interface must exist.
+func discoveryOptions(cfg *config.Config) ([]hueclient.DiscoveryOption, error) {
+	opts := []hueclient.DiscoveryOption{hueclient.WithMDNSTimeout(cfg.Discovery.Timeout)}
+
+	if cfg.Discovery.Interface != "" {
+		if err := hueclient.ValidateNetworkInterface(cfg.Discovery.Interface); err != nil {
+			return nil, err
+		}
+		opts = append(opts, hueclient.WithMDNSInterface(cfg.Discovery.Interface))
+	}
+
+	if cfg.Discovery.Subnet != "" {
+		_, subnet, err := net.ParseCIDR(cfg.Discovery.Subnet)
+		if err != nil {
+			return nil, fmt.Errorf("invalid discovery subnet %q: %w", cfg.Discovery.Subnet, err)
+		}
+		opts = append(opts, hueclient.WithMDNSSubnet(subnet))
+	}
+
+	if cfg.Discovery.DisableCloud || hueclient.CloudDiscoveryDisabled() {
+		opts = append(opts, hueclient.WithoutCloudDiscovery())
+	}
+
+	return opts, nil
 }
@@ -0,0 +1,279 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"com.github.yveskaufmann/hue-lighter/internal/config"
+	"com.github.yveskaufmann/hue-lighter/internal/geolocation"
+	hueclient "com.github.yveskaufmann/hue-lighter/internal/hue_client"
+	"com.github.yveskaufmann/hue-lighter/internal/logging"
+	"com.github.yveskaufmann/hue-lighter/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBootstrap_ReturnsErrors(t *testing.T) {
+	validConfigPath := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(validConfigPath, []byte(testutils.ValidHueConfigYAML()), 0644))
+
+	tests := []struct {
+		name        string
+		env         map[string]string
+		expectedErr string
+	}{
+		{
+			name:        "missing config",
+			env:         map[string]string{"CONFIG_PATH": filepath.Join(t.TempDir(), "missing.yaml")},
+			expectedErr: "failed to load config: config file not found",
+		},
+		{
+			name: "unsupported API key store",
+			env: map[string]string{
+				"CONFIG_PATH":       validConfigPath,
+				"HUE_API_KEY_STORE": "redis",
+			},
+			expectedErr: "failed to create API key store",
+		},
+		{
+			name: "missing CA bundle",
+			env: map[string]string{
+				"CONFIG_PATH":            validConfigPath,
+				"HUE_API_KEY_STORE":      "memory",
+				"HUE_CA_CERTS_PATH":      filepath.Join(t.TempDir(), "missing.pem"),
+				"HUE_ALLOW_INSECURE_TLS": "false",
+			},
+			expectedErr: "CA bundle check failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				defer testutils.SetEnv(t, key, value)()
+			}
+
+			app, err := Bootstrap()
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedErr)
+			assert.Nil(t, app)
+		})
+	}
+}
+
+func TestDiscoverBridgeWithRetry(t *testing.T) {
+	bridge := &hueclient.DiscoveredBridge{ID: "ECB5FAFFFE123456", IP: "192.168.1.2"}
+
+	// failingDiscovery fails the given number of times before it finds the bridge.
+	failingDiscovery := func(failures int, attempts *int) func(ctx context.Context) (*hueclient.DiscoveredBridge, error) {
+		return func(ctx context.Context) (*hueclient.DiscoveredBridge, error) {
+			*attempts++
+			if *attempts <= failures {
+				return nil, hueclient.ErrNoBridgesFound
+			}
+			return bridge, nil
+		}
+	}
+
+	tests := []struct {
+		name             string
+		failures         int
+		retries          int
+		wantErr          bool
+		expectedAttempts int
+	}{
+		{name: "finds bridge after two failures", failures: 2, retries: 5, expectedAttempts: 3},
+		{name: "retries until found without bound", failures: 7, retries: -1, expectedAttempts: 8},
+		{name: "gives up after the retries", failures: 5, retries: 2, wantErr: true, expectedAttempts: 3},
+		{name: "does not retry if retries are disabled", failures: 1, retries: 0, wantErr: true, expectedAttempts: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+
+			found, err := discoverBridgeWithRetry(context.Background(), failingDiscovery(tt.failures, &attempts), tt.retries, time.Millisecond, logging.NewDiscardLogger())
+
+			if tt.wantErr {
+				assert.ErrorIs(t, err, hueclient.ErrNoBridgesFound)
+				assert.Nil(t, found)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, bridge, found)
+			}
+			assert.Equal(t, tt.expectedAttempts, attempts)
+		})
+	}
+
+	t.Run("stops waiting when the context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		attempts := 0
+		discover := func(ctx context.Context) (*hueclient.DiscoveredBridge, error) {
+			attempts++
+			cancel()
+			return nil, hueclient.ErrNoBridgesFound
+		}
+
+		_, err := discoverBridgeWithRetry(ctx, discover, -1, time.Hour, logging.NewDiscardLogger())
+
+		assert.ErrorIs(t, err, hueclient.ErrNoBridgesFound)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 1, attempts)
+	})
+}
+
+func TestBootstrapOptions_DiscoveryRetries(t *testing.T) {
+	cfg := config.Defaults()
+
+	assert.Equal(t, config.DefaultDiscoveryRetries, (&bootstrapOptions{}).discoveryRetries(cfg))
+
+	options := &bootstrapOptions{}
+	WithoutDiscoveryRetries()(options)
+	assert.Equal(t, 0, options.discoveryRetries(cfg))
+}
+
+func TestBridgeLocator(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"name": "Hue Bridge", "bridgeid": "ECB5FAFFFE123456"}`))
+	}))
+	defer server.Close()
+
+	cfg := config.Defaults()
+	cfg.Bridge.IP = server.Listener.Addr().String()
+
+	bridge, err := bridgeLocator(cfg, hueclient.NewBridgeDiscoveryService(logging.NewDiscardLogger()))(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, "ECB5FAFFFE123456", bridge.ID)
+	assert.Equal(t, server.Listener.Addr().String(), bridge.IP)
+}
+
+func TestDiscoveryOptions(t *testing.T) {
+	defer testutils.SetEnv(t, "HUE_DISABLE_CLOUD_DISCOVERY", "")()
+
+	t.Run("defaults to timeout only", func(t *testing.T) {
+		opts, err := discoveryOptions(config.Defaults())
+
+		require.NoError(t, err)
+		assert.Len(t, opts, 1)
+	})
+
+	t.Run("adds interface and subnet", func(t *testing.T) {
+		interfaces, err := net.Interfaces()
+		require.NoError(t, err)
+		require.NotEmpty(t, interfaces)
+
+		cfg := config.Defaults()
+		cfg.Discovery.Interface = interfaces[0].Name
+		cfg.Discovery.Subnet = "192.168.1.0/24"
+
+		opts, err := discoveryOptions(cfg)
+
+		require.NoError(t, err)
+		assert.Len(t, opts, 3)
+	})
+
+	t.Run("fails for unknown interface", func(t *testing.T) {
+		cfg := config.Defaults()
+		cfg.Discovery.Interface = "does-not-exist0"
+
+		_, err := discoveryOptions(cfg)
+
+		assert.ErrorContains(t, err, `network interface "does-not-exist0" not found`)
+	})
+
+	t.Run("disables cloud discovery by config", func(t *testing.T) {
+		cfg := config.Defaults()
+		cfg.Discovery.DisableCloud = true
+
+		opts, err := discoveryOptions(cfg)
+
+		require.NoError(t, err)
+		assert.Len(t, opts, 2)
+	})
+
+	t.Run("disables cloud discovery by environment", func(t *testing.T) {
+		defer testutils.SetEnv(t, "HUE_DISABLE_CLOUD_DISCOVERY", "true")()
+
+		opts, err := discoveryOptions(config.Defaults())
+
+		require.NoError(t, err)
+		assert.Len(t, opts, 2)
+	})
+}
+
+type fakeLocationResolver struct {
+	coordinates geolocation.Coordinates
+	err         error
+	calls       int
+}
+
+func (f *fakeLocationResolver) Resolve(ctx context.Context, source geolocation.Source) (geolocation.Coordinates, error) {
+	f.calls++
+	return f.coordinates, f.err
+}
+
+func TestResolveLocation(t *testing.T) {
+	t.Run("populates empty location from source", func(t *testing.T) {
+		cfg := config.Defaults()
+		cfg.Location.Source = geolocation.SourceIP
+		resolver := &fakeLocationResolver{coordinates: geolocation.Coordinates{Latitude: 52.52, Longitude: 13.405}}
+
+		require.NoError(t, resolveLocation(context.Background(), cfg, resolver))
+
+		assert.Equal(t, 52.52, cfg.Location.Latitude)
+		assert.Equal(t, 13.405, cfg.Location.Longitude)
+	})
+
+	t.Run("keeps configured location", func(t *testing.T) {
+		cfg := config.Defaults()
+		cfg.Location.Source = geolocation.SourceIP
+		cfg.Location.Latitude, cfg.Location.Longitude = 48.14, 11.58
+		resolver := &fakeLocationResolver{}
+
+		require.NoError(t, resolveLocation(context.Background(), cfg, resolver))
+
+		assert.Equal(t, 48.14, cfg.Location.Latitude)
+		assert.Zero(t, resolver.calls)
+	})
+
+	t.Run("returns resolver error", func(t *testing.T) {
+		cfg := config.Defaults()
+		cfg.Location.Source = geolocation.SourceIP
+		resolver := &fakeLocationResolver{err: errors.New("network unreachable")}
+
+		err := resolveLocation(context.Background(), cfg, resolver)
+
+		assert.ErrorContains(t, err, "failed to resolve location")
+		assert.ErrorContains(t, err, "network unreachable")
+	})
+}
+
+func TestConfigLoader_ResolvesLocationFromCache(t *testing.T) {
+	dir := t.TempDir()
+	cachePath := filepath.Join(dir, "location.json")
+	require.NoError(t, os.WriteFile(cachePath, []byte(`{"latitude":48.14,"longitude":11.58}`), 0600))
+
+	configPath := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`location:
+  source: ip
+lights:
+  - id: "light-1"
+paths:
+  location_cache: `+cachePath), 0644))
+	defer testutils.SetEnv(t, "CONFIG_PATH", configPath)()
+
+	cfg, err := configLoader(logging.NewDiscardLogger())()
+
+	require.NoError(t, err)
+	assert.Equal(t, 48.14, cfg.Location.Latitude)
+	assert.Equal(t, 11.58, cfg.Location.Longitude)
+}
@@ -0,0 +1,33 @@
+package app
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type bridgeClock interface {
+	GetBridgeTime() (time.Time, error)
+}
+
+// checkClockDrift compares the host time now with the bridge clock and warns if
+// they differ by more than maxDrift. It returns the drift, positive if the host
+// clock is ahead of the bridge. A failing check is only logged since the
+// automation works without it.
+func checkClockDrift(bridge bridgeClock, now time.Time, maxDrift time.Duration, logger *log.Entry) time.Duration {
+	bridgeTime, err := bridge.GetBridgeTime()
+	if err != nil {
+		logger.WithError(err).Warn("Could not check the clock drift to the bridge")
+		return 0
+	}
+
+	drift := now.Sub(bridgeTime)
+	if drift.Abs() > maxDrift {
+		logger.Warnf("Host clock differs from the bridge clock by %s (max. %s), lights may switch at the wrong time. "+
+			"Check the time synchronization (NTP) of the host and the bridge", drift.Round(time.Second), maxDrift)
+	} else {
+		logger.Debugf("Host clock differs from the bridge clock by %s", drift.Round(time.Second))
+	}
+
+	return drift
+}
@@ -0,0 +1,74 @@
+package app
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeBridgeClock struct {
+	time time.Time
+	err  error
+}
+
+func (f fakeBridgeClock) GetBridgeTime() (time.Time, error) {
+	return f.time, f.err
+}
+
+func TestCheckClockDrift(t *testing.T) {
+	hostTime := time.Date(2024, 6, 21, 19, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		bridge        fakeBridgeClock
+		expectedDrift time.Duration
+		expectWarning string
+	}{
+		{
+			name:          "clocks in sync",
+			bridge:        fakeBridgeClock{time: hostTime.Add(-2 * time.Second)},
+			expectedDrift: 2 * time.Second,
+		},
+		{
+			name:          "host clock ahead of bridge",
+			bridge:        fakeBridgeClock{time: hostTime.Add(-5 * time.Minute)},
+			expectedDrift: 5 * time.Minute,
+			expectWarning: "Host clock differs from the bridge clock by 5m0s",
+		},
+		{
+			name:          "host clock behind bridge",
+			bridge:        fakeBridgeClock{time: hostTime.Add(90 * time.Second)},
+			expectedDrift: -90 * time.Second,
+			expectWarning: "Host clock differs from the bridge clock by -1m30s",
+		},
+		{
+			name:          "bridge time unavailable",
+			bridge:        fakeBridgeClock{err: errors.New("hue: get bridge time: unauthorized user")},
+			expectedDrift: 0,
+			expectWarning: "Could not check the clock drift to the bridge",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, hook := test.NewNullLogger()
+
+			drift := checkClockDrift(tt.bridge, hostTime, time.Minute, logger.WithField("test", tt.name))
+
+			assert.Equal(t, tt.expectedDrift, drift)
+			if tt.expectWarning == "" {
+				assert.Empty(t, hook.AllEntries())
+				return
+			}
+			entry := hook.LastEntry()
+			require.NotNil(t, entry)
+			assert.Equal(t, logrus.WarnLevel, entry.Level)
+			assert.Contains(t, entry.Message, tt.expectWarning)
+		})
+	}
+}
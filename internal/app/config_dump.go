@@ -0,0 +1,84 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"com.github.yveskaufmann/hue-lighter/internal/config"
+	hueclient "com.github.yveskaufmann/hue-lighter/internal/hue_client"
+	"com.github.yveskaufmann/hue-lighter/internal/logging"
+	"gopkg.in/yaml.v3"
+)
+
+// DumpConfig loads the config file at path, or if empty like the service at the
+// default path (CONFIG_PATH) or from the flags and environment variables, and
+// writes the effective config with defaults and environment overrides applied to
+// w, either as YAML or as JSON. The API key is redacted. It does not connect to a bridge.
+func DumpConfig(path string, w io.Writer, asJSON bool) error {
+	var cfg *config.Config
+	var err error
+	if path == "" {
+		cfg, err = loadDefaultConfig()
+	} else {
+		cfg, err = config.LoadConfig(path)
+	}
+	if err != nil {
+		return err
+	}
+
+	return writeConfigDump(w, effectiveConfig(cfg), asJSON)
+}
+
+// effectiveConfig returns a copy of cfg with the paths, the API key and the cloud
+// discovery the environment variables override, like the service resolves them on startup.
+func effectiveConfig(cfg *config.Config) *config.Config {
+	effective := *cfg
+
+	if path := os.Getenv("HUE_API_KEY_STORE_PATH"); path != "" {
+		effective.Paths.APIKeyStore = path
+	}
+	if path := os.Getenv("HUE_CA_CERTS_PATH"); path != "" {
+		effective.Paths.CABundle = path
+	}
+	if os.Getenv("HUE_CA_CERTS_PEM") != "" {
+		effective.Paths.CABundle = hueclient.InlineCABundlePath
+	}
+	if hueclient.CloudDiscoveryDisabled() {
+		effective.Discovery.DisableCloud = true
+	}
+	if hueclient.ProvidedAPIKey(cfg.Bridge.APIKey) != "" {
+		effective.Bridge.APIKey = logging.Redacted
+	}
+
+	return &effective
+}
+
+// writeConfigDump encodes the config as YAML, the JSON output is converted from it
+// so that both use the keys of the config file.
+func writeConfigDump(w io.Writer, cfg *config.Config, asJSON bool) error {
+	var data bytes.Buffer
+	encoder := yaml.NewEncoder(&data)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(cfg); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	if !asJSON {
+		_, err := w.Write(data.Bytes())
+		return err
+	}
+
+	var document map[string]any
+	if err := yaml.Unmarshal(data.Bytes(), &document); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	jsonEncoder := json.NewEncoder(w)
+	jsonEncoder.SetIndent("", "  ")
+	return jsonEncoder.Encode(document)
+}
@@ -0,0 +1,170 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"com.github.yveskaufmann/hue-lighter/internal/logging"
+	"com.github.yveskaufmann/hue-lighter/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const minimalDumpConfig = `location:
+  latitude: 52.52
+  longitude: 13.405
+lights:
+  - id: "light-1"
+`
+
+func writeDumpConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestDumpConfig_FillsInDefaults(t *testing.T) {
+	for _, key := range []string{"HUE_API_KEY", "HUE_API_KEY_STORE_PATH", "HUE_CA_CERTS_PATH", "HUE_CA_CERTS_PEM", "HUE_DISABLE_CLOUD_DISCOVERY"} {
+		defer testutils.SetEnv(t, key, "")()
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, DumpConfig(writeDumpConfig(t, minimalDumpConfig), &out, false))
+
+	assert.Equal(t, `meta:
+  version: ""
+  name: ""
+  description: ""
+  app_name: ""
+  append_hostname: false
+location:
+  latitude: 52.52
+  longitude: 13.405
+  source: ""
+  day_length_check:
+    mode: warn
+    min: 4h0m0s
+    max: 20h0m0s
+  timezone: ""
+lights:
+  - id: light-1
+    name: null
+    brightness: null
+    min_brightness: null
+    enabled: null
+    trigger: ""
+    on_time: null
+    color_temperature: null
+    color: null
+smart_scene:
+  id: ""
+color_temperature:
+  start_mirek: null
+  end_mirek: null
+  end_time: null
+brightness_schedule: []
+wake_up:
+  duration: 0s
+  brightness: 100
+  start_mirek: null
+  end_mirek: null
+  easing: linear
+shutdown:
+  grouped_light_id: null
+  delay: 0s
+  fade_duration: 0s
+  turn_off: all
+shutdown_scene: null
+automation:
+  tick_interval: 1s
+  light_state_refresh_interval: 5m0s
+  light_state_refresh_mode: poll
+  max_clock_drift: 0s
+  startup_jitter: 0s
+  sync_on_startup: false
+  off_time: null
+  reload_debounce: 500ms
+  unreachable_lights: skip
+  unreachable_light_retries: 2
+  duplicate_lights: error
+  max_brightness: null
+bridge:
+  light_api: v2
+  rediscovery_threshold: 3
+  log_body_limit: 256
+  reregister_on_unauthorized: false
+  api_key: ""
+  ip: ""
+discovery:
+  timeout: 15s
+  interface: ""
+  subnet: ""
+  retries: 5
+  retry_backoff: 2s
+  disable_cloud: false
+paths:
+  api_key_store: /var/lib/hue-lighter/api-keys.json
+  ca_bundle: /etc/hue-lighter/cacert_bundle.pem
+  location_cache: /var/lib/hue-lighter/location.json
+`, out.String())
+}
+
+func TestDumpConfig_AppliesEnvironmentOverrides(t *testing.T) {
+	defer testutils.SetEnv(t, "HUE_API_KEY", "secret-api-key")()
+	defer testutils.SetEnv(t, "HUE_API_KEY_STORE_PATH", "/tmp/api-keys.json")()
+	defer testutils.SetEnv(t, "HUE_CA_CERTS_PATH", "/tmp/cacert_bundle.pem")()
+	defer testutils.SetEnv(t, "HUE_CA_CERTS_PEM", "")()
+	defer testutils.SetEnv(t, "HUE_DISABLE_CLOUD_DISCOVERY", "true")()
+
+	var out bytes.Buffer
+	require.NoError(t, DumpConfig(writeDumpConfig(t, minimalDumpConfig), &out, true))
+
+	var dumped struct {
+		Bridge struct {
+			APIKey string `json:"api_key"`
+		} `json:"bridge"`
+		Paths struct {
+			APIKeyStore string `json:"api_key_store"`
+			CABundle    string `json:"ca_bundle"`
+		} `json:"paths"`
+		Automation struct {
+			TickInterval string `json:"tick_interval"`
+		} `json:"automation"`
+		Discovery struct {
+			DisableCloud bool `json:"disable_cloud"`
+		} `json:"discovery"`
+	}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &dumped))
+
+	assert.Equal(t, logging.Redacted, dumped.Bridge.APIKey)
+	assert.Equal(t, "/tmp/api-keys.json", dumped.Paths.APIKeyStore)
+	assert.Equal(t, "/tmp/cacert_bundle.pem", dumped.Paths.CABundle)
+	assert.Equal(t, "1s", dumped.Automation.TickInterval)
+	assert.True(t, dumped.Discovery.DisableCloud)
+	assert.NotContains(t, out.String(), "secret-api-key")
+}
+
+func TestDumpConfig_InvalidConfig(t *testing.T) {
+	var out bytes.Buffer
+	err := DumpConfig(writeDumpConfig(t, "location:\n  timezone: Mars/Olympus_Mons\n"), &out, false)
+
+	assert.ErrorContains(t, err, "location.timezone: unknown time zone Mars/Olympus_Mons")
+	assert.Empty(t, out.String())
+}
+
+func TestDumpConfig_WithoutConfigFileFromEnvironment(t *testing.T) {
+	defer testutils.SetEnv(t, "CONFIG_PATH", filepath.Join(t.TempDir(), "missing.yaml"))()
+	defer testutils.SetEnv(t, "HUE_LATITUDE", "52.52")()
+	defer testutils.SetEnv(t, "HUE_LONGITUDE", "13.405")()
+	defer testutils.SetEnv(t, "HUE_LIGHTS", "light-1")()
+
+	var out bytes.Buffer
+	require.NoError(t, DumpConfig("", &out, false))
+
+	assert.Contains(t, out.String(), "latitude: 52.52\n")
+	assert.Contains(t, out.String(), "- id: light-1\n")
+}
@@ -0,0 +1,277 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"com.github.yveskaufmann/hue-lighter/internal/config"
+	hueclient "com.github.yveskaufmann/hue-lighter/internal/hue_client"
+	"com.github.yveskaufmann/hue-lighter/internal/logging"
+)
+
+// DoctorCheck is the outcome of a single diagnostic check.
+type DoctorCheck struct {
+	Name string
+	// Detail describes the checked value, e.g. the resolved path
+	Detail string
+	Err    error
+	// Hint tells the user how to fix a failed check
+	Hint    string
+	Skipped bool
+}
+
+func (c DoctorCheck) Passed() bool {
+	return c.Err == nil && !c.Skipped
+}
+
+type lightLister interface {
+	GetAllLights() (*hueclient.LightList, error)
+}
+
+// doctor runs the diagnostic checks, its dependencies are functions so that
+// the checks can be tested without config files, CA bundle or bridge.
+type doctor struct {
+	loadConfig      func() (*config.Config, error)
+	resolveCABundle func(defaultPath string) (string, error)
+	insecureAllowed func() bool
+	discoverBridge  func(ctx context.Context, cfg *config.Config) (*hueclient.DiscoveredBridge, error)
+	newAPIKeyStore  func(cfg *config.Config) (hueclient.APIKeyStore, error)
+	newClient       func(cfg *config.Config, bridge *hueclient.DiscoveredBridge, store hueclient.APIKeyStore, certPath string) (lightLister, error)
+}
+
+func newDoctor() *doctor {
+	logger := logging.NewDiscardLogger()
+
+	return &doctor{
+		loadConfig:      loadDefaultConfig,
+		resolveCABundle: hueclient.ResolveCABundlePath,
+		insecureAllowed: hueclient.InsecureTLSAllowed,
+		discoverBridge: func(ctx context.Context, cfg *config.Config) (*hueclient.DiscoveredBridge, error) {
+			opts, err := discoveryOptions(cfg)
+			if err != nil {
+				return nil, err
+			}
+			discoveryService := hueclient.NewBridgeDiscoveryService(logger, opts...)
+			return bridgeLocator(cfg, discoveryService)(ctx)
+		},
+		newAPIKeyStore: func(cfg *config.Config) (hueclient.APIKeyStore, error) {
+			return hueclient.NewAPIKeyStore(logger, cfg.Paths.APIKeyStore)
+		},
+		newClient: func(cfg *config.Config, bridge *hueclient.DiscoveredBridge, store hueclient.APIKeyStore, certPath string) (lightLister, error) {
+			certPin, err := hueclient.ResolveCertificatePin(logger)
+			if err != nil {
+				return nil, fmt.Errorf("invalid bridge certificate fingerprint: %w", err)
+			}
+
+			tlsOptions := []hueclient.TLSOption{hueclient.WithCertificatePin(certPin)}
+			if certPath == "" {
+				tlsOptions = append(tlsOptions, hueclient.WithInsecureSkipCAVerification())
+			}
+
+			return hueclient.NewClient(cfg.DeviceName(), bridge.ID, bridge.IP, store, certPath, logger,
+				hueclient.WithTLSOptions(tlsOptions...),
+				hueclient.WithAppName(cfg.Meta.AppName),
+				hueclient.WithLightAPI(cfg.Bridge.LightAPI))
+		},
+	}
+}
+
+// RunDoctor checks the setup step by step, writes a pass/fail line with a
+// remediation hint for each check to w and reports whether all checks passed.
+func RunDoctor(ctx context.Context, w io.Writer) bool {
+	checks := newDoctor().run(ctx)
+	writeDoctorChecks(w, checks)
+
+	for _, check := range checks {
+		if !check.Passed() {
+			return false
+		}
+	}
+	return true
+}
+
+func (d *doctor) run(ctx context.Context) []DoctorCheck {
+	cfg, configCheck := d.checkConfig()
+	if cfg == nil {
+		return append([]DoctorCheck{configCheck}, skippedChecks("config", "CA bundle", "Bridge", "API key", "Lights")...)
+	}
+
+	certPath, caBundleCheck := d.checkCABundle(cfg)
+	bridge, bridgeCheck := d.checkBridge(ctx, cfg)
+	if bridge == nil {
+		return append([]DoctorCheck{configCheck, caBundleCheck, bridgeCheck}, skippedChecks("bridge", "API key", "Lights")...)
+	}
+
+	store, apiKeyCheck := d.checkAPIKey(cfg, bridge)
+	checks := []DoctorCheck{configCheck, caBundleCheck, bridgeCheck, apiKeyCheck}
+	if !caBundleCheck.Passed() || !apiKeyCheck.Passed() {
+		return append(checks, skippedChecks("CA bundle and API key", "Lights")...)
+	}
+
+	client, err := d.newClient(cfg, bridge, store, certPath)
+	if err != nil {
+		return append(checks, DoctorCheck{
+			Name: "Lights",
+			Err:  err,
+			Hint: "Check the CA bundle and HUE_BRIDGE_CERT_FINGERPRINT",
+		})
+	}
+
+	return append(checks, d.checkLights(client))
+}
+
+func skippedChecks(requires string, names ...string) []DoctorCheck {
+	var checks []DoctorCheck
+	for _, name := range names {
+		checks = append(checks, DoctorCheck{Name: name, Skipped: true, Detail: "requires " + requires})
+	}
+	return checks
+}
+
+func (d *doctor) checkConfig() (*config.Config, DoctorCheck) {
+	check := DoctorCheck{Name: "Config"}
+
+	cfg, err := d.loadConfig()
+	if err != nil {
+		check.Err = err
+		check.Hint = "Create the config from configs/config.example.yaml or point CONFIG_PATH to it, or set HUE_LATITUDE and HUE_LONGITUDE"
+		return nil, check
+	}
+
+	check.Detail = fmt.Sprintf("%d light(s) configured", len(cfg.Lights))
+	return cfg, check
+}
+
+// checkCABundle returns the resolved CA bundle path, it is empty if the bundle
+// is missing and insecure TLS is allowed.
+func (d *doctor) checkCABundle(cfg *config.Config) (string, DoctorCheck) {
+	check := DoctorCheck{Name: "CA bundle"}
+
+	certPath, err := d.resolveCABundle(cfg.Paths.CABundle)
+	if err != nil {
+		if d.insecureAllowed() {
+			check.Detail = "missing, connecting without CA verification because HUE_ALLOW_INSECURE_TLS is set (insecure)"
+			return "", check
+		}
+		check.Err = err
+		check.Hint = "Download the Philips Hue CA bundle, see README.md, or set HUE_CA_CERTS_PATH or HUE_CA_CERTS_PEM"
+		return "", check
+	}
+
+	check.Detail = certPath
+	return certPath, check
+}
+
+func (d *doctor) checkBridge(ctx context.Context, cfg *config.Config) (*hueclient.DiscoveredBridge, DoctorCheck) {
+	check := DoctorCheck{Name: "Bridge"}
+
+	bridge, err := d.discoverBridge(ctx, cfg)
+	if err != nil {
+		check.Err = err
+		check.Hint = "Make sure the bridge is powered on and in the same network, mDNS may be blocked by the firewall"
+		return nil, check
+	}
+
+	if bridge.FactoryNew {
+		check.Err = fmt.Errorf("%w: %s at %s", hueclient.ErrBridgeFactoryNew, bridge.ID, bridge.IP)
+		check.Hint = "Complete the setup of the bridge in the Philips Hue app, then run the doctor again"
+		return nil, check
+	}
+
+	check.Detail = fmt.Sprintf("%s at %s", bridge.ID, bridge.IP)
+	return bridge, check
+}
+
+func (d *doctor) checkAPIKey(cfg *config.Config, bridge *hueclient.DiscoveredBridge) (hueclient.APIKeyStore, DoctorCheck) {
+	check := DoctorCheck{Name: "API key"}
+
+	store, err := d.newAPIKeyStore(cfg)
+	if err != nil {
+		check.Err = err
+		check.Hint = "Check HUE_API_KEY_STORE and HUE_API_KEY_STORE_PATH"
+		return nil, check
+	}
+
+	identifier := fmt.Sprintf("%s#%s", bridge.ID, cfg.DeviceName())
+	if provided := hueclient.ProvidedAPIKey(cfg.Bridge.APIKey); provided != "" {
+		return d.checkProvidedAPIKey(check, store, identifier, provided, cfg.DeviceName())
+	}
+
+	if key, err := store.Get(identifier); err != nil || key == "" {
+		if err == nil {
+			err = hueclient.ErrMissingAPIKey
+		}
+		check.Err = err
+		check.Hint = "Start the service and press the link button on the bridge to register the device, or provide an API key with HUE_API_KEY"
+		return store, check
+	}
+
+	check.Detail = fmt.Sprintf("registered as %q", cfg.DeviceName())
+	return store, check
+}
+
+// checkProvidedAPIKey compares the provided API key with the stored one without
+// changing the store, the service stores the provided key when it starts. The
+// returned store holds the provided key for the lights check.
+func (d *doctor) checkProvidedAPIKey(check DoctorCheck, store hueclient.APIKeyStore, identifier string, provided string, deviceName string) (hueclient.APIKeyStore, DoctorCheck) {
+	stored, err := store.Get(identifier)
+	if err != nil && !errors.Is(err, hueclient.ErrMissingAPIKey) {
+		check.Err = err
+		check.Hint = "Check HUE_API_KEY_STORE and HUE_API_KEY_STORE_PATH"
+		return nil, check
+	}
+
+	switch stored {
+	case provided:
+		check.Detail = fmt.Sprintf("registered as %q with the provided API key", deviceName)
+		return store, check
+	case "":
+		check.Detail = fmt.Sprintf("provided API key for %q, it is stored when the service starts", deviceName)
+	default:
+		check.Detail = fmt.Sprintf("provided API key for %q differs from the stored one, it replaces it when the service starts", deviceName)
+	}
+
+	providedStore := hueclient.NewInMemoryAPIKeyStore(logging.NewDiscardLogger())
+	if err := providedStore.Set(identifier, provided); err != nil {
+		check.Err = err
+		return nil, check
+	}
+	return providedStore, check
+}
+
+func (d *doctor) checkLights(client lightLister) DoctorCheck {
+	check := DoctorCheck{Name: "Lights"}
+
+	lights, err := client.GetAllLights()
+	if err != nil {
+		check.Err = err
+		check.Hint = "The API key may have been revoked in the Hue app, remove it from the API key store and register again"
+		return check
+	}
+
+	if lights == nil {
+		check.Err = errors.New("bridge returned no lights")
+		return check
+	}
+
+	check.Detail = fmt.Sprintf("%d light(s) found", len(lights.Data))
+	return check
+}
+
+func writeDoctorChecks(w io.Writer, checks []DoctorCheck) {
+	for _, check := range checks {
+		switch {
+		case check.Skipped:
+			fmt.Fprintf(w, "[SKIP] %s: %s\n", check.Name, check.Detail)
+		case check.Err != nil:
+			fmt.Fprintf(w, "[FAIL] %s: %v\n", check.Name, check.Err)
+			if check.Hint != "" {
+				fmt.Fprintf(w, "       hint: %s\n", check.Hint)
+			}
+		default:
+			fmt.Fprintf(w, "[PASS] %s: %s\n", check.Name, check.Detail)
+		}
+	}
+}
@@ -0,0 +1,326 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"com.github.yveskaufmann/hue-lighter/internal/config"
+	hueclient "com.github.yveskaufmann/hue-lighter/internal/hue_client"
+	"com.github.yveskaufmann/hue-lighter/internal/logging"
+	"com.github.yveskaufmann/hue-lighter/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeLightLister struct {
+	lights *hueclient.LightList
+	err    error
+}
+
+func (f fakeLightLister) GetAllLights() (*hueclient.LightList, error) {
+	return f.lights, f.err
+}
+
+func newTestDoctorConfig() *config.Config {
+	cfg := config.Defaults()
+	cfg.Meta.Name = "test-device"
+	lightID := "light-1"
+	cfg.Lights = []config.LightConfig{{ID: &lightID}}
+	return cfg
+}
+
+// newTestDoctor creates a doctor whose checks all pass.
+func newTestDoctor(t *testing.T) *doctor {
+	t.Helper()
+
+	logger := logging.NewDiscardLogger()
+	bridge := &hueclient.DiscoveredBridge{ID: "ECB5FAFFFE123456", IP: "192.168.1.2"}
+
+	return &doctor{
+		loadConfig:      func() (*config.Config, error) { return newTestDoctorConfig(), nil },
+		resolveCABundle: func(defaultPath string) (string, error) { return defaultPath, nil },
+		insecureAllowed: func() bool { return false },
+		discoverBridge: func(ctx context.Context, cfg *config.Config) (*hueclient.DiscoveredBridge, error) {
+			return bridge, nil
+		},
+		newAPIKeyStore: func(cfg *config.Config) (hueclient.APIKeyStore, error) {
+			store := hueclient.NewInMemoryAPIKeyStore(logger)
+			store.Set("ECB5FAFFFE123456#test-device", "api-key")
+			return store, nil
+		},
+		newClient: func(cfg *config.Config, bridge *hueclient.DiscoveredBridge, store hueclient.APIKeyStore, certPath string) (lightLister, error) {
+			return fakeLightLister{lights: &hueclient.LightList{Data: []hueclient.LightListItem{{ID: "light-1"}}}}, nil
+		},
+	}
+}
+
+func TestDoctor_checkConfig(t *testing.T) {
+	t.Run("passes with valid config", func(t *testing.T) {
+		d := newTestDoctor(t)
+
+		cfg, check := d.checkConfig()
+
+		require.NotNil(t, cfg)
+		assert.True(t, check.Passed())
+		assert.Equal(t, "1 light(s) configured", check.Detail)
+	})
+
+	t.Run("fails with hint when config cannot be loaded", func(t *testing.T) {
+		d := newTestDoctor(t)
+		d.loadConfig = func() (*config.Config, error) { return nil, errors.New("config file not found") }
+
+		cfg, check := d.checkConfig()
+
+		assert.Nil(t, cfg)
+		assert.EqualError(t, check.Err, "config file not found")
+		assert.Contains(t, check.Hint, "CONFIG_PATH")
+	})
+}
+
+func TestDoctor_checkCABundle(t *testing.T) {
+	tests := []struct {
+		name             string
+		resolveErr       error
+		insecureAllowed  bool
+		expectedPassed   bool
+		expectedCertPath string
+		expectedDetail   string
+	}{
+		{
+			name:             "passes with resolved bundle",
+			expectedPassed:   true,
+			expectedCertPath: "/etc/hue-lighter/cacert_bundle.pem",
+			expectedDetail:   "/etc/hue-lighter/cacert_bundle.pem",
+		},
+		{
+			name:           "fails when bundle is missing",
+			resolveErr:     errors.New("CA bundle not found"),
+			expectedPassed: false,
+		},
+		{
+			name:            "passes with warning when bundle is missing and insecure TLS is allowed",
+			resolveErr:      errors.New("CA bundle not found"),
+			insecureAllowed: true,
+			expectedPassed:  true,
+			expectedDetail:  "missing, connecting without CA verification because HUE_ALLOW_INSECURE_TLS is set (insecure)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newTestDoctor(t)
+			d.resolveCABundle = func(defaultPath string) (string, error) {
+				if tt.resolveErr != nil {
+					return "", tt.resolveErr
+				}
+				return defaultPath, nil
+			}
+			d.insecureAllowed = func() bool { return tt.insecureAllowed }
+
+			certPath, check := d.checkCABundle(newTestDoctorConfig())
+
+			assert.Equal(t, tt.expectedPassed, check.Passed())
+			assert.Equal(t, tt.expectedCertPath, certPath)
+			if tt.expectedPassed {
+				assert.Equal(t, tt.expectedDetail, check.Detail)
+			} else {
+				assert.NotEmpty(t, check.Hint)
+			}
+		})
+	}
+}
+
+func TestDoctor_checkBridge(t *testing.T) {
+	t.Run("passes with discovered bridge", func(t *testing.T) {
+		bridge, check := newTestDoctor(t).checkBridge(context.Background(), newTestDoctorConfig())
+
+		require.NotNil(t, bridge)
+		assert.True(t, check.Passed())
+		assert.Equal(t, "ECB5FAFFFE123456 at 192.168.1.2", check.Detail)
+	})
+
+	t.Run("fails when no bridge is found", func(t *testing.T) {
+		d := newTestDoctor(t)
+		d.discoverBridge = func(ctx context.Context, cfg *config.Config) (*hueclient.DiscoveredBridge, error) {
+			return nil, errors.New("no Hue Bridges found")
+		}
+
+		bridge, check := d.checkBridge(context.Background(), newTestDoctorConfig())
+
+		assert.Nil(t, bridge)
+		assert.EqualError(t, check.Err, "no Hue Bridges found")
+		assert.NotEmpty(t, check.Hint)
+	})
+
+	t.Run("fails when bridge is factory-new", func(t *testing.T) {
+		d := newTestDoctor(t)
+		d.discoverBridge = func(ctx context.Context, cfg *config.Config) (*hueclient.DiscoveredBridge, error) {
+			return &hueclient.DiscoveredBridge{ID: "ECB5FAFFFE123456", IP: "192.168.1.2", FactoryNew: true}, nil
+		}
+
+		bridge, check := d.checkBridge(context.Background(), newTestDoctorConfig())
+
+		assert.Nil(t, bridge)
+		assert.ErrorIs(t, check.Err, hueclient.ErrBridgeFactoryNew)
+		assert.EqualError(t, check.Err, "bridge is factory-new: ECB5FAFFFE123456 at 192.168.1.2")
+		assert.Contains(t, check.Hint, "Hue app")
+	})
+}
+
+func TestDoctor_checkAPIKey(t *testing.T) {
+	bridge := &hueclient.DiscoveredBridge{ID: "ECB5FAFFFE123456", IP: "192.168.1.2"}
+
+	t.Run("passes with stored API key", func(t *testing.T) {
+		_, check := newTestDoctor(t).checkAPIKey(newTestDoctorConfig(), bridge)
+
+		assert.True(t, check.Passed())
+	})
+
+	t.Run("fails when API key is missing", func(t *testing.T) {
+		d := newTestDoctor(t)
+		d.newAPIKeyStore = func(cfg *config.Config) (hueclient.APIKeyStore, error) {
+			return hueclient.NewInMemoryAPIKeyStore(nil), nil
+		}
+
+		_, check := d.checkAPIKey(newTestDoctorConfig(), bridge)
+
+		assert.ErrorIs(t, check.Err, hueclient.ErrMissingAPIKey)
+		assert.Contains(t, check.Hint, "link button")
+	})
+
+	t.Run("passes with provided API key without storing it", func(t *testing.T) {
+		defer testutils.SetEnv(t, "HUE_API_KEY", "provided-key")()
+		d := newTestDoctor(t)
+		configuredStore := hueclient.NewInMemoryAPIKeyStore(nil)
+		d.newAPIKeyStore = func(cfg *config.Config) (hueclient.APIKeyStore, error) {
+			return configuredStore, nil
+		}
+
+		store, check := d.checkAPIKey(newTestDoctorConfig(), bridge)
+
+		assert.True(t, check.Passed())
+		assert.Contains(t, check.Detail, "it is stored when the service starts")
+		apiKey, err := store.Get("ECB5FAFFFE123456#test-device")
+		require.NoError(t, err)
+		assert.Equal(t, "provided-key", apiKey)
+		_, err = configuredStore.Get("ECB5FAFFFE123456#test-device")
+		assert.ErrorIs(t, err, hueclient.ErrMissingAPIKey, "the doctor must not change the API key store")
+	})
+
+	t.Run("compares provided API key with the stored one", func(t *testing.T) {
+		tests := []struct {
+			name           string
+			storedKey      string
+			expectedDetail string
+		}{
+			{name: "same key", storedKey: "provided-key", expectedDetail: `registered as "test-device" with the provided API key`},
+			{name: "different key", storedKey: "stored-key", expectedDetail: `provided API key for "test-device" differs from the stored one, it replaces it when the service starts`},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				defer testutils.SetEnv(t, "HUE_API_KEY", "provided-key")()
+				d := newTestDoctor(t)
+				configuredStore := hueclient.NewInMemoryAPIKeyStore(nil)
+				require.NoError(t, configuredStore.Set("ECB5FAFFFE123456#test-device", tt.storedKey))
+				d.newAPIKeyStore = func(cfg *config.Config) (hueclient.APIKeyStore, error) {
+					return configuredStore, nil
+				}
+
+				store, check := d.checkAPIKey(newTestDoctorConfig(), bridge)
+
+				assert.True(t, check.Passed())
+				assert.Equal(t, tt.expectedDetail, check.Detail)
+				apiKey, err := store.Get("ECB5FAFFFE123456#test-device")
+				require.NoError(t, err)
+				assert.Equal(t, "provided-key", apiKey)
+				storedKey, err := configuredStore.Get("ECB5FAFFFE123456#test-device")
+				require.NoError(t, err)
+				assert.Equal(t, tt.storedKey, storedKey)
+			})
+		}
+	})
+
+	t.Run("fails when API key store cannot be created", func(t *testing.T) {
+		d := newTestDoctor(t)
+		d.newAPIKeyStore = func(cfg *config.Config) (hueclient.APIKeyStore, error) {
+			return nil, errors.New("unsupported API key store type")
+		}
+
+		store, check := d.checkAPIKey(newTestDoctorConfig(), bridge)
+
+		assert.Nil(t, store)
+		assert.EqualError(t, check.Err, "unsupported API key store type")
+	})
+}
+
+func TestDoctor_checkLights(t *testing.T) {
+	t.Run("passes when lights can be listed", func(t *testing.T) {
+		check := newTestDoctor(t).checkLights(fakeLightLister{lights: &hueclient.LightList{Data: []hueclient.LightListItem{{ID: "light-1"}, {ID: "light-2"}}}})
+
+		assert.True(t, check.Passed())
+		assert.Equal(t, "2 light(s) found", check.Detail)
+	})
+
+	t.Run("fails when lights cannot be listed", func(t *testing.T) {
+		check := newTestDoctor(t).checkLights(fakeLightLister{err: errors.New("hue: get lights: unauthorized user")})
+
+		assert.EqualError(t, check.Err, "hue: get lights: unauthorized user")
+		assert.NotEmpty(t, check.Hint)
+	})
+}
+
+func TestDoctor_run(t *testing.T) {
+	t.Run("all checks pass", func(t *testing.T) {
+		checks := newTestDoctor(t).run(context.Background())
+
+		require.Len(t, checks, 5)
+		for _, check := range checks {
+			assert.True(t, check.Passed(), check.Name)
+		}
+	})
+
+	t.Run("skips remaining checks when config fails", func(t *testing.T) {
+		d := newTestDoctor(t)
+		d.loadConfig = func() (*config.Config, error) { return nil, errors.New("invalid config") }
+
+		checks := d.run(context.Background())
+
+		require.Len(t, checks, 5)
+		assert.Error(t, checks[0].Err)
+		for _, check := range checks[1:] {
+			assert.True(t, check.Skipped, check.Name)
+		}
+	})
+
+	t.Run("skips lights check when API key is missing", func(t *testing.T) {
+		d := newTestDoctor(t)
+		d.newAPIKeyStore = func(cfg *config.Config) (hueclient.APIKeyStore, error) {
+			return hueclient.NewInMemoryAPIKeyStore(nil), nil
+		}
+
+		checks := d.run(context.Background())
+
+		require.Len(t, checks, 5)
+		assert.Equal(t, "API key", checks[3].Name)
+		assert.Error(t, checks[3].Err)
+		assert.True(t, checks[4].Skipped)
+	})
+}
+
+func TestWriteDoctorChecks(t *testing.T) {
+	var out bytes.Buffer
+
+	writeDoctorChecks(&out, []DoctorCheck{
+		{Name: "Config", Detail: "1 light(s) configured"},
+		{Name: "Bridge", Err: errors.New("no Hue Bridges found"), Hint: "check the network"},
+		{Name: "Lights", Skipped: true, Detail: "requires bridge"},
+	})
+
+	assert.Equal(t, "[PASS] Config: 1 light(s) configured\n"+
+		"[FAIL] Bridge: no Hue Bridges found\n"+
+		"       hint: check the network\n"+
+		"[SKIP] Lights: requires bridge\n", out.String())
+}
@@ -0,0 +1,65 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"com.github.yveskaufmann/hue-lighter/internal/config"
+	hueclient "com.github.yveskaufmann/hue-lighter/internal/hue_client"
+)
+
+// ExportConfig writes a starter config to w which lists all lights of the bridge,
+// the lights are commented out and the coordinates are placeholders.
+func (a *App) ExportConfig(w io.Writer) error {
+	lights, err := a.client.GetAllLights()
+	if err != nil {
+		return fmt.Errorf("failed to list lights: %w", err)
+	}
+
+	return writeStarterConfig(w, a.config, a.client.BridgeID(), lights)
+}
+
+// starterLights returns the lights as light configs sorted by name.
+func starterLights(lights *hueclient.LightList) []config.LightConfig {
+	configs := []config.LightConfig{}
+	for _, light := range lights.Data {
+		id, name := light.ID, light.Meta.Name
+		configs = append(configs, config.LightConfig{ID: &id, Name: &name})
+	}
+
+	sort.SliceStable(configs, func(i, j int) bool {
+		return *configs[i].Name < *configs[j].Name
+	})
+	return configs
+}
+
+// writeStarterConfig keeps the meta section of cfg, so that the exported config
+// uses the API key under which the device is registered.
+func writeStarterConfig(w io.Writer, cfg *config.Config, bridgeID string, lights *hueclient.LightList) error {
+	var b strings.Builder
+	b.WriteString("meta:\n")
+	b.WriteString("  version: 1\n")
+	fmt.Fprintf(&b, "  name: %s\n", strconv.Quote(cfg.Meta.Name))
+	if cfg.Meta.AppName != "" {
+		fmt.Fprintf(&b, "  app_name: %s\n", strconv.Quote(cfg.Meta.AppName))
+	}
+	if cfg.Meta.AppendHostname {
+		b.WriteString("  append_hostname: true\n")
+	}
+	b.WriteString("location:\n")
+	b.WriteString("  # Replace with your coordinates, or remove them and set source: ip\n")
+	b.WriteString("  latitude: 0.0\n")
+	b.WriteString("  longitude: 0.0\n")
+	b.WriteString("lights:\n")
+	fmt.Fprintf(&b, "  # Uncomment the lights to automate, exported from bridge %s.\n", bridgeID)
+	for _, light := range starterLights(lights) {
+		fmt.Fprintf(&b, "  # - id: %s\n", strconv.Quote(*light.ID))
+		fmt.Fprintf(&b, "  #   name: %s\n", strconv.Quote(*light.Name))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
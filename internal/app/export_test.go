@@ -0,0 +1,69 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"com.github.yveskaufmann/hue-lighter/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteStarterConfig(t *testing.T) {
+	cfg := config.Defaults()
+	cfg.Meta.Name = "Hue Lighter Automation"
+
+	var out bytes.Buffer
+	require.NoError(t, writeStarterConfig(&out, cfg, "ECB5FAFFFE123456", newTestLightList()))
+
+	assert.Equal(t, `meta:
+  version: 1
+  name: "Hue Lighter Automation"
+location:
+  # Replace with your coordinates, or remove them and set source: ip
+  latitude: 0.0
+  longitude: 0.0
+lights:
+  # Uncomment the lights to automate, exported from bridge ECB5FAFFFE123456.
+  # - id: "zzzzzzzz-zzzz-zzzz-zzzz-zzzzzzzzzzzz"
+  #   name: "Hallway Plug"
+  # - id: "xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx"
+  #   name: "Office Hue Play Left"
+  # - id: "yyyyyyyy-yyyy-yyyy-yyyy-yyyyyyyyyyyy"
+  #   name: "Office Hue Play Right"
+`, out.String())
+}
+
+func TestWriteStarterConfig_LoadsAfterUncommenting(t *testing.T) {
+	cfg := config.Defaults()
+	cfg.Meta.Name = "office"
+	cfg.Meta.AppName = "hue-lighter-office"
+
+	var out bytes.Buffer
+	require.NoError(t, writeStarterConfig(&out, cfg, "ECB5FAFFFE123456", newTestLightList()))
+
+	uncommented := strings.NewReplacer("  # - ", "  - ", "  #   ", "    ").Replace(out.String())
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(uncommented), 0644))
+
+	loaded, err := config.LoadConfig(configPath)
+
+	require.NoError(t, err)
+	assert.Equal(t, "office", loaded.Meta.Name)
+	assert.Equal(t, "hue-lighter-office", loaded.Meta.AppName)
+	assert.Equal(t, starterLights(newTestLightList()), loaded.Lights)
+}
+
+func TestWriteStarterConfig_KeepsAppendHostname(t *testing.T) {
+	cfg := config.Defaults()
+	cfg.Meta.Name = "office"
+	cfg.Meta.AppendHostname = true
+
+	var out bytes.Buffer
+	require.NoError(t, writeStarterConfig(&out, cfg, "ECB5FAFFFE123456", newTestLightList()))
+
+	assert.Contains(t, out.String(), "meta:\n  version: 1\n  name: \"office\"\n  append_hostname: true\nlocation:\n")
+}
@@ -0,0 +1,113 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	hueclient "com.github.yveskaufmann/hue-lighter/internal/hue_client"
+	"com.github.yveskaufmann/hue-lighter/internal/logging"
+)
+
+// Identity is a device registered at a bridge whose API key is stored.
+type Identity struct {
+	// ID is the identifier in the API key store, "bridgeID#deviceName"
+	ID       string `json:"id"`
+	BridgeID string `json:"bridge_id"`
+	Device   string `json:"device"`
+}
+
+// PrintIdentities lists the identities of the configured API key store and
+// writes them to w, either as aligned table or as JSON. The API keys are not printed.
+func PrintIdentities(w io.Writer, asJSON bool) error {
+	store, err := newConfiguredAPIKeyStore()
+	if err != nil {
+		return err
+	}
+
+	identities, err := listIdentities(store)
+	if err != nil {
+		return err
+	}
+	return writeIdentities(w, identities, asJSON)
+}
+
+// RemoveIdentity removes the API key of the identity "bridgeID#deviceName"
+// from the configured API key store. The device stays registered at the bridge,
+// it can be removed there in the Hue app.
+func RemoveIdentity(w io.Writer, identity string) error {
+	store, err := newConfiguredAPIKeyStore()
+	if err != nil {
+		return err
+	}
+
+	if err := removeIdentity(store, identity); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Removed API key of %s\n", identity)
+	return nil
+}
+
+// newConfiguredAPIKeyStore creates the API key store of the config, it does not
+// require a bridge.
+func newConfiguredAPIKeyStore() (hueclient.APIKeyStore, error) {
+	cfg, err := loadDefaultConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	store, err := hueclient.NewAPIKeyStore(logging.NewDiscardLogger(), cfg.Paths.APIKeyStore)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create API key store: %w", err)
+	}
+	return store, nil
+}
+
+func listIdentities(store hueclient.APIKeyStore) ([]Identity, error) {
+	ids, err := store.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list identities: %w", err)
+	}
+
+	identities := []Identity{}
+	for _, id := range ids {
+		bridgeID, device, _ := strings.Cut(id, "#")
+		identities = append(identities, Identity{ID: id, BridgeID: bridgeID, Device: device})
+	}
+	return identities, nil
+}
+
+// removeIdentity fails for unknown identities, so that a typo does not go unnoticed.
+func removeIdentity(store hueclient.APIKeyStore, identity string) error {
+	ids, err := store.List()
+	if err != nil {
+		return fmt.Errorf("failed to list identities: %w", err)
+	}
+	if !slices.Contains(ids, identity) {
+		return fmt.Errorf("identity %q not found, list the stored identities with 'hue-lighter identities'", identity)
+	}
+
+	if err := store.Remove(identity); err != nil {
+		return fmt.Errorf("failed to remove identity %q: %w", identity, err)
+	}
+	return nil
+}
+
+func writeIdentities(w io.Writer, identities []Identity, asJSON bool) error {
+	if asJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(identities)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "BRIDGE\tDEVICE")
+	for _, identity := range identities {
+		fmt.Fprintf(tw, "%s\t%s\n", identity.BridgeID, identity.Device)
+	}
+
+	return tw.Flush()
+}
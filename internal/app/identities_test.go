@@ -0,0 +1,103 @@
+package app
+
+import (
+	"bytes"
+	"testing"
+
+	hueclient "com.github.yveskaufmann/hue-lighter/internal/hue_client"
+	"com.github.yveskaufmann/hue-lighter/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestIdentityStore(t *testing.T) hueclient.APIKeyStore {
+	t.Helper()
+
+	store := hueclient.NewInMemoryAPIKeyStore(logging.NewDiscardLogger())
+	require.NoError(t, store.Set("ECB5FAFFFE123456#office-pc", "api-key-1"))
+	require.NoError(t, store.Set("ECB5FAFFFE123456#living-room", "api-key-2"))
+	return store
+}
+
+func TestWriteIdentities(t *testing.T) {
+	tests := []struct {
+		name     string
+		asJSON   bool
+		expected string
+	}{
+		{
+			name: "aligned table sorted by identity",
+			expected: "BRIDGE            DEVICE\n" +
+				"ECB5FAFFFE123456  living-room\n" +
+				"ECB5FAFFFE123456  office-pc\n",
+		},
+		{
+			name:   "json without API keys",
+			asJSON: true,
+			expected: `[
+  {
+    "id": "ECB5FAFFFE123456#living-room",
+    "bridge_id": "ECB5FAFFFE123456",
+    "device": "living-room"
+  },
+  {
+    "id": "ECB5FAFFFE123456#office-pc",
+    "bridge_id": "ECB5FAFFFE123456",
+    "device": "office-pc"
+  }
+]
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identities, err := listIdentities(newTestIdentityStore(t))
+			require.NoError(t, err)
+
+			var out bytes.Buffer
+			require.NoError(t, writeIdentities(&out, identities, tt.asJSON))
+
+			assert.Equal(t, tt.expected, out.String())
+			assert.NotContains(t, out.String(), "api-key")
+		})
+	}
+}
+
+func TestRemoveIdentity(t *testing.T) {
+	tests := []struct {
+		name               string
+		identity           string
+		expectedErr        string
+		expectedIdentities []string
+	}{
+		{
+			name:               "removes stored identity",
+			identity:           "ECB5FAFFFE123456#living-room",
+			expectedIdentities: []string{"ECB5FAFFFE123456#office-pc"},
+		},
+		{
+			name:               "fails for unknown identity",
+			identity:           "ECB5FAFFFE123456#kitchen",
+			expectedErr:        `identity "ECB5FAFFFE123456#kitchen" not found, list the stored identities with 'hue-lighter identities'`,
+			expectedIdentities: []string{"ECB5FAFFFE123456#living-room", "ECB5FAFFFE123456#office-pc"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newTestIdentityStore(t)
+
+			err := removeIdentity(store, tt.identity)
+
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			identities, err := store.List()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedIdentities, identities)
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	hueclient "com.github.yveskaufmann/hue-lighter/internal/hue_client"
+	log "github.com/sirupsen/logrus"
+)
+
+// streamReconnectBackoff is the first wait before the event stream is reconnected.
+const streamReconnectBackoff = time.Second
+
+// maxStreamReconnectBackoff caps the doubling wait between the reconnects.
+const maxStreamReconnectBackoff = time.Minute
+
+// streamLightStates passes the events of the bridge event stream to handle until
+// ctx is done. A closed stream is reconnected after a wait which starts at backoff
+// and doubles up to maxStreamReconnectBackoff, it is reset once an event arrived.
+func streamLightStates(ctx context.Context, stream func(ctx context.Context, handle func(*hueclient.StreamEvent)) error, handle func(*hueclient.StreamEvent), backoff time.Duration, logger *log.Entry) {
+	wait := backoff
+	for {
+		received := false
+		err := stream(ctx, func(event *hueclient.StreamEvent) {
+			received = true
+			handle(event)
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		if received {
+			wait = backoff
+		}
+
+		logger.WithError(err).Warnf("Event stream of the bridge closed, reconnecting in %s", wait)
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+		wait = min(2*wait, maxStreamReconnectBackoff)
+	}
+}
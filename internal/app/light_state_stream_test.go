@@ -0,0 +1,64 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	hueclient "com.github.yveskaufmann/hue-lighter/internal/hue_client"
+	"com.github.yveskaufmann/hue-lighter/internal/logging"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamLightStates(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	connects := 0
+	stream := func(ctx context.Context, handle func(*hueclient.StreamEvent)) error {
+		connects++
+		if connects < 3 {
+			return errors.New("connection closed by the bridge")
+		}
+		handle(&hueclient.StreamEvent{ID: "event-1"})
+		cancel()
+		return ctx.Err()
+	}
+
+	var received []string
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		streamLightStates(ctx, stream, func(event *hueclient.StreamEvent) {
+			received = append(received, event.ID)
+		}, time.Millisecond, logging.NewDiscardLogger())
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("streamLightStates did not return after the context was cancelled")
+	}
+	assert.Equal(t, 3, connects)
+	assert.Equal(t, []string{"event-1"}, received)
+}
+
+func TestStreamLightStates_StopsWhileWaiting(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		streamLightStates(ctx, func(ctx context.Context, handle func(*hueclient.StreamEvent)) error {
+			return errors.New("bridge unreachable")
+		}, func(*hueclient.StreamEvent) {}, time.Hour, logging.NewDiscardLogger())
+	}()
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("streamLightStates did not return after the context was cancelled")
+	}
+}
@@ -0,0 +1,103 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"com.github.yveskaufmann/hue-lighter/internal/config"
+	hueclient "com.github.yveskaufmann/hue-lighter/internal/hue_client"
+)
+
+// LightSummary describes a light of the bridge, it helps to find the light IDs for the config.
+type LightSummary struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	On   bool   `json:"on"`
+	// Brightness in percent, nil if the light is not dimmable
+	Brightness *float32 `json:"brightness,omitempty"`
+}
+
+// PrintLights lists all lights of the bridge and writes them to w, either as
+// aligned table or as JSON.
+func (a *App) PrintLights(w io.Writer, asJSON bool) error {
+	lights, err := a.client.GetAllLights()
+	if err != nil {
+		return fmt.Errorf("failed to list lights: %w", err)
+	}
+
+	return writeLights(w, summarizeLights(lights), asJSON)
+}
+
+// summarizeLights returns the lights sorted by name.
+func summarizeLights(lights *hueclient.LightList) []LightSummary {
+	summaries := []LightSummary{}
+	for _, light := range lights.Data {
+		summary := LightSummary{ID: light.ID, Name: light.Meta.Name, On: light.On.On}
+		if light.Dimming != nil {
+			brightness := light.Dimming.Brightness
+			summary.Brightness = &brightness
+		}
+		summaries = append(summaries, summary)
+	}
+
+	sort.SliceStable(summaries, func(i, j int) bool {
+		return summaries[i].Name < summaries[j].Name
+	})
+	return summaries
+}
+
+func writeLights(w io.Writer, lights []LightSummary, asJSON bool) error {
+	if asJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(lights)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tNAME\tSTATE\tBRIGHTNESS")
+	for _, light := range lights {
+		state := "off"
+		if light.On {
+			state = "on"
+		}
+		brightness := "-"
+		if light.Brightness != nil {
+			brightness = fmt.Sprintf("%.0f%%", *light.Brightness)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", light.ID, light.Name, state, brightness)
+	}
+
+	return tw.Flush()
+}
+
+// resolveLightNames sets the ID of the configured lights which only have a name
+// to the light of the bridge with that name, compared case-insensitively. The
+// lights are only listed if a light has no ID. It fails if no light or more than
+// one light of the bridge has the name.
+func resolveLightNames(cfg *config.Config, listLights func() (*hueclient.LightList, error)) error {
+	var lights *hueclient.LightList
+	for i := range cfg.Lights {
+		lightCfg := &cfg.Lights[i]
+		if lightCfg.ID != nil {
+			continue
+		}
+
+		if lights == nil {
+			var err error
+			if lights, err = listLights(); err != nil {
+				return fmt.Errorf("failed to resolve the light names: %w", err)
+			}
+		}
+
+		light, ok := lights.FindByName(*lightCfg.Name)
+		if !ok {
+			return fmt.Errorf("no single light named %q found on the bridge, configure the id of the light instead", *lightCfg.Name)
+		}
+		id := light.ID
+		lightCfg.ID = &id
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package app
+
+import (
+	"bytes"
+	"testing"
+
+	"com.github.yveskaufmann/hue-lighter/internal/config"
+	hueclient "com.github.yveskaufmann/hue-lighter/internal/hue_client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLightList() *hueclient.LightList {
+	return &hueclient.LightList{Data: []hueclient.LightListItem{
+		{
+			ID:      "yyyyyyyy-yyyy-yyyy-yyyy-yyyyyyyyyyyy",
+			Meta:    hueclient.LightMeta{Name: "Office Hue Play Right"},
+			On:      hueclient.LightOnState{On: true},
+			Dimming: &hueclient.LightDimmingState{Brightness: 40.5},
+		},
+		{
+			ID:   "zzzzzzzz-zzzz-zzzz-zzzz-zzzzzzzzzzzz",
+			Meta: hueclient.LightMeta{Name: "Hallway Plug"},
+		},
+		{
+			ID:      "xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx",
+			Meta:    hueclient.LightMeta{Name: "Office Hue Play Left"},
+			Dimming: &hueclient.LightDimmingState{Brightness: 100},
+		},
+	}}
+}
+
+func TestWriteLights(t *testing.T) {
+	tests := []struct {
+		name     string
+		lights   *hueclient.LightList
+		asJSON   bool
+		expected string
+	}{
+		{
+			name:   "aligned table sorted by name",
+			lights: newTestLightList(),
+			expected: "ID                                    NAME                   STATE  BRIGHTNESS\n" +
+				"zzzzzzzz-zzzz-zzzz-zzzz-zzzzzzzzzzzz  Hallway Plug           off    -\n" +
+				"xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx  Office Hue Play Left   off    100%\n" +
+				"yyyyyyyy-yyyy-yyyy-yyyy-yyyyyyyyyyyy  Office Hue Play Right  on     40%\n",
+		},
+		{
+			name:     "table without lights",
+			lights:   &hueclient.LightList{},
+			expected: "ID  NAME  STATE  BRIGHTNESS\n",
+		},
+		{
+			name:   "json",
+			lights: newTestLightList(),
+			asJSON: true,
+			expected: `[
+				{"id": "zzzzzzzz-zzzz-zzzz-zzzz-zzzzzzzzzzzz", "name": "Hallway Plug", "on": false},
+				{"id": "xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx", "name": "Office Hue Play Left", "on": false, "brightness": 100},
+				{"id": "yyyyyyyy-yyyy-yyyy-yyyy-yyyyyyyyyyyy", "name": "Office Hue Play Right", "on": true, "brightness": 40.5}
+			]`,
+		},
+		{
+			name:     "json without lights",
+			lights:   &hueclient.LightList{},
+			asJSON:   true,
+			expected: `[]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+
+			err := writeLights(&out, summarizeLights(tt.lights), tt.asJSON)
+
+			require.NoError(t, err)
+			if tt.asJSON {
+				assert.JSONEq(t, tt.expected, out.String())
+			} else {
+				assert.Equal(t, tt.expected, out.String())
+			}
+		})
+	}
+}
+
+func TestResolveLightNames(t *testing.T) {
+	id := "zzzzzzzz-zzzz-zzzz-zzzz-zzzzzzzzzzzz"
+	name := func(n string) *string { return &n }
+
+	t.Run("resolves the lights configured by name", func(t *testing.T) {
+		cfg := &config.Config{Lights: []config.LightConfig{
+			{ID: &id},
+			{Name: name("office hue play left")},
+		}}
+
+		require.NoError(t, resolveLightNames(cfg, func() (*hueclient.LightList, error) { return newTestLightList(), nil }))
+
+		assert.Equal(t, id, *cfg.Lights[0].ID)
+		require.NotNil(t, cfg.Lights[1].ID)
+		assert.Equal(t, "xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx", *cfg.Lights[1].ID)
+	})
+
+	t.Run("does not list the lights if all have an ID", func(t *testing.T) {
+		cfg := &config.Config{Lights: []config.LightConfig{{ID: &id}}}
+
+		require.NoError(t, resolveLightNames(cfg, func() (*hueclient.LightList, error) {
+			t.Fatal("lights must not be listed")
+			return nil, nil
+		}))
+	})
+
+	t.Run("rejects an ambiguous name", func(t *testing.T) {
+		lights := newTestLightList()
+		lights.Data = append(lights.Data, hueclient.LightListItem{ID: "other", Meta: hueclient.LightMeta{Name: "Hallway Plug"}})
+		cfg := &config.Config{Lights: []config.LightConfig{{Name: name("Hallway Plug")}}}
+
+		err := resolveLightNames(cfg, func() (*hueclient.LightList, error) { return lights, nil })
+
+		assert.EqualError(t, err, `no single light named "Hallway Plug" found on the bridge, configure the id of the light instead`)
+		assert.Nil(t, cfg.Lights[0].ID)
+	})
+
+	t.Run("rejects an unknown name", func(t *testing.T) {
+		cfg := &config.Config{Lights: []config.LightConfig{{Name: name("Kitchen")}}}
+
+		err := resolveLightNames(cfg, func() (*hueclient.LightList, error) { return newTestLightList(), nil })
+
+		assert.ErrorContains(t, err, `no single light named "Kitchen" found on the bridge`)
+	})
+}
@@ -0,0 +1,173 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	hueclient "com.github.yveskaufmann/hue-lighter/internal/hue_client"
+)
+
+// RegistrationSummary describes an application registered at the bridge.
+type RegistrationSummary struct {
+	hueclient.Registration
+	// Stale registrations were created by this app on this host, but their API key
+	// is not stored anymore
+	Stale bool `json:"stale"`
+}
+
+// PrintRegistrations lists the applications registered at the bridge and writes
+// them to w, either as aligned table or as JSON. The API keys are not printed.
+func (a *App) PrintRegistrations(w io.Writer, asJSON bool) error {
+	summaries, err := a.registrationSummaries()
+	if err != nil {
+		return err
+	}
+	return writeRegistrations(w, summaries, asJSON)
+}
+
+// PruneRegistrations removes the stale registrations of this app from the bridge.
+// A failed removal does not stop the removal of the other registrations.
+func (a *App) PruneRegistrations(w io.Writer) error {
+	summaries, err := a.registrationSummaries()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	removed := 0
+	for _, summary := range summaries {
+		if !summary.Stale {
+			continue
+		}
+		if err := a.client.RemoveRegistration(summary.Registration); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		removed++
+		fmt.Fprintf(w, "Removed registration %s created %s\n", summary.Name, formatRegistrationDate(summary.Created))
+	}
+	fmt.Fprintf(w, "Removed %d stale registration(s)\n", removed)
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%w, remove the registrations in your Hue account at https://account.meethue.com/apps instead", errors.Join(errs...))
+	}
+	return nil
+}
+
+func (a *App) registrationSummaries() ([]RegistrationSummary, error) {
+	registrations, err := a.client.ListRegistrations()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list registrations: %w", err)
+	}
+
+	storedKeys, err := storedAPIKeys(a.apiKeyStore, a.client.BridgeID())
+	if err != nil {
+		return nil, err
+	}
+
+	deviceTypes, err := ownDeviceTypes(a.apiKeyStore, a.client.BridgeID(), a.config.Meta.AppName, a.client.DeviceName())
+	if err != nil {
+		return nil, err
+	}
+	return summarizeRegistrations(registrations, deviceTypes, storedKeys), nil
+}
+
+// ownDeviceTypes returns the devicetypes under which this host registers at the
+// bridge: the one of the configured device name and the ones of the devices
+// stored for the bridge. Other hosts register under other device names, e.g.
+// with meta.append_hostname, and their registrations must not be pruned.
+func ownDeviceTypes(store hueclient.APIKeyStore, bridgeID string, appName string, deviceName string) (map[string]bool, error) {
+	identities, err := store.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list identities: %w", err)
+	}
+
+	deviceNames := []string{deviceName}
+	for _, identity := range identities {
+		identityBridgeID, identityDevice, _ := strings.Cut(identity, "#")
+		if strings.EqualFold(identityBridgeID, bridgeID) {
+			deviceNames = append(deviceNames, identityDevice)
+		}
+	}
+
+	deviceTypes := make(map[string]bool)
+	for _, name := range deviceNames {
+		deviceType, err := hueclient.FormatDeviceType(appName, name)
+		if err != nil {
+			return nil, err
+		}
+		deviceTypes[deviceType] = true
+	}
+	return deviceTypes, nil
+}
+
+// storedAPIKeys returns the API keys stored for the devices of the bridge.
+func storedAPIKeys(store hueclient.APIKeyStore, bridgeID string) (map[string]bool, error) {
+	identities, err := store.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list identities: %w", err)
+	}
+
+	keys := make(map[string]bool)
+	for _, identity := range identities {
+		identityBridgeID, _, _ := strings.Cut(identity, "#")
+		// The discovery endpoint reports lower case IDs, the bridge config upper case ones
+		if !strings.EqualFold(identityBridgeID, bridgeID) {
+			continue
+		}
+		apiKey, err := store.Get(identity)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read API key of %s: %w", identity, err)
+		}
+		keys[apiKey] = true
+	}
+	return keys, nil
+}
+
+// summarizeRegistrations marks the registrations of this host's devicetypes whose
+// API key is not stored as stale, e.g. after the API key store was lost or a
+// device was removed.
+func summarizeRegistrations(registrations []hueclient.Registration, deviceTypes map[string]bool, storedKeys map[string]bool) []RegistrationSummary {
+	summaries := []RegistrationSummary{}
+	for _, registration := range registrations {
+		summaries = append(summaries, RegistrationSummary{
+			Registration: registration,
+			Stale:        deviceTypes[registration.Name] && !storedKeys[registration.Username],
+		})
+	}
+	return summaries
+}
+
+func writeRegistrations(w io.Writer, registrations []RegistrationSummary, asJSON bool) error {
+	if asJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(registrations)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tCREATED\tLAST USED\tSTALE")
+	for _, registration := range registrations {
+		stale := ""
+		if registration.Stale {
+			stale = "yes"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", registration.Name, formatRegistrationDate(registration.Created),
+			formatRegistrationDate(registration.LastUsed), stale)
+	}
+
+	return tw.Flush()
+}
+
+// formatRegistrationDate formats dates the bridge did not record as "-".
+func formatRegistrationDate(date time.Time) string {
+	if date.IsZero() {
+		return "-"
+	}
+	return date.Format(time.DateTime)
+}
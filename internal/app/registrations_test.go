@@ -0,0 +1,127 @@
+package app
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	hueclient "com.github.yveskaufmann/hue-lighter/internal/hue_client"
+	"com.github.yveskaufmann/hue-lighter/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRegistrations() []hueclient.Registration {
+	return []hueclient.Registration{
+		{Username: "app-key", Name: "Hue 5#iPhone", Created: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{Username: "lost-key", Name: "hue-lighter#office-pc", Created: time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC), LastUsed: time.Date(2023, 6, 1, 8, 0, 0, 0, time.UTC)},
+		{Username: "api-key-1", Name: "hue-lighter#office-pc", Created: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC), LastUsed: time.Date(2025, 1, 10, 17, 59, 0, 0, time.UTC)},
+		{Username: "other-app-key", Name: "hue-lighter-kitchen#pi", Created: time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)},
+		{Username: "other-host-key", Name: "hue-lighter#office-pc@kitchen", Created: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)},
+	}
+}
+
+func TestStoredAPIKeys(t *testing.T) {
+	store := newTestIdentityStore(t)
+	require.NoError(t, store.Set("OTHERBRIDGE00001#office-pc", "other-bridge-key"))
+
+	keys, err := storedAPIKeys(store, "ecb5fafffe123456")
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"api-key-1": true, "api-key-2": true}, keys)
+}
+
+func TestSummarizeRegistrations(t *testing.T) {
+	tests := []struct {
+		name          string
+		deviceTypes   map[string]bool
+		expectedStale []bool
+	}{
+		{
+			name:          "marks registrations of own devicetypes without stored API key as stale",
+			deviceTypes:   map[string]bool{"hue-lighter#office-pc": true},
+			expectedStale: []bool{false, true, false, false, false},
+		},
+		{
+			name:          "only considers registrations of own devicetypes",
+			deviceTypes:   map[string]bool{"hue-lighter-kitchen#pi": true},
+			expectedStale: []bool{false, false, false, true, false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summaries := summarizeRegistrations(newTestRegistrations(), tt.deviceTypes, map[string]bool{"api-key-1": true})
+
+			var stale []bool
+			for _, summary := range summaries {
+				stale = append(stale, summary.Stale)
+			}
+			assert.Equal(t, tt.expectedStale, stale)
+		})
+	}
+}
+
+func TestOwnDeviceTypes(t *testing.T) {
+	store := newTestIdentityStore(t)
+	require.NoError(t, store.Set("OTHERBRIDGE00001#garage", "other-bridge-key"))
+
+	deviceTypes, err := ownDeviceTypes(store, "ecb5fafffe123456", "", "office-pc@attic")
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{
+		"hue-lighter#office-pc@attic": true,
+		"hue-lighter#office-pc":       true,
+		"hue-lighter#living-room":     true,
+	}, deviceTypes)
+}
+
+func TestSummarizeRegistrations_KeepsRegistrationsOfOtherHosts(t *testing.T) {
+	// The key store of this host was lost, another host registered with its hostname appended
+	registrations := []hueclient.Registration{
+		{Username: "lost-key", Name: "hue-lighter#office-pc"},
+		{Username: "other-host-key", Name: "hue-lighter#office-pc@kitchen"},
+	}
+	deviceTypes, err := ownDeviceTypes(hueclient.NewInMemoryAPIKeyStore(logging.NewDiscardLogger()), "ECB5FAFFFE123456", "", "office-pc")
+	require.NoError(t, err)
+
+	summaries := summarizeRegistrations(registrations, deviceTypes, map[string]bool{})
+
+	require.Len(t, summaries, 2)
+	assert.True(t, summaries[0].Stale)
+	assert.False(t, summaries[1].Stale, "registration of another host must survive the prune")
+}
+
+func TestWriteRegistrations(t *testing.T) {
+	summaries := summarizeRegistrations(newTestRegistrations(), map[string]bool{"hue-lighter#office-pc": true}, map[string]bool{"api-key-1": true})
+
+	t.Run("aligned table", func(t *testing.T) {
+		var out bytes.Buffer
+
+		require.NoError(t, writeRegistrations(&out, summaries[:4], false))
+
+		assert.Equal(t, "NAME                    CREATED              LAST USED            STALE\n"+
+			"Hue 5#iPhone            2022-01-01 00:00:00  -                    \n"+
+			"hue-lighter#office-pc   2023-05-01 10:00:00  2023-06-01 08:00:00  yes\n"+
+			"hue-lighter#office-pc   2024-03-01 10:00:00  2025-01-10 17:59:00  \n"+
+			"hue-lighter-kitchen#pi  2024-04-01 10:00:00  -                    \n", out.String())
+	})
+
+	t.Run("json without API keys", func(t *testing.T) {
+		var out bytes.Buffer
+
+		require.NoError(t, writeRegistrations(&out, summaries[1:2], true))
+
+		assert.JSONEq(t, `[
+			{"name": "hue-lighter#office-pc", "created": "2023-05-01T10:00:00Z", "last_used": "2023-06-01T08:00:00Z", "stale": true}
+		]`, out.String())
+		assert.NotContains(t, out.String(), "lost-key")
+	})
+}
+
+func TestStoredAPIKeys_EmptyStore(t *testing.T) {
+	keys, err := storedAPIKeys(hueclient.NewInMemoryAPIKeyStore(logging.NewDiscardLogger()), "ECB5FAFFFE123456")
+
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+}
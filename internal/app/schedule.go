@@ -0,0 +1,41 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"com.github.yveskaufmann/hue-lighter/internal/services/light_automation"
+)
+
+// clockFormat is the format of the times of day in the schedule output.
+const clockFormat = "15:04"
+
+// formatPeriods formats the on periods of a light in loc as "HH:MM-HH:MM",
+// separated by commas, or "off" if the light stays off.
+func formatPeriods(periods []light_automation.OnPeriod, loc *time.Location) string {
+	if len(periods) == 0 {
+		return "off"
+	}
+
+	formatted := make([]string, 0, len(periods))
+	for _, period := range periods {
+		formatted = append(formatted, period.On.In(loc).Format(clockFormat)+"-"+period.Off.In(loc).Format(clockFormat))
+	}
+	return strings.Join(formatted, ", ")
+}
+
+// writeLightSchedules writes a line with the on periods in loc per light to w.
+func writeLightSchedules(w io.Writer, lights []light_automation.LightSchedule, loc *time.Location) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, light := range lights {
+		periods := formatPeriods(light.Periods, loc)
+		if light.Disabled {
+			periods = "disabled"
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\n", light.ID, light.Name, light.Trigger, periods)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,41 @@
+package app
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"com.github.yveskaufmann/hue-lighter/internal/services/light_automation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatPeriods(t *testing.T) {
+	at := func(day int, hour int, minute int) time.Time {
+		return time.Date(2024, 6, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	assert.Equal(t, "off", formatPeriods(nil, time.UTC))
+	assert.Equal(t, "19:33-02:43", formatPeriods([]light_automation.OnPeriod{{On: at(21, 19, 33), Off: at(22, 2, 43)}}, time.UTC))
+	assert.Equal(t, "19:33-23:00, 02:13-02:43", formatPeriods([]light_automation.OnPeriod{
+		{On: at(21, 19, 33), Off: at(21, 23, 0)},
+		{On: at(22, 2, 13), Off: at(22, 2, 43)},
+	}, time.UTC))
+	assert.Equal(t, "21:33-04:43", formatPeriods([]light_automation.OnPeriod{{On: at(21, 19, 33), Off: at(22, 2, 43)}}, time.FixedZone("CEST", 2*60*60)))
+}
+
+func TestWriteLightSchedules(t *testing.T) {
+	on := time.Date(2024, 6, 21, 19, 33, 0, 0, time.UTC)
+	off := time.Date(2024, 6, 22, 2, 43, 0, 0, time.UTC)
+
+	var out bytes.Buffer
+	require.NoError(t, writeLightSchedules(&out, []light_automation.LightSchedule{
+		{ID: "light-1", Name: "Desk", Trigger: "sun", Periods: []light_automation.OnPeriod{{On: on, Off: off}}},
+		{ID: "light-2", Name: "Hallway", Trigger: "time", Periods: []light_automation.OnPeriod{}},
+		{ID: "light-3", Name: "Lamp", Trigger: "sun", Periods: []light_automation.OnPeriod{}, Disabled: true},
+	}, time.UTC))
+
+	assert.Equal(t, "  light-1  Desk     sun   19:33-02:43\n"+
+		"  light-2  Hallway  time  off\n"+
+		"  light-3  Lamp     sun   disabled\n", out.String())
+}
@@ -0,0 +1,68 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"com.github.yveskaufmann/hue-lighter/internal/services/light_automation"
+)
+
+// PrintStatus requests the status of the running service and writes it to w,
+// either human readable or as JSON.
+func (a *App) PrintStatus(w io.Writer, asJSON bool) error {
+	status, err := a.eventService.RequestStatus()
+	if err != nil {
+		return fmt.Errorf("failed to request status: %w", err)
+	}
+
+	if asJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(status)
+	}
+
+	return writeStatus(w, status)
+}
+
+func writeStatus(w io.Writer, status *light_automation.Status) error {
+	period := "day"
+	if status.Night {
+		period = "night"
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Bridge:\t%s\n", status.BridgeID)
+	fmt.Fprintf(tw, "Period:\t%s\n", period)
+	if status.Paused {
+		fmt.Fprintln(tw, "Automation:\tpaused")
+	}
+	fmt.Fprintf(tw, "Next sunrise:\t%s\n", status.NextSunrise.Local().Format(time.DateTime))
+	fmt.Fprintf(tw, "Next sunset:\t%s\n", status.NextSunset.Local().Format(time.DateTime))
+	if status.Schedule.OffTime != nil {
+		fmt.Fprintf(tw, "Off time:\t%s\n", status.Schedule.OffTime.Local().Format(time.DateTime))
+	}
+	if status.Schedule.WakeUp != nil {
+		fmt.Fprintf(tw, "Wake-up:\t%s\n", status.Schedule.WakeUp.Local().Format(time.DateTime))
+	}
+	fmt.Fprintln(tw, "Lights:")
+	for i, light := range status.Lights {
+		state := "off"
+		if light.On {
+			state = "on"
+		}
+		if light.Disabled {
+			state += " (disabled)"
+		}
+		// The schedule lists the lights in the same order
+		var periods string
+		if i < len(status.Schedule.Lights) && !light.Disabled {
+			periods = "tonight " + formatPeriods(status.Schedule.Lights[i].Periods, time.Local)
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\n", light.ID, light.Name, state, periods)
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,153 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"com.github.yveskaufmann/hue-lighter/internal/config"
+	"com.github.yveskaufmann/hue-lighter/internal/services/light_automation"
+	"com.github.yveskaufmann/hue-lighter/internal/sunset"
+)
+
+// ValidateConfig loads and validates the config file at path, or if empty like the
+// service at the default path (CONFIG_PATH) or from the flags and environment
+// variables, and writes a summary with sunrise and sunset on the day of now and
+// the schedule of the lights to w. It does not connect to a bridge.
+func ValidateConfig(path string, now time.Time, w io.Writer) error {
+	var cfg *config.Config
+	var err error
+	if path == "" {
+		cfg, err = loadDefaultConfig()
+	} else {
+		cfg, err = config.LoadConfig(path)
+	}
+	if err != nil {
+		return err
+	}
+
+	dayLengthErr := checkDayLength(cfg.Location, now.Year())
+	if dayLengthErr != nil && cfg.Location.DayLengthCheck.Mode == config.DayLengthCheckError {
+		return dayLengthErr
+	}
+
+	timezone := now.Location()
+	if cfg.Location.Timezone != "" {
+		timezone = cfg.Location.TimeZone()
+	}
+	timezoneErr := checkTimezone(cfg.Location, now.Year())
+
+	schedule := light_automation.ComputeSchedule(cfg, now)
+
+	fmt.Fprintln(w, "Config is valid")
+	fmt.Fprintf(w, "Location: %.4f, %.4f\n", cfg.Location.Latitude, cfg.Location.Longitude)
+	fmt.Fprintf(w, "Lights:   %d configured\n", len(cfg.Lights))
+	fmt.Fprintf(w, "Sunrise:  %s\n", schedule.Sunrise.In(timezone).Format(time.DateTime+" MST"))
+	fmt.Fprintf(w, "Sunset:   %s\n", schedule.Sunset.In(timezone).Format(time.DateTime+" MST"))
+	if schedule.OffTime != nil {
+		fmt.Fprintf(w, "Off time: %s\n", schedule.OffTime.In(timezone).Format(time.DateTime+" MST"))
+	}
+	if schedule.WakeUp != nil {
+		fmt.Fprintf(w, "Wake-up:  %s\n", schedule.WakeUp.In(timezone).Format(time.DateTime+" MST"))
+	}
+	fmt.Fprintln(w, "Schedule:")
+	if err := writeLightSchedules(w, schedule.Lights, timezone); err != nil {
+		return err
+	}
+	for _, warning := range cfg.Warnings() {
+		fmt.Fprintf(w, "Warning:  %s\n", warning)
+	}
+	if dayLengthErr != nil {
+		fmt.Fprintf(w, "Warning:  %v\n", dayLengthErr)
+	}
+	if timezoneErr != nil {
+		fmt.Fprintf(w, "Warning:  %v\n", timezoneErr)
+	}
+
+	return nil
+}
+
+// maxDayLengthCheckLatitude is the latitude from which on extreme day lengths are
+// real, e.g. polar nights and midnight suns, the day length is not checked there.
+const maxDayLengthCheckLatitude = 60
+
+// checkDayLength reports an error if the shortest or the longest day of the year
+// at a non-polar location is outside the range of the day length check. The
+// solstices are the days with the shortest and the longest day.
+func checkDayLength(location config.Location, year int) error {
+	check := location.DayLengthCheck
+	if check.Mode == config.DayLengthCheckOff || math.Abs(location.Latitude) >= maxDayLengthCheckLatitude {
+		return nil
+	}
+
+	shortest, longest := yearDayLengths(location.Latitude, location.Longitude, year)
+	if shortest >= check.Min && longest <= check.Max {
+		return nil
+	}
+
+	err := fmt.Errorf("implausible day length between %s and %s at %.4f, %.4f, expected between %s and %s",
+		formatHours(shortest), formatHours(longest), location.Latitude, location.Longitude, formatHours(check.Min), formatHours(check.Max))
+
+	// Swapped coordinates are likely if the day length is plausible with the longitude as latitude.
+	if math.Abs(location.Longitude) <= 90 {
+		shortest, longest = yearDayLengths(location.Longitude, location.Latitude, year)
+		if shortest >= check.Min && longest <= check.Max {
+			return fmt.Errorf("%w, check whether latitude and longitude are swapped", err)
+		}
+	}
+	return err
+}
+
+// yearDayLengths returns the day length at the June and December solstices, the shorter one first.
+func yearDayLengths(latitude float64, longitude float64, year int) (time.Duration, time.Duration) {
+	june := sunset.DayLength(latitude, longitude, time.Date(year, time.June, 21, 12, 0, 0, 0, time.UTC))
+	december := sunset.DayLength(latitude, longitude, time.Date(year, time.December, 21, 12, 0, 0, 0, time.UTC))
+	return min(june, december), max(june, december)
+}
+
+func formatHours(d time.Duration) string {
+	return fmt.Sprintf("%.1fh", d.Hours())
+}
+
+// maxTimezoneDeviation is the largest plausible difference between the standard
+// offset of a time zone and the solar offset of a longitude, e.g. western China
+// uses UTC+08:00 at a solar offset of about UTC+05:00.
+const maxTimezoneDeviation = 3 * time.Hour
+
+// checkTimezone reports an error if the standard offset of the configured time
+// zone is implausible for the longitude, e.g. Berlin coordinates with the time
+// zone America/New_York. Nothing is checked without configured time zone.
+func checkTimezone(location config.Location, year int) error {
+	if location.Timezone == "" {
+		return nil
+	}
+
+	offset := standardOffset(location.TimeZone(), year)
+	solarOffset := time.Duration(location.Longitude / 15 * float64(time.Hour))
+
+	// Offsets beyond the date line wrap around, e.g. UTC+14:00 at longitude -157
+	deviation := math.Remainder((offset - solarOffset).Hours(), 24)
+	if math.Abs(deviation) <= maxTimezoneDeviation.Hours() {
+		return nil
+	}
+
+	return fmt.Errorf("time zone %s (%s) is implausible for longitude %.4f, expected about %s",
+		location.Timezone, formatOffset(offset), location.Longitude, formatOffset(solarOffset.Round(time.Hour)))
+}
+
+// standardOffset returns the UTC offset of the time zone outside of daylight
+// saving time, which is the smaller offset of January and July.
+func standardOffset(loc *time.Location, year int) time.Duration {
+	_, january := time.Date(year, time.January, 1, 12, 0, 0, 0, loc).Zone()
+	_, july := time.Date(year, time.July, 1, 12, 0, 0, 0, loc).Zone()
+	return time.Duration(min(january, july)) * time.Second
+}
+
+func formatOffset(offset time.Duration) string {
+	sign := "+"
+	if offset < 0 {
+		sign, offset = "-", -offset
+	}
+	return fmt.Sprintf("UTC%s%02d:%02d", sign, int(offset.Hours()), int(offset.Minutes())%60)
+}
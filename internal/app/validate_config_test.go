@@ -0,0 +1,213 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"com.github.yveskaufmann/hue-lighter/internal/sunset"
+	"com.github.yveskaufmann/hue-lighter/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateConfig(t *testing.T) {
+	now := time.Date(2024, 6, 21, 12, 0, 0, 0, time.UTC)
+
+	t.Run("prints sunrise and sunset for valid config", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(configPath, []byte(testutils.ValidHueConfigYAML()), 0644))
+		sunriseTime, sunsetTime := sunset.CalculateSunriseSunsetAt(52.5, 13.4, now)
+
+		var out bytes.Buffer
+		err := ValidateConfig(configPath, now, &out)
+
+		require.NoError(t, err)
+		assert.Equal(t, "Config is valid\n"+
+			"Location: 52.5000, 13.4000\n"+
+			"Lights:   2 configured\n"+
+			"Sunrise:  "+sunriseTime.Format("2006-01-02 15:04:05")+" UTC\n"+
+			"Sunset:   "+sunsetTime.Format("2006-01-02 15:04:05")+" UTC\n"+
+			"Schedule:\n"+
+			"  light-1  Test Light 1  sun  19:33-02:43\n"+
+			"  light-2  Test Light 2  sun  19:33-02:43\n", out.String())
+	})
+
+	t.Run("prints off time and wake-up of the schedule", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "config.yaml")
+		content := testutils.ValidHueConfigYAML() + "\nautomation:\n  off_time: \"23:00\"\nwake_up:\n  duration: 30m\n"
+		require.NoError(t, os.WriteFile(configPath, []byte(content), 0644))
+
+		var out bytes.Buffer
+		err := ValidateConfig(configPath, now, &out)
+
+		require.NoError(t, err)
+		assert.Contains(t, out.String(), "Off time: 2024-06-21 23:00:00 UTC\n")
+		assert.Contains(t, out.String(), "Wake-up:  2024-06-22 02:13:33 UTC\n")
+		assert.Contains(t, out.String(), "  light-1  Test Light 1  sun  19:33-23:00, 02:13-02:43\n")
+	})
+
+	t.Run("fails for invalid config", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(configPath, []byte(testutils.InvalidHueConfigYAML("invalid-latitude")), 0644))
+
+		var out bytes.Buffer
+		err := ValidateConfig(configPath, now, &out)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid location coordinates")
+		assert.Empty(t, out.String())
+	})
+
+	t.Run("prints warnings about ignored duplicate lights", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "config.yaml")
+		content := testutils.ValidHueConfigYAML() + "\n  - id: \"light-1\"\nautomation:\n  duplicate_lights: dedupe\n"
+		require.NoError(t, os.WriteFile(configPath, []byte(content), 0644))
+
+		var out bytes.Buffer
+		err := ValidateConfig(configPath, now, &out)
+
+		require.NoError(t, err)
+		assert.Contains(t, out.String(), "Lights:   2 configured\n")
+		assert.Contains(t, out.String(), "Warning:  light \"light-1\" is listed more than once in lights, ignoring the duplicate\n")
+	})
+
+	t.Run("fails for missing config", func(t *testing.T) {
+		var out bytes.Buffer
+		err := ValidateConfig(filepath.Join(t.TempDir(), "missing.yaml"), now, &out)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "config file not found")
+	})
+}
+
+func TestValidateConfig_DayLengthCheck(t *testing.T) {
+	now := time.Date(2024, 6, 21, 12, 0, 0, 0, time.UTC)
+	// Nairobi with swapped coordinates, checked against the day lengths near the equator.
+	const swappedLocation = "location:\n  latitude: 36.8219\n  longitude: -1.2921\n  day_length_check:\n    min: 10h\n    max: 14h\n"
+	const swappedWarning = "implausible day length between 9.6h and 14.7h at 36.8219, -1.2921, expected between 10.0h and 14.0h, check whether latitude and longitude are swapped"
+	const lights = "lights:\n  - id: \"light-1\"\n"
+
+	tests := []struct {
+		name            string
+		config          string
+		wantErr         bool
+		expectedErr     string
+		expectedWarning string
+	}{
+		{
+			name:   "accepts plausible day length",
+			config: "location:\n  latitude: 28.6139\n  longitude: 77.2090\n" + lights,
+		},
+		{
+			name:            "warns about swapped coordinates",
+			config:          swappedLocation + lights,
+			expectedWarning: "Warning:  " + swappedWarning + "\n",
+		},
+		{
+			name:        "rejects swapped coordinates in error mode",
+			config:      swappedLocation + "    mode: error\n" + lights,
+			wantErr:     true,
+			expectedErr: swappedWarning,
+		},
+		{
+			name:   "skips the check when disabled",
+			config: swappedLocation + "    mode: \"off\"\n" + lights,
+		},
+		{
+			name:            "does not suggest swapping if the longitude is no latitude",
+			config:          "location:\n  latitude: 35.6762\n  longitude: 139.6503\n  day_length_check:\n    min: 10h\n" + lights,
+			expectedWarning: "Warning:  implausible day length between 9.7h and 14.6h at 35.6762, 139.6503, expected between 10.0h and 20.0h\n",
+		},
+		{
+			name:   "skips polar locations",
+			config: "location:\n  latitude: 71.2906\n  longitude: -156.7887\n  day_length_check:\n    mode: error\n" + lights,
+		},
+		{
+			name:   "skips sub-polar locations",
+			config: "location:\n  latitude: -62.2000\n  longitude: -58.9667\n" + lights,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "config.yaml")
+			require.NoError(t, os.WriteFile(configPath, []byte(tt.config), 0644))
+
+			var out bytes.Buffer
+			err := ValidateConfig(configPath, now, &out)
+
+			if tt.wantErr {
+				assert.EqualError(t, err, tt.expectedErr)
+				assert.Empty(t, out.String())
+				return
+			}
+			require.NoError(t, err)
+			if tt.expectedWarning == "" {
+				assert.NotContains(t, out.String(), "Warning")
+			} else {
+				assert.True(t, strings.HasSuffix(out.String(), tt.expectedWarning), out.String())
+			}
+		})
+	}
+}
+
+func TestValidateConfig_TimezoneCheck(t *testing.T) {
+	now := time.Date(2024, 6, 21, 12, 0, 0, 0, time.UTC)
+	const berlin = "location:\n  latitude: 52.5200\n  longitude: 13.4050\n"
+	const lights = "lights:\n  - id: \"light-1\"\n"
+
+	tests := []struct {
+		name            string
+		config          string
+		expectedWarning string
+	}{
+		{
+			name:   "accepts matching time zone",
+			config: berlin + "  timezone: Europe/Berlin\n" + lights,
+		},
+		{
+			name:            "warns about mismatched time zone",
+			config:          berlin + "  timezone: America/New_York\n" + lights,
+			expectedWarning: "Warning:  time zone America/New_York (UTC-05:00) is implausible for longitude 13.4050, expected about UTC+01:00\n",
+		},
+		{
+			name:   "accepts time zone beyond the date line",
+			config: "location:\n  latitude: 1.8721\n  longitude: -157.4278\n  timezone: Pacific/Kiritimati\n" + lights,
+		},
+		{
+			name:   "skips the check without time zone",
+			config: berlin + lights,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "config.yaml")
+			require.NoError(t, os.WriteFile(configPath, []byte(tt.config), 0644))
+
+			var out bytes.Buffer
+			err := ValidateConfig(configPath, now, &out)
+
+			require.NoError(t, err)
+			if tt.expectedWarning == "" {
+				assert.NotContains(t, out.String(), "Warning")
+			} else {
+				assert.True(t, strings.HasSuffix(out.String(), tt.expectedWarning), out.String())
+			}
+		})
+	}
+
+	t.Run("prints sunrise and sunset in the configured time zone", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(configPath, []byte(berlin+"  timezone: Europe/Berlin\n"+lights), 0644))
+
+		var out bytes.Buffer
+		require.NoError(t, ValidateConfig(configPath, now, &out))
+
+		assert.Contains(t, out.String(), " CEST\n")
+	})
+}
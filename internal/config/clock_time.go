@@ -0,0 +1,51 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ClockTime is a wall clock time of day, configured as "HH:MM" in 24h format.
+type ClockTime struct {
+	Hour   int
+	Minute int
+}
+
+// ParseClockTime parses a "HH:MM" time of day.
+func ParseClockTime(value string) (ClockTime, error) {
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return ClockTime{}, fmt.Errorf("invalid time of day %q, expected HH:MM", value)
+	}
+	return ClockTime{Hour: parsed.Hour(), Minute: parsed.Minute()}, nil
+}
+
+func (c *ClockTime) UnmarshalYAML(node *yaml.Node) error {
+	var value string
+	if err := node.Decode(&value); err != nil {
+		return err
+	}
+
+	parsed, err := ParseClockTime(value)
+	if err != nil {
+		return err
+	}
+
+	*c = parsed
+	return nil
+}
+
+func (c ClockTime) MarshalYAML() (interface{}, error) {
+	return c.String(), nil
+}
+
+func (c ClockTime) String() string {
+	return fmt.Sprintf("%02d:%02d", c.Hour, c.Minute)
+}
+
+// On returns the clock time on the calendar day of t in the location of t.
+func (c ClockTime) On(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), c.Hour, c.Minute, 0, 0, t.Location())
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseClockTime(t *testing.T) {
+	parsed, err := ParseClockTime("23:05")
+	require.NoError(t, err)
+	assert.Equal(t, ClockTime{Hour: 23, Minute: 5}, parsed)
+	assert.Equal(t, "23:05", parsed.String())
+
+	for _, invalid := range []string{"", "25:00", "7pm", "12:60"} {
+		_, err := ParseClockTime(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestClockTime_UnmarshalYAML(t *testing.T) {
+	var value struct {
+		At ClockTime `yaml:"at"`
+	}
+
+	require.NoError(t, yaml.Unmarshal([]byte(`at: "01:30"`), &value))
+	assert.Equal(t, ClockTime{Hour: 1, Minute: 30}, value.At)
+
+	err := yaml.Unmarshal([]byte(`at: "1.30"`), &value)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "expected HH:MM")
+}
+
+func TestClockTime_On(t *testing.T) {
+	day := time.Date(2025, 3, 1, 14, 12, 9, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2025, 3, 1, 23, 0, 0, 0, time.UTC), ClockTime{Hour: 23}.On(day))
+}
@@ -1,17 +1,286 @@
 package config
 
+import (
+	"time"
+
+	hueclient "com.github.yveskaufmann/hue-lighter/internal/hue_client"
+)
+
 type Config struct {
 	Meta struct {
 		Version     string `yaml:"version"`
 		Name        string `yaml:"name"`
 		Description string `yaml:"description"`
+		// AppName is shown as application in the app list of the bridge, defaults
+		// to hue-lighter. Useful to distinguish multiple instances.
+		AppName string `yaml:"app_name"`
+		// AppendHostname registers the device as "<name>@<hostname>", to tell apart
+		// multiple hosts running hue-lighter against one bridge. See DeviceName.
+		AppendHostname bool `yaml:"append_hostname"`
 	} `yaml:"meta"`
-	Location struct {
-		Latitude  float64 `yaml:"latitude"`
-		Longitude float64 `yaml:"longitude"`
-	} `yaml:"location"`
-	Lights []struct {
-		ID   *string `yaml:"id"`
-		Name *string `yaml:"name"`
-	} `yaml:"lights"`
+	Location Location      `yaml:"location"`
+	Lights   []LightConfig `yaml:"lights"`
+	// SmartScene is recalled at night instead of turning on the lights with the
+	// "sun" trigger, the bridge then runs the time based progression of its states.
+	// The lights of the scene should be configured, so that they are turned off at sunrise.
+	SmartScene struct {
+		// ID of the smart_scene resource, not set disables the smart scene.
+		ID string `yaml:"id"`
+	} `yaml:"smart_scene"`
+	// ColorTemperature gradually changes the color temperature of the lights over
+	// the evening, from StartMirek at sunset to EndMirek at EndTime.
+	ColorTemperature struct {
+		StartMirek *int       `yaml:"start_mirek"`
+		EndMirek   *int       `yaml:"end_mirek"`
+		EndTime    *ClockTime `yaml:"end_time"`
+	} `yaml:"color_temperature"`
+	// BrightnessSchedule is a brightness curve relative to sunset, the brightness of
+	// lights which are on is interpolated between the points along their easing.
+	BrightnessSchedule []BrightnessPoint `yaml:"brightness_schedule"`
+	// WakeUp simulates a dawn, the brightness of the lights rises within the window
+	// of Duration before sunrise and their color temperature moves from StartMirek
+	// to EndMirek. It takes precedence over the evening color temperature and the
+	// brightness schedule.
+	WakeUp struct {
+		// Duration of the window ending at sunrise, zero disables the wake-up.
+		Duration time.Duration `yaml:"duration"`
+		// Brightness in percent (0-100] reached at sunrise, defaults to 100.
+		Brightness float32 `yaml:"brightness"`
+		// StartMirek and EndMirek are optional, the color temperature is kept if not set.
+		StartMirek *int `yaml:"start_mirek"`
+		EndMirek   *int `yaml:"end_mirek"`
+		// Easing of the brightness ramp, defaults to linear.
+		Easing Easing `yaml:"easing"`
+	} `yaml:"wake_up"`
+	Shutdown struct {
+		// GroupedLightID of a grouped_light which contains all configured lights,
+		// e.g. the one of the home. If set, lights are turned off with a single
+		// request on shutdown instead of one request per light.
+		GroupedLightID *string `yaml:"grouped_light_id"`
+		// Delay before the lights are turned off on shutdown, zero turns them off immediately.
+		Delay time.Duration `yaml:"delay"`
+		// FadeDuration is the transition time in which the lights dim down when
+		// turned off on shutdown, zero turns them off without transition.
+		FadeDuration time.Duration `yaml:"fade_duration"`
+		// TurnOff selects which lights are turned off on shutdown, defaults to all configured lights.
+		TurnOff ShutdownTurnOffPolicy `yaml:"turn_off"`
+	} `yaml:"shutdown"`
+	// ShutdownScene is the ID of a scene which is recalled on shutdown instead of
+	// turning off the lights, e.g. a dim night light. If the recall fails the
+	// lights are turned off as configured in Shutdown.
+	ShutdownScene *string `yaml:"shutdown_scene"`
+	Automation    struct {
+		// TickInterval in which the automation checks whether lights must be switched.
+		TickInterval time.Duration `yaml:"tick_interval"`
+		// LightStateRefreshInterval in which the light states are read from the bridge,
+		// to notice lights switched by other apps. Defaults to 5m in "poll" mode and to
+		// 30m in "stream" mode, where the refresh is only a safety net.
+		LightStateRefreshInterval time.Duration `yaml:"light_state_refresh_interval"`
+		// LightStateRefreshMode selects how switches by other apps are noticed, see
+		// LightStateRefreshMode. Defaults to "poll".
+		LightStateRefreshMode LightStateRefreshMode `yaml:"light_state_refresh_mode"`
+		// MaxClockDrift enables a check at startup which warns if the host clock differs
+		// from the bridge clock by more than this duration, zero disables the check.
+		MaxClockDrift time.Duration `yaml:"max_clock_drift"`
+		// StartupJitter delays the start of the automation by a random duration up to this
+		// value, to spread the requests of several instances started at once. Zero disables it.
+		StartupJitter time.Duration `yaml:"startup_jitter"`
+		// SyncOnStartup switches the lights to the state of the current sun period
+		// right after the start, instead of with the first tick.
+		SyncOnStartup bool `yaml:"sync_on_startup"`
+		// OffTime turns the lights off at this time of day ("HH:MM") although it is
+		// still night, they stay off until the wake-up window or the sunrise. It only
+		// applies if it falls between sunset and sunrise, not set keeps the lights on all night.
+		OffTime *ClockTime `yaml:"off_time"`
+		// ReloadDebounce coalesces the config reloads requested within this window
+		// into a single reload, e.g. when an editor writes the file several times.
+		ReloadDebounce time.Duration `yaml:"reload_debounce"`
+		// UnreachableLights selects how the automation reacts to a light whose state
+		// cannot be read, see UnreachableLightPolicy. Defaults to "skip".
+		UnreachableLights UnreachableLightPolicy `yaml:"unreachable_lights"`
+		// UnreachableLightRetries is the number of additional reads of the "retry" policy,
+		// zero reads a light only once like the other policies.
+		UnreachableLightRetries *int `yaml:"unreachable_light_retries"`
+		// DuplicateLights selects how a light listed more than once is handled,
+		// "error" (default) rejects the config and "dedupe" keeps the first entry.
+		DuplicateLights DuplicateLightPolicy `yaml:"duplicate_lights"`
+		// MaxBrightness in percent caps the brightness of every light the automation
+		// turns on, dims or wakes up, also above min_brightness. Not set disables the cap.
+		MaxBrightness *float32 `yaml:"max_brightness"`
+	} `yaml:"automation"`
+	Bridge struct {
+		// LightAPI selects the bridge API used to switch lights: "v2" (default), "v1"
+		// for bridges with an old firmware or "auto" to fall back to v1 if v2 is not supported.
+		LightAPI hueclient.LightAPI `yaml:"light_api"`
+		// RediscoveryThreshold is the number of consecutive connection failures after
+		// which the bridge is re-discovered by its ID, e.g. when it got a new IP via DHCP.
+		// A negative value disables the re-discovery.
+		RediscoveryThreshold int `yaml:"rediscovery_threshold"`
+		// LogBodyLimit is the number of bytes of a request body logged at debug level,
+		// longer bodies are truncated. A negative value disables the logging of request bodies.
+		LogBodyLimit int `yaml:"log_body_limit"`
+		// ReregisterOnUnauthorized registers the device again if the bridge rejects
+		// the stored API key, e.g. after the app was removed in the Hue app. The link
+		// button must be pressed within 15 seconds, disabled by default.
+		ReregisterOnUnauthorized bool `yaml:"reregister_on_unauthorized"`
+		// APIKey of the device obtained elsewhere, e.g. from another tool, it is stored
+		// for the device so that the link button registration is skipped.
+		// `HUE_API_KEY` takes precedence, prefer it to keep the key out of the config file.
+		APIKey string `yaml:"api_key"`
+		// IP address or host name of the bridge, it skips the discovery. The bridge
+		// ID is read from the bridge. Not set discovers the bridge.
+		IP string `yaml:"ip"`
+	} `yaml:"bridge"`
+	Discovery struct {
+		// Timeout of the mDNS bridge discovery, the discovery endpoint is asked at the same time
+		// unless DisableCloud is set.
+		Timeout time.Duration `yaml:"timeout"`
+		// Interface restricts the mDNS discovery to a network interface, e.g. "eth0".
+		Interface string `yaml:"interface"`
+		// Subnet restricts the mDNS discovery to bridges within a subnet in CIDR notation, e.g. "192.168.1.0/24".
+		Subnet string `yaml:"subnet"`
+		// Retries is the number of additional discoveries at startup if no bridge was
+		// found, e.g. when the service starts before the bridge is on the network.
+		// Zero disables the retries, a negative value retries until the bridge is found.
+		Retries *int `yaml:"retries"`
+		// RetryBackoff is the wait before the first retry, it doubles with every
+		// retry up to one minute.
+		RetryBackoff time.Duration `yaml:"retry_backoff"`
+		// DisableCloud never asks the discovery endpoint of Philips and only uses mDNS,
+		// also enabled by `HUE_DISABLE_CLOUD_DISCOVERY=true`.
+		DisableCloud bool `yaml:"disable_cloud"`
+	} `yaml:"discovery"`
+	// Paths can be overridden by the `HUE_API_KEY_STORE_PATH` and `HUE_CA_CERTS_PATH` environment variables,
+	// `HUE_CA_CERTS_PEM` replaces the CA bundle file with its PEM contents.
+	Paths struct {
+		APIKeyStore string `yaml:"api_key_store"`
+		CABundle    string `yaml:"ca_bundle"`
+		// LocationCache stores the location resolved from Location.Source.
+		LocationCache string `yaml:"location_cache"`
+	} `yaml:"paths"`
+
+	// warnings about the config which did not prevent loading it
+	warnings []string
+}
+
+// Warnings returns the problems LoadConfig fixed or ignored, e.g. removed duplicate lights.
+func (c *Config) Warnings() []string {
+	return c.warnings
+}
+
+type LightConfig struct {
+	ID   *string `yaml:"id"`
+	Name *string `yaml:"name"`
+	// Brightness in percent (0-100] applied when the light is turned on, the
+	// current brightness of the light is kept if not set.
+	Brightness *float32 `yaml:"brightness"`
+	// MinBrightness in percent below which the light is never dimmed, to avoid
+	// flickering or turning off bulbs with a high hardware minimum.
+	MinBrightness *float32 `yaml:"min_brightness"`
+	// Enabled excludes the light from the automation if false, e.g. for seasonal
+	// lights, without removing it from the config. Lights are enabled by default.
+	Enabled *bool `yaml:"enabled"`
+	// Trigger selects what turns the light on, "sun" (default) turns it on at
+	// sunset and "time" at OnTime. Both turn it off at sunrise.
+	Trigger LightTrigger `yaml:"trigger"`
+	// OnTime ("HH:MM") at which a light with the "time" trigger is turned on.
+	OnTime *ClockTime `yaml:"on_time"`
+	// ColorTemperature in mirek [hueclient.MinMirek, hueclient.MaxMirek] applied when the light is
+	// turned on, the light is then excluded from the color temperature gradient.
+	ColorTemperature *int `yaml:"color_temperature"`
+	// Color applied when the light is turned on, it must not be set together with
+	// ColorTemperature. The light is excluded from the color temperature gradient.
+	Color *ColorXY `yaml:"color"`
+}
+
+// ColorXY is a color as CIE XY position, both coordinates are in [0, 1].
+type ColorXY struct {
+	X float32 `yaml:"x"`
+	Y float32 `yaml:"y"`
+}
+
+// LocationFromSource reports whether the location must be resolved from
+// Location.Source because no coordinates are configured.
+func (c *Config) LocationFromSource() bool {
+	return c.Location.Source != "" && c.Location.Latitude == 0 && c.Location.Longitude == 0
+}
+
+// HasColor reports whether a color or a color temperature is configured for the light.
+func (l LightConfig) HasColor() bool {
+	return l.Color != nil || l.ColorTemperature != nil
+}
+
+// IsEnabled reports whether the light takes part in the automation.
+func (l LightConfig) IsEnabled() bool {
+	return l.Enabled == nil || *l.Enabled
+}
+
+// LightTrigger selects what turns a light on.
+type LightTrigger string
+
+const (
+	// LightTriggerSun turns the light on at sunset.
+	LightTriggerSun LightTrigger = "sun"
+	// LightTriggerTime turns the light on at a fixed time of day, e.g. 20:00
+	// although the sun sets later in summer.
+	LightTriggerTime LightTrigger = "time"
+)
+
+// BrightnessPoint is a point of the brightness schedule.
+type BrightnessPoint struct {
+	// Offset to the sunset, negative before the sunset, e.g. "-30m" or "2h".
+	Offset time.Duration `yaml:"offset"`
+	// Brightness in percent (0-100] at this point.
+	Brightness float32 `yaml:"brightness"`
+	// Easing of the fade from the previous point to this point, defaults to linear.
+	Easing Easing `yaml:"easing"`
+}
+
+// ShutdownTurnOffPolicy selects which lights are turned off on shutdown.
+type ShutdownTurnOffPolicy string
+
+const (
+	// ShutdownTurnOffAll turns off all configured lights.
+	ShutdownTurnOffAll ShutdownTurnOffPolicy = "all"
+	// ShutdownTurnOffOwned only turns off the lights which were turned on by the
+	// automation, lights turned on manually stay on.
+	ShutdownTurnOffOwned ShutdownTurnOffPolicy = "owned"
+)
+
+// UnreachableLightPolicy selects how the automation reacts to a light whose
+// state cannot be read when the light states are refreshed.
+type UnreachableLightPolicy string
+
+const (
+	// UnreachableLightSkip keeps the last known state of the light.
+	UnreachableLightSkip UnreachableLightPolicy = "skip"
+	// UnreachableLightAssumeOff treats the light as off, so that it is switched on
+	// again at night as soon as it is reachable.
+	UnreachableLightAssumeOff UnreachableLightPolicy = "assume_off"
+	// UnreachableLightRetry reads the state again up to unreachable_light_retries
+	// times and keeps the last known state if all reads fail.
+	UnreachableLightRetry UnreachableLightPolicy = "retry"
+)
+
+// LightStateRefreshMode selects how the automation learns about lights switched
+// by other apps.
+type LightStateRefreshMode string
+
+const (
+	// LightStateRefreshPoll reads the light states every light_state_refresh_interval.
+	LightStateRefreshPoll LightStateRefreshMode = "poll"
+	// LightStateRefreshStream updates the light states from the event stream of the
+	// bridge as they change, they are still read every light_state_refresh_interval
+	// in case an event was missed.
+	LightStateRefreshStream LightStateRefreshMode = "stream"
+)
+
+// WakeUpEnabled reports whether the wake-up before sunrise is configured.
+func (c *Config) WakeUpEnabled() bool {
+	return c.WakeUp.Duration > 0
+}
+
+// ColorTemperatureEnabled reports whether the evening color temperature gradient is configured.
+func (c *Config) ColorTemperatureEnabled() bool {
+	return c.ColorTemperature.StartMirek != nil && c.ColorTemperature.EndMirek != nil && c.ColorTemperature.EndTime != nil
 }
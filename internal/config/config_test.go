@@ -0,0 +1,69 @@
+package config
+
+import (
+	"testing"
+
+	"com.github.yveskaufmann/hue-lighter/internal/geolocation"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestLightConfig_IsEnabled(t *testing.T) {
+	tests := []struct {
+		name     string
+		yaml     string
+		expected bool
+	}{
+		{
+			name:     "enabled by default",
+			yaml:     `id: light-1`,
+			expected: true,
+		},
+		{
+			name:     "explicitly enabled",
+			yaml:     "id: light-1\nenabled: true",
+			expected: true,
+		},
+		{
+			name:     "disabled",
+			yaml:     "id: light-1\nenabled: false",
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var light LightConfig
+			require.NoError(t, yaml.Unmarshal([]byte(tt.yaml), &light))
+
+			assert.Equal(t, tt.expected, light.IsEnabled())
+		})
+	}
+}
+
+func TestConfig_LocationFromSource(t *testing.T) {
+	tests := []struct {
+		name      string
+		source    geolocation.Source
+		latitude  float64
+		longitude float64
+		expected  bool
+	}{
+		{name: "without source", expected: false},
+		{name: "source without coordinates", source: geolocation.SourceIP, expected: true},
+		{name: "configured coordinates take precedence", source: geolocation.SourceIP, latitude: 52.5, longitude: 13.4, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{}
+			config.Location.Source = tt.source
+			config.Location.Latitude = tt.latitude
+			config.Location.Longitude = tt.longitude
+
+			assert.Equal(t, tt.expected, config.LocationFromSource())
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"time"
+
+	"com.github.yveskaufmann/hue-lighter/internal/geolocation"
+	hueclient "com.github.yveskaufmann/hue-lighter/internal/hue_client"
+)
+
+// Default values of optional config fields, they are applied by LoadConfig.
+const (
+	DefaultTickInterval              = time.Second
+	DefaultLightStateRefreshInterval = 5 * time.Minute
+	DefaultStreamRefreshInterval     = 30 * time.Minute
+	DefaultLightStateRefreshMode     = LightStateRefreshPoll
+	DefaultReloadDebounce            = 500 * time.Millisecond
+	DefaultUnreachableLights         = UnreachableLightSkip
+	DefaultUnreachableLightRetries   = 2
+	DefaultDuplicateLights           = DuplicateLightsError
+	DefaultDiscoveryTimeout          = hueclient.DefaultMDNSTimeout
+	DefaultDiscoveryRetries          = 5
+	DefaultDiscoveryRetryBackoff     = 2 * time.Second
+	DefaultAPIKeyStorePath           = hueclient.DefaultAPIKeyStorePath
+	DefaultCABundlePath              = hueclient.DefaultCABundlePath
+	DefaultLocationCachePath         = geolocation.DefaultCachePath
+	DefaultShutdownTurnOff           = ShutdownTurnOffAll
+	DefaultWakeUpBrightness          = 100
+	DefaultEasing                    = EasingLinear
+	DefaultLightAPI                  = hueclient.LightAPIV2
+	DefaultRediscoveryThreshold      = hueclient.DefaultRediscoveryThreshold
+	DefaultLogBodyLimit              = hueclient.DefaultLogBodyLimit
+	DefaultDayLengthCheck            = DayLengthCheckWarn
+	DefaultMinDayLength              = 4 * time.Hour
+	DefaultMaxDayLength              = 20 * time.Hour
+)
+
+// Defaults returns a config which only contains the default values.
+func Defaults() *Config {
+	config := &Config{}
+	config.applyDefaults()
+	return config
+}
+
+// applyDefaults sets every omitted optional field to its default value.
+func (c *Config) applyDefaults() {
+	if c.Location.DayLengthCheck.Mode == "" {
+		c.Location.DayLengthCheck.Mode = DefaultDayLengthCheck
+	}
+	if c.Location.DayLengthCheck.Min == 0 {
+		c.Location.DayLengthCheck.Min = DefaultMinDayLength
+	}
+	if c.Location.DayLengthCheck.Max == 0 {
+		c.Location.DayLengthCheck.Max = DefaultMaxDayLength
+	}
+	if c.Automation.TickInterval == 0 {
+		c.Automation.TickInterval = DefaultTickInterval
+	}
+	if c.Automation.LightStateRefreshMode == "" {
+		c.Automation.LightStateRefreshMode = DefaultLightStateRefreshMode
+	}
+	if c.Automation.LightStateRefreshInterval == 0 {
+		c.Automation.LightStateRefreshInterval = DefaultLightStateRefreshInterval
+		if c.Automation.LightStateRefreshMode == LightStateRefreshStream {
+			c.Automation.LightStateRefreshInterval = DefaultStreamRefreshInterval
+		}
+	}
+	if c.Automation.ReloadDebounce == 0 {
+		c.Automation.ReloadDebounce = DefaultReloadDebounce
+	}
+	if c.Automation.UnreachableLights == "" {
+		c.Automation.UnreachableLights = DefaultUnreachableLights
+	}
+	if c.Automation.UnreachableLightRetries == nil {
+		retries := DefaultUnreachableLightRetries
+		c.Automation.UnreachableLightRetries = &retries
+	}
+	if c.Automation.DuplicateLights == "" {
+		c.Automation.DuplicateLights = DefaultDuplicateLights
+	}
+	if c.WakeUp.Brightness == 0 {
+		c.WakeUp.Brightness = DefaultWakeUpBrightness
+	}
+	if c.WakeUp.Easing == "" {
+		c.WakeUp.Easing = DefaultEasing
+	}
+	for i := range c.BrightnessSchedule {
+		if c.BrightnessSchedule[i].Easing == "" {
+			c.BrightnessSchedule[i].Easing = DefaultEasing
+		}
+	}
+	if c.Shutdown.TurnOff == "" {
+		c.Shutdown.TurnOff = DefaultShutdownTurnOff
+	}
+	if c.Bridge.LightAPI == "" {
+		c.Bridge.LightAPI = DefaultLightAPI
+	}
+	if c.Bridge.RediscoveryThreshold == 0 {
+		c.Bridge.RediscoveryThreshold = DefaultRediscoveryThreshold
+	}
+	if c.Bridge.LogBodyLimit == 0 {
+		c.Bridge.LogBodyLimit = DefaultLogBodyLimit
+	}
+	if c.Discovery.Timeout == 0 {
+		c.Discovery.Timeout = DefaultDiscoveryTimeout
+	}
+	if c.Discovery.Retries == nil {
+		retries := DefaultDiscoveryRetries
+		c.Discovery.Retries = &retries
+	}
+	if c.Discovery.RetryBackoff == 0 {
+		c.Discovery.RetryBackoff = DefaultDiscoveryRetryBackoff
+	}
+	if c.Paths.APIKeyStore == "" {
+		c.Paths.APIKeyStore = DefaultAPIKeyStorePath
+	}
+	if c.Paths.CABundle == "" {
+		c.Paths.CABundle = DefaultCABundlePath
+	}
+	if c.Paths.LocationCache == "" {
+		c.Paths.LocationCache = DefaultLocationCachePath
+	}
+}
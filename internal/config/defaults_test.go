@@ -0,0 +1,243 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	hueclient "com.github.yveskaufmann/hue-lighter/internal/hue_client"
+	"com.github.yveskaufmann/hue-lighter/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaults(t *testing.T) {
+	config := Defaults()
+
+	assert.Equal(t, time.Second, config.Automation.TickInterval)
+	assert.Equal(t, 5*time.Minute, config.Automation.LightStateRefreshInterval)
+	assert.Equal(t, LightStateRefreshPoll, config.Automation.LightStateRefreshMode)
+	assert.Equal(t, 500*time.Millisecond, config.Automation.ReloadDebounce)
+	assert.Equal(t, UnreachableLightSkip, config.Automation.UnreachableLights)
+	require.NotNil(t, config.Automation.UnreachableLightRetries)
+	assert.Equal(t, 2, *config.Automation.UnreachableLightRetries)
+	assert.Equal(t, DuplicateLightsError, config.Automation.DuplicateLights)
+	assert.Equal(t, 15*time.Second, config.Discovery.Timeout)
+	require.NotNil(t, config.Discovery.Retries)
+	assert.Equal(t, 5, *config.Discovery.Retries)
+	assert.Equal(t, 2*time.Second, config.Discovery.RetryBackoff)
+	assert.Equal(t, float32(100), config.WakeUp.Brightness)
+	assert.Equal(t, EasingLinear, config.WakeUp.Easing)
+	assert.Equal(t, ShutdownTurnOffAll, config.Shutdown.TurnOff)
+	assert.Equal(t, hueclient.LightAPIV2, config.Bridge.LightAPI)
+	assert.Equal(t, 3, config.Bridge.RediscoveryThreshold)
+	assert.Equal(t, 256, config.Bridge.LogBodyLimit)
+	assert.Equal(t, DayLengthCheck{Mode: DayLengthCheckWarn, Min: 4 * time.Hour, Max: 20 * time.Hour}, config.Location.DayLengthCheck)
+	assert.Equal(t, "/var/lib/hue-lighter/api-keys.json", config.Paths.APIKeyStore)
+	assert.Equal(t, "/etc/hue-lighter/cacert_bundle.pem", config.Paths.CABundle)
+	assert.Equal(t, "/var/lib/hue-lighter/location.json", config.Paths.LocationCache)
+}
+
+func TestLoadConfig_Defaults(t *testing.T) {
+	tests := []struct {
+		name        string
+		fileContent string
+		expected    func() *Config
+		wantErr     bool
+		expectedErr string
+	}{
+		{
+			name:        "applies defaults to omitted fields",
+			fileContent: testutils.ValidHueConfigYAML(),
+			expected:    Defaults,
+		},
+		{
+			name: "keeps configured values",
+			fileContent: testutils.ValidHueConfigYAML() + `
+automation:
+  tick_interval: 10s
+  light_state_refresh_interval: 1m
+  light_state_refresh_mode: stream
+  reload_debounce: 2s
+  unreachable_lights: retry
+  unreachable_light_retries: 5
+  duplicate_lights: dedupe
+discovery:
+  timeout: 3s
+  retries: -1
+  retry_backoff: 10s
+paths:
+  api_key_store: /home/hue/api-keys.json
+  ca_bundle: /home/hue/cacert_bundle.pem
+  location_cache: /home/hue/location.json`,
+			expected: func() *Config {
+				config := &Config{}
+				config.Automation.TickInterval = 10 * time.Second
+				config.Automation.LightStateRefreshInterval = time.Minute
+				config.Automation.LightStateRefreshMode = LightStateRefreshStream
+				config.Automation.ReloadDebounce = 2 * time.Second
+				config.Automation.UnreachableLights = UnreachableLightRetry
+				config.Automation.UnreachableLightRetries = intPtr(5)
+				config.Automation.DuplicateLights = DuplicateLightsDedupe
+				config.Discovery.Timeout = 3 * time.Second
+				config.Discovery.Retries = intPtr(-1)
+				config.Discovery.RetryBackoff = 10 * time.Second
+				config.Paths.APIKeyStore = "/home/hue/api-keys.json"
+				config.Paths.CABundle = "/home/hue/cacert_bundle.pem"
+				config.Paths.LocationCache = "/home/hue/location.json"
+				return config
+			},
+		},
+		{
+			name: "applies defaults to partially configured sections",
+			fileContent: testutils.ValidHueConfigYAML() + `
+automation:
+  tick_interval: 30s`,
+			expected: func() *Config {
+				config := Defaults()
+				config.Automation.TickInterval = 30 * time.Second
+				return config
+			},
+		},
+		{
+			name: "disables discovery retries",
+			fileContent: testutils.ValidHueConfigYAML() + `
+discovery:
+  retries: 0`,
+			expected: func() *Config {
+				config := Defaults()
+				config.Discovery.Retries = intPtr(0)
+				return config
+			},
+		},
+		{
+			name: "disables unreachable light retries",
+			fileContent: testutils.ValidHueConfigYAML() + `
+automation:
+  unreachable_light_retries: 0`,
+			expected: func() *Config {
+				config := Defaults()
+				config.Automation.UnreachableLightRetries = intPtr(0)
+				return config
+			},
+		},
+		{
+			name: "polls at the configured interval",
+			fileContent: testutils.ValidHueConfigYAML() + `
+automation:
+  light_state_refresh_interval: 30s`,
+			expected: func() *Config {
+				config := Defaults()
+				config.Automation.LightStateRefreshInterval = 30 * time.Second
+				return config
+			},
+		},
+		{
+			name: "refreshes less often in stream mode",
+			fileContent: testutils.ValidHueConfigYAML() + `
+automation:
+  light_state_refresh_mode: stream`,
+			expected: func() *Config {
+				config := Defaults()
+				config.Automation.LightStateRefreshMode = LightStateRefreshStream
+				config.Automation.LightStateRefreshInterval = 30 * time.Minute
+				return config
+			},
+		},
+		{
+			name: "rejects unknown light state refresh mode",
+			fileContent: testutils.ValidHueConfigYAML() + `
+automation:
+  light_state_refresh_mode: push`,
+			wantErr:     true,
+			expectedErr: `automation.light_state_refresh_mode must be "poll" or "stream", got "push"`,
+		},
+		{
+			name: "rejects negative tick interval",
+			fileContent: testutils.ValidHueConfigYAML() + `
+automation:
+  tick_interval: -1s`,
+			wantErr:     true,
+			expectedErr: "automation.tick_interval must be positive",
+		},
+		{
+			name: "rejects negative reload debounce",
+			fileContent: testutils.ValidHueConfigYAML() + `
+automation:
+  reload_debounce: -1s`,
+			wantErr:     true,
+			expectedErr: "automation.reload_debounce must be positive",
+		},
+		{
+			name: "rejects unknown unreachable light policy",
+			fileContent: testutils.ValidHueConfigYAML() + `
+automation:
+  unreachable_lights: ignore`,
+			wantErr:     true,
+			expectedErr: `automation.unreachable_lights must be "skip", "assume_off" or "retry", got "ignore"`,
+		},
+		{
+			name: "rejects negative unreachable light retries",
+			fileContent: testutils.ValidHueConfigYAML() + `
+automation:
+  unreachable_light_retries: -1`,
+			wantErr:     true,
+			expectedErr: "automation.unreachable_light_retries must be positive",
+		},
+		{
+			name: "rejects unknown day length check mode",
+			fileContent: `location:
+  latitude: 52.5
+  longitude: 13.4
+  day_length_check:
+    mode: fail
+lights:
+  - id: "light-1"`,
+			wantErr:     true,
+			expectedErr: `location.day_length_check.mode must be "warn", "error" or "off", got "fail"`,
+		},
+		{
+			name: "rejects day length check min above max",
+			fileContent: `location:
+  latitude: 52.5
+  longitude: 13.4
+  day_length_check:
+    min: 21h
+lights:
+  - id: "light-1"`,
+			wantErr:     true,
+			expectedErr: "location.day_length_check.min must be less than max",
+		},
+		{
+			name: "rejects negative discovery timeout",
+			fileContent: testutils.ValidHueConfigYAML() + `
+discovery:
+  timeout: -5s`,
+			wantErr:     true,
+			expectedErr: "discovery.timeout must be positive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "config.yaml")
+			require.NoError(t, os.WriteFile(configPath, []byte(tt.fileContent), 0644))
+
+			config, err := LoadConfig(configPath)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErr)
+				assert.Nil(t, config)
+				return
+			}
+
+			require.NoError(t, err)
+			expected := tt.expected()
+			assert.Equal(t, expected.Automation, config.Automation)
+			assert.Equal(t, expected.Discovery, config.Discovery)
+			assert.Equal(t, expected.Paths, config.Paths)
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	hueclient "com.github.yveskaufmann/hue-lighter/internal/hue_client"
+)
+
+// hostname returns the host name appended by Meta.AppendHostname, it is replaced in tests.
+var hostname = os.Hostname
+
+// DeviceName returns the name under which the device is registered at the bridge,
+// meta.name or "<meta.name>@<hostname>" if meta.append_hostname is set. Like
+// FormatDeviceType, the name is truncated so that the devicetype does not exceed
+// hueclient.MaxDeviceTypeLength, but the hostname is kept to tell the hosts apart.
+func (c *Config) DeviceName() string {
+	if !c.Meta.AppendHostname {
+		return c.Meta.Name
+	}
+
+	host, err := hostname()
+	if err != nil || host == "" {
+		// Validate rejects the config if the hostname cannot be read
+		return c.Meta.Name
+	}
+
+	name := c.Meta.Name
+	if maxNameLength := c.maxNameLength(host); maxNameLength > 0 && utf8.RuneCountInString(name) > maxNameLength {
+		name = strings.TrimSpace(string([]rune(name)[:maxNameLength]))
+	}
+	return name + "@" + host
+}
+
+// maxNameLength returns the length left for meta.name in the devicetype
+// "<app name>#<name>@<host>".
+func (c *Config) maxNameLength(host string) int {
+	appName := c.Meta.AppName
+	if appName == "" {
+		appName = hueclient.APP_NAME
+	}
+	return hueclient.MaxDeviceTypeLength - utf8.RuneCountInString(appName) - utf8.RuneCountInString(host) - 2
+}
+
+// validateDeviceName checks that the hostname fits into the devicetype of the
+// bridge, FormatDeviceType would otherwise cut off the hostname.
+func (c *Config) validateDeviceName() error {
+	if !c.Meta.AppendHostname {
+		return nil
+	}
+
+	host, err := hostname()
+	if err != nil {
+		return fmt.Errorf("meta.append_hostname: failed to read the hostname: %w", err)
+	}
+	if host == "" {
+		return errors.New("meta.append_hostname: the hostname is empty")
+	}
+
+	if c.maxNameLength(host) < 1 {
+		return fmt.Errorf("meta.append_hostname: the hostname %q leaves no room for meta.name in the device type, at most %d characters are accepted",
+			host, hueclient.MaxDeviceTypeLength)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"errors"
+	"testing"
+
+	hueclient "com.github.yveskaufmann/hue-lighter/internal/hue_client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func stubHostname(t *testing.T, host string, err error) {
+	t.Helper()
+	original := hostname
+	hostname = func() (string, error) { return host, err }
+	t.Cleanup(func() { hostname = original })
+}
+
+func TestConfig_DeviceName(t *testing.T) {
+	stubHostname(t, "pi-livingroom", nil)
+
+	cfg := &Config{}
+	cfg.Meta.Name = "office"
+	assert.Equal(t, "office", cfg.DeviceName())
+
+	cfg.Meta.AppendHostname = true
+	assert.Equal(t, "office@pi-livingroom", cfg.DeviceName())
+
+	deviceType, err := hueclient.FormatDeviceType(cfg.Meta.AppName, cfg.DeviceName())
+	require.NoError(t, err)
+	assert.Equal(t, "hue-lighter#office@pi-livingroom", deviceType)
+}
+
+func TestConfig_DeviceName_TruncatesDefaultName(t *testing.T) {
+	stubHostname(t, "pi-livingroom", nil)
+
+	cfg := &Config{}
+	cfg.Meta.Name = "Hue Lighter Automation"
+	cfg.Meta.AppendHostname = true
+	require.NoError(t, cfg.Validate())
+
+	assert.Equal(t, "Hue Lighter Au@pi-livingroom", cfg.DeviceName())
+	deviceType, err := hueclient.FormatDeviceType(cfg.Meta.AppName, cfg.DeviceName())
+	require.NoError(t, err)
+	assert.Equal(t, "hue-lighter#Hue Lighter Au@pi-livingroom", deviceType)
+	assert.Len(t, deviceType, hueclient.MaxDeviceTypeLength)
+}
+
+func TestConfig_ValidateDeviceName(t *testing.T) {
+	tests := []struct {
+		name     string
+		appName  string
+		hostname string
+		err      error
+		wantErr  bool
+		errMsg   string
+	}{
+		{
+			name:     "device type within the limit",
+			hostname: "pi-livingroom",
+		},
+		{
+			name:     "device type exactly at the limit",
+			hostname: "pi-livingroom-0123456",
+		},
+		{
+			name:     "name is truncated to fit the hostname",
+			hostname: "pi-livingroom-01234567",
+		},
+		{
+			name:     "hostname leaves no room for the name",
+			hostname: "pi-livingroom-0123456789abcd",
+			wantErr:  true,
+			errMsg:   `meta.append_hostname: the hostname "pi-livingroom-0123456789abcd" leaves no room for meta.name in the device type, at most 40 characters are accepted`,
+		},
+		{
+			name:     "counts the configured app name",
+			appName:  "hue-lighter-office",
+			hostname: "pi-livingroom-012345",
+			wantErr:  true,
+			errMsg:   `the hostname "pi-livingroom-012345" leaves no room for meta.name`,
+		},
+		{
+			name:    "hostname cannot be read",
+			err:     errors.New("no hostname"),
+			wantErr: true,
+			errMsg:  "meta.append_hostname: failed to read the hostname: no hostname",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stubHostname(t, tt.hostname, tt.err)
+
+			cfg := &Config{}
+			cfg.Meta.Name = "office"
+			cfg.Meta.AppName = tt.appName
+			cfg.Meta.AppendHostname = true
+
+			err := cfg.Validate()
+
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errMsg)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
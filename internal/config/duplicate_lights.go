@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DuplicateLightPolicy selects how LoadConfig handles a light listed more than once.
+type DuplicateLightPolicy string
+
+const (
+	// DuplicateLightsError rejects the config.
+	DuplicateLightsError DuplicateLightPolicy = "error"
+	// DuplicateLightsDedupe keeps the first entry of the light and reports the
+	// dropped entries in Warnings.
+	DuplicateLightsDedupe DuplicateLightPolicy = "dedupe"
+)
+
+// lightKey identifies a configured light by its ID, or by its name if it has no ID.
+// Names are compared case-insensitively like the bridge light names.
+func lightKey(light LightConfig) string {
+	if light.ID != nil {
+		return *light.ID
+	}
+	if light.Name != nil {
+		return "name:" + strings.ToLower(*light.Name)
+	}
+	return ""
+}
+
+// describeLight names the light in errors and warnings.
+func describeLight(light LightConfig) string {
+	if light.ID != nil {
+		return fmt.Sprintf("light %q", *light.ID)
+	}
+	return fmt.Sprintf("light named %q", *light.Name)
+}
+
+// validateDuplicateLights returns an error for the first light listed more than once.
+func (c *Config) validateDuplicateLights() error {
+	seen := make(map[string]bool, len(c.Lights))
+	for _, light := range c.Lights {
+		key := lightKey(light)
+		if key == "" {
+			continue
+		}
+		if seen[key] {
+			return fmt.Errorf("%s is listed more than once in lights, remove the duplicate or set automation.duplicate_lights to %q",
+				describeLight(light), DuplicateLightsDedupe)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// dedupeLights removes every entry of a light but the first one, if the
+// duplicate light policy is "dedupe", and adds a warning per removed entry.
+func (c *Config) dedupeLights() {
+	if c.Automation.DuplicateLights != DuplicateLightsDedupe {
+		return
+	}
+
+	seen := make(map[string]bool, len(c.Lights))
+	lights := make([]LightConfig, 0, len(c.Lights))
+	for _, light := range c.Lights {
+		key := lightKey(light)
+		if key != "" && seen[key] {
+			c.warnings = append(c.warnings, fmt.Sprintf("%s is listed more than once in lights, ignoring the duplicate", describeLight(light)))
+			continue
+		}
+		seen[key] = true
+		lights = append(lights, light)
+	}
+	c.Lights = lights
+}
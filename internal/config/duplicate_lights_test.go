@@ -0,0 +1,96 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig_DuplicateLights(t *testing.T) {
+	const lights = `lights:
+  - id: "light-1"
+    name: "Hallway"
+  - id: "light-2"
+  - id: "light-1"
+    name: "Hallway again"
+`
+
+	tests := []struct {
+		name             string
+		content          string
+		wantErr          bool
+		expectedErrMsg   string
+		expectedIDs      []string
+		expectedWarnings []string
+		// expectedName of the first light, the first entry of a duplicate is kept
+		expectedName string
+	}{
+		{
+			name:           "rejects duplicate light ID by default",
+			content:        lights,
+			wantErr:        true,
+			expectedErrMsg: `light "light-1" is listed more than once in lights, remove the duplicate or set automation.duplicate_lights to "dedupe"`,
+		},
+		{
+			name: "rejects duplicate light name without ID",
+			content: `lights:
+  - name: "Hallway"
+  - name: "hallway"
+`,
+			wantErr:        true,
+			expectedErrMsg: `light named "hallway" is listed more than once in lights`,
+		},
+		{
+			name: "accepts lights with the same name but different IDs",
+			content: `lights:
+  - id: "light-1"
+    name: "Lamp"
+  - id: "light-2"
+    name: "Lamp"
+`,
+			expectedIDs: []string{"light-1", "light-2"},
+		},
+		{
+			name:             "keeps the first entry with dedupe",
+			content:          lights + "automation:\n  duplicate_lights: dedupe\n",
+			expectedIDs:      []string{"light-1", "light-2"},
+			expectedWarnings: []string{`light "light-1" is listed more than once in lights, ignoring the duplicate`},
+			expectedName:     "Hallway",
+		},
+		{
+			name:           "rejects unknown policy",
+			content:        "lights:\n  - id: \"light-1\"\nautomation:\n  duplicate_lights: ignore\n",
+			wantErr:        true,
+			expectedErrMsg: `automation.duplicate_lights must be "error" or "dedupe", got "ignore"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "config.yaml")
+			content := "location:\n  latitude: 52.5\n  longitude: 13.4\n" + tt.content
+			require.NoError(t, os.WriteFile(configPath, []byte(content), 0644))
+
+			config, err := LoadConfig(configPath)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErrMsg)
+				return
+			}
+			require.NoError(t, err)
+			var ids []string
+			for _, light := range config.Lights {
+				ids = append(ids, *light.ID)
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+			assert.Equal(t, tt.expectedWarnings, config.Warnings())
+			if tt.expectedName != "" {
+				assert.Equal(t, tt.expectedName, *config.Lights[0].Name)
+			}
+		})
+	}
+}
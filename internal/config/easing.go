@@ -0,0 +1,28 @@
+package config
+
+import "fmt"
+
+// Easing selects the curve along which a fade moves from its start to its end value.
+type Easing string
+
+const (
+	// EasingLinear changes the value at a constant rate.
+	EasingLinear Easing = "linear"
+	// EasingEaseIn starts slowly and speeds up towards the end.
+	EasingEaseIn Easing = "ease-in"
+	// EasingEaseOut starts quickly and slows down towards the end.
+	EasingEaseOut Easing = "ease-out"
+	// EasingEaseInOut starts and ends slowly.
+	EasingEaseInOut Easing = "ease-in-out"
+)
+
+// validateEasing returns an error naming field if easing is not a known curve.
+func validateEasing(field string, easing Easing) error {
+	switch easing {
+	case "", EasingLinear, EasingEaseIn, EasingEaseOut, EasingEaseInOut:
+		return nil
+	default:
+		return fmt.Errorf("%s must be %q, %q, %q or %q, got %q", field,
+			EasingLinear, EasingEaseIn, EasingEaseOut, EasingEaseInOut, easing)
+	}
+}
@@ -0,0 +1,121 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+
+	hueclient "com.github.yveskaufmann/hue-lighter/internal/hue_client"
+)
+
+// configFlag is a config value given as command line flag or as environment
+// variable, the flag takes precedence.
+type configFlag struct {
+	name string
+	env  string
+}
+
+// The flags from which ConfigFromFlags builds a config.
+var (
+	flagLatitude  = configFlag{name: "latitude", env: "HUE_LATITUDE"}
+	flagLongitude = configFlag{name: "longitude", env: "HUE_LONGITUDE"}
+	flagLights    = configFlag{name: "lights", env: "HUE_LIGHTS"}
+	flagBridgeIP  = configFlag{name: "bridge-ip", env: "HUE_BRIDGE_IP"}
+	flagName      = configFlag{name: "name", env: "HUE_DEVICE_NAME"}
+)
+
+// lookup returns the value of the flag given as "--<name> <value>" or
+// "--<name>=<value>" in args, or else the value of its environment variable if set.
+// Other arguments, e.g. "--json", are ignored.
+func (f configFlag) lookup(args []string) (string, bool) {
+	for i, arg := range args {
+		if value, ok := strings.CutPrefix(arg, "--"+f.name+"="); ok {
+			return value, true
+		}
+		if arg == "--"+f.name && i+1 < len(args) {
+			return args[i+1], true
+		}
+	}
+	value := os.Getenv(f.env)
+	return value, value != ""
+}
+
+// HasConfigFlags reports whether args or the environment give the location, the
+// minimum from which ConfigFromFlags builds a config.
+func HasConfigFlags(args []string) bool {
+	_, hasLatitude := flagLatitude.lookup(args)
+	_, hasLongitude := flagLongitude.lookup(args)
+	return hasLatitude || hasLongitude
+}
+
+// ConfigFromFlags builds a config from the flags in args and the environment
+// variables, for running without a config file, e.g. in a container:
+//
+//	--latitude, HUE_LATITUDE      latitude of the location (required)
+//	--longitude, HUE_LONGITUDE    longitude of the location (required)
+//	--lights, HUE_LIGHTS          comma-separated IDs of the lights to automate
+//	--bridge-ip, HUE_BRIDGE_IP    IP of the bridge, skips the discovery
+//	--name, HUE_DEVICE_NAME       device name, defaults to hue-lighter
+//
+// All other fields have their default values, the config is validated like a config file.
+func ConfigFromFlags(args []string) (*Config, error) {
+	config := &Config{}
+
+	latitude, err := parseCoordinateFlag(flagLatitude, args)
+	if err != nil {
+		return nil, err
+	}
+	longitude, err := parseCoordinateFlag(flagLongitude, args)
+	if err != nil {
+		return nil, err
+	}
+	config.Location.Latitude, config.Location.Longitude = latitude, longitude
+
+	if lights, ok := flagLights.lookup(args); ok {
+		for _, id := range strings.Split(lights, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				config.Lights = append(config.Lights, LightConfig{ID: &id})
+			}
+		}
+	}
+
+	config.Meta.Name = hueclient.DefaultDeviceName
+	if name, ok := flagName.lookup(args); ok && strings.TrimSpace(name) != "" {
+		config.Meta.Name = strings.TrimSpace(name)
+	}
+	if bridgeIP, ok := flagBridgeIP.lookup(args); ok {
+		config.Bridge.IP = strings.TrimSpace(bridgeIP)
+	}
+
+	if err := config.prepare(); err != nil {
+		return nil, fmt.Errorf("invalid config from flags: %w", err)
+	}
+	return config, nil
+}
+
+// parseCoordinateFlag parses the required coordinate flag.
+func parseCoordinateFlag(flag configFlag, args []string) (float64, error) {
+	value, ok := flag.lookup(args)
+	if !ok {
+		return 0, fmt.Errorf("--%s (or %s) is required without a config file", flag.name, flag.env)
+	}
+	coordinate, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return 0, fmt.Errorf("--%s must be a number, got %q", flag.name, value)
+	}
+	return coordinate, nil
+}
+
+// LoadConfigFromDefaultPathOrFlags loads the config file at the default path
+// (CONFIG_PATH). If it does not exist and args or the environment give the
+// location, the config is built by ConfigFromFlags instead.
+func LoadConfigFromDefaultPathOrFlags(args []string) (*Config, error) {
+	path := defaultConfigPath()
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) && HasConfigFlags(args) {
+		return ConfigFromFlags(args)
+	}
+	return LoadConfig(path)
+}
@@ -0,0 +1,135 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	hueclient "com.github.yveskaufmann/hue-lighter/internal/hue_client"
+	"com.github.yveskaufmann/hue-lighter/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// clearFlagEnv unsets the environment variables of the config flags for the test.
+func clearFlagEnv(t *testing.T) {
+	t.Helper()
+	for _, flag := range []configFlag{flagLatitude, flagLongitude, flagLights, flagBridgeIP, flagName} {
+		t.Cleanup(testutils.SetEnv(t, flag.env, ""))
+	}
+}
+
+func TestConfigFromFlags(t *testing.T) {
+	clearFlagEnv(t)
+
+	cfg, err := ConfigFromFlags([]string{
+		"--latitude", "52.52",
+		"--longitude=13.405",
+		"--lights", "light-1, light-2,,",
+		"--bridge-ip=192.168.1.2",
+		"--name", "container",
+		"--json",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 52.52, cfg.Location.Latitude)
+	assert.Equal(t, 13.405, cfg.Location.Longitude)
+	assert.Equal(t, "container", cfg.Meta.Name)
+	assert.Equal(t, "192.168.1.2", cfg.Bridge.IP)
+	require.Len(t, cfg.Lights, 2)
+	assert.Equal(t, "light-1", *cfg.Lights[0].ID)
+	assert.Equal(t, "light-2", *cfg.Lights[1].ID)
+	// The defaults are applied like for a config file
+	assert.Equal(t, DefaultTickInterval, cfg.Automation.TickInterval)
+	assert.Equal(t, DefaultAPIKeyStorePath, cfg.Paths.APIKeyStore)
+}
+
+func TestConfigFromFlags_Environment(t *testing.T) {
+	clearFlagEnv(t)
+	defer testutils.SetEnv(t, "HUE_LATITUDE", "48.137")()
+	defer testutils.SetEnv(t, "HUE_LONGITUDE", "11.575")()
+	defer testutils.SetEnv(t, "HUE_LIGHTS", "light-1")()
+
+	cfg, err := ConfigFromFlags([]string{"--latitude", "52.52"})
+
+	require.NoError(t, err)
+	assert.Equal(t, 52.52, cfg.Location.Latitude, "the flag takes precedence")
+	assert.Equal(t, 11.575, cfg.Location.Longitude)
+	assert.Equal(t, hueclient.DefaultDeviceName, cfg.Meta.Name)
+	assert.Empty(t, cfg.Bridge.IP)
+	require.Len(t, cfg.Lights, 1)
+	assert.Equal(t, "light-1", *cfg.Lights[0].ID)
+}
+
+func TestConfigFromFlags_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		errMsg string
+	}{
+		{
+			name:   "missing longitude",
+			args:   []string{"--latitude", "52.52"},
+			errMsg: "--longitude (or HUE_LONGITUDE) is required without a config file",
+		},
+		{
+			name:   "latitude is not a number",
+			args:   []string{"--latitude", "north", "--longitude", "13.405"},
+			errMsg: `--latitude must be a number, got "north"`,
+		},
+		{
+			name:   "validated like a config file",
+			args:   []string{"--latitude", "152.52", "--longitude", "13.405"},
+			errMsg: "invalid config from flags: invalid location coordinates",
+		},
+		{
+			name:   "duplicate lights are rejected",
+			args:   []string{"--latitude", "52.52", "--longitude", "13.405", "--lights", "light-1,light-1"},
+			errMsg: `invalid config from flags: light "light-1" is listed more than once`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearFlagEnv(t)
+
+			_, err := ConfigFromFlags(tt.args)
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestLoadConfigFromDefaultPathOrFlags(t *testing.T) {
+	clearFlagEnv(t)
+	flags := []string{"--latitude", "52.52", "--longitude", "13.405", "--lights", "light-1"}
+
+	t.Run("builds the config from flags without a config file", func(t *testing.T) {
+		defer testutils.SetEnv(t, "CONFIG_PATH", filepath.Join(t.TempDir(), "missing.yaml"))()
+
+		cfg, err := LoadConfigFromDefaultPathOrFlags(flags)
+
+		require.NoError(t, err)
+		assert.Equal(t, 52.52, cfg.Location.Latitude)
+	})
+
+	t.Run("prefers the config file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("location:\n  latitude: 48.137\n  longitude: 11.575\n"), 0644))
+		defer testutils.SetEnv(t, "CONFIG_PATH", path)()
+
+		cfg, err := LoadConfigFromDefaultPathOrFlags(flags)
+
+		require.NoError(t, err)
+		assert.Equal(t, 48.137, cfg.Location.Latitude)
+	})
+
+	t.Run("reports the missing config file without flags", func(t *testing.T) {
+		defer testutils.SetEnv(t, "CONFIG_PATH", filepath.Join(t.TempDir(), "missing.yaml"))()
+
+		_, err := LoadConfigFromDefaultPathOrFlags([]string{"--status"})
+
+		assert.ErrorContains(t, err, "config file not found")
+	})
+}
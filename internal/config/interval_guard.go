@@ -0,0 +1,30 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// Lowest intervals accepted by the config, shorter intervals would flood the bridge
+// with requests. Shorter configured intervals are raised to them.
+const (
+	MinTickInterval              = time.Second
+	MinLightStateRefreshInterval = 10 * time.Second
+)
+
+// clampIntervals raises the intervals below their minimum and adds a warning per
+// raised interval. Negative intervals are kept, so that Validate rejects them.
+func (c *Config) clampIntervals() {
+	c.Automation.TickInterval = c.clampInterval("automation.tick_interval", c.Automation.TickInterval, MinTickInterval)
+	c.Automation.LightStateRefreshInterval = c.clampInterval("automation.light_state_refresh_interval",
+		c.Automation.LightStateRefreshInterval, MinLightStateRefreshInterval)
+}
+
+func (c *Config) clampInterval(field string, interval time.Duration, minimum time.Duration) time.Duration {
+	if interval <= 0 || interval >= minimum {
+		return interval
+	}
+
+	c.warnings = append(c.warnings, fmt.Sprintf("%s %s is below the minimum of %s to protect the bridge, using %s", field, interval, minimum, minimum))
+	return minimum
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"com.github.yveskaufmann/hue-lighter/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig_ClampsIntervals(t *testing.T) {
+	tests := []struct {
+		name                    string
+		automation              string
+		expectedTickInterval    time.Duration
+		expectedRefreshInterval time.Duration
+		expectedWarnings        []string
+	}{
+		{
+			name:                    "keeps intervals at the minimum",
+			automation:              "  tick_interval: 1s\n  light_state_refresh_interval: 10s\n",
+			expectedTickInterval:    time.Second,
+			expectedRefreshInterval: 10 * time.Second,
+		},
+		{
+			name:                    "raises tick interval below the minimum",
+			automation:              "  tick_interval: 100ms\n",
+			expectedTickInterval:    time.Second,
+			expectedRefreshInterval: DefaultLightStateRefreshInterval,
+			expectedWarnings:        []string{"automation.tick_interval 100ms is below the minimum of 1s to protect the bridge, using 1s"},
+		},
+		{
+			name:                    "raises refresh interval below the minimum",
+			automation:              "  light_state_refresh_interval: 2s\n",
+			expectedTickInterval:    DefaultTickInterval,
+			expectedRefreshInterval: 10 * time.Second,
+			expectedWarnings:        []string{"automation.light_state_refresh_interval 2s is below the minimum of 10s to protect the bridge, using 10s"},
+		},
+		{
+			name:                    "raises both intervals",
+			automation:              "  tick_interval: 1ms\n  light_state_refresh_interval: 1ms\n",
+			expectedTickInterval:    time.Second,
+			expectedRefreshInterval: 10 * time.Second,
+			expectedWarnings: []string{
+				"automation.tick_interval 1ms is below the minimum of 1s to protect the bridge, using 1s",
+				"automation.light_state_refresh_interval 1ms is below the minimum of 10s to protect the bridge, using 10s",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "config.yaml")
+			content := testutils.ValidHueConfigYAML() + "\nautomation:\n" + tt.automation
+			require.NoError(t, os.WriteFile(configPath, []byte(content), 0644))
+
+			config, err := LoadConfig(configPath)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedTickInterval, config.Automation.TickInterval)
+			assert.Equal(t, tt.expectedRefreshInterval, config.Automation.LightStateRefreshInterval)
+			assert.Equal(t, tt.expectedWarnings, config.Warnings())
+		})
+	}
+
+	t.Run("still rejects negative intervals", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "config.yaml")
+		content := testutils.ValidHueConfigYAML() + "\nautomation:\n  light_state_refresh_interval: -1s\n"
+		require.NoError(t, os.WriteFile(configPath, []byte(content), 0644))
+
+		_, err := LoadConfig(configPath)
+
+		assert.ErrorContains(t, err, "automation.light_state_refresh_interval must be positive")
+	})
+}
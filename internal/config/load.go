@@ -3,19 +3,28 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net"
 	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
 
+	"com.github.yveskaufmann/hue-lighter/internal/geolocation"
+	hueclient "com.github.yveskaufmann/hue-lighter/internal/hue_client"
 	"gopkg.in/yaml.v3"
 )
 
 func LoadConfigFromDefaultPath() (*Config, error) {
+	return LoadConfig(defaultConfigPath())
+}
 
+// defaultConfigPath returns CONFIG_PATH, or /etc/hue-lighter/config.yaml if unset.
+func defaultConfigPath() string {
 	configPath := os.Getenv("CONFIG_PATH")
 	if configPath == "" {
 		configPath = "/etc/hue-lighter/config.yaml"
 	}
-
-	return LoadConfig(configPath)
+	return configPath
 }
 
 func LoadConfig(path string) (*Config, error) {
@@ -39,14 +48,28 @@ func LoadConfig(path string) (*Config, error) {
 		return nil, fmt.Errorf("failed to decode config file %q: %w", path, err)
 	}
 
-	if err := config.validate(); err != nil {
+	if err := config.prepare(); err != nil {
 		return nil, fmt.Errorf("invalid config in file %q: %w", path, err)
 	}
 
 	return &config, nil
 }
 
-func (c *Config) validate() error {
+// prepare applies the defaults, raises too short intervals, removes duplicate
+// lights and validates the config.
+func (c *Config) prepare() error {
+	c.applyDefaults()
+	c.clampIntervals()
+	c.dedupeLights()
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	c.Location.timeZone = c.Location.loadTimeZone()
+	return nil
+}
+
+// Validate checks the config for invalid values, it does not require a bridge.
+func (c *Config) Validate() error {
 	if c == nil {
 		return errors.New("config is nil")
 	}
@@ -55,12 +78,244 @@ func (c *Config) validate() error {
 		c.Location.Longitude < -180 || c.Location.Longitude > 180 {
 		return errors.New("invalid location coordinates")
 	}
+	switch c.Location.Source {
+	case "", geolocation.SourceIP:
+	default:
+		return fmt.Errorf("location.source must be %q, got %q", geolocation.SourceIP, c.Location.Source)
+	}
+	if c.Location.Timezone != "" {
+		if _, err := time.LoadLocation(c.Location.Timezone); err != nil {
+			return fmt.Errorf("location.timezone: %w", err)
+		}
+	}
+	if err := c.validateDayLengthCheck(); err != nil {
+		return err
+	}
+
+	if appName := c.Meta.AppName; appName != "" {
+		if utf8.RuneCountInString(appName) > hueclient.MaxAppNameLength {
+			return fmt.Errorf("meta.app_name must be at most %d characters", hueclient.MaxAppNameLength)
+		}
+		if strings.Contains(appName, "#") {
+			return errors.New("meta.app_name must not contain '#'")
+		}
+	}
+	if err := c.validateDeviceName(); err != nil {
+		return err
+	}
 
 	for _, light := range c.Lights {
 		if light.ID == nil && light.Name == nil {
 			return errors.New("light must have either ID or Name")
 		}
+		if light.Brightness != nil && (*light.Brightness <= 0 || *light.Brightness > 100) {
+			return errors.New("light brightness must be in range (0, 100]")
+		}
+		if light.MinBrightness != nil && (*light.MinBrightness < 0 || *light.MinBrightness > 100) {
+			return errors.New("light min_brightness must be in range [0, 100]")
+		}
+		if err := validateLightTrigger(light); err != nil {
+			return err
+		}
+		if err := validateLightColor(light); err != nil {
+			return err
+		}
+	}
+	if err := c.validateDuplicateLights(); err != nil {
+		return err
+	}
+
+	if err := c.validateColorTemperature(); err != nil {
+		return err
+	}
+
+	if err := c.validateBrightnessSchedule(); err != nil {
+		return err
+	}
+
+	if err := c.validateWakeUp(); err != nil {
+		return err
 	}
 
+	if c.Automation.TickInterval < 0 {
+		return errors.New("automation.tick_interval must be positive")
+	}
+	if c.Automation.LightStateRefreshInterval < 0 {
+		return errors.New("automation.light_state_refresh_interval must be positive")
+	}
+	switch c.Automation.LightStateRefreshMode {
+	case "", LightStateRefreshPoll, LightStateRefreshStream:
+	default:
+		return fmt.Errorf("automation.light_state_refresh_mode must be %q or %q, got %q",
+			LightStateRefreshPoll, LightStateRefreshStream, c.Automation.LightStateRefreshMode)
+	}
+	if c.Automation.StartupJitter < 0 {
+		return errors.New("automation.startup_jitter must be positive")
+	}
+	if c.Automation.ReloadDebounce < 0 {
+		return errors.New("automation.reload_debounce must be positive")
+	}
+	switch c.Automation.UnreachableLights {
+	case "", UnreachableLightSkip, UnreachableLightAssumeOff, UnreachableLightRetry:
+	default:
+		return fmt.Errorf("automation.unreachable_lights must be %q, %q or %q, got %q",
+			UnreachableLightSkip, UnreachableLightAssumeOff, UnreachableLightRetry, c.Automation.UnreachableLights)
+	}
+	if c.Automation.UnreachableLightRetries != nil && *c.Automation.UnreachableLightRetries < 0 {
+		return errors.New("automation.unreachable_light_retries must be positive")
+	}
+	switch c.Automation.DuplicateLights {
+	case "", DuplicateLightsError, DuplicateLightsDedupe:
+	default:
+		return fmt.Errorf("automation.duplicate_lights must be %q or %q, got %q",
+			DuplicateLightsError, DuplicateLightsDedupe, c.Automation.DuplicateLights)
+	}
+	if c.Automation.MaxBrightness != nil && (*c.Automation.MaxBrightness <= 0 || *c.Automation.MaxBrightness > 100) {
+		return errors.New("automation.max_brightness must be in range (0, 100]")
+	}
+	if c.Automation.MaxClockDrift < 0 {
+		return errors.New("automation.max_clock_drift must be positive")
+	}
+	if c.Shutdown.Delay < 0 {
+		return errors.New("shutdown.delay must be positive")
+	}
+	if c.Shutdown.FadeDuration < 0 {
+		return errors.New("shutdown.fade_duration must be positive")
+	}
+	switch c.Shutdown.TurnOff {
+	case "", ShutdownTurnOffAll, ShutdownTurnOffOwned:
+	default:
+		return fmt.Errorf("shutdown.turn_off must be %q or %q, got %q", ShutdownTurnOffAll, ShutdownTurnOffOwned, c.Shutdown.TurnOff)
+	}
+	if c.ShutdownScene != nil && strings.TrimSpace(*c.ShutdownScene) == "" {
+		return errors.New("shutdown_scene must not be empty, remove it to turn off the lights on shutdown")
+	}
+	if strings.Contains(c.Bridge.IP, "/") {
+		return fmt.Errorf("bridge.ip must be an IP address or host name without scheme or path, got %q", c.Bridge.IP)
+	}
+	switch c.Bridge.LightAPI {
+	case "", hueclient.LightAPIV2, hueclient.LightAPIV1, hueclient.LightAPIAuto:
+	default:
+		return fmt.Errorf("bridge.light_api must be %q, %q or %q, got %q", hueclient.LightAPIV2, hueclient.LightAPIV1, hueclient.LightAPIAuto, c.Bridge.LightAPI)
+	}
+	if c.Discovery.Timeout < 0 {
+		return errors.New("discovery.timeout must be positive")
+	}
+	if c.Discovery.RetryBackoff < 0 {
+		return errors.New("discovery.retry_backoff must be positive")
+	}
+	if c.Discovery.Subnet != "" {
+		if _, _, err := net.ParseCIDR(c.Discovery.Subnet); err != nil {
+			return fmt.Errorf("discovery.subnet must be in CIDR notation: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) validateColorTemperature() error {
+	ct := c.ColorTemperature
+	if ct.StartMirek == nil && ct.EndMirek == nil && ct.EndTime == nil {
+		return nil
+	}
+
+	if !c.ColorTemperatureEnabled() {
+		return errors.New("color_temperature requires start_mirek, end_mirek and end_time")
+	}
+
+	for _, mirek := range []int{*ct.StartMirek, *ct.EndMirek} {
+		if mirek < hueclient.MinMirek || mirek > hueclient.MaxMirek {
+			return fmt.Errorf("color_temperature mirek %d out of range [%d, %d]", mirek, hueclient.MinMirek, hueclient.MaxMirek)
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) validateBrightnessSchedule() error {
+	for i, point := range c.BrightnessSchedule {
+		if point.Brightness <= 0 || point.Brightness > 100 {
+			return fmt.Errorf("brightness_schedule point %d: brightness must be in range (0, 100]", i)
+		}
+		if i > 0 && point.Offset <= c.BrightnessSchedule[i-1].Offset {
+			return fmt.Errorf("brightness_schedule point %d: offsets must be in ascending order", i)
+		}
+		if err := validateEasing(fmt.Sprintf("brightness_schedule point %d: easing", i), point.Easing); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Config) validateWakeUp() error {
+	wake := c.WakeUp
+	if wake.Duration < 0 {
+		return errors.New("wake_up.duration must be positive")
+	}
+	if wake.Brightness < 0 || wake.Brightness > 100 {
+		return errors.New("wake_up.brightness must be in range (0, 100]")
+	}
+	if (wake.StartMirek == nil) != (wake.EndMirek == nil) {
+		return errors.New("wake_up requires both start_mirek and end_mirek or none")
+	}
+	if err := validateEasing("wake_up.easing", wake.Easing); err != nil {
+		return err
+	}
+	if wake.StartMirek != nil {
+		for _, mirek := range []int{*wake.StartMirek, *wake.EndMirek} {
+			if mirek < hueclient.MinMirek || mirek > hueclient.MaxMirek {
+				return fmt.Errorf("wake_up mirek %d out of range [%d, %d]", mirek, hueclient.MinMirek, hueclient.MaxMirek)
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Config) validateDayLengthCheck() error {
+	check := c.Location.DayLengthCheck
+	switch check.Mode {
+	case "", DayLengthCheckWarn, DayLengthCheckError, DayLengthCheckOff:
+	default:
+		return fmt.Errorf("location.day_length_check.mode must be %q, %q or %q, got %q",
+			DayLengthCheckWarn, DayLengthCheckError, DayLengthCheckOff, check.Mode)
+	}
+	if check.Min < 0 || check.Max < 0 {
+		return errors.New("location.day_length_check.min and max must be positive")
+	}
+	if check.Min > 0 && check.Max > 0 && check.Min >= check.Max {
+		return errors.New("location.day_length_check.min must be less than max")
+	}
+	return nil
+}
+
+// validateLightColor checks that at most one of color and color_temperature is set
+// and that the set one is in the range supported by Hue bulbs.
+func validateLightColor(light LightConfig) error {
+	if light.Color != nil && light.ColorTemperature != nil {
+		return errors.New("light color and color_temperature must not both be set")
+	}
+	if mirek := light.ColorTemperature; mirek != nil && (*mirek < hueclient.MinMirek || *mirek > hueclient.MaxMirek) {
+		return fmt.Errorf("light color_temperature %d out of range [%d, %d]", *mirek, hueclient.MinMirek, hueclient.MaxMirek)
+	}
+	if color := light.Color; color != nil && (color.X < 0 || color.X > 1 || color.Y < 0 || color.Y > 1) {
+		return errors.New("light color x and y must be in range [0, 1]")
+	}
+	return nil
+}
+
+// validateLightTrigger checks that on_time is set only and always with the "time" trigger.
+func validateLightTrigger(light LightConfig) error {
+	switch light.Trigger {
+	case "", LightTriggerSun:
+		if light.OnTime != nil {
+			return fmt.Errorf("light on_time requires trigger %q", LightTriggerTime)
+		}
+	case LightTriggerTime:
+		if light.OnTime == nil {
+			return fmt.Errorf("light trigger %q requires on_time", LightTriggerTime)
+		}
+	default:
+		return fmt.Errorf("light trigger must be %q or %q, got %q", LightTriggerSun, LightTriggerTime, light.Trigger)
+	}
 	return nil
 }
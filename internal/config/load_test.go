@@ -178,3 +178,116 @@ func TestLoadConfig_FileOpenError(t *testing.T) {
 	// Should not contain the helpful message for missing files
 	assert.NotContains(t, err.Error(), "Please create your config file by copying the example:")
 }
+
+func TestLoadConfig_OffTime(t *testing.T) {
+	tests := []struct {
+		name           string
+		offTime        string
+		wantErr        bool
+		expectedErrMsg string
+	}{
+		{name: "parses off time", offTime: "01:30"},
+		{name: "rejects invalid format", offTime: "1h30", wantErr: true, expectedErrMsg: `invalid time of day "1h30", expected HH:MM`},
+		{name: "rejects out of range time", offTime: "25:00", wantErr: true, expectedErrMsg: `invalid time of day "25:00", expected HH:MM`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "config.yaml")
+			content := testutils.ValidHueConfigYAML() + "\nautomation:\n  off_time: \"" + tt.offTime + "\"\n"
+			require.NoError(t, os.WriteFile(configPath, []byte(content), 0644))
+
+			config, err := LoadConfig(configPath)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErrMsg)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, config.Automation.OffTime)
+			assert.Equal(t, ClockTime{Hour: 1, Minute: 30}, *config.Automation.OffTime)
+		})
+	}
+}
+
+func TestLoadConfig_LightColor(t *testing.T) {
+	tests := []struct {
+		name           string
+		light          string
+		wantErr        bool
+		expectedErrMsg string
+	}{
+		{name: "accepts color temperature", light: "    color_temperature: 366\n"},
+		{name: "accepts color", light: "    color:\n      x: 0.5\n      y: 0.4\n"},
+		{name: "rejects color and color temperature", light: "    color_temperature: 366\n    color:\n      x: 0.5\n      y: 0.4\n", wantErr: true, expectedErrMsg: "light color and color_temperature must not both be set"},
+		{name: "rejects color temperature out of range", light: "    color_temperature: 100\n", wantErr: true, expectedErrMsg: "light color_temperature 100 out of range [153, 500]"},
+		{name: "rejects color out of range", light: "    color:\n      x: 1.5\n      y: 0.4\n", wantErr: true, expectedErrMsg: "light color x and y must be in range [0, 1]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "config.yaml")
+			content := "location:\n  latitude: 52.5\n  longitude: 13.4\nlights:\n  - id: \"light-1\"\n" + tt.light
+			require.NoError(t, os.WriteFile(configPath, []byte(content), 0644))
+
+			config, err := LoadConfig(configPath)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErrMsg)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, config.Lights, 1)
+			assert.True(t, config.Lights[0].HasColor())
+		})
+	}
+}
+
+func TestLoadConfig_LightTrigger(t *testing.T) {
+	tests := []struct {
+		name           string
+		light          string
+		wantErr        bool
+		expectedErrMsg string
+	}{
+		{name: "defaults to sun trigger", light: ""},
+		{name: "accepts sun trigger", light: "    trigger: sun\n"},
+		{name: "accepts time trigger with on time", light: "    trigger: time\n    on_time: \"20:00\"\n"},
+		{name: "rejects time trigger without on time", light: "    trigger: time\n", wantErr: true, expectedErrMsg: `light trigger "time" requires on_time`},
+		{name: "rejects on time with sun trigger", light: "    trigger: sun\n    on_time: \"20:00\"\n", wantErr: true, expectedErrMsg: `light on_time requires trigger "time"`},
+		{name: "rejects on time without trigger", light: "    on_time: \"20:00\"\n", wantErr: true, expectedErrMsg: `light on_time requires trigger "time"`},
+		{name: "rejects unknown trigger", light: "    trigger: dusk\n", wantErr: true, expectedErrMsg: `light trigger must be "sun" or "time", got "dusk"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "config.yaml")
+			content := "location:\n  latitude: 52.5\n  longitude: 13.4\nlights:\n  - id: \"light-1\"\n" + tt.light
+			require.NoError(t, os.WriteFile(configPath, []byte(content), 0644))
+
+			config, err := LoadConfig(configPath)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErrMsg)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, config.Lights, 1)
+		})
+	}
+}
+
+func TestLoadConfig_ShutdownScene(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	content := testutils.ValidHueConfigYAML() + "\nshutdown_scene: \"scene-night\"\n"
+	require.NoError(t, os.WriteFile(configPath, []byte(content), 0644))
+
+	config, err := LoadConfig(configPath)
+
+	require.NoError(t, err)
+	require.NotNil(t, config.ShutdownScene)
+	assert.Equal(t, "scene-night", *config.ShutdownScene)
+}
@@ -0,0 +1,124 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"com.github.yveskaufmann/hue-lighter/internal/geolocation"
+	"gopkg.in/yaml.v3"
+)
+
+// Location is configured either with latitude and longitude or as a single
+// "latitude,longitude" string, e.g. "52.52,13.40" copied from a map app.
+type Location struct {
+	Latitude  float64 `yaml:"latitude"`
+	Longitude float64 `yaml:"longitude"`
+	// Source resolves the location at startup if latitude and longitude are not
+	// set, "ip" looks it up from the public IP. The result is cached in Paths.LocationCache.
+	Source geolocation.Source `yaml:"source"`
+	// DayLengthCheck makes validate-config report a location whose day length
+	// leaves a plausible range, e.g. because latitude and longitude were swapped.
+	DayLengthCheck DayLengthCheck `yaml:"day_length_check"`
+	// Timezone is the IANA name of the time zone in which the clock times of the
+	// config are interpreted, e.g. "Europe/Berlin". Defaults to the time zone of the host.
+	Timezone string `yaml:"timezone"`
+
+	// timeZone is Timezone resolved by LoadConfig, so that the zoneinfo is not
+	// read again on every tick.
+	timeZone *time.Location
+}
+
+// TimeZone returns the configured time zone, time.Local if none is configured
+// or it cannot be loaded. Validate rejects unknown time zones.
+func (l Location) TimeZone() *time.Location {
+	if l.timeZone != nil {
+		return l.timeZone
+	}
+	return l.loadTimeZone()
+}
+
+func (l Location) loadTimeZone() *time.Location {
+	if l.Timezone == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(l.Timezone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// DayLengthCheck is the range of the day length plausible for non-polar locations,
+// the shortest and the longest day of the year must be within [Min, Max].
+type DayLengthCheck struct {
+	// Mode is "warn" (default), "error" or "off", e.g. for locations north of
+	// the 60th parallel where longer days are plausible.
+	Mode DayLengthCheckMode `yaml:"mode"`
+	Min  time.Duration      `yaml:"min"`
+	Max  time.Duration      `yaml:"max"`
+}
+
+// DayLengthCheckMode selects how validate-config reports an implausible day length.
+type DayLengthCheckMode string
+
+const (
+	// DayLengthCheckWarn prints a warning, the config is still valid.
+	DayLengthCheckWarn DayLengthCheckMode = "warn"
+	// DayLengthCheckError rejects the config.
+	DayLengthCheckError DayLengthCheckMode = "error"
+	// DayLengthCheckOff disables the check.
+	DayLengthCheckOff DayLengthCheckMode = "off"
+)
+
+// ParseCoordinates parses a "latitude,longitude" string, spaces around the values are ignored.
+func ParseCoordinates(value string) (Location, error) {
+	latValue, lonValue, found := strings.Cut(value, ",")
+	if !found {
+		return Location{}, fmt.Errorf("invalid location %q, expected \"latitude,longitude\"", value)
+	}
+
+	latitude, err := parseCoordinate(latValue, 90)
+	if err != nil {
+		return Location{}, fmt.Errorf("invalid latitude in location %q: %w", value, err)
+	}
+	longitude, err := parseCoordinate(lonValue, 180)
+	if err != nil {
+		return Location{}, fmt.Errorf("invalid longitude in location %q: %w", value, err)
+	}
+
+	return Location{Latitude: latitude, Longitude: longitude}, nil
+}
+
+func parseCoordinate(value string, limit float64) (float64, error) {
+	coordinate, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || math.IsNaN(coordinate) {
+		return 0, fmt.Errorf("%q is not a number", strings.TrimSpace(value))
+	}
+	if coordinate < -limit || coordinate > limit {
+		return 0, fmt.Errorf("%v out of range [%v, %v]", coordinate, -limit, limit)
+	}
+	return coordinate, nil
+}
+
+func (l *Location) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		var value string
+		if err := node.Decode(&value); err != nil {
+			return err
+		}
+
+		parsed, err := ParseCoordinates(value)
+		if err != nil {
+			return err
+		}
+		*l = parsed
+		return nil
+	}
+
+	// plain has no UnmarshalYAML method, so that decoding it does not recurse.
+	type plain Location
+	return node.Decode((*plain)(l))
+}
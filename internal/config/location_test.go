@@ -0,0 +1,92 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"com.github.yveskaufmann/hue-lighter/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCoordinates(t *testing.T) {
+	tests := []struct {
+		name           string
+		value          string
+		expected       Location
+		wantErr        bool
+		expectedErrMsg string
+	}{
+		{name: "parses coordinates", value: "52.52,13.405", expected: Location{Latitude: 52.52, Longitude: 13.405}},
+		{name: "ignores spaces", value: " -33.8688 , 151.2093 ", expected: Location{Latitude: -33.8688, Longitude: 151.2093}},
+		{name: "rejects missing longitude", value: "52.52", wantErr: true, expectedErrMsg: `invalid location "52.52", expected "latitude,longitude"`},
+		{name: "rejects non-numeric latitude", value: "north,13.405", wantErr: true, expectedErrMsg: `invalid latitude in location "north,13.405": "north" is not a number`},
+		{name: "rejects NaN", value: "NaN,13.405", wantErr: true, expectedErrMsg: `invalid latitude in location "NaN,13.405": "NaN" is not a number`},
+		{name: "rejects out of range latitude", value: "91,13.405", wantErr: true, expectedErrMsg: `invalid latitude in location "91,13.405": 91 out of range [-90, 90]`},
+		{name: "rejects out of range longitude", value: "52.52,181", wantErr: true, expectedErrMsg: `invalid longitude in location "52.52,181": 181 out of range [-180, 180]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			location, err := ParseCoordinates(tt.value)
+
+			if tt.wantErr {
+				assert.EqualError(t, err, tt.expectedErrMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, location)
+		})
+	}
+}
+
+func TestLoadConfig_LocationRepresentations(t *testing.T) {
+	nested := testutils.ValidHueConfigYAML()
+	inline := strings.Replace(nested, "location:\n  latitude: 52.5\n  longitude: 13.4", `location: "52.5, 13.4"`, 1)
+	require.NotEqual(t, nested, inline)
+
+	load := func(content string) *Config {
+		configPath := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(configPath, []byte(content), 0644))
+		config, err := LoadConfig(configPath)
+		require.NoError(t, err)
+		return config
+	}
+
+	assert.Equal(t, load(nested), load(inline))
+	location := load(inline).Location
+	assert.Equal(t, 52.5, location.Latitude)
+	assert.Equal(t, 13.4, location.Longitude)
+}
+
+func TestLoadConfig_InvalidLocationString(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	content := "location: \"52.5;13.4\"\nlights:\n  - id: \"light-1\"\n    name: \"Test Light 1\"\n"
+	require.NoError(t, os.WriteFile(configPath, []byte(content), 0644))
+
+	_, err := LoadConfig(configPath)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid location "52.5;13.4", expected "latitude,longitude"`)
+}
+
+func TestLoadConfig_ResolvesTimeZoneOnce(t *testing.T) {
+	content := strings.Replace(testutils.ValidHueConfigYAML(), "longitude: 13.4", "longitude: 13.4\n  timezone: Europe/Berlin", 1)
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(content), 0644))
+
+	config, err := LoadConfig(configPath)
+	require.NoError(t, err)
+
+	require.NotNil(t, config.Location.timeZone)
+	assert.Equal(t, "Europe/Berlin", config.Location.timeZone.String())
+	assert.Same(t, config.Location.timeZone, config.Location.TimeZone())
+}
+
+func TestLocation_TimeZone_WithoutLoadConfig(t *testing.T) {
+	assert.Equal(t, time.Local, Location{}.TimeZone())
+	assert.Equal(t, "Asia/Tokyo", Location{Timezone: "Asia/Tokyo"}.TimeZone().String())
+}
@@ -2,12 +2,15 @@ package config
 
 import (
 	"testing"
+	"time"
 
+	"com.github.yveskaufmann/hue-lighter/internal/geolocation"
+	hueclient "com.github.yveskaufmann/hue-lighter/internal/hue_client"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
-func TestConfig_validate(t *testing.T) {
+func TestConfig_Validate(t *testing.T) {
 	tests := []struct {
 		name    string
 		config  *Config
@@ -23,17 +26,11 @@ func TestConfig_validate(t *testing.T) {
 		{
 			name: "valid config with valid coordinates",
 			config: &Config{
-				Location: struct {
-					Latitude  float64 `yaml:"latitude"`
-					Longitude float64 `yaml:"longitude"`
-				}{
+				Location: Location{
 					Latitude:  52.5,
 					Longitude: 13.4,
 				},
-				Lights: []struct {
-					ID   *string `yaml:"id"`
-					Name *string `yaml:"name"`
-				}{
+				Lights: []LightConfig{
 					{ID: stringPtr("light-1")},
 				},
 			},
@@ -42,17 +39,11 @@ func TestConfig_validate(t *testing.T) {
 		{
 			name: "valid config with edge case coordinates",
 			config: &Config{
-				Location: struct {
-					Latitude  float64 `yaml:"latitude"`
-					Longitude float64 `yaml:"longitude"`
-				}{
+				Location: Location{
 					Latitude:  90.0,
 					Longitude: 180.0,
 				},
-				Lights: []struct {
-					ID   *string `yaml:"id"`
-					Name *string `yaml:"name"`
-				}{
+				Lights: []LightConfig{
 					{Name: stringPtr("test-light")},
 				},
 			},
@@ -61,17 +52,11 @@ func TestConfig_validate(t *testing.T) {
 		{
 			name: "valid config with negative edge case coordinates",
 			config: &Config{
-				Location: struct {
-					Latitude  float64 `yaml:"latitude"`
-					Longitude float64 `yaml:"longitude"`
-				}{
+				Location: Location{
 					Latitude:  -90.0,
 					Longitude: -180.0,
 				},
-				Lights: []struct {
-					ID   *string `yaml:"id"`
-					Name *string `yaml:"name"`
-				}{
+				Lights: []LightConfig{
 					{ID: stringPtr("light-1"), Name: stringPtr("light-name")},
 				},
 			},
@@ -80,10 +65,7 @@ func TestConfig_validate(t *testing.T) {
 		{
 			name: "invalid latitude too high",
 			config: &Config{
-				Location: struct {
-					Latitude  float64 `yaml:"latitude"`
-					Longitude float64 `yaml:"longitude"`
-				}{
+				Location: Location{
 					Latitude:  91.0,
 					Longitude: 0.0,
 				},
@@ -94,10 +76,7 @@ func TestConfig_validate(t *testing.T) {
 		{
 			name: "invalid latitude too low",
 			config: &Config{
-				Location: struct {
-					Latitude  float64 `yaml:"latitude"`
-					Longitude float64 `yaml:"longitude"`
-				}{
+				Location: Location{
 					Latitude:  -91.0,
 					Longitude: 0.0,
 				},
@@ -108,10 +87,7 @@ func TestConfig_validate(t *testing.T) {
 		{
 			name: "invalid longitude too high",
 			config: &Config{
-				Location: struct {
-					Latitude  float64 `yaml:"latitude"`
-					Longitude float64 `yaml:"longitude"`
-				}{
+				Location: Location{
 					Latitude:  0.0,
 					Longitude: 181.0,
 				},
@@ -122,10 +98,7 @@ func TestConfig_validate(t *testing.T) {
 		{
 			name: "invalid longitude too low",
 			config: &Config{
-				Location: struct {
-					Latitude  float64 `yaml:"latitude"`
-					Longitude float64 `yaml:"longitude"`
-				}{
+				Location: Location{
 					Latitude:  0.0,
 					Longitude: -181.0,
 				},
@@ -136,17 +109,11 @@ func TestConfig_validate(t *testing.T) {
 		{
 			name: "light with neither ID nor name",
 			config: &Config{
-				Location: struct {
-					Latitude  float64 `yaml:"latitude"`
-					Longitude float64 `yaml:"longitude"`
-				}{
+				Location: Location{
 					Latitude:  52.5,
 					Longitude: 13.4,
 				},
-				Lights: []struct {
-					ID   *string `yaml:"id"`
-					Name *string `yaml:"name"`
-				}{
+				Lights: []LightConfig{
 					{}, // Neither ID nor Name set
 				},
 			},
@@ -156,17 +123,11 @@ func TestConfig_validate(t *testing.T) {
 		{
 			name: "valid config with multiple lights",
 			config: &Config{
-				Location: struct {
-					Latitude  float64 `yaml:"latitude"`
-					Longitude float64 `yaml:"longitude"`
-				}{
+				Location: Location{
 					Latitude:  52.5,
 					Longitude: 13.4,
 				},
-				Lights: []struct {
-					ID   *string `yaml:"id"`
-					Name *string `yaml:"name"`
-				}{
+				Lights: []LightConfig{
 					{ID: stringPtr("light-1")},
 					{Name: stringPtr("light-2")},
 					{ID: stringPtr("light-3"), Name: stringPtr("light-3-name")},
@@ -177,34 +138,22 @@ func TestConfig_validate(t *testing.T) {
 		{
 			name: "valid config with empty lights array",
 			config: &Config{
-				Location: struct {
-					Latitude  float64 `yaml:"latitude"`
-					Longitude float64 `yaml:"longitude"`
-				}{
+				Location: Location{
 					Latitude:  52.5,
 					Longitude: 13.4,
 				},
-				Lights: []struct {
-					ID   *string `yaml:"id"`
-					Name *string `yaml:"name"`
-				}{},
+				Lights: []LightConfig{},
 			},
 			wantErr: false,
 		},
 		{
 			name: "mixed valid and invalid lights",
 			config: &Config{
-				Location: struct {
-					Latitude  float64 `yaml:"latitude"`
-					Longitude float64 `yaml:"longitude"`
-				}{
+				Location: Location{
 					Latitude:  52.5,
 					Longitude: 13.4,
 				},
-				Lights: []struct {
-					ID   *string `yaml:"id"`
-					Name *string `yaml:"name"`
-				}{
+				Lights: []LightConfig{
 					{ID: stringPtr("light-1")},
 					{}, // Invalid light
 				},
@@ -212,11 +161,277 @@ func TestConfig_validate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "light must have either ID or Name",
 		},
+		{
+			name: "light with brightness and min brightness in range",
+			config: &Config{
+				Lights: []LightConfig{
+					{ID: stringPtr("light-1"), Brightness: float32Ptr(1), MinBrightness: float32Ptr(10)},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "light with brightness out of range",
+			config: &Config{
+				Lights: []LightConfig{
+					{ID: stringPtr("light-1"), Brightness: float32Ptr(101)},
+				},
+			},
+			wantErr: true,
+			errMsg:  "light brightness must be in range",
+		},
+		{
+			name: "light with negative min brightness",
+			config: &Config{
+				Lights: []LightConfig{
+					{ID: stringPtr("light-1"), MinBrightness: float32Ptr(-1)},
+				},
+			},
+			wantErr: true,
+			errMsg:  "light min_brightness must be in range",
+		},
+		{
+			name: "complete color temperature gradient",
+			config: configWith(func(c *Config) {
+				c.ColorTemperature.StartMirek = intPtr(250)
+				c.ColorTemperature.EndMirek = intPtr(454)
+				c.ColorTemperature.EndTime = &ClockTime{Hour: 23}
+			}),
+			wantErr: false,
+		},
+		{
+			name: "incomplete color temperature gradient",
+			config: configWith(func(c *Config) {
+				c.ColorTemperature.StartMirek = intPtr(250)
+				c.ColorTemperature.EndTime = &ClockTime{Hour: 23}
+			}),
+			wantErr: true,
+			errMsg:  "color_temperature requires start_mirek, end_mirek and end_time",
+		},
+		{
+			name: "color temperature mirek out of range",
+			config: configWith(func(c *Config) {
+				c.ColorTemperature.StartMirek = intPtr(100)
+				c.ColorTemperature.EndMirek = intPtr(454)
+				c.ColorTemperature.EndTime = &ClockTime{Hour: 23}
+			}),
+			wantErr: true,
+			errMsg:  "color_temperature mirek 100 out of range",
+		},
+		{
+			name:    "valid app name",
+			config:  configWith(func(c *Config) { c.Meta.AppName = "hue-lighter-office" }),
+			wantErr: false,
+		},
+		{
+			name:    "app name at length limit",
+			config:  configWith(func(c *Config) { c.Meta.AppName = "abcdefghijklmnopqrst" }),
+			wantErr: false,
+		},
+		{
+			name:    "app name too long",
+			config:  configWith(func(c *Config) { c.Meta.AppName = "abcdefghijklmnopqrstu" }),
+			wantErr: true,
+			errMsg:  "meta.app_name must be at most 20 characters",
+		},
+		{
+			name:    "app name with separator",
+			config:  configWith(func(c *Config) { c.Meta.AppName = "hue#office" }),
+			wantErr: true,
+			errMsg:  "meta.app_name must not contain '#'",
+		},
+		{
+			name: "valid brightness schedule",
+			config: configWith(func(c *Config) {
+				c.BrightnessSchedule = []BrightnessPoint{
+					{Offset: -30 * time.Minute, Brightness: 100},
+					{Offset: 2 * time.Hour, Brightness: 20},
+				}
+			}),
+			wantErr: false,
+		},
+		{
+			name: "brightness schedule out of order",
+			config: configWith(func(c *Config) {
+				c.BrightnessSchedule = []BrightnessPoint{
+					{Offset: 2 * time.Hour, Brightness: 100},
+					{Offset: time.Hour, Brightness: 20},
+				}
+			}),
+			wantErr: true,
+			errMsg:  "brightness_schedule point 1: offsets must be in ascending order",
+		},
+		{
+			name:    "brightness schedule brightness out of range",
+			config:  configWith(func(c *Config) { c.BrightnessSchedule = []BrightnessPoint{{Offset: 0, Brightness: 0}} }),
+			wantErr: true,
+			errMsg:  "brightness_schedule point 0: brightness must be in range (0, 100]",
+		},
+		{
+			name: "brightness schedule with easing",
+			config: configWith(func(c *Config) {
+				c.BrightnessSchedule = []BrightnessPoint{
+					{Offset: 0, Brightness: 100},
+					{Offset: 2 * time.Hour, Brightness: 20, Easing: EasingEaseInOut},
+				}
+			}),
+			wantErr: false,
+		},
+		{
+			name: "brightness schedule with unknown easing",
+			config: configWith(func(c *Config) {
+				c.BrightnessSchedule = []BrightnessPoint{{Offset: 0, Brightness: 100, Easing: "bounce"}}
+			}),
+			wantErr: true,
+			errMsg:  `brightness_schedule point 0: easing must be "linear", "ease-in", "ease-out" or "ease-in-out", got "bounce"`,
+		},
+		{
+			name:    "valid discovery subnet",
+			config:  configWith(func(c *Config) { c.Discovery.Subnet = "192.168.1.0/24" }),
+			wantErr: false,
+		},
+		{
+			name:    "discovery subnet without prefix length",
+			config:  configWith(func(c *Config) { c.Discovery.Subnet = "192.168.1.0" }),
+			wantErr: true,
+			errMsg:  "discovery.subnet must be in CIDR notation",
+		},
+		{
+			name:    "shutdown turns off owned lights",
+			config:  configWith(func(c *Config) { c.Shutdown.TurnOff = ShutdownTurnOffOwned }),
+			wantErr: false,
+		},
+		{
+			name:    "unknown shutdown turn off policy",
+			config:  configWith(func(c *Config) { c.Shutdown.TurnOff = "some" }),
+			wantErr: true,
+			errMsg:  `shutdown.turn_off must be "all" or "owned", got "some"`,
+		},
+		{
+			name:    "shutdown scene",
+			config:  configWith(func(c *Config) { c.ShutdownScene = stringPtr("ssssssss-ssss-ssss-ssss-ssssssssssss") }),
+			wantErr: false,
+		},
+		{
+			name:    "empty shutdown scene",
+			config:  configWith(func(c *Config) { c.ShutdownScene = stringPtr(" ") }),
+			wantErr: true,
+			errMsg:  "shutdown_scene must not be empty, remove it to turn off the lights on shutdown",
+		},
+		{
+			name: "wake-up with color temperature",
+			config: configWith(func(c *Config) {
+				c.WakeUp.Duration = 30 * time.Minute
+				c.WakeUp.Brightness = 80
+				c.WakeUp.StartMirek = intPtr(454)
+				c.WakeUp.EndMirek = intPtr(250)
+			}),
+			wantErr: false,
+		},
+		{
+			name: "negative wake-up duration",
+			config: configWith(func(c *Config) {
+				c.WakeUp.Duration = -time.Minute
+				c.WakeUp.Brightness = 80
+			}),
+			wantErr: true,
+			errMsg:  "wake_up.duration must be positive",
+		},
+		{
+			name: "wake-up brightness above 100",
+			config: configWith(func(c *Config) {
+				c.WakeUp.Duration = 30 * time.Minute
+				c.WakeUp.Brightness = 120
+			}),
+			wantErr: true,
+			errMsg:  "wake_up.brightness must be in range (0, 100]",
+		},
+		{
+			name: "wake-up with start mirek only",
+			config: configWith(func(c *Config) {
+				c.WakeUp.Duration = 30 * time.Minute
+				c.WakeUp.Brightness = 80
+				c.WakeUp.StartMirek = intPtr(454)
+			}),
+			wantErr: true,
+			errMsg:  "wake_up requires both start_mirek and end_mirek or none",
+		},
+		{
+			name: "wake-up mirek out of range",
+			config: configWith(func(c *Config) {
+				c.WakeUp.Duration = 30 * time.Minute
+				c.WakeUp.Brightness = 80
+				c.WakeUp.StartMirek = intPtr(454)
+				c.WakeUp.EndMirek = intPtr(100)
+			}),
+			wantErr: true,
+			errMsg:  "wake_up mirek 100 out of range [153, 500]",
+		},
+		{
+			name: "wake-up with unknown easing",
+			config: configWith(func(c *Config) {
+				c.WakeUp.Duration = 30 * time.Minute
+				c.WakeUp.Brightness = 80
+				c.WakeUp.Easing = "smooth"
+			}),
+			wantErr: true,
+			errMsg:  `wake_up.easing must be "linear", "ease-in", "ease-out" or "ease-in-out", got "smooth"`,
+		},
+		{
+			name:    "auto light API",
+			config:  configWith(func(c *Config) { c.Bridge.LightAPI = hueclient.LightAPIAuto }),
+			wantErr: false,
+		},
+		{
+			name:    "unknown light API",
+			config:  configWith(func(c *Config) { c.Bridge.LightAPI = "v3" }),
+			wantErr: true,
+			errMsg:  `bridge.light_api must be "v2", "v1" or "auto", got "v3"`,
+		},
+		{
+			name:    "ip location source",
+			config:  configWith(func(c *Config) { c.Location.Source = geolocation.SourceIP }),
+			wantErr: false,
+		},
+		{
+			name:    "unknown location source",
+			config:  configWith(func(c *Config) { c.Location.Source = "bridge" }),
+			wantErr: true,
+			errMsg:  `location.source must be "ip", got "bridge"`,
+		},
+		{
+			name:    "known timezone",
+			config:  configWith(func(c *Config) { c.Location.Timezone = "Europe/Berlin" }),
+			wantErr: false,
+		},
+		{
+			name:    "unknown timezone",
+			config:  configWith(func(c *Config) { c.Location.Timezone = "Europe/Atlantis" }),
+			wantErr: true,
+			errMsg:  `location.timezone: unknown time zone Europe/Atlantis`,
+		},
+		{
+			name:    "max brightness in range",
+			config:  configWith(func(c *Config) { c.Automation.MaxBrightness = float32Ptr(80) }),
+			wantErr: false,
+		},
+		{
+			name:    "zero max brightness",
+			config:  configWith(func(c *Config) { c.Automation.MaxBrightness = float32Ptr(0) }),
+			wantErr: true,
+			errMsg:  "automation.max_brightness must be in range (0, 100]",
+		},
+		{
+			name:    "max brightness above 100",
+			config:  configWith(func(c *Config) { c.Automation.MaxBrightness = float32Ptr(101) }),
+			wantErr: true,
+			errMsg:  "automation.max_brightness must be in range (0, 100]",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := tt.config.validate()
+			err := tt.config.Validate()
 
 			if tt.wantErr {
 				require.Error(t, err)
@@ -232,3 +447,20 @@ func TestConfig_validate(t *testing.T) {
 func stringPtr(s string) *string {
 	return &s
 }
+
+// Helper function to create float32 pointers for testing
+func float32Ptr(f float32) *float32 {
+	return &f
+}
+
+// Helper function to create int pointers for testing
+func intPtr(i int) *int {
+	return &i
+}
+
+// configWith returns an empty config modified by mutate.
+func configWith(mutate func(c *Config)) *Config {
+	config := &Config{}
+	mutate(config)
+	return config
+}
@@ -0,0 +1,216 @@
+// Package geolocation resolves the coordinates of the host, so that the location
+// does not need to be configured for the sunset and sunrise calculation.
+package geolocation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"time"
+
+	hueclient "com.github.yveskaufmann/hue-lighter/internal/hue_client"
+	log "github.com/sirupsen/logrus"
+)
+
+// Source selects where the location is resolved from.
+//
+// The Hue bridge is not offered as source, its geolocation resource only
+// reports whether a location is configured but not the coordinates.
+type Source string
+
+const (
+	// SourceIP resolves the approximate location from the public IP of the host.
+	SourceIP Source = "ip"
+)
+
+// DefaultCachePath is the file in which the resolved location is cached.
+const DefaultCachePath = "/var/lib/hue-lighter/location.json"
+
+// DefaultLookupTimeout limits the IP geolocation lookup.
+const DefaultLookupTimeout = 10 * time.Second
+
+const ipLookupURL = "https://ipapi.co/json/"
+
+// ErrInvalidCoordinates is returned if the looked up coordinates are out of range.
+var ErrInvalidCoordinates = errors.New("invalid coordinates")
+
+// Coordinates of a location in decimal degrees.
+type Coordinates struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+func (c Coordinates) valid() bool {
+	return c.Latitude >= -90 && c.Latitude <= 90 && c.Longitude >= -180 && c.Longitude <= 180
+}
+
+// ipLookupResponse is the response of the IP geolocation service, it reports
+// failures like rate limits with error and reason.
+type ipLookupResponse struct {
+	Coordinates
+	Error  bool   `json:"error"`
+	Reason string `json:"reason"`
+}
+
+// Locator resolves the location once and caches it in a file, later calls and
+// restarts read the cache. Remove the cache file to resolve the location again.
+type Locator struct {
+	logger        *log.Entry
+	cachePath     string
+	lookupURL     string
+	lookupTimeout time.Duration
+	httpClient    *http.Client
+}
+
+// Option configures optional behaviour of the Locator.
+type Option func(*Locator)
+
+// WithCachePath sets the cache file, defaults to DefaultCachePath. An empty path disables the cache.
+func WithCachePath(cachePath string) Option {
+	return func(l *Locator) {
+		l.cachePath = cachePath
+	}
+}
+
+// WithLookupURL overrides the IP geolocation service, it must answer with a JSON
+// object containing latitude and longitude.
+func WithLookupURL(url string) Option {
+	return func(l *Locator) {
+		l.lookupURL = url
+	}
+}
+
+// WithLookupTimeout limits the IP geolocation lookup, defaults to DefaultLookupTimeout.
+func WithLookupTimeout(timeout time.Duration) Option {
+	return func(l *Locator) {
+		if timeout > 0 {
+			l.lookupTimeout = timeout
+		}
+	}
+}
+
+// WithHTTPClient sets the client used for the lookup, defaults to http.DefaultClient.
+func WithHTTPClient(client *http.Client) Option {
+	return func(l *Locator) {
+		l.httpClient = client
+	}
+}
+
+func NewLocator(logger *log.Entry, opts ...Option) *Locator {
+	l := &Locator{
+		logger:        logger,
+		cachePath:     DefaultCachePath,
+		lookupURL:     ipLookupURL,
+		lookupTimeout: DefaultLookupTimeout,
+		httpClient:    http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Resolve returns the cached location or looks it up from the source and caches it.
+func (l *Locator) Resolve(ctx context.Context, source Source) (Coordinates, error) {
+	if source != SourceIP {
+		return Coordinates{}, fmt.Errorf("unsupported location source %q", source)
+	}
+
+	if coordinates, ok := l.readCache(); ok {
+		l.logger.WithField("cachePath", l.cachePath).Debug("Using cached location")
+		return coordinates, nil
+	}
+
+	coordinates, err := l.lookupIP(ctx)
+	if err != nil {
+		return Coordinates{}, fmt.Errorf("failed to resolve location via IP geolocation: %w", err)
+	}
+	l.logger.WithFields(log.Fields{"latitude": coordinates.Latitude, "longitude": coordinates.Longitude}).
+		Info("Resolved location via IP geolocation")
+
+	if err := l.writeCache(coordinates); err != nil {
+		// The location is resolved again on the next start.
+		l.logger.WithError(err).Warn("Failed to cache location")
+	}
+
+	return coordinates, nil
+}
+
+func (l *Locator) lookupIP(ctx context.Context) (Coordinates, error) {
+	ctx, cancel := context.WithTimeout(ctx, l.lookupTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, l.lookupURL, nil)
+	if err != nil {
+		return Coordinates{}, fmt.Errorf("failed to create geolocation request: %w", err)
+	}
+	req.Header.Set("User-Agent", hueclient.DefaultUserAgent())
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := l.httpClient.Do(req)
+	if err != nil {
+		return Coordinates{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return Coordinates{}, fmt.Errorf("request failed with status code: %d, response: %s", resp.StatusCode, body)
+	}
+
+	var result ipLookupResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return Coordinates{}, fmt.Errorf("failed to decode geolocation response: %w", err)
+	}
+	if result.Error {
+		return Coordinates{}, fmt.Errorf("geolocation lookup failed: %s", result.Reason)
+	}
+	if !result.Coordinates.valid() {
+		return Coordinates{}, fmt.Errorf("%w: %v, %v", ErrInvalidCoordinates, result.Latitude, result.Longitude)
+	}
+
+	return result.Coordinates, nil
+}
+
+// readCache returns the cached location, a missing or broken cache is ignored.
+func (l *Locator) readCache() (Coordinates, bool) {
+	if l.cachePath == "" {
+		return Coordinates{}, false
+	}
+
+	data, err := os.ReadFile(l.cachePath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			l.logger.WithError(err).Warn("Failed to read cached location")
+		}
+		return Coordinates{}, false
+	}
+
+	var coordinates Coordinates
+	if err := json.Unmarshal(data, &coordinates); err != nil || !coordinates.valid() {
+		l.logger.WithField("cachePath", l.cachePath).Warn("Ignoring invalid cached location")
+		return Coordinates{}, false
+	}
+	return coordinates, true
+}
+
+func (l *Locator) writeCache(coordinates Coordinates) error {
+	if l.cachePath == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(path.Dir(l.cachePath), 0700); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(coordinates)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(l.cachePath, data, 0600)
+}
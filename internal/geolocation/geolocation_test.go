@@ -0,0 +1,124 @@
+package geolocation
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"com.github.yveskaufmann/hue-lighter/internal/logging"
+	"com.github.yveskaufmann/hue-lighter/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLocator(t *testing.T, lookupURL string) (*Locator, string) {
+	t.Helper()
+	cachePath := filepath.Join(t.TempDir(), "hue-lighter", "location.json")
+	return NewLocator(logging.NewDiscardLogger(), WithCachePath(cachePath), WithLookupURL(lookupURL)), cachePath
+}
+
+func TestLocator_Resolve(t *testing.T) {
+	tests := []struct {
+		name        string
+		statusCode  int
+		response    interface{}
+		expected    Coordinates
+		wantErr     bool
+		expectedErr string
+	}{
+		{
+			name:       "resolves coordinates",
+			statusCode: http.StatusOK,
+			response:   map[string]interface{}{"ip": "203.0.113.7", "city": "Berlin", "latitude": 52.52, "longitude": 13.405},
+			expected:   Coordinates{Latitude: 52.52, Longitude: 13.405},
+		},
+		{
+			name:        "fails on error response",
+			statusCode:  http.StatusOK,
+			response:    map[string]interface{}{"error": true, "reason": "RateLimited"},
+			wantErr:     true,
+			expectedErr: "geolocation lookup failed: RateLimited",
+		},
+		{
+			name:        "fails on status code",
+			statusCode:  http.StatusTooManyRequests,
+			response:    map[string]string{"message": "too many requests"},
+			wantErr:     true,
+			expectedErr: "request failed with status code: 429",
+		},
+		{
+			name:        "fails on invalid coordinates",
+			statusCode:  http.StatusOK,
+			response:    map[string]float64{"latitude": 123, "longitude": 13.405},
+			wantErr:     true,
+			expectedErr: "invalid coordinates",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, recorder := testutils.MockHueBridgeRecorder(tt.statusCode, tt.response)
+			defer server.Close()
+			locator, cachePath := newTestLocator(t, server.URL)
+
+			coordinates, err := locator.Resolve(context.Background(), SourceIP)
+
+			require.Len(t, recorder.Requests(), 1)
+			assert.Contains(t, recorder.Requests()[0].Header.Get("User-Agent"), "hue-lighter/")
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErr)
+				assert.NoFileExists(t, cachePath)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, coordinates)
+			assert.FileExists(t, cachePath)
+		})
+	}
+}
+
+func TestLocator_Resolve_UsesCache(t *testing.T) {
+	server, recorder := testutils.MockHueBridgeRecorder(http.StatusOK, map[string]float64{"latitude": 48.14, "longitude": 11.58})
+	defer server.Close()
+	locator, cachePath := newTestLocator(t, server.URL)
+
+	first, err := locator.Resolve(context.Background(), SourceIP)
+	require.NoError(t, err)
+
+	// A new locator, e.g. after a restart, reads the cache
+	second, err := NewLocator(logging.NewDiscardLogger(), WithCachePath(cachePath), WithLookupURL(server.URL)).
+		Resolve(context.Background(), SourceIP)
+	require.NoError(t, err)
+
+	assert.Equal(t, first, second)
+	assert.Len(t, recorder.Requests(), 1)
+
+	info, err := os.Stat(cachePath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestLocator_Resolve_IgnoresInvalidCache(t *testing.T) {
+	server, recorder := testutils.MockHueBridgeRecorder(http.StatusOK, map[string]float64{"latitude": 48.14, "longitude": 11.58})
+	defer server.Close()
+	locator, cachePath := newTestLocator(t, server.URL)
+	require.NoError(t, os.MkdirAll(filepath.Dir(cachePath), 0700))
+	require.NoError(t, os.WriteFile(cachePath, []byte("not json"), 0600))
+
+	coordinates, err := locator.Resolve(context.Background(), SourceIP)
+
+	require.NoError(t, err)
+	assert.Equal(t, Coordinates{Latitude: 48.14, Longitude: 11.58}, coordinates)
+	assert.Len(t, recorder.Requests(), 1)
+}
+
+func TestLocator_Resolve_UnsupportedSource(t *testing.T) {
+	locator, _ := newTestLocator(t, "http://127.0.0.1:0")
+
+	_, err := locator.Resolve(context.Background(), "bridge")
+
+	assert.EqualError(t, err, `unsupported location source "bridge"`)
+}
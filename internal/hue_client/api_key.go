@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"sort"
+	"sync"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -12,13 +14,52 @@ import (
 
 var ErrMissingAPIKey = fmt.Errorf("missing API key for Hue bridge")
 
+// APIKeyStore stores the API keys by identity, an identity is the bridge ID and
+// the device name joined by '#', so that several devices can register at a bridge.
 type APIKeyStore interface {
 	Get(bridgeID string) (string, error)
 	Set(bridgeID string, apiKey string) error
 	Remove(bridgeID string) error
+	// List returns the sorted identities of all stored API keys, but not the keys.
+	List() ([]string, error)
 }
 
+// SecretRegistry receives secrets which must not appear in the logs, it is
+// implemented by *logging.Redactor.
+type SecretRegistry interface {
+	AddSecret(secret string)
+}
+
+// redactingAPIKeyStore registers every API key read from or written to the
+// wrapped store, so that the logs redact it.
+type redactingAPIKeyStore struct {
+	APIKeyStore
+	secrets SecretRegistry
+}
+
+// NewRedactingAPIKeyStore wraps the store and registers every API key passing
+// through it at the secret registry.
+func NewRedactingAPIKeyStore(store APIKeyStore, secrets SecretRegistry) APIKeyStore {
+	return &redactingAPIKeyStore{APIKeyStore: store, secrets: secrets}
+}
+
+func (s *redactingAPIKeyStore) Get(bridgeID string) (string, error) {
+	apiKey, err := s.APIKeyStore.Get(bridgeID)
+	if err != nil {
+		return "", err
+	}
+	s.secrets.AddSecret(apiKey)
+	return apiKey, nil
+}
+
+func (s *redactingAPIKeyStore) Set(bridgeID string, apiKey string) error {
+	s.secrets.AddSecret(apiKey)
+	return s.APIKeyStore.Set(bridgeID, apiKey)
+}
+
+// InMemoryAPIKeyStore is safe for concurrent use, e.g. by the automation and the event service.
 type InMemoryAPIKeyStore struct {
+	mu     sync.RWMutex
 	store  map[string]string
 	logger *log.Entry
 }
@@ -26,12 +67,14 @@ type InMemoryAPIKeyStore struct {
 func NewInMemoryAPIKeyStore(logger *log.Entry) *InMemoryAPIKeyStore {
 	return &InMemoryAPIKeyStore{
 		store:  make(map[string]string),
-		logger: logger.WithField("component", "InMemoryAPIKeyStore"),
+		logger: componentLogger(logger, "InMemoryAPIKeyStore"),
 	}
 }
 
 func (s *InMemoryAPIKeyStore) Get(bridgeID string) (string, error) {
+	s.mu.RLock()
 	apiKey, exists := s.store[bridgeID]
+	s.mu.RUnlock()
 	if !exists {
 		s.logger.Warnf("API key for bridge %s not found", bridgeID)
 		return "", ErrMissingAPIKey
@@ -40,20 +83,58 @@ func (s *InMemoryAPIKeyStore) Get(bridgeID string) (string, error) {
 }
 
 func (s *InMemoryAPIKeyStore) Set(bridgeID string, apiKey string) error {
+	s.mu.Lock()
 	s.store[bridgeID] = apiKey
+	s.mu.Unlock()
 	s.logger.Infof("Stored API key for bridge %s (redacted)", bridgeID)
 	return nil
 }
 
 func (s *InMemoryAPIKeyStore) Remove(bridgeID string) error {
+	s.mu.Lock()
 	delete(s.store, bridgeID)
+	s.mu.Unlock()
 	s.logger.Infof("Removed API key for bridge %s", bridgeID)
 
 	return nil
 }
 
+func (s *InMemoryAPIKeyStore) List() ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	identities := make([]string, 0, len(s.store))
+	for identity := range s.store {
+		identities = append(identities, identity)
+	}
+	sort.Strings(identities)
+	return identities, nil
+}
+
+// replaceAll replaces all stored keys, e.g. with those loaded from a file.
+func (s *InMemoryAPIKeyStore) replaceAll(keys map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.store = keys
+}
+
+// snapshot returns a copy of all stored keys.
+func (s *InMemoryAPIKeyStore) snapshot() map[string]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make(map[string]string, len(s.store))
+	for bridgeID, apiKey := range s.store {
+		keys[bridgeID] = apiKey
+	}
+	return keys
+}
+
+// FileAPIKeyStore is safe for concurrent use, loading and saving the file is serialized.
 type FileAPIKeyStore struct {
-	store             InMemoryAPIKeyStore
+	// mu serializes the operations, so that a save does not overwrite a concurrent change
+	mu                sync.Mutex
+	store             *InMemoryAPIKeyStore
 	filePath          string
 	lastLoadTimestamp time.Time
 	refreshInterval   time.Duration
@@ -61,9 +142,9 @@ type FileAPIKeyStore struct {
 }
 
 func NewFileAPIKeyStore(filePath string, logger *log.Entry) (*FileAPIKeyStore, error) {
-	logger = logger.WithField("component", "FileAPIKeyStore")
+	logger = componentLogger(logger, "FileAPIKeyStore")
 
-	memoryStore := InMemoryAPIKeyStore{
+	memoryStore := &InMemoryAPIKeyStore{
 		store:  make(map[string]string),
 		logger: logger,
 	}
@@ -104,10 +185,12 @@ func (s *FileAPIKeyStore) load() error {
 
 	defer file.Close()
 
+	keys := make(map[string]string)
 	decoder := json.NewDecoder(file)
-	if err = decoder.Decode(&s.store.store); err != nil {
+	if err = decoder.Decode(&keys); err != nil {
 		return err
 	}
+	s.store.replaceAll(keys)
 
 	s.lastLoadTimestamp = time.Now()
 	s.logger.WithFields(log.Fields{"storePath": s.filePath}).Info("Loaded API keys from file store")
@@ -129,7 +212,7 @@ func (s *FileAPIKeyStore) save() error {
 	defer file.Close()
 
 	encoder := json.NewEncoder(file)
-	if err = encoder.Encode(s.store.store); err != nil {
+	if err = encoder.Encode(s.store.snapshot()); err != nil {
 		return err
 	}
 
@@ -139,6 +222,9 @@ func (s *FileAPIKeyStore) save() error {
 }
 
 func (s *FileAPIKeyStore) Get(bridgeID string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	if err := s.load(); err != nil {
 		return "", err
 	}
@@ -147,6 +233,9 @@ func (s *FileAPIKeyStore) Get(bridgeID string) (string, error) {
 }
 
 func (s *FileAPIKeyStore) Set(bridgeID string, apiKey string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	if err := s.load(); err != nil {
 		return err
 	}
@@ -158,6 +247,9 @@ func (s *FileAPIKeyStore) Set(bridgeID string, apiKey string) error {
 }
 
 func (s *FileAPIKeyStore) Remove(bridgeID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	if err := s.load(); err != nil {
 		return err
 	}
@@ -167,3 +259,14 @@ func (s *FileAPIKeyStore) Remove(bridgeID string) error {
 	}
 	return s.save()
 }
+
+func (s *FileAPIKeyStore) List() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	return s.store.List()
+}
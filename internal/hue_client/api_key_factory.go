@@ -3,17 +3,44 @@ package hueclient
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 )
 
-func NewAPIKeyStore(logger *log.Entry) (APIKeyStore, error) {
+const (
+	// APIKeyStoreTypeFile persists API keys as JSON file, this is the default.
+	APIKeyStoreTypeFile = "file"
+	// APIKeyStoreTypeMemory keeps API keys in memory only, they are lost on restart.
+	APIKeyStoreTypeMemory = "memory"
+)
+
+// DefaultAPIKeyStorePath is the location of the file API key store of the installed service.
+const DefaultAPIKeyStorePath = "/var/lib/hue-lighter/api-keys.json"
 
-	// TODO: Support to use different API key stores implementations based on configuration
+// NewAPIKeyStore creates the API key store selected by `HUE_API_KEY_STORE`.
+// Supported values are "file" (default) and "memory". The file store is located
+// at `HUE_API_KEY_STORE_PATH`, the given default path or DefaultAPIKeyStorePath.
+func NewAPIKeyStore(logger *log.Entry, defaultPath string) (APIKeyStore, error) {
+
+	storeType := strings.ToLower(strings.TrimSpace(os.Getenv("HUE_API_KEY_STORE")))
+
+	switch storeType {
+	case "", APIKeyStoreTypeFile:
+		// continue below
+	case APIKeyStoreTypeMemory:
+		logger.Warn("Using in-memory API key store, API keys are not persisted and the device must be registered again after a restart")
+		return NewInMemoryAPIKeyStore(logger), nil
+	default:
+		return nil, fmt.Errorf("unsupported API key store type %q, expected %q or %q", storeType, APIKeyStoreTypeFile, APIKeyStoreTypeMemory)
+	}
 
 	apiStorePath := os.Getenv("HUE_API_KEY_STORE_PATH")
 	if apiStorePath == "" {
-		apiStorePath = "/var/lib/hue-lighter/api-keys.json"
+		apiStorePath = defaultPath
+	}
+	if apiStorePath == "" {
+		apiStorePath = DefaultAPIKeyStorePath
 	}
 
 	apiKeyStore, err := NewFileAPIKeyStore(apiStorePath, logger)
@@ -1,12 +1,16 @@
 package hueclient
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
+	"com.github.yveskaufmann/hue-lighter/internal/testutils"
 	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -106,6 +110,28 @@ func TestInMemoryAPIKeyStore(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, "new-key", apiKey)
 	})
+
+	t.Run("List identities", func(t *testing.T) {
+		store := NewInMemoryAPIKeyStore(logger)
+
+		identities, err := store.List()
+		require.NoError(t, err)
+		assert.Empty(t, identities)
+
+		require.NoError(t, store.Set("bridge-2#office", "api-key-2"))
+		require.NoError(t, store.Set("bridge-1#living-room", "api-key-1"))
+		require.NoError(t, store.Set("bridge-1#office", "api-key-3"))
+
+		identities, err = store.List()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"bridge-1#living-room", "bridge-1#office", "bridge-2#office"}, identities)
+
+		require.NoError(t, store.Remove("bridge-1#office"))
+
+		identities, err = store.List()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"bridge-1#living-room", "bridge-2#office"}, identities)
+	})
 }
 
 func TestFileAPIKeyStore(t *testing.T) {
@@ -296,9 +322,177 @@ func TestFileAPIKeyStore(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, "shared-key-2", apiKey)
 	})
+
+	t.Run("List and remove identities with file persistence", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		filePath := filepath.Join(tmpDir, "api-keys.json")
+
+		err := os.WriteFile(filePath, []byte(`{"bridge-1#office":"key-1","bridge-1#kitchen":"key-2"}`), 0600)
+		require.NoError(t, err)
+
+		store, err := NewFileAPIKeyStore(filePath, logger)
+		require.NoError(t, err)
+
+		identities, err := store.List()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"bridge-1#kitchen", "bridge-1#office"}, identities)
+
+		require.NoError(t, store.Remove("bridge-1#kitchen"))
+
+		// A new store reads the identities from the file
+		reopened, err := NewFileAPIKeyStore(filePath, logger)
+		require.NoError(t, err)
+
+		identities, err = reopened.List()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"bridge-1#office"}, identities)
+	})
 }
 
 func TestErrMissingAPIKey(t *testing.T) {
 	assert.NotNil(t, ErrMissingAPIKey)
 	assert.Contains(t, ErrMissingAPIKey.Error(), "missing API key")
 }
+
+func TestNewAPIKeyStore(t *testing.T) {
+	logger := logrus.New().WithField("test", "factory")
+
+	t.Run("returns in-memory store for memory type", func(t *testing.T) {
+		defer testutils.SetEnv(t, "HUE_API_KEY_STORE", "memory")()
+
+		store, err := NewAPIKeyStore(logger, "")
+
+		require.NoError(t, err)
+		assert.IsType(t, &InMemoryAPIKeyStore{}, store)
+	})
+
+	t.Run("returns file store by default", func(t *testing.T) {
+		defer testutils.SetEnv(t, "HUE_API_KEY_STORE", "")()
+		defer testutils.SetEnv(t, "HUE_API_KEY_STORE_PATH", filepath.Join(t.TempDir(), "api-keys.json"))()
+
+		store, err := NewAPIKeyStore(logger, "")
+
+		require.NoError(t, err)
+		assert.IsType(t, &FileAPIKeyStore{}, store)
+	})
+
+	t.Run("fails for unsupported type", func(t *testing.T) {
+		defer testutils.SetEnv(t, "HUE_API_KEY_STORE", "redis")()
+
+		store, err := NewAPIKeyStore(logger, "")
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported API key store type")
+		assert.Nil(t, store)
+	})
+}
+
+func TestNewAPIKeyStore_Path(t *testing.T) {
+	logger := logrus.New().WithField("test", "factory")
+	defer testutils.SetEnv(t, "HUE_API_KEY_STORE", "")()
+
+	t.Run("uses default path when environment is not set", func(t *testing.T) {
+		defer testutils.SetEnv(t, "HUE_API_KEY_STORE_PATH", "")()
+		defaultPath := filepath.Join(t.TempDir(), "api-keys.json")
+
+		store, err := NewAPIKeyStore(logger, defaultPath)
+
+		require.NoError(t, err)
+		assert.Equal(t, defaultPath, store.(*FileAPIKeyStore).filePath)
+	})
+
+	t.Run("environment takes precedence over default path", func(t *testing.T) {
+		envPath := filepath.Join(t.TempDir(), "env-api-keys.json")
+		defer testutils.SetEnv(t, "HUE_API_KEY_STORE_PATH", envPath)()
+
+		store, err := NewAPIKeyStore(logger, filepath.Join(t.TempDir(), "api-keys.json"))
+
+		require.NoError(t, err)
+		assert.Equal(t, envPath, store.(*FileAPIKeyStore).filePath)
+	})
+}
+
+type recordingSecretRegistry struct {
+	secrets []string
+}
+
+func (r *recordingSecretRegistry) AddSecret(secret string) {
+	r.secrets = append(r.secrets, secret)
+}
+
+func TestRedactingAPIKeyStore(t *testing.T) {
+	registry := &recordingSecretRegistry{}
+	inner := newMockAPIKeyStore()
+	inner.store["bridge-1#device"] = "stored-key"
+	store := NewRedactingAPIKeyStore(inner, registry)
+
+	key, err := store.Get("bridge-1#device")
+	require.NoError(t, err)
+	assert.Equal(t, "stored-key", key)
+
+	require.NoError(t, store.Set("bridge-2#device", "new-key"))
+	assert.Equal(t, "new-key", inner.store["bridge-2#device"])
+
+	_, err = store.Get("bridge-3#device")
+	assert.ErrorIs(t, err, ErrMissingAPIKey)
+
+	require.NoError(t, store.Remove("bridge-1#device"))
+	assert.NotContains(t, inner.store, "bridge-1#device")
+
+	assert.Equal(t, []string{"stored-key", "new-key"}, registry.secrets)
+}
+
+// hammerAPIKeyStore calls Get, Set and Remove of the store from many goroutines,
+// run with -race to detect unsynchronized access.
+func hammerAPIKeyStore(t *testing.T, store APIKeyStore, iterations int) {
+	t.Helper()
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < 8; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				bridgeID := fmt.Sprintf("bridge-%d", (worker+i)%4)
+				switch i % 3 {
+				case 0:
+					assert.NoError(t, store.Set(bridgeID, fmt.Sprintf("key-%d-%d", worker, i)))
+				case 1:
+					store.Get(bridgeID)
+				case 2:
+					assert.NoError(t, store.Remove(bridgeID))
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestInMemoryAPIKeyStore_Concurrent(t *testing.T) {
+	logger, _ := test.NewNullLogger()
+	store := NewInMemoryAPIKeyStore(logger.WithField("test", t.Name()))
+
+	hammerAPIKeyStore(t, store, 500)
+
+	require.NoError(t, store.Set("bridge-0", "final-key"))
+	apiKey, err := store.Get("bridge-0")
+	require.NoError(t, err)
+	assert.Equal(t, "final-key", apiKey)
+}
+
+func TestFileAPIKeyStore_Concurrent(t *testing.T) {
+	logger, _ := test.NewNullLogger()
+	filePath := filepath.Join(t.TempDir(), "api-keys.json")
+	store, err := NewFileAPIKeyStore(filePath, logger.WithField("test", t.Name()))
+	require.NoError(t, err)
+	store.refreshInterval = 0
+
+	hammerAPIKeyStore(t, store, 50)
+
+	require.NoError(t, store.Set("bridge-0", "final-key"))
+	reloaded, err := NewFileAPIKeyStore(filePath, logger.WithField("test", t.Name()))
+	require.NoError(t, err)
+	apiKey, err := reloaded.Get("bridge-0")
+	require.NoError(t, err)
+	assert.Equal(t, "final-key", apiKey)
+}
@@ -0,0 +1,49 @@
+package hueclient
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MigrateReplacedBridgeAPIKey moves the API key of the device stored for the bridge
+// which was replaced by the given bridge to the ID of the given bridge, so that the
+// device does not need to register again after the bridge was replaced in the Hue app.
+// It reports whether a key was migrated, an API key already stored for the given
+// bridge is kept.
+func MigrateReplacedBridgeAPIKey(store APIKeyStore, bridge *DiscoveredBridge, deviceName string) (bool, error) {
+	if bridge.ReplacesBridgeID == "" {
+		return false, nil
+	}
+
+	identity := fmt.Sprintf("%s#%s", bridge.ID, deviceName)
+	if key, _ := store.Get(identity); key != "" {
+		return false, nil
+	}
+
+	identities, err := store.List()
+	if err != nil {
+		return false, fmt.Errorf("failed to list API key identities: %w", err)
+	}
+
+	for _, replacedIdentity := range identities {
+		bridgeID, device, _ := strings.Cut(replacedIdentity, "#")
+		// The discovery endpoint reports lower case IDs, the bridge config upper case ones
+		if !strings.EqualFold(bridgeID, bridge.ReplacesBridgeID) || device != deviceName {
+			continue
+		}
+
+		apiKey, err := store.Get(replacedIdentity)
+		if err != nil {
+			return false, fmt.Errorf("failed to read API key of replaced bridge %s: %w", bridgeID, err)
+		}
+		if err := store.Set(identity, apiKey); err != nil {
+			return false, fmt.Errorf("failed to store API key for bridge %s: %w", bridge.ID, err)
+		}
+		if err := store.Remove(replacedIdentity); err != nil {
+			return true, fmt.Errorf("failed to remove API key of replaced bridge %s: %w", bridgeID, err)
+		}
+		return true, nil
+	}
+
+	return false, nil
+}
@@ -0,0 +1,73 @@
+package hueclient
+
+import (
+	"errors"
+	"testing"
+
+	"com.github.yveskaufmann/hue-lighter/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrateReplacedBridgeAPIKey(t *testing.T) {
+	tests := []struct {
+		name             string
+		storedKeys       map[string]string
+		bridge           *DiscoveredBridge
+		expectedMigrated bool
+		expectedKeys     map[string]string
+	}{
+		{
+			name:             "migrates API key of replaced bridge",
+			storedKeys:       map[string]string{"OLDBRIDGE000001#office": "old-key", "OLDBRIDGE000001#kitchen": "kitchen-key"},
+			bridge:           &DiscoveredBridge{ID: "newbridge000002", ReplacesBridgeID: "OLDBRIDGE000001"},
+			expectedMigrated: true,
+			expectedKeys:     map[string]string{"newbridge000002#office": "old-key", "OLDBRIDGE000001#kitchen": "kitchen-key"},
+		},
+		{
+			name:         "keeps stores without bridge replacement",
+			storedKeys:   map[string]string{"OLDBRIDGE000001#office": "old-key"},
+			bridge:       &DiscoveredBridge{ID: "newbridge000002"},
+			expectedKeys: map[string]string{"OLDBRIDGE000001#office": "old-key"},
+		},
+		{
+			name:         "keeps API key already stored for the new bridge",
+			storedKeys:   map[string]string{"OLDBRIDGE000001#office": "old-key", "newbridge000002#office": "new-key"},
+			bridge:       &DiscoveredBridge{ID: "newbridge000002", ReplacesBridgeID: "OLDBRIDGE000001"},
+			expectedKeys: map[string]string{"OLDBRIDGE000001#office": "old-key", "newbridge000002#office": "new-key"},
+		},
+		{
+			name:         "ignores API keys of other devices",
+			storedKeys:   map[string]string{"OLDBRIDGE000001#kitchen": "kitchen-key"},
+			bridge:       &DiscoveredBridge{ID: "newbridge000002", ReplacesBridgeID: "OLDBRIDGE000001"},
+			expectedKeys: map[string]string{"OLDBRIDGE000001#kitchen": "kitchen-key"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewInMemoryAPIKeyStore(logging.NewDiscardLogger())
+			for identity, apiKey := range tt.storedKeys {
+				require.NoError(t, store.Set(identity, apiKey))
+			}
+
+			migrated, err := MigrateReplacedBridgeAPIKey(store, tt.bridge, "office")
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedMigrated, migrated)
+			assert.Equal(t, tt.expectedKeys, store.snapshot())
+		})
+	}
+
+	t.Run("fails when API key cannot be stored", func(t *testing.T) {
+		store := newMockAPIKeyStore()
+		store.store["OLDBRIDGE000001#office"] = "old-key"
+		store.setErr = errors.New("read-only file system")
+
+		migrated, err := MigrateReplacedBridgeAPIKey(store, &DiscoveredBridge{ID: "newbridge000002", ReplacesBridgeID: "OLDBRIDGE000001"}, "office")
+
+		assert.False(t, migrated)
+		assert.EqualError(t, err, "failed to store API key for bridge newbridge000002: read-only file system")
+		assert.Equal(t, map[string]string{"OLDBRIDGE000001#office": "old-key"}, store.store)
+	})
+}
@@ -0,0 +1,26 @@
+package hueclient
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ProbeBridge reads the config of the bridge, which requires no API key, to verify
+// that the bridge is reachable and is the bridge of the client, e.g. before the
+// user is asked to press the link button.
+func (c *Client) ProbeBridge() (*BridgeConfig, error) {
+	var config BridgeConfig
+	if err := c.doRequest("api/0/config", http.MethodGet, nil, &config); err != nil {
+		return nil, newOperationError(ErrPrefixProbeBridge, "", err)
+	}
+
+	if config.BridgeID == "" {
+		return nil, newOperationError(ErrPrefixProbeBridge, "", fmt.Errorf("%w at %s, its config contains no bridge ID", ErrNotHueBridge, c.getBaseURL()))
+	}
+	if !strings.EqualFold(config.BridgeID, c.bridgeID) {
+		return nil, newOperationError(ErrPrefixProbeBridge, "", fmt.Errorf("%w: expected %s, got %s", ErrBridgeNotFound, c.bridgeID, config.BridgeID))
+	}
+
+	return &config, nil
+}
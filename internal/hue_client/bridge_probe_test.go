@@ -0,0 +1,74 @@
+package hueclient
+
+import (
+	"net/http"
+	"testing"
+
+	"com.github.yveskaufmann/hue-lighter/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_ProbeBridge(t *testing.T) {
+	tests := []struct {
+		name        string
+		statusCode  int
+		response    interface{}
+		wantErr     bool
+		expectedErr error
+		errMsg      string
+	}{
+		{
+			name:       "accepts the bridge of the client",
+			statusCode: http.StatusOK,
+			response:   map[string]interface{}{"bridgeid": "BRIDGE-123", "name": "Hue Bridge"},
+		},
+		{
+			name:        "fails for another bridge",
+			statusCode:  http.StatusOK,
+			response:    map[string]interface{}{"bridgeid": "bridge-456"},
+			wantErr:     true,
+			expectedErr: ErrBridgeNotFound,
+			errMsg:      "hue: probe bridge: bridge not found: expected bridge-123, got bridge-456",
+		},
+		{
+			name:        "fails without bridge ID",
+			statusCode:  http.StatusOK,
+			response:    map[string]interface{}{"name": "Router"},
+			wantErr:     true,
+			expectedErr: ErrNotHueBridge,
+			errMsg:      "its config contains no bridge ID",
+		},
+		{
+			name:       "fails on server error",
+			statusCode: http.StatusInternalServerError,
+			wantErr:    true,
+			errMsg:     "hue: probe bridge",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, recorder := testutils.MockHueBridgeRecorder(tt.statusCode, tt.response)
+			defer server.Close()
+
+			config, err := newTestClient(t, server).ProbeBridge()
+
+			requests := recorder.Requests()
+			require.Len(t, requests, 1)
+			assert.Equal(t, http.MethodGet, requests[0].Method)
+			assert.Equal(t, "/api/0/config", requests[0].Path)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				if tt.expectedErr != nil {
+					assert.ErrorIs(t, err, tt.expectedErr)
+				}
+				assert.Contains(t, err.Error(), tt.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "Hue Bridge", config.Name)
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package hueclient
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+// bridgeTimeLayout is the layout of the UTC time in the v1 bridge config.
+const bridgeTimeLayout = "2006-01-02T15:04:05"
+
+// bridgeTimeConfig is the part of the v1 bridge config holding its clock, the
+// CLIP v2 bridge resource only reports the time zone.
+type bridgeTimeConfig struct {
+	UTC string `json:"UTC"`
+}
+
+// GetBridgeTime returns the current time of the bridge clock in UTC, it has a
+// resolution of one second.
+func (c *Client) GetBridgeTime() (time.Time, error) {
+	apiKey, err := c.apiKey()
+	if err != nil {
+		return time.Time{}, newOperationError(ErrPrefixGetBridgeTime, "", err)
+	}
+
+	var config bridgeTimeConfig
+	if err := c.doRequest("api/"+apiKey+"/config", http.MethodGet, nil, &config); err != nil {
+		return time.Time{}, newOperationError(ErrPrefixGetBridgeTime, "", err)
+	}
+
+	if config.UTC == "" {
+		return time.Time{}, newOperationError(ErrPrefixGetBridgeTime, "", errors.New("bridge config contains no time, the API key may be unauthorized"))
+	}
+
+	bridgeTime, err := time.ParseInLocation(bridgeTimeLayout, config.UTC, time.UTC)
+	if err != nil {
+		return time.Time{}, newOperationError(ErrPrefixGetBridgeTime, "", err)
+	}
+
+	return bridgeTime, nil
+}
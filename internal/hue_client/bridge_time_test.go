@@ -0,0 +1,61 @@
+package hueclient
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"com.github.yveskaufmann/hue-lighter/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_GetBridgeTime(t *testing.T) {
+	tests := []struct {
+		name         string
+		response     interface{}
+		expectedTime time.Time
+		wantErr      bool
+		expectedErr  string
+	}{
+		{
+			name:         "parses UTC time of bridge config",
+			response:     map[string]interface{}{"UTC": "2024-06-21T19:30:15", "localtime": "2024-06-21T21:30:15"},
+			expectedTime: time.Date(2024, 6, 21, 19, 30, 15, 0, time.UTC),
+		},
+		{
+			name:        "fails without time in bridge config",
+			response:    map[string]interface{}{"name": "Hue Bridge"},
+			wantErr:     true,
+			expectedErr: "hue: get bridge time: bridge config contains no time",
+		},
+		{
+			name:        "fails with invalid time",
+			response:    map[string]interface{}{"UTC": "yesterday"},
+			wantErr:     true,
+			expectedErr: "hue: get bridge time: parsing time",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, recorder := testutils.MockHueBridgeRecorder(200, tt.response)
+			defer server.Close()
+
+			bridgeTime, err := newTestClient(t, server).GetBridgeTime()
+
+			requests := recorder.Requests()
+			require.Len(t, requests, 1)
+			assert.Equal(t, http.MethodGet, requests[0].Method)
+			assert.Equal(t, "/api/test-api-key/config", requests[0].Path)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedTime, bridgeTime)
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package hueclient
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// CertificatePin verifies the SHA-256 fingerprint of the bridge certificate.
+//
+// When created with an expected fingerprint, every certificate presented by the bridge
+// must match it. Without an expected fingerprint the pin works in trust-on-first-use
+// (TOFU) mode: the fingerprint observed on the first connect is captured and a change
+// is reported via the onChange callback. The observed fingerprint is only kept in
+// memory, a restarted process trusts the first certificate it sees again.
+type CertificatePin struct {
+	mu          sync.Mutex
+	fingerprint string
+	pinned      bool
+	onChange    func(previous string, current string)
+}
+
+// NewCertificatePin creates a pin for the given SHA-256 fingerprint. The fingerprint
+// may be hex encoded with or without colon separators. An empty fingerprint enables
+// TOFU mode, onChange may be nil if changes should not be reported.
+func NewCertificatePin(fingerprint string, onChange func(previous string, current string)) (*CertificatePin, error) {
+	pin := &CertificatePin{onChange: onChange}
+
+	if fingerprint == "" {
+		return pin, nil
+	}
+
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(fingerprint), ":", ""))
+	decoded, err := hex.DecodeString(normalized)
+	if err != nil || len(decoded) != sha256.Size {
+		return nil, fmt.Errorf("invalid certificate fingerprint %q: expected a hex encoded SHA-256 digest", fingerprint)
+	}
+
+	pin.fingerprint = normalized
+	pin.pinned = true
+	return pin, nil
+}
+
+// ResolveCertificatePin creates a CertificatePin from `HUE_BRIDGE_CERT_FINGERPRINT`.
+// If the variable is not set, a TOFU pin is returned which logs a warning when the
+// bridge certificate changes.
+func ResolveCertificatePin(logger *log.Entry) (*CertificatePin, error) {
+	return NewCertificatePin(os.Getenv("HUE_BRIDGE_CERT_FINGERPRINT"), func(previous string, current string) {
+		logger.WithFields(log.Fields{
+			"previousFingerprint": previous,
+			"currentFingerprint":  current,
+		}).Warn("Hue bridge certificate fingerprint changed since first connect")
+	})
+}
+
+// Fingerprint returns the pinned or, in TOFU mode, the last observed fingerprint,
+// which replaces the previous one after a change. It is empty as long as no
+// certificate has been observed in TOFU mode.
+func (p *CertificatePin) Fingerprint() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.fingerprint
+}
+
+// Pinned reports whether the pin was configured with an expected fingerprint.
+func (p *CertificatePin) Pinned() bool {
+	return p.pinned
+}
+
+func (p *CertificatePin) verify(cert *x509.Certificate) error {
+	current := CertificateFingerprint(cert)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.pinned {
+		if current != p.fingerprint {
+			return fmt.Errorf("%w: server certificate fingerprint %s does not match pinned fingerprint %s", ErrFingerprintMismatch, current, p.fingerprint)
+		}
+		return nil
+	}
+
+	previous := p.fingerprint
+	p.fingerprint = current
+	if previous != "" && previous != current && p.onChange != nil {
+		p.onChange(previous, current)
+	}
+
+	return nil
+}
+
+// CertificateFingerprint returns the lowercase hex encoded SHA-256 digest of the
+// DER encoded certificate.
+func CertificateFingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(sum[:])
+}
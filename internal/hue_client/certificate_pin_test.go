@@ -0,0 +1,132 @@
+package hueclient
+
+import (
+	"crypto/x509"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCertificatePin(t *testing.T) {
+	validFingerprint := strings.Repeat("ab", 32)
+
+	tests := []struct {
+		name        string
+		fingerprint string
+		wantPinned  bool
+		wantErr     bool
+	}{
+		{
+			name:        "empty fingerprint enables TOFU mode",
+			fingerprint: "",
+			wantPinned:  false,
+		},
+		{
+			name:        "accepts plain hex fingerprint",
+			fingerprint: validFingerprint,
+			wantPinned:  true,
+		},
+		{
+			name:        "accepts colon separated uppercase fingerprint",
+			fingerprint: strings.TrimSuffix(strings.Repeat("AB:", 32), ":"),
+			wantPinned:  true,
+		},
+		{
+			name:        "rejects non hex fingerprint",
+			fingerprint: strings.Repeat("zz", 32),
+			wantErr:     true,
+		},
+		{
+			name:        "rejects fingerprint of wrong length",
+			fingerprint: "abcdef",
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pin, err := NewCertificatePin(tt.fingerprint, nil)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "invalid certificate fingerprint")
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantPinned, pin.Pinned())
+			if tt.wantPinned {
+				assert.Equal(t, validFingerprint, pin.Fingerprint())
+			}
+		})
+	}
+}
+
+func TestCreateCustomCertVerifier_CertificatePin(t *testing.T) {
+	const bridgeID = "ecb5fafffe123456"
+
+	root := newTestCert(t, "Test Root CA", true, nil)
+	leaf := newTestCert(t, bridgeID, false, root)
+	otherLeaf := newTestCert(t, bridgeID, false, root)
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(root.cert)
+
+	t.Run("accepts certificate matching the pin", func(t *testing.T) {
+		pin, err := NewCertificatePin(CertificateFingerprint(leaf.cert), nil)
+		require.NoError(t, err)
+
+		verify := createCustomCertVerifier(bridgeID, rootCAs, pin)
+
+		assert.NoError(t, verify([][]byte{leaf.raw}, nil))
+	})
+
+	t.Run("rejects certificate not matching the pin", func(t *testing.T) {
+		pin, err := NewCertificatePin(CertificateFingerprint(leaf.cert), nil)
+		require.NoError(t, err)
+
+		verify := createCustomCertVerifier(bridgeID, rootCAs, pin)
+		err = verify([][]byte{otherLeaf.raw}, nil)
+
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrFingerprintMismatch)
+		assert.Contains(t, err.Error(), "does not match pinned fingerprint")
+		assert.Equal(t, CertificateFingerprint(leaf.cert), pin.Fingerprint())
+	})
+
+	t.Run("captures fingerprint on first connect without pin", func(t *testing.T) {
+		var changes [][2]string
+		pin, err := NewCertificatePin("", func(previous string, current string) {
+			changes = append(changes, [2]string{previous, current})
+		})
+		require.NoError(t, err)
+		assert.Empty(t, pin.Fingerprint())
+
+		verify := createCustomCertVerifier(bridgeID, rootCAs, pin)
+
+		require.NoError(t, verify([][]byte{leaf.raw}, nil))
+		assert.Equal(t, CertificateFingerprint(leaf.cert), pin.Fingerprint())
+		assert.Empty(t, changes)
+
+		require.NoError(t, verify([][]byte{leaf.raw}, nil))
+		assert.Empty(t, changes)
+
+		require.NoError(t, verify([][]byte{otherLeaf.raw}, nil))
+		require.Len(t, changes, 1)
+		assert.Equal(t, CertificateFingerprint(leaf.cert), changes[0][0])
+		assert.Equal(t, CertificateFingerprint(otherLeaf.cert), changes[0][1])
+		assert.Equal(t, CertificateFingerprint(otherLeaf.cert), pin.Fingerprint())
+	})
+
+	t.Run("does not capture fingerprint of rejected certificate", func(t *testing.T) {
+		pin, err := NewCertificatePin("", nil)
+		require.NoError(t, err)
+
+		verify := createCustomCertVerifier("ecb5fafffe000000", rootCAs, pin)
+
+		require.Error(t, verify([][]byte{leaf.raw}, nil))
+		assert.Empty(t, pin.Fingerprint())
+	})
+}
@@ -2,68 +2,293 @@ package hueclient
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
+	"sync"
+	"time"
 
+	"com.github.yveskaufmann/hue-lighter/internal/logging"
 	log "github.com/sirupsen/logrus"
 )
 
 type Client struct {
 	deviceName  string
+	appName     string
 	baseURL     string
 	bridgeID    string
 	apiKeyStore APIKeyStore
 	client      *http.Client
 	logger      *log.Entry
+	lightCache  *lightCache
+	// strictIdentity requires update responses to reference the updated resource
+	strictIdentity bool
+	lightAPI       LightAPI
+	rediscovery    *rediscovery
+	reregistration *reregistration
+	// logBodyLimit is the number of bytes of a request body logged at debug level
+	logBodyLimit int
+	// maxBrightness caps the brightness requested by the brightness setters, zero disables the cap
+	maxBrightness float32
+	// maxBrightnessMu guards maxBrightness, it changes when the config is reloaded
+	maxBrightnessMu sync.RWMutex
+	// registrationBackoff is the wait before the first registration retry, zero uses registrationRetryBackoff
+	registrationBackoff time.Duration
+	// baseURLMu guards baseURL, it changes when the bridge is re-discovered
+	baseURLMu sync.RWMutex
 }
 
-func NewClient(deviceName string, bridgeID string, bridgeIP string, apiKeyStore APIKeyStore, caBundlePath string, logger *log.Entry) (*Client, error) {
+// ClientOption configures optional behaviour of the Client.
+type ClientOption func(*clientOptions)
 
-	logger = logger.WithField("component", "HueClient")
+type clientOptions struct {
+	tlsOptions     []TLSOption
+	logger         *log.Entry
+	lightCacheTTL  time.Duration
+	strictIdentity bool
+	appName        string
+	userAgent      string
+	metrics        RequestMetrics
+	lightAPI       LightAPI
+	rediscovery    *rediscovery
+	reregistration *reregistration
+	connectionPool connectionPool
+	logBodyLimit   int
+	maxBrightness  float32
+}
+
+// WithLightCache caches the lights read by GetOneLightById for the given TTL.
+// The cache entry of a light is dropped as soon as the light is updated through the client.
+func WithLightCache(ttl time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.lightCacheTTL = ttl
+	}
+}
 
-	tlsConfig, err := NewBridgeTLSConfig(bridgeID, caBundlePath)
+// DefaultLogBodyLimit is the number of bytes of a request body logged at debug level.
+const DefaultLogBodyLimit = 256
+
+// WithLogBodyLimit truncates the request bodies logged at debug level after limit
+// bytes, e.g. gradients contain many colors. A negative limit disables the logging
+// of request bodies. Defaults to DefaultLogBodyLimit.
+func WithLogBodyLimit(limit int) ClientOption {
+	return func(o *clientOptions) {
+		o.logBodyLimit = limit
+	}
+}
+
+// WithStrictIdentity makes updates fail with ErrIdentityMismatch if the bridge
+// response does not reference the requested resource, e.g. when an update was silently ignored.
+func WithStrictIdentity() ClientOption {
+	return func(o *clientOptions) {
+		o.strictIdentity = true
+	}
+}
+
+// WithAppName sets the application name shown in the app list of the bridge,
+// defaults to APP_NAME.
+func WithAppName(appName string) ClientOption {
+	return func(o *clientOptions) {
+		o.appName = appName
+	}
+}
+
+// WithUserAgent overrides the User-Agent header of the bridge requests, defaults to DefaultUserAgent.
+func WithUserAgent(userAgent string) ClientOption {
+	return func(o *clientOptions) {
+		o.userAgent = userAgent
+	}
+}
+
+// WithRequestMetrics reports every bridge request to the given metrics.
+func WithRequestMetrics(metrics RequestMetrics) ClientOption {
+	return func(o *clientOptions) {
+		o.metrics = metrics
+	}
+}
+
+// WithLogger replaces the logger passed to NewClient, e.g. to route the
+// client logs through the logger of an embedding application.
+func WithLogger(logger *log.Entry) ClientOption {
+	return func(o *clientOptions) {
+		o.logger = logger
+	}
+}
+
+// WithTLSOptions passes the given options to the bridge TLS config of the client.
+func WithTLSOptions(opts ...TLSOption) ClientOption {
+	return func(o *clientOptions) {
+		o.tlsOptions = append(o.tlsOptions, opts...)
+	}
+}
+
+func NewClient(deviceName string, bridgeID string, bridgeIP string, apiKeyStore APIKeyStore, caBundlePath string, logger *log.Entry, opts ...ClientOption) (*Client, error) {
+
+	options := clientOptions{logger: logger}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	logger = componentLogger(options.logger, "HueClient").WithField("bridge_id", bridgeID)
+
+	tlsConfig, err := NewBridgeTLSConfig(bridgeID, caBundlePath, options.tlsOptions...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create TLS config: %w", err)
 	}
 
-	return &Client{
+	transport := newInstrumentedTransport(newBridgeTransport(tlsConfig, options.connectionPool), logger, options.userAgent, options.metrics, bridgeRequestSubject)
+
+	client := &Client{
 		deviceName:  deviceName,
+		appName:     options.appName,
 		baseURL:     fmt.Sprintf("https://%s", bridgeIP),
 		apiKeyStore: apiKeyStore,
-		client:      &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}},
+		client:      &http.Client{Transport: transport},
 		bridgeID:    bridgeID,
 		logger:      logger,
-	}, nil
+
+		strictIdentity: options.strictIdentity,
+		lightAPI:       options.lightAPI,
+		rediscovery:    options.rediscovery,
+		reregistration: options.reregistration,
+		logBodyLimit:   options.logBodyLimit,
+		maxBrightness:  options.maxBrightness,
+	}
+
+	if options.lightCacheTTL > 0 {
+		client.lightCache = newLightCache(options.lightCacheTTL)
+	}
+
+	return client, nil
+}
+
+// verifyIdentity checks in strict identity mode that the update response references the requested resource.
+func (c *Client) verifyIdentity(id string, identities []ResourceIdentifier) error {
+	if !c.strictIdentity {
+		return nil
+	}
+
+	if len(identities) == 0 {
+		return fmt.Errorf("%w: empty response", ErrIdentityMismatch)
+	}
+
+	if identities[0].RID != id {
+		return fmt.Errorf("%w: got %q", ErrIdentityMismatch, identities[0].RID)
+	}
+
+	return nil
 }
 
+// bridgeRequestSubject names the bridge requests in the log.
+const bridgeRequestSubject = "Bridge request"
+
 func (c *Client) doRequest(path string, method string, reqBody interface{}, respResource interface{}) error {
 
-	var reqBodyReader io.Reader
+	var body []byte
 	if reqBody != nil {
 		w := bytes.Buffer{}
 		encoder := json.NewEncoder(&w)
 		if err := encoder.Encode(reqBody); err != nil {
-			return fmt.Errorf("failed to encode request body: %v", err)
+			return fmt.Errorf("failed to encode request body: %w", err)
 		}
-		reqBodyReader = &w
-
-		c.logger.Debugf("Light Request Body: %s", w.String())
+		body = w.Bytes()
 	}
 
 	if after, ok := strings.CutPrefix(path, "/"); ok {
 		path = after
 	}
-	url := fmt.Sprintf("%s/%s", c.baseURL, path)
 
-	c.logger.Debugf("Making %s request to %s", method, url)
+	// The registration body is noise, it is never logged
+	if body != nil && path != "api" && c.logBodyLimit >= 0 {
+		c.logger.WithFields(log.Fields{
+			"method": method,
+			"path":   "/" + redactAPIKeyPath(path),
+		}).Debugf("Request body: %s", truncateLogBody(body, c.logBodyLimit))
+	}
 
-	req, err := http.NewRequest(method, url, reqBodyReader)
+	// The key the request is sent with, the v1 API carries it in the path instead of a header
+	var apiKey string
+	if c.reregistration != nil {
+		apiKey, _ = c.apiKey()
+	}
+
+	attempt := 1
+	baseURL := c.getBaseURL()
+	response, err := c.send(baseURL, path, method, body, attempt)
+	if err != nil && isConnectionFailure(err) && c.relocateBridge(baseURL) {
+		attempt++
+		response, err = c.send(c.getBaseURL(), path, method, body, attempt)
+	}
+	if err != nil {
+		return err
+	}
+	c.resetConnectionFailures()
+
+	if c.reregistration != nil && isUnauthorized(response, path, apiKey) && c.reregisterDevice(apiKey) {
+		response.Body.Close()
+		newAPIKey, err := c.apiKey()
+		if err != nil {
+			return err
+		}
+		path = replaceV1APIKeyPath(path, apiKey, newAPIKey)
+		attempt++
+		response, err = c.send(c.getBaseURL(), path, method, body, attempt)
+		if err != nil {
+			return err
+		}
+	}
+
+	defer response.Body.Close()
+
+	// A wrong host usually answers with an HTML error page, e.g. a 404
+	if err := checkNotHTML(response); err != nil {
+		return err
+	}
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return newStatusError(response)
+	}
+
+	// A 207 Multi-Status response is decoded like any other 2xx response, it lists
+	// the updated resources and the errors of the failed ones side by side.
+	decoder := json.NewDecoder(response.Body)
+	if err := decoder.Decode(&respResource); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
+
+// truncateLogBody cuts the body after limit bytes and marks the cut with an
+// ellipsis, a zero limit uses DefaultLogBodyLimit.
+func truncateLogBody(body []byte, limit int) string {
+	if limit == 0 {
+		limit = DefaultLogBodyLimit
+	}
+	if len(body) <= limit {
+		return string(body)
+	}
+	return string(body[:limit]) + "..."
+}
+
+// send makes a single request to the bridge at baseURL, the error wraps the
+// cause so that connection failures can be told apart. The attempt is logged by
+// the instrumented transport.
+func (c *Client) send(baseURL string, path string, method string, body []byte, attempt int) (*http.Response, error) {
+	url := fmt.Sprintf("%s/%s", baseURL, path)
+
+	var reqBodyReader io.Reader
+	if body != nil {
+		reqBodyReader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(withAttempt(context.Background(), attempt), method, url, reqBodyReader)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %v", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	skipApiKey := false
@@ -72,42 +297,50 @@ func (c *Client) doRequest(path string, method string, reqBody interface{}, resp
 	}
 
 	if !skipApiKey {
-		apiKey, err := c.apiKeyStore.Get(fmt.Sprintf("%s#%s", c.bridgeID, c.deviceName))
+		apiKey, err := c.apiKey()
 		if err != nil {
-			if errors.Is(err, ErrMissingAPIKey) {
-				return fmt.Errorf("%w %q", ErrMissingAPIKey, c.bridgeID)
-			}
-			return fmt.Errorf("failed to load api key for hue bridge %q: %w", c.bridgeID, err)
+			return nil, err
 		}
 		req.Header.Set("hue-application-key", apiKey)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
+	// The User-Agent is set and the request is logged by the instrumented transport.
 	response, err := c.client.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to do request: %v", err)
+		return nil, fmt.Errorf("failed to do request: %w", err)
 	}
 
-	if response.StatusCode < 200 || response.StatusCode >= 300 {
+	return response, nil
+}
 
-		defer response.Body.Close()
+// redactAPIKeyPath hides the API key of v1 API paths like `api/<key>/lights`, and
+// the API key of the registration in `api/<key>/config/whitelist/<key>`.
+func redactAPIKeyPath(path string) string {
+	rest, ok := strings.CutPrefix(path, "api/")
+	if !ok || rest == "" {
+		return path
+	}
 
-		body, err := io.ReadAll(response.Body)
-		if err != nil {
-			return fmt.Errorf("failed to read response body: %v", err)
+	if _, after, found := strings.Cut(rest, "/"); found {
+		if strings.HasPrefix(after, "config/whitelist/") {
+			after = "config/whitelist/" + logging.Redacted
 		}
-
-		return fmt.Errorf("request failed with status code: %d, response: %s", response.StatusCode, body)
+		return "api/" + logging.Redacted + "/" + after
 	}
+	return "api/" + logging.Redacted
+}
 
-	defer response.Body.Close()
-
-	decoder := json.NewDecoder(response.Body)
-	if err := decoder.Decode(&respResource); err != nil {
-		return fmt.Errorf("failed to decode response: %v", err)
+// apiKey returns the API key of the device from the API key store.
+func (c *Client) apiKey() (string, error) {
+	apiKey, err := c.apiKeyStore.Get(c.apiKeyIdentity())
+	if err != nil {
+		if errors.Is(err, ErrMissingAPIKey) {
+			return "", fmt.Errorf("%w %q", ErrMissingAPIKey, c.bridgeID)
+		}
+		return "", fmt.Errorf("failed to load api key for hue bridge %q: %w", c.bridgeID, err)
 	}
-
-	return nil
+	return apiKey, nil
 }
 
 func (c *Client) BridgeID() string {
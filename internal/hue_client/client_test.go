@@ -1,11 +1,20 @@
 package hueclient
 
 import (
+	"bytes"
+	"context"
 	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
+	"com.github.yveskaufmann/hue-lighter/internal/logging"
 	"com.github.yveskaufmann/hue-lighter/internal/testutils"
 	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -48,6 +57,39 @@ func (m *mockAPIKeyStore) Remove(bridgeID string) error {
 	return nil
 }
 
+func (m *mockAPIKeyStore) List() ([]string, error) {
+	identities := make([]string, 0, len(m.store))
+	for identity := range m.store {
+		identities = append(identities, identity)
+	}
+	sort.Strings(identities)
+	return identities, nil
+}
+
+// newTestClient creates a client talking to the given test server with an API key
+// registered for "bridge-123#test-device".
+func newTestClient(t *testing.T, server *httptest.Server) *Client {
+	t.Helper()
+
+	apiKeyStore := newMockAPIKeyStore()
+	apiKeyStore.Set("bridge-123#test-device", "test-api-key")
+
+	logger := logrus.New().WithField("test", t.Name())
+	return &Client{
+		deviceName:  "test-device",
+		baseURL:     server.URL,
+		bridgeID:    "bridge-123",
+		apiKeyStore: apiKeyStore,
+		client:      &http.Client{Transport: newTestTransport(server, logger)},
+		logger:      logger,
+	}
+}
+
+// newTestTransport instruments the transport of the test server like NewClient does.
+func newTestTransport(server *httptest.Server, logger *logrus.Entry) *instrumentedTransport {
+	return newInstrumentedTransport(server.Client().Transport, logger, "", nil, bridgeRequestSubject)
+}
+
 func TestNewClient(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -249,3 +291,258 @@ func TestClient_DeviceName(t *testing.T) {
 	client := &Client{deviceName: "test-device-name"}
 	assert.Equal(t, "test-device-name", client.DeviceName())
 }
+
+func TestNewClient_WithLogger(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "Test Root CA", true, nil)
+	caBundlePath := writeTestCertPEM(t, dir, "ca.pem", ca)
+
+	hookLogger, hook := test.NewNullLogger()
+	logger := logrus.NewEntry(hookLogger)
+
+	tests := []struct {
+		name   string
+		logger *logrus.Entry
+		opts   []ClientOption
+	}{
+		{
+			name: "uses logger passed as option",
+			opts: []ClientOption{WithLogger(logger)},
+		},
+		{
+			name:   "option replaces logger passed as argument",
+			logger: logrus.New().WithField("test", "replaced"),
+			opts:   []ClientOption{WithLogger(logger)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hook.Reset()
+
+			client, err := NewClient("test-device", "bridge-123", "192.168.1.100", newMockAPIKeyStore(), caBundlePath, tt.logger, tt.opts...)
+			require.NoError(t, err)
+
+			client.logger.Info("hello")
+
+			require.Len(t, hook.AllEntries(), 1)
+			assert.Equal(t, "hello", hook.LastEntry().Message)
+			assert.Equal(t, "HueClient", hook.LastEntry().Data["component"])
+			assert.Equal(t, "bridge-123", hook.LastEntry().Data["bridge_id"])
+		})
+	}
+}
+
+func TestNewClient_WithoutLogger(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "Test Root CA", true, nil)
+	caBundlePath := writeTestCertPEM(t, dir, "ca.pem", ca)
+
+	client, err := NewClient("test-device", "bridge-123", "192.168.1.100", newMockAPIKeyStore(), caBundlePath, nil)
+
+	require.NoError(t, err)
+	assert.NotSame(t, logrus.StandardLogger(), client.logger.Logger)
+	assert.NotPanics(t, func() { client.logger.Info("discarded") })
+}
+
+func TestClient_doRequest_LogsLatency(t *testing.T) {
+	hookLogger, hook := test.NewNullLogger()
+	hookLogger.SetLevel(logrus.DebugLevel)
+
+	t.Run("logs completed request", func(t *testing.T) {
+		hook.Reset()
+		server := testutils.MockHueBridgeResponse(200, map[string]interface{}{"data": []interface{}{}})
+		defer server.Close()
+
+		client := newTestClient(t, server)
+		client.client.Transport = newTestTransport(server, logrus.NewEntry(hookLogger))
+
+		_, err := client.GetAllLights()
+		require.NoError(t, err)
+
+		var entry *logrus.Entry
+		for _, e := range hook.AllEntries() {
+			if e.Message == "Bridge request completed" {
+				entry = e
+			}
+		}
+		require.NotNil(t, entry)
+		assert.Equal(t, logrus.DebugLevel, entry.Level)
+		assert.Equal(t, "GET", entry.Data["method"])
+		assert.Equal(t, "/clip/v2/resource/light", entry.Data["path"])
+		assert.Equal(t, 200, entry.Data["status"])
+		assert.IsType(t, time.Duration(0), entry.Data["elapsed"])
+	})
+
+	t.Run("logs failed request", func(t *testing.T) {
+		hook.Reset()
+		server := testutils.MockHueBridgeResponse(200, nil)
+		client := newTestClient(t, server)
+		client.client.Transport = newTestTransport(server, logrus.NewEntry(hookLogger))
+		server.Close()
+
+		_, err := client.GetAllLights()
+		require.Error(t, err)
+
+		entry := hook.LastEntry()
+		require.NotNil(t, entry)
+		assert.Equal(t, "Bridge request failed", entry.Message)
+		assert.Contains(t, entry.Data, "elapsed")
+		assert.Contains(t, entry.Data, logrus.ErrorKey)
+	})
+}
+
+func TestClient_doRequest_LogsStructuredFields(t *testing.T) {
+	hookLogger, hook := test.NewNullLogger()
+	hookLogger.SetLevel(logrus.DebugLevel)
+
+	staleURL, moved := newMovedBridge(t)
+	client := newTestClient(t, moved)
+	client.baseURL = staleURL
+	client.logger = logrus.NewEntry(hookLogger).WithField("bridge_id", "bridge-123")
+	client.client.Transport = newTestTransport(moved, client.logger)
+	client.rediscovery = &rediscovery{
+		threshold: 1,
+		locate: func(ctx context.Context, bridgeID string) (string, error) {
+			return strings.TrimPrefix(moved.URL, "https://"), nil
+		},
+	}
+
+	_, err := client.UpdateOneLightById("light-1", &LightBodyUpdate{On: &LightOnState{On: true}})
+	require.NoError(t, err)
+
+	entries := map[string][]*logrus.Entry{}
+	for _, entry := range hook.AllEntries() {
+		entries[entry.Message] = append(entries[entry.Message], entry)
+	}
+
+	require.Len(t, entries["Request body: {\"on\":{\"on\":true}}\n"], 1)
+	bodyEntry := entries["Request body: {\"on\":{\"on\":true}}\n"][0]
+	assert.Equal(t, "PUT", bodyEntry.Data["method"])
+	assert.Equal(t, "/clip/v2/resource/light/light-1", bodyEntry.Data["path"])
+
+	require.Len(t, entries["Bridge request failed"], 1)
+	require.Len(t, entries["Bridge request completed"], 1)
+	for _, entry := range []*logrus.Entry{entries["Bridge request failed"][0], entries["Bridge request completed"][0]} {
+		assert.Equal(t, "bridge-123", entry.Data["bridge_id"])
+		assert.Equal(t, "PUT", entry.Data["method"])
+		assert.Equal(t, "/clip/v2/resource/light/light-1", entry.Data["path"])
+		assert.Contains(t, entry.Data, "elapsed")
+	}
+	assert.Equal(t, 1, entries["Bridge request failed"][0].Data["attempt"])
+	assert.NotContains(t, entries["Bridge request failed"][0].Data, "status")
+	assert.Equal(t, 2, entries["Bridge request completed"][0].Data["attempt"])
+	assert.Equal(t, 200, entries["Bridge request completed"][0].Data["status"])
+}
+
+func TestClient_doRequest_TruncatesLoggedBody(t *testing.T) {
+	tests := []struct {
+		name            string
+		logBodyLimit    int
+		register        bool
+		expectedMessage string
+	}{
+		{
+			name:            "truncates long body",
+			logBodyLimit:    10,
+			expectedMessage: "Request body: {\"on\":{\"on...",
+		},
+		{
+			name:            "uses default limit",
+			expectedMessage: "Request body: {\"on\":{\"on\":true}}\n",
+		},
+		{
+			name:         "negative limit disables body logging",
+			logBodyLimit: -1,
+		},
+		{
+			name:         "never logs registration body",
+			logBodyLimit: 1000,
+			register:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hookLogger, hook := test.NewNullLogger()
+			hookLogger.SetLevel(logrus.DebugLevel)
+
+			server := testutils.MockHueBridgeResponse(200, []map[string]interface{}{{"success": map[string]string{"username": "new-key"}}})
+			defer server.Close()
+			client := newTestClient(t, server)
+			client.logger = logrus.NewEntry(hookLogger)
+			client.logBodyLimit = tt.logBodyLimit
+
+			if tt.register {
+				_, err := client.RegisterDevice("office")
+				require.NoError(t, err)
+			} else {
+				err := client.doRequest("/clip/v2/resource/light/light-1", http.MethodPut, &LightBodyUpdate{On: &LightOnState{On: true}}, nil)
+				require.NoError(t, err)
+			}
+
+			var messages []string
+			for _, entry := range hook.AllEntries() {
+				if strings.HasPrefix(entry.Message, "Request body") {
+					messages = append(messages, entry.Message)
+				}
+			}
+			if tt.expectedMessage == "" {
+				assert.Empty(t, messages)
+			} else {
+				assert.Equal(t, []string{tt.expectedMessage}, messages)
+			}
+		})
+	}
+}
+
+func TestTruncateLogBody(t *testing.T) {
+	long := strings.Repeat("a", DefaultLogBodyLimit+1)
+
+	assert.Equal(t, "short", truncateLogBody([]byte("short"), 5))
+	assert.Equal(t, "sho...", truncateLogBody([]byte("short"), 3))
+	assert.Equal(t, long[:DefaultLogBodyLimit]+"...", truncateLogBody([]byte(long), 0))
+}
+
+func TestRedactAPIKeyPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{name: "v2 resource path", path: "clip/v2/resource/light", expected: "clip/v2/resource/light"},
+		{name: "v1 registration", path: "api", expected: "api"},
+		{name: "v1 resource path", path: "api/secret-key/lights/3/state", expected: "api/[REDACTED]/lights/3/state"},
+		{name: "v1 path with key only", path: "api/secret-key", expected: "api/[REDACTED]"},
+		{name: "v1 registration removal", path: "api/secret-key/config/whitelist/other-key", expected: "api/[REDACTED]/config/whitelist/[REDACTED]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, redactAPIKeyPath(tt.path))
+		})
+	}
+}
+
+func TestClient_LogsWithoutSecrets(t *testing.T) {
+	server := testutils.MockHueBridgeResponse(200, map[string]interface{}{"UTC": "2024-06-21T12:00:00"})
+	defer server.Close()
+
+	output := &bytes.Buffer{}
+	redactor := logging.NewRedactor()
+	logger := logging.NewLogger(logging.WithOutput(output), logging.WithLevel(logrus.DebugLevel), logging.WithRedaction(redactor))
+
+	client := newTestClient(t, server)
+	client.logger = logger
+	client.client.Transport = newTestTransport(server, logger)
+	client.apiKeyStore = NewRedactingAPIKeyStore(client.apiKeyStore, redactor)
+
+	_, err := client.GetBridgeTime()
+	require.NoError(t, err)
+	_, err = client.GetAllLights()
+	require.NoError(t, err)
+	client.logger.Infof("Bridge answered for key %s", "test-api-key")
+
+	assert.Contains(t, output.String(), "api/[REDACTED]/config")
+	assert.NotContains(t, output.String(), "test-api-key")
+}
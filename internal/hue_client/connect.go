@@ -0,0 +1,68 @@
+package hueclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultDeviceName is used by Connect when Options.DeviceName is empty.
+const DefaultDeviceName = "hue-lighter"
+
+// Options describe an already registered bridge for Connect.
+type Options struct {
+	// BridgeIP is the IP address or host name of the bridge.
+	BridgeIP string
+	// BridgeID is the ID of the bridge, it is used to verify the bridge certificate.
+	// When empty, it is read from the unauthenticated bridge config endpoint.
+	BridgeID string
+	// APIKey is the application key the bridge issued for this application.
+	APIKey string
+	// CABundlePath points to the Philips Hue CA bundle, see NewBridgeTLSConfig.
+	CABundlePath string
+	// DeviceName identifies the application, defaults to DefaultDeviceName.
+	DeviceName string
+	// Logger receives the client logs, logs are discarded when nil.
+	Logger *log.Entry
+}
+
+// Connect creates a client for an already registered bridge. The API key is kept
+// in memory only, so neither an API key file nor a device registration is required.
+func Connect(ctx context.Context, opts Options, clientOpts ...ClientOption) (*Client, error) {
+	if opts.BridgeIP == "" {
+		return nil, errors.New("bridge IP is required")
+	}
+	if opts.APIKey == "" {
+		return nil, errors.New("API key is required")
+	}
+	if opts.CABundlePath == "" {
+		return nil, errors.New("CA bundle path is required")
+	}
+
+	deviceName := opts.DeviceName
+	if deviceName == "" {
+		deviceName = DefaultDeviceName
+	}
+
+	bridgeID := opts.BridgeID
+	if bridgeID == "" {
+		bridgeConfig, err := NewBridgeDiscoveryService(opts.Logger).fetchBridgeConfigByIP(ctx, opts.BridgeIP)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve bridge ID: %w", err)
+		}
+		bridgeID = bridgeConfig.BridgeID
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	store := NewInMemoryAPIKeyStore(opts.Logger)
+	if err := store.Set(fmt.Sprintf("%s#%s", bridgeID, deviceName), opts.APIKey); err != nil {
+		return nil, fmt.Errorf("failed to store API key: %w", err)
+	}
+
+	return NewClient(deviceName, bridgeID, opts.BridgeIP, store, opts.CABundlePath, opts.Logger, clientOpts...)
+}
@@ -0,0 +1,108 @@
+package hueclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnect(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "Test Root CA", true, nil)
+	caBundlePath := writeTestCertPEM(t, dir, "ca.pem", ca)
+
+	bridgeConfigServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/0/config", r.URL.Path)
+		w.Write([]byte(`{"name":"Hue Bridge","bridgeid":"ECB5FAFFFE123456"}`))
+	}))
+	defer bridgeConfigServer.Close()
+
+	tests := []struct {
+		name               string
+		opts               Options
+		wantErr            bool
+		expectedErr        string
+		expectedBridgeID   string
+		expectedDeviceName string
+	}{
+		{
+			name:               "connects with all options",
+			opts:               Options{BridgeIP: "192.168.1.100", BridgeID: "bridge-123", APIKey: "test-api-key", CABundlePath: caBundlePath, DeviceName: "my-app"},
+			expectedBridgeID:   "bridge-123",
+			expectedDeviceName: "my-app",
+		},
+		{
+			name:               "defaults device name",
+			opts:               Options{BridgeIP: "192.168.1.100", BridgeID: "bridge-123", APIKey: "test-api-key", CABundlePath: caBundlePath},
+			expectedBridgeID:   "bridge-123",
+			expectedDeviceName: DefaultDeviceName,
+		},
+		{
+			name:               "resolves bridge ID from bridge config",
+			opts:               Options{BridgeIP: strings.TrimPrefix(bridgeConfigServer.URL, "http://"), APIKey: "test-api-key", CABundlePath: caBundlePath},
+			expectedBridgeID:   "ECB5FAFFFE123456",
+			expectedDeviceName: DefaultDeviceName,
+		},
+		{
+			name:        "requires bridge IP",
+			opts:        Options{BridgeID: "bridge-123", APIKey: "test-api-key", CABundlePath: caBundlePath},
+			wantErr:     true,
+			expectedErr: "bridge IP is required",
+		},
+		{
+			name:        "requires API key",
+			opts:        Options{BridgeIP: "192.168.1.100", BridgeID: "bridge-123", CABundlePath: caBundlePath},
+			wantErr:     true,
+			expectedErr: "API key is required",
+		},
+		{
+			name:        "requires CA bundle path",
+			opts:        Options{BridgeIP: "192.168.1.100", BridgeID: "bridge-123", APIKey: "test-api-key"},
+			wantErr:     true,
+			expectedErr: "CA bundle path is required",
+		},
+		{
+			name:        "fails with missing CA bundle",
+			opts:        Options{BridgeIP: "192.168.1.100", BridgeID: "bridge-123", APIKey: "test-api-key", CABundlePath: "/nonexistent/ca-bundle.pem"},
+			wantErr:     true,
+			expectedErr: "failed to create TLS config",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := Connect(context.Background(), tt.opts)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErr)
+				assert.Nil(t, client)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedBridgeID, client.BridgeID())
+			assert.Equal(t, tt.expectedDeviceName, client.DeviceName())
+
+			apiKey, err := client.apiKeyStore.Get(tt.expectedBridgeID + "#" + tt.expectedDeviceName)
+			require.NoError(t, err)
+			assert.Equal(t, "test-api-key", apiKey)
+		})
+	}
+}
+
+func TestConnect_CanceledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	client, err := Connect(ctx, Options{BridgeIP: "192.168.1.100", APIKey: "test-api-key", CABundlePath: "/etc/hue-lighter/cacert_bundle.pem"})
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, client)
+}
@@ -0,0 +1,83 @@
+package hueclient
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"time"
+)
+
+// DefaultMaxIdleConns is the number of idle connections kept open to the bridge,
+// the client talks to a single host, so that a small pool suffices for the polling.
+const DefaultMaxIdleConns = 2
+
+// DefaultIdleConnTimeout is how long an idle connection to the bridge is kept open.
+const DefaultIdleConnTimeout = 90 * time.Second
+
+// DefaultKeepAlive is the interval of the TCP keep-alive probes of the connections to the bridge.
+const DefaultKeepAlive = 30 * time.Second
+
+// WithMaxIdleConns sets the number of idle connections kept open to the bridge,
+// defaults to DefaultMaxIdleConns. A negative value disables the reuse of
+// connections, every request opens a new connection.
+func WithMaxIdleConns(maxIdleConns int) ClientOption {
+	return func(o *clientOptions) {
+		o.connectionPool.maxIdleConns = maxIdleConns
+	}
+}
+
+// WithIdleConnTimeout sets how long an idle connection to the bridge is kept
+// open, defaults to DefaultIdleConnTimeout.
+func WithIdleConnTimeout(timeout time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.connectionPool.idleConnTimeout = timeout
+	}
+}
+
+// WithKeepAlive sets the interval of the TCP keep-alive probes, defaults to
+// DefaultKeepAlive. A negative value disables the probes.
+func WithKeepAlive(interval time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.connectionPool.keepAlive = interval
+	}
+}
+
+// connectionPool configures the connections to the bridge, zero values are replaced by the defaults.
+type connectionPool struct {
+	maxIdleConns    int
+	idleConnTimeout time.Duration
+	keepAlive       time.Duration
+}
+
+func (p connectionPool) withDefaults() connectionPool {
+	if p.maxIdleConns == 0 {
+		p.maxIdleConns = DefaultMaxIdleConns
+	}
+	if p.idleConnTimeout == 0 {
+		p.idleConnTimeout = DefaultIdleConnTimeout
+	}
+	if p.keepAlive == 0 {
+		p.keepAlive = DefaultKeepAlive
+	}
+	return p
+}
+
+// newBridgeTransport creates the transport of the bridge requests.
+func newBridgeTransport(tlsConfig *tls.Config, pool connectionPool) *http.Transport {
+	pool = pool.withDefaults()
+	dialer := &net.Dialer{KeepAlive: pool.keepAlive}
+
+	transport := &http.Transport{
+		TLSClientConfig:     tlsConfig,
+		DialContext:         dialer.DialContext,
+		MaxIdleConns:        pool.maxIdleConns,
+		MaxIdleConnsPerHost: pool.maxIdleConns,
+		IdleConnTimeout:     pool.idleConnTimeout,
+	}
+	if pool.maxIdleConns < 0 {
+		transport.MaxIdleConns = 0
+		transport.MaxIdleConnsPerHost = 0
+		transport.DisableKeepAlives = true
+	}
+	return transport
+}
@@ -0,0 +1,88 @@
+package hueclient
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewClient_ConnectionPool(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "Test Root CA", true, nil)
+	caBundlePath := writeTestCertPEM(t, dir, "ca.pem", ca)
+
+	tests := []struct {
+		name                    string
+		opts                    []ClientOption
+		expectedMaxIdleConns    int
+		expectedIdleConnTimeout time.Duration
+		expectedKeepAlives      bool
+	}{
+		{
+			name:                    "defaults for a single bridge",
+			expectedMaxIdleConns:    DefaultMaxIdleConns,
+			expectedIdleConnTimeout: DefaultIdleConnTimeout,
+			expectedKeepAlives:      true,
+		},
+		{
+			name:                    "configured from options",
+			opts:                    []ClientOption{WithMaxIdleConns(4), WithIdleConnTimeout(30 * time.Second)},
+			expectedMaxIdleConns:    4,
+			expectedIdleConnTimeout: 30 * time.Second,
+			expectedKeepAlives:      true,
+		},
+		{
+			name:                    "negative max idle conns disables connection reuse",
+			opts:                    []ClientOption{WithMaxIdleConns(-1)},
+			expectedMaxIdleConns:    0,
+			expectedIdleConnTimeout: DefaultIdleConnTimeout,
+			expectedKeepAlives:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClient("test-device", "bridge-123", "192.168.1.100", newMockAPIKeyStore(), caBundlePath, nil, tt.opts...)
+			require.NoError(t, err)
+
+			instrumented, ok := client.client.Transport.(*instrumentedTransport)
+			require.True(t, ok)
+			transport, ok := instrumented.next.(*http.Transport)
+			require.True(t, ok)
+
+			assert.Equal(t, tt.expectedMaxIdleConns, transport.MaxIdleConns)
+			assert.Equal(t, tt.expectedMaxIdleConns, transport.MaxIdleConnsPerHost)
+			assert.Equal(t, tt.expectedIdleConnTimeout, transport.IdleConnTimeout)
+			assert.Equal(t, !tt.expectedKeepAlives, transport.DisableKeepAlives)
+			assert.NotNil(t, transport.TLSClientConfig)
+			assert.NotNil(t, transport.DialContext)
+		})
+	}
+}
+
+func TestConnectionPool_withDefaults(t *testing.T) {
+	tests := []struct {
+		name     string
+		pool     connectionPool
+		expected connectionPool
+	}{
+		{
+			name:     "replaces zero values by defaults",
+			expected: connectionPool{maxIdleConns: DefaultMaxIdleConns, idleConnTimeout: DefaultIdleConnTimeout, keepAlive: DefaultKeepAlive},
+		},
+		{
+			name:     "keeps configured values",
+			pool:     connectionPool{maxIdleConns: 1, idleConnTimeout: time.Minute, keepAlive: -1},
+			expected: connectionPool{maxIdleConns: 1, idleConnTimeout: time.Minute, keepAlive: -1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.pool.withDefaults())
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package hueclient
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+)
+
+// checkNotHTML fails with ErrNotHueBridge if the response is an HTML page, which
+// would otherwise fail with a confusing JSON decode error. Responses without or
+// with another content type are decoded as before.
+func checkNotHTML(resp *http.Response) error {
+	contentType := resp.Header.Get("Content-Type")
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil
+	}
+
+	switch mediaType {
+	case "text/html", "application/xhtml+xml":
+		return fmt.Errorf("%w at %s, it answered with an HTML page (%s), check the bridge IP", ErrNotHueBridge, resp.Request.URL.Host, mediaType)
+	}
+	return nil
+}
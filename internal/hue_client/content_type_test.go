@@ -0,0 +1,97 @@
+package hueclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCaptivePortalServer answers every request with an HTML login page and the given status.
+func newCaptivePortalServer(t *testing.T, contentType string, status int) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(status)
+		fmt.Fprint(w, "<!DOCTYPE html><html><body>Please log in</body></html>")
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClient_DoRequest_HTMLResponse(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		status      int
+		errMsg      string
+	}{
+		{
+			name:        "html page",
+			contentType: "text/html; charset=utf-8",
+			status:      http.StatusOK,
+			errMsg:      "not a Hue bridge at %s, it answered with an HTML page (text/html), check the bridge IP",
+		},
+		{
+			name:        "xhtml page",
+			contentType: "application/xhtml+xml",
+			status:      http.StatusOK,
+			errMsg:      "not a Hue bridge at %s, it answered with an HTML page (application/xhtml+xml), check the bridge IP",
+		},
+		{
+			name:        "html not found page",
+			contentType: "text/html",
+			status:      http.StatusNotFound,
+			errMsg:      "not a Hue bridge at %s, it answered with an HTML page (text/html), check the bridge IP",
+		},
+		{
+			name:        "html forbidden page",
+			contentType: "text/html",
+			status:      http.StatusForbidden,
+			errMsg:      "not a Hue bridge at %s, it answered with an HTML page (text/html), check the bridge IP",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newCaptivePortalServer(t, tt.contentType, tt.status)
+
+			_, err := newTestClient(t, server).GetAllLights()
+
+			assert.ErrorIs(t, err, ErrNotHueBridge)
+			assert.ErrorContains(t, err, fmt.Sprintf(tt.errMsg, server.Listener.Addr().String()))
+		})
+	}
+}
+
+func TestClient_DoRequest_StatusErrorBodyIsLimited(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, strings.Repeat("x", 2*maxStatusErrorBody))
+	}))
+	defer server.Close()
+
+	_, err := newTestClient(t, server).GetAllLights()
+
+	var statusErr *StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusInternalServerError, statusErr.StatusCode)
+	assert.Len(t, statusErr.Body, maxStatusErrorBody)
+}
+
+func TestBridgeDiscoveryService_FetchBridgeConfigByIP_HTMLResponse(t *testing.T) {
+	server := newCaptivePortalServer(t, "text/html", http.StatusOK)
+	bridgeIP := server.Listener.Addr().String()
+
+	_, err := NewBridgeDiscoveryService(nil).fetchBridgeConfigByIP(context.Background(), bridgeIP)
+
+	assert.ErrorIs(t, err, ErrNotHueBridge)
+	assert.EqualError(t, err, fmt.Sprintf("not a Hue bridge at %s, it answered with an HTML page (text/html), check the bridge IP", bridgeIP))
+}
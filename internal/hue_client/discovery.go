@@ -3,9 +3,13 @@ package hueclient
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
+	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/brutella/dnssd"
@@ -16,6 +20,11 @@ type DiscoveredBridge struct {
 	IP   string
 	ID   string
 	Name string
+	// FactoryNew is set by DiscoverFirstBridgeCtx if the bridge has not completed its setup
+	FactoryNew bool
+	// ReplacesBridgeID is set by DiscoverFirstBridgeCtx to the ID of the bridge
+	// which was replaced by this one, see MigrateReplacedBridgeAPIKey
+	ReplacesBridgeID string
 }
 
 type BridgeConfig struct {
@@ -36,42 +45,275 @@ type DiscoverBridgeResult struct {
 	Name              string `json:"name"`
 }
 
+// bridgeConfigTimeout limits the request of the bridge config, which is only
+// used to detect factory-new and replacement bridges.
+const bridgeConfigTimeout = 5 * time.Second
+
+// DefaultMDNSTimeout is the time the discovery browses for a bridge via mDNS.
+const DefaultMDNSTimeout = 15 * time.Second
+
+const discoveryEndpointURL = "https://discovery.meethue.com"
+
 type BridgeDiscoveryService struct {
-	logger *log.Entry
+	logger      *log.Entry
+	mdnsTimeout time.Duration
+	// mdnsInterface and mdnsSubnet restrict which mDNS answers are accepted,
+	// empty/nil accepts answers from all interfaces.
+	mdnsInterface string
+	mdnsSubnet    *net.IPNet
+	lookupType    func(ctx context.Context, service string, add dnssd.AddFunc, rmv dnssd.RmvFunc) error
+	endpointURL   string
+	userAgent     string
+	metrics       RequestMetrics
+	httpClient    *http.Client
+	// cloudDisabled skips the discovery endpoint, bridges are only discovered via mDNS
+	cloudDisabled bool
+}
+
+// DiscoveryOption configures optional behaviour of the BridgeDiscoveryService.
+type DiscoveryOption func(*BridgeDiscoveryService)
+
+// WithMDNSTimeout sets the time to browse for a bridge via mDNS, defaults to DefaultMDNSTimeout.
+func WithMDNSTimeout(timeout time.Duration) DiscoveryOption {
+	return func(d *BridgeDiscoveryService) {
+		if timeout > 0 {
+			d.mdnsTimeout = timeout
+		}
+	}
+}
+
+// WithMDNSInterface only accepts mDNS answers received on the named network interface,
+// useful on hosts with VPNs or multiple NICs. See ValidateNetworkInterface.
+func WithMDNSInterface(name string) DiscoveryOption {
+	return func(d *BridgeDiscoveryService) {
+		d.mdnsInterface = name
+	}
+}
+
+// WithMDNSSubnet only accepts bridge addresses within the given subnet.
+func WithMDNSSubnet(subnet *net.IPNet) DiscoveryOption {
+	return func(d *BridgeDiscoveryService) {
+		d.mdnsSubnet = subnet
+	}
+}
+
+// WithDiscoveryUserAgent overrides the User-Agent header of the discovery requests,
+// defaults to DefaultUserAgent.
+func WithDiscoveryUserAgent(userAgent string) DiscoveryOption {
+	return func(d *BridgeDiscoveryService) {
+		d.userAgent = userAgent
+	}
+}
+
+// WithDiscoveryRequestMetrics reports every discovery request to the given metrics.
+func WithDiscoveryRequestMetrics(metrics RequestMetrics) DiscoveryOption {
+	return func(d *BridgeDiscoveryService) {
+		d.metrics = metrics
+	}
 }
 
-func NewBridgeDiscoveryService(logger *log.Entry) *BridgeDiscoveryService {
-	return &BridgeDiscoveryService{
-		logger: logger.WithField("component", "BridgeDiscoveryService"),
+// WithoutCloudDiscovery never asks the discovery endpoint of Philips (discovery.meethue.com),
+// which learns the public IP of the host, and only discovers bridges via mDNS.
+// The discovery then fails on networks which block multicast.
+func WithoutCloudDiscovery() DiscoveryOption {
+	return func(d *BridgeDiscoveryService) {
+		d.cloudDisabled = true
 	}
 }
 
+// CloudDiscoveryDisabled reports whether `HUE_DISABLE_CLOUD_DISCOVERY` opts out of
+// the discovery endpoint, see WithoutCloudDiscovery.
+func CloudDiscoveryDisabled() bool {
+	disabled, err := strconv.ParseBool(strings.TrimSpace(os.Getenv("HUE_DISABLE_CLOUD_DISCOVERY")))
+	return err == nil && disabled
+}
+
+// ValidateNetworkInterface returns an error if no network interface with the given name exists.
+func ValidateNetworkInterface(name string) error {
+	if _, err := net.InterfaceByName(name); err != nil {
+		return fmt.Errorf("network interface %q not found: %w", name, err)
+	}
+	return nil
+}
+
+func NewBridgeDiscoveryService(logger *log.Entry, opts ...DiscoveryOption) *BridgeDiscoveryService {
+	d := &BridgeDiscoveryService{
+		logger:      componentLogger(logger, "BridgeDiscoveryService"),
+		mdnsTimeout: DefaultMDNSTimeout,
+		lookupType:  dnssd.LookupType,
+		endpointURL: discoveryEndpointURL,
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	d.httpClient = &http.Client{Transport: newInstrumentedTransport(http.DefaultTransport, d.logger, d.userAgent, d.metrics, "Discovery request")}
+	return d
+}
+
 // DiscoverFirstBridge tries to discover a single Hue Bridge on the local network.
 func (d *BridgeDiscoveryService) DiscoverFirstBridge(logger *log.Entry) (*DiscoveredBridge, error) {
-	bridges, err := d.DiscoverBridges()
+	return d.DiscoverFirstBridgeCtx(context.Background())
+}
+
+// DiscoverFirstBridgeCtx tries to discover a single Hue Bridge on the local network
+// and returns as soon as the context is cancelled.
+func (d *BridgeDiscoveryService) DiscoverFirstBridgeCtx(ctx context.Context) (*DiscoveredBridge, error) {
+	bridges, err := d.DiscoverBridgesCtx(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to discover bridge: %w", err)
 	}
 
 	if len(bridges) == 0 {
-		return nil, fmt.Errorf("no Hue Bridges found")
+		return nil, ErrNoBridgesFound
 	}
 
-	return bridges[0], nil
+	bridge := bridges[0]
+	d.inspectBridge(ctx, bridge)
+	return bridge, nil
 }
 
-func (d *BridgeDiscoveryService) DiscoverBridges() ([]*DiscoveredBridge, error) {
-	bridgeIp, err := d.FindHueBridgeBymDNS()
+// inspectBridge reads the config of the bridge to detect whether it is factory-new
+// or replaced another bridge. It warns about factory-new bridges, because registering
+// at them fails with a confusing error. The discovery does not fail if the config
+// cannot be read.
+func (d *BridgeDiscoveryService) inspectBridge(ctx context.Context, bridge *DiscoveredBridge) {
+	ctx, cancel := context.WithTimeout(ctx, bridgeConfigTimeout)
+	defer cancel()
+
+	config, err := d.fetchBridgeConfigByIP(ctx, bridge.IP)
 	if err != nil {
-		// Falling back to discover.meethue.com endpoint
-		return d.fetchBridgesFromDiscoverEndpoint()
+		d.logger.WithError(err).Debugf("Could not read the config of bridge %s", bridge.ID)
+		return
 	}
 
-	if bridgeIp == "" {
+	d.applyBridgeConfig(bridge, config)
+}
+
+// applyBridgeConfig sets whether the bridge is factory-new or replaced another
+// bridge and warns about factory-new bridges.
+func (d *BridgeDiscoveryService) applyBridgeConfig(bridge *DiscoveredBridge, config *BridgeConfig) {
+	if config.ReplacesBridgeID != nil {
+		bridge.ReplacesBridgeID = *config.ReplacesBridgeID
+	}
+	bridge.FactoryNew = config.FactoryNew
+	if bridge.FactoryNew {
+		d.logger.Warnf("Hue Bridge %s is factory-new, complete its setup in the Philips Hue app first, "+
+			"registering hue-lighter fails until then", bridge.ID)
+	}
+}
+
+// BridgeAtIP returns the bridge at the given IP instead of discovering it, e.g.
+// for a bridge with a fixed IP on a network which blocks multicast. The ID of the
+// bridge is read from its config, neither mDNS nor the discovery endpoint are used.
+func (d *BridgeDiscoveryService) BridgeAtIP(ctx context.Context, ip string) (*DiscoveredBridge, error) {
+	ctx, cancel := context.WithTimeout(ctx, bridgeConfigTimeout)
+	defer cancel()
+
+	config, err := d.fetchBridgeConfigByIP(ctx, ip)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the config of the bridge at %q: %w", ip, err)
+	}
+
+	bridge := &DiscoveredBridge{IP: ip, ID: config.BridgeID, Name: config.Name}
+	d.applyBridgeConfig(bridge, config)
+	return bridge, nil
+}
+
+// LocateBridge discovers the bridges on the local network and returns the IP of
+// the bridge with the given ID, it can be passed to WithRediscovery.
+func (d *BridgeDiscoveryService) LocateBridge(ctx context.Context, bridgeID string) (string, error) {
+	bridges, err := d.DiscoverBridgesCtx(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to discover bridge %q: %w", bridgeID, err)
+	}
+
+	for _, bridge := range bridges {
+		if strings.EqualFold(bridge.ID, bridgeID) {
+			return bridge.IP, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: %q", ErrBridgeNotFound, bridgeID)
+}
+
+func (d *BridgeDiscoveryService) DiscoverBridges() ([]*DiscoveredBridge, error) {
+	return d.DiscoverBridgesCtx(context.Background())
+}
+
+type discoveryResult struct {
+	method  string
+	bridges []*DiscoveredBridge
+	err     error
+}
+
+// DiscoverBridgesCtx discovers bridges by mDNS and the discovery endpoint at the same
+// time and returns the bridges of whichever method finds bridges first, because mDNS
+// often takes long to time out while the discovery endpoint answers instantly.
+// Only mDNS is used if the cloud discovery is disabled, see WithoutCloudDiscovery.
+func (d *BridgeDiscoveryService) DiscoverBridgesCtx(ctx context.Context) ([]*DiscoveredBridge, error) {
+	discoveryCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	methods := 2
+	if d.cloudDisabled {
+		methods = 1
+	}
+
+	// Buffered, so that the slower method does not block after we returned.
+	results := make(chan discoveryResult, methods)
+	go func() {
+		bridges, err := d.discoverBridgesBymDNS(discoveryCtx)
+		results <- discoveryResult{method: "mDNS", bridges: bridges, err: err}
+	}()
+	if !d.cloudDisabled {
+		go func() {
+			bridges, err := d.fetchBridgesFromDiscoverEndpoint(discoveryCtx)
+			results <- discoveryResult{method: "discovery endpoint", bridges: bridges, err: err}
+		}()
+	}
+
+	var errs []error
+	for range methods {
+		result := <-results
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		if result.err != nil {
+			d.logger.WithError(result.err).Debugf("Bridge discovery via %s failed", result.method)
+			errs = append(errs, result.err)
+			continue
+		}
+		if len(result.bridges) > 0 {
+			d.logger.Debugf("Discovered %d bridge(s) via %s", len(result.bridges), result.method)
+			return deduplicateBridges(result.bridges), nil
+		}
+	}
+
+	return nil, errors.Join(errs...)
+}
+
+// deduplicateBridges removes bridges with the same ID, keeping the first occurrence.
+func deduplicateBridges(bridges []*DiscoveredBridge) []*DiscoveredBridge {
+	seen := make(map[string]bool, len(bridges))
+	var unique []*DiscoveredBridge
+	for _, bridge := range bridges {
+		id := strings.ToLower(bridge.ID)
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		unique = append(unique, bridge)
+	}
+	return unique
+}
+
+func (d *BridgeDiscoveryService) discoverBridgesBymDNS(ctx context.Context) ([]*DiscoveredBridge, error) {
+	bridgeIp, err := d.FindHueBridgeBymDNSCtx(ctx)
+	if err != nil {
 		return nil, fmt.Errorf("failed to discover bridge with mDNS discovery: %w", err)
 	}
 
-	config, err := d.fetchBridgeConfigByIP(bridgeIp)
+	config, err := d.fetchBridgeConfigByIP(ctx, bridgeIp)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch config for discovered bridge \"%s\": %w", bridgeIp, err)
 	}
@@ -84,13 +326,26 @@ func (d *BridgeDiscoveryService) DiscoverBridges() ([]*DiscoveredBridge, error)
 }
 
 func (d *BridgeDiscoveryService) FindHueBridgeBymDNS() (string, error) {
-	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Second*15))
+	return d.FindHueBridgeBymDNSCtx(context.Background())
+}
+
+// FindHueBridgeBymDNSCtx browses for a Hue Bridge via mDNS until the mDNS timeout
+// elapsed or the context is cancelled.
+func (d *BridgeDiscoveryService) FindHueBridgeBymDNSCtx(ctx context.Context) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.mdnsTimeout)
 	defer cancel()
 
-	addrChan := make(chan []net.IP)
+	addrChan := make(chan string)
 
 	addFn := func(e dnssd.BrowseEntry) {
-		addrChan <- e.IPs
+		ip := d.bridgeIPFromEntry(e)
+		if ip == "" {
+			return
+		}
+		select {
+		case addrChan <- ip:
+		case <-ctx.Done():
+		}
 	}
 
 	rmvFn := func(e dnssd.BrowseEntry) {
@@ -100,32 +355,48 @@ func (d *BridgeDiscoveryService) FindHueBridgeBymDNS() (string, error) {
 
 	service := "_hue._tcp.local."
 	go func() {
-		if err := dnssd.LookupType(ctx, service, addFn, rmvFn); err != nil {
-			if err != context.Canceled {
-				fmt.Println("Error during mDNS lookup:", err)
+		if err := d.lookupType(ctx, service, addFn, rmvFn); err != nil {
+			if !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+				d.logger.WithError(err).Warn("Error during mDNS lookup")
 			}
 		}
 	}()
 
 	select {
 	case <-ctx.Done():
-		fmt.Println("Discovery timeout")
-		return "", fmt.Errorf("discovery timeout")
-	case ips := <-addrChan:
-		if len(ips) > 0 {
-			for _, ip := range ips {
-				if ip.To4() != nil {
-					return ip.String(), nil
-				}
-			}
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			d.logger.Info("mDNS discovery timeout")
+			return "", ErrDiscoveryTimeout
 		}
+		return "", ctx.Err()
+	case ip := <-addrChan:
+		return ip, nil
+	}
+}
+
+// bridgeIPFromEntry returns the first IPv4 address of the entry which matches the
+// configured interface and subnet, or an empty string if none matches.
+func (d *BridgeDiscoveryService) bridgeIPFromEntry(e dnssd.BrowseEntry) string {
+	if d.mdnsInterface != "" && e.IfaceName != d.mdnsInterface {
+		d.logger.Debugf("Ignoring mDNS answer received on interface %q", e.IfaceName)
+		return ""
 	}
 
-	return "", nil
+	for _, ip := range e.IPs {
+		if ip.To4() == nil {
+			continue
+		}
+		if d.mdnsSubnet != nil && !d.mdnsSubnet.Contains(ip) {
+			d.logger.Debugf("Ignoring bridge address %s outside of subnet %s", ip, d.mdnsSubnet)
+			continue
+		}
+		return ip.String()
+	}
+	return ""
 }
 
-func (d *BridgeDiscoveryService) fetchBridgesFromDiscoverEndpoint() ([]*DiscoveredBridge, error) {
-	bridges, err := d.fetchBridgesByDiscoverEndpoint()
+func (d *BridgeDiscoveryService) fetchBridgesFromDiscoverEndpoint(ctx context.Context) ([]*DiscoveredBridge, error) {
+	bridges, err := d.fetchBridgesByDiscoverEndpoint(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to discover bridges via discover endpoint: %w", err)
 	}
@@ -140,9 +411,12 @@ func (d *BridgeDiscoveryService) fetchBridgesFromDiscoverEndpoint() ([]*Discover
 	return discoveredBridges, nil
 }
 
-func (d *BridgeDiscoveryService) fetchBridgesByDiscoverEndpoint() ([]*DiscoverBridgeResult, error) {
-
-	resp, err := http.Get("https://discovery.meethue.com")
+func (d *BridgeDiscoveryService) fetchBridgesByDiscoverEndpoint(ctx context.Context) ([]*DiscoverBridgeResult, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.endpointURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create discovery request: %w", err)
+	}
+	resp, err := d.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to discover bridge: %w", err)
 	}
@@ -150,7 +424,7 @@ func (d *BridgeDiscoveryService) fetchBridgesByDiscoverEndpoint() ([]*DiscoverBr
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("discovery request failed with status code: %v", resp.StatusCode)
+		return nil, fmt.Errorf("discovery %w", newStatusError(resp))
 	}
 
 	var result []*DiscoverBridgeResult
@@ -163,23 +437,30 @@ func (d *BridgeDiscoveryService) fetchBridgesByDiscoverEndpoint() ([]*DiscoverBr
 	return result, nil
 }
 
-func (d *BridgeDiscoveryService) fetchBridgeConfigByIP(bridgeIP string) (*BridgeConfig, error) {
+func (d *BridgeDiscoveryService) fetchBridgeConfigByIP(ctx context.Context, bridgeIP string) (*BridgeConfig, error) {
 	url := fmt.Sprintf("http://%s/api/0/config", bridgeIP)
-	resp, err := http.Get(url)
-
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get bridge config: %v", err)
+		return nil, fmt.Errorf("failed to create bridge config request: %w", err)
 	}
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bridge config: %w", err)
+	}
+	defer resp.Body.Close()
+
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("bridge config request failed with status code: %d", resp.StatusCode)
+		return nil, fmt.Errorf("bridge config %w", newStatusError(resp))
+	}
+	if err := checkNotHTML(resp); err != nil {
+		return nil, err
 	}
 
 	var config BridgeConfig
 
-	defer resp.Body.Close()
 	decoder := json.NewDecoder(resp.Body)
 	if err := decoder.Decode(&config); err != nil {
-		return nil, fmt.Errorf("failed to decode bridge config response: %v", err)
+		return nil, fmt.Errorf("failed to decode bridge config response: %w", err)
 	}
 
 	return &config, nil
@@ -0,0 +1,381 @@
+package hueclient
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"com.github.yveskaufmann/hue-lighter/internal/testutils"
+	"github.com/brutella/dnssd"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBridgeDiscoveryService_DiscoverFirstBridgeCtx_Cancelled(t *testing.T) {
+	tests := []struct {
+		name        string
+		newContext  func() (context.Context, context.CancelFunc)
+		expectedErr error
+	}{
+		{
+			name: "returns immediately with cancelled context",
+			newContext: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx, cancel
+			},
+			expectedErr: context.Canceled,
+		},
+		{
+			name: "returns when context is cancelled during mDNS lookup",
+			newContext: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				time.AfterFunc(50*time.Millisecond, cancel)
+				return ctx, cancel
+			},
+			expectedErr: context.Canceled,
+		},
+		{
+			name: "returns when context deadline is exceeded",
+			newContext: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 50*time.Millisecond)
+			},
+			expectedErr: context.DeadlineExceeded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewBridgeDiscoveryService(logrus.New().WithField("test", tt.name))
+			ctx, cancel := tt.newContext()
+			defer cancel()
+
+			start := time.Now()
+			bridge, err := service.DiscoverFirstBridgeCtx(ctx)
+
+			require.Error(t, err)
+			assert.ErrorIs(t, err, tt.expectedErr)
+			assert.Nil(t, bridge)
+			assert.Less(t, time.Since(start), 2*time.Second)
+		})
+	}
+}
+
+func TestBridgeDiscoveryService_FindHueBridgeBymDNSCtx_Filters(t *testing.T) {
+	_, subnet, err := net.ParseCIDR("192.168.1.0/24")
+	require.NoError(t, err)
+
+	entries := []dnssd.BrowseEntry{
+		{IfaceName: "tun0", IPs: []net.IP{net.ParseIP("10.8.0.2")}},
+		{IfaceName: "eth0", IPs: []net.IP{net.ParseIP("fe80::1"), net.ParseIP("10.0.0.2")}},
+		{IfaceName: "eth0", IPs: []net.IP{net.ParseIP("192.168.1.2")}},
+	}
+
+	tests := []struct {
+		name       string
+		opts       []DiscoveryOption
+		expectedIP string
+	}{
+		{
+			name:       "accepts first answer without restriction",
+			expectedIP: "10.8.0.2",
+		},
+		{
+			name:       "accepts only answers on the interface",
+			opts:       []DiscoveryOption{WithMDNSInterface("eth0")},
+			expectedIP: "10.0.0.2",
+		},
+		{
+			name:       "accepts only addresses within the subnet",
+			opts:       []DiscoveryOption{WithMDNSSubnet(subnet)},
+			expectedIP: "192.168.1.2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewBridgeDiscoveryService(nil, tt.opts...)
+			service.lookupType = func(ctx context.Context, service string, add dnssd.AddFunc, rmv dnssd.RmvFunc) error {
+				assert.Equal(t, "_hue._tcp.local.", service)
+				for _, e := range entries {
+					add(e)
+				}
+				<-ctx.Done()
+				return ctx.Err()
+			}
+
+			ip, err := service.FindHueBridgeBymDNSCtx(context.Background())
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedIP, ip)
+		})
+	}
+
+	t.Run("times out when no answer matches", func(t *testing.T) {
+		service := NewBridgeDiscoveryService(nil, WithMDNSInterface("wlan0"), WithMDNSTimeout(50*time.Millisecond))
+		service.lookupType = func(ctx context.Context, service string, add dnssd.AddFunc, rmv dnssd.RmvFunc) error {
+			for _, e := range entries {
+				add(e)
+			}
+			<-ctx.Done()
+			return ctx.Err()
+		}
+
+		_, err := service.FindHueBridgeBymDNSCtx(context.Background())
+
+		assert.EqualError(t, err, "discovery timeout")
+	})
+}
+
+func TestValidateNetworkInterface(t *testing.T) {
+	interfaces, err := net.Interfaces()
+	require.NoError(t, err)
+	require.NotEmpty(t, interfaces)
+
+	assert.NoError(t, ValidateNetworkInterface(interfaces[0].Name))
+	assert.ErrorContains(t, ValidateNetworkInterface("does-not-exist0"), `network interface "does-not-exist0" not found`)
+}
+
+// slowLookupType simulates an mDNS lookup which finds no bridge until it times out.
+func slowLookupType(ctx context.Context, service string, add dnssd.AddFunc, rmv dnssd.RmvFunc) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestBridgeDiscoveryService_DiscoverBridgesCtx_Parallel(t *testing.T) {
+	t.Run("discovery endpoint wins when mDNS is slow", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`[
+				{"id": "ecb5fafffe123456", "internalipaddress": "192.168.1.2"},
+				{"id": "ECB5FAFFFE123456", "internalipaddress": "192.168.1.2"},
+				{"id": "ecb5fafffe654321", "internalipaddress": "192.168.1.3"}
+			]`))
+		}))
+		defer server.Close()
+
+		service := NewBridgeDiscoveryService(nil)
+		service.lookupType = slowLookupType
+		service.endpointURL = server.URL
+
+		start := time.Now()
+		bridges, err := service.DiscoverBridgesCtx(context.Background())
+
+		require.NoError(t, err)
+		assert.Less(t, time.Since(start), DefaultMDNSTimeout/2)
+		require.Len(t, bridges, 2)
+		assert.Equal(t, "ecb5fafffe123456", bridges[0].ID)
+		assert.Equal(t, "ecb5fafffe654321", bridges[1].ID)
+	})
+
+	t.Run("returns errors of both methods when both fail", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		service := NewBridgeDiscoveryService(nil, WithMDNSTimeout(50*time.Millisecond))
+		service.lookupType = slowLookupType
+		service.endpointURL = server.URL
+
+		bridges, err := service.DiscoverBridgesCtx(context.Background())
+
+		assert.Nil(t, bridges)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "discovery timeout")
+		assert.Contains(t, err.Error(), "status code: 500")
+	})
+}
+
+func TestBridgeDiscoveryService_DiscoverBridgesCtx_WithoutCloudDiscovery(t *testing.T) {
+	var endpointCalls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		endpointCalls.Add(1)
+		w.Write([]byte(`[{"id": "ecb5fafffe123456", "internalipaddress": "192.168.1.2"}]`))
+	}))
+	defer server.Close()
+
+	service := NewBridgeDiscoveryService(nil, WithMDNSTimeout(50*time.Millisecond), WithoutCloudDiscovery())
+	service.lookupType = slowLookupType
+	service.endpointURL = server.URL
+
+	bridges, err := service.DiscoverBridgesCtx(context.Background())
+
+	assert.Nil(t, bridges)
+	assert.ErrorContains(t, err, "discovery timeout")
+	assert.Zero(t, endpointCalls.Load())
+}
+
+func TestCloudDiscoveryDisabled(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected bool
+	}{
+		{value: "", expected: false},
+		{value: "true", expected: true},
+		{value: " 1 ", expected: true},
+		{value: "false", expected: false},
+		{value: "yes", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			defer testutils.SetEnv(t, "HUE_DISABLE_CLOUD_DISCOVERY", tt.value)()
+
+			assert.Equal(t, tt.expected, CloudDiscoveryDisabled())
+		})
+	}
+}
+
+func TestBridgeDiscoveryService_LocateBridge(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[
+			{"id": "ecb5fafffe123456", "internalipaddress": "192.168.1.2"},
+			{"id": "ecb5fafffe654321", "internalipaddress": "192.168.1.3"}
+		]`))
+	}))
+	defer server.Close()
+
+	service := NewBridgeDiscoveryService(nil)
+	service.lookupType = slowLookupType
+	service.endpointURL = server.URL
+
+	t.Run("returns IP of bridge with matching ID", func(t *testing.T) {
+		ip, err := service.LocateBridge(context.Background(), "ECB5FAFFFE654321")
+
+		require.NoError(t, err)
+		assert.Equal(t, "192.168.1.3", ip)
+	})
+
+	t.Run("fails when bridge is not discovered", func(t *testing.T) {
+		_, err := service.LocateBridge(context.Background(), "ecb5fafffe000000")
+
+		assert.ErrorIs(t, err, ErrBridgeNotFound)
+	})
+}
+
+func TestBridgeDiscoveryService_BridgeAtIP(t *testing.T) {
+	t.Run("reads the bridge ID from the bridge config", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/0/config", r.URL.Path)
+			w.Write([]byte(`{"name": "Hue Bridge", "bridgeid": "ECB5FAFFFE123456", "replacesbridgeid": "001788FFFE654321"}`))
+		}))
+		defer server.Close()
+
+		service := NewBridgeDiscoveryService(nil)
+		service.lookupType = func(ctx context.Context, service string, add dnssd.AddFunc, rmv dnssd.RmvFunc) error {
+			t.Fatal("mDNS must not be used")
+			return nil
+		}
+		service.endpointURL = "http://discovery.invalid"
+
+		bridge, err := service.BridgeAtIP(context.Background(), server.Listener.Addr().String())
+
+		require.NoError(t, err)
+		assert.Equal(t, &DiscoveredBridge{
+			IP:               server.Listener.Addr().String(),
+			ID:               "ECB5FAFFFE123456",
+			Name:             "Hue Bridge",
+			ReplacesBridgeID: "001788FFFE654321",
+		}, bridge)
+	})
+
+	t.Run("fails if the bridge config cannot be read", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		_, err := NewBridgeDiscoveryService(nil).BridgeAtIP(context.Background(), server.Listener.Addr().String())
+
+		assert.ErrorContains(t, err, "failed to read the config of the bridge at")
+		assert.ErrorContains(t, err, "status code: 404")
+	})
+}
+
+func TestBridgeDiscoveryService_DiscoverFirstBridgeCtx_BridgeConfig(t *testing.T) {
+	tests := []struct {
+		name                     string
+		bridgeConfig             string
+		expectedFactoryNew       bool
+		expectedReplacesBridgeID string
+		expectedWarning          bool
+	}{
+		{
+			name:               "warns about factory-new bridge",
+			bridgeConfig:       `{"bridgeid": "ECB5FAFFFE123456", "factorynew": true}`,
+			expectedFactoryNew: true,
+			expectedWarning:    true,
+		},
+		{
+			name:         "does not warn about set up bridge",
+			bridgeConfig: `{"bridgeid": "ECB5FAFFFE123456", "factorynew": false}`,
+		},
+		{
+			name:                     "detects replacement bridge",
+			bridgeConfig:             `{"bridgeid": "ECB5FAFFFE123456", "factorynew": false, "replacesbridgeid": "001788FFFE654321"}`,
+			expectedReplacesBridgeID: "001788FFFE654321",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bridgeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/api/0/config", r.URL.Path)
+				w.Write([]byte(tt.bridgeConfig))
+			}))
+			defer bridgeServer.Close()
+
+			endpointServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintf(w, `[{"id": "ecb5fafffe123456", "internalipaddress": %q}]`, bridgeServer.Listener.Addr().String())
+			}))
+			defer endpointServer.Close()
+
+			logger, hook := test.NewNullLogger()
+			service := NewBridgeDiscoveryService(logrus.NewEntry(logger))
+			service.lookupType = slowLookupType
+			service.endpointURL = endpointServer.URL
+
+			bridge, err := service.DiscoverFirstBridgeCtx(context.Background())
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedFactoryNew, bridge.FactoryNew)
+			assert.Equal(t, tt.expectedReplacesBridgeID, bridge.ReplacesBridgeID)
+			var warned bool
+			for _, entry := range hook.AllEntries() {
+				if entry.Level == logrus.WarnLevel && strings.Contains(entry.Message, "factory-new") {
+					warned = true
+				}
+			}
+			assert.Equal(t, tt.expectedWarning, warned)
+		})
+	}
+
+	t.Run("ignores unreadable bridge config", func(t *testing.T) {
+		bridgeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer bridgeServer.Close()
+
+		endpointServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `[{"id": "ecb5fafffe123456", "internalipaddress": %q}]`, bridgeServer.Listener.Addr().String())
+		}))
+		defer endpointServer.Close()
+
+		service := NewBridgeDiscoveryService(nil)
+		service.lookupType = slowLookupType
+		service.endpointURL = endpointServer.URL
+
+		bridge, err := service.DiscoverFirstBridgeCtx(context.Background())
+
+		require.NoError(t, err)
+		assert.False(t, bridge.FactoryNew)
+	})
+}
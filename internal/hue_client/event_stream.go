@@ -0,0 +1,107 @@
+package hueclient
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// EventTypeUpdate is the type of the events the bridge sends when a resource changed.
+const EventTypeUpdate = "update"
+
+// maxEventSize limits a single event of the stream, the bridge batches the
+// changes of a second into one event.
+const maxEventSize = 1 << 20
+
+// StreamEvent is an event of the bridge event stream.
+type StreamEvent struct {
+	ID           string                `json:"id"`
+	Type         string                `json:"type"`
+	CreationTime string                `json:"creationtime"`
+	Data         []StreamEventResource `json:"data"`
+}
+
+// StreamEventResource contains the changed fields of a resource, fields which
+// did not change are not set.
+type StreamEventResource struct {
+	ID      string             `json:"id"`
+	Type    string             `json:"type"`
+	On      *LightOnState      `json:"on,omitempty"`
+	Dimming *LightDimmingState `json:"dimming,omitempty"`
+}
+
+// StreamEvents connects to the server-sent event stream of the bridge and calls
+// handle for every received event until ctx is cancelled or the connection is
+// closed. It always returns an error, ctx.Err() after a cancellation.
+func (c *Client) StreamEvents(ctx context.Context, handle func(event *StreamEvent)) error {
+	apiKey, err := c.apiKey()
+	if err != nil {
+		return newOperationError(ErrPrefixEventStream, "", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.getBaseURL()+"/eventstream/clip/v2", nil)
+	if err != nil {
+		return newOperationError(ErrPrefixEventStream, "", fmt.Errorf("failed to create request: %w", err))
+	}
+	req.Header.Set("hue-application-key", apiKey)
+	req.Header.Set("Accept", "text/event-stream")
+
+	response, err := c.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return newOperationError(ErrPrefixEventStream, "", fmt.Errorf("failed to do request: %w", err))
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return newOperationError(ErrPrefixEventStream, "", newStatusError(response))
+	}
+
+	err = readEventStream(response, handle)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err != nil {
+		return newOperationError(ErrPrefixEventStream, "", err)
+	}
+	return newOperationError(ErrPrefixEventStream, "", errors.New("connection closed by the bridge"))
+}
+
+// readEventStream reads the messages of the stream, the data lines of a message
+// are joined and contain a JSON array of events. Comments and other fields, like
+// the event id and the heartbeat of the bridge, are ignored.
+func readEventStream(response *http.Response, handle func(event *StreamEvent)) error {
+	scanner := bufio.NewScanner(response.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEventSize)
+
+	var data strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line != "" {
+			if value, ok := strings.CutPrefix(line, "data:"); ok {
+				data.WriteString(strings.TrimPrefix(value, " "))
+			}
+			continue
+		}
+
+		if data.Len() == 0 {
+			continue
+		}
+		var events []StreamEvent
+		if err := json.Unmarshal([]byte(data.String()), &events); err != nil {
+			return fmt.Errorf("failed to decode event: %w", err)
+		}
+		data.Reset()
+		for i := range events {
+			handle(&events[i])
+		}
+	}
+
+	return scanner.Err()
+}
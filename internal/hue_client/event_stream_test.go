@@ -0,0 +1,98 @@
+package hueclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_StreamEvents(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/eventstream/clip/v2", r.URL.Path)
+		assert.Equal(t, "test-api-key", r.Header.Get("hue-application-key"))
+		assert.Equal(t, "text/event-stream", r.Header.Get("Accept"))
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, ": hi\n\n")
+		fmt.Fprint(w, "id: 1700000000:0\n")
+		fmt.Fprint(w, `data: [{"creationtime":"2025-01-10T18:00:00Z","id":"event-1","type":"update",`+
+			`"data":[{"id":"light-1","type":"light","on":{"on":true}},{"id":"light-2","type":"light","dimming":{"brightness":40}}]}]`+"\n\n")
+		fmt.Fprint(w, "id: 1700000001:0\n")
+		fmt.Fprint(w, `data: [{"id":"event-2","type":"update","data":[{"id":"light-1","type":"light","on":{"on":false}}]}]`+"\n\n")
+	}))
+	defer server.Close()
+
+	var events []*StreamEvent
+	err := newTestClient(t, server).StreamEvents(context.Background(), func(event *StreamEvent) {
+		events = append(events, event)
+	})
+
+	assert.EqualError(t, err, "hue: event stream: connection closed by the bridge")
+	require.Len(t, events, 2)
+	assert.Equal(t, "event-1", events[0].ID)
+	assert.Equal(t, EventTypeUpdate, events[0].Type)
+	assert.Equal(t, []StreamEventResource{
+		{ID: "light-1", Type: "light", On: &LightOnState{On: true}},
+		{ID: "light-2", Type: "light", Dimming: &LightDimmingState{Brightness: 40}},
+	}, events[0].Data)
+	assert.Equal(t, []StreamEventResource{{ID: "light-1", Type: "light", On: &LightOnState{On: false}}}, events[1].Data)
+}
+
+func TestClient_StreamEvents_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		errMsg string
+	}{
+		{
+			name:   "rejected by the bridge",
+			status: http.StatusForbidden,
+			body:   "forbidden",
+			errMsg: "hue: event stream: request failed with status code: 403, response: forbidden",
+		},
+		{
+			name:   "malformed event",
+			status: http.StatusOK,
+			body:   "data: {not json\n\n",
+			errMsg: "hue: event stream: failed to decode event: invalid character 'n' looking for beginning of object key string",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			}))
+			defer server.Close()
+
+			err := newTestClient(t, server).StreamEvents(context.Background(), func(event *StreamEvent) {})
+
+			assert.EqualError(t, err, tt.errMsg)
+		})
+	}
+}
+
+func TestClient_StreamEvents_Cancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, `data: [{"id":"event-1","type":"update","data":[]}]`+"\n\n")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err := newTestClient(t, server).StreamEvents(ctx, func(event *StreamEvent) {
+		cancel()
+	})
+
+	assert.True(t, errors.Is(err, context.Canceled), "unexpected error: %v", err)
+}
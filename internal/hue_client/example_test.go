@@ -0,0 +1,46 @@
+package hueclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	hueclient "com.github.yveskaufmann/hue-lighter/internal/hue_client"
+)
+
+func ExampleConnect() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := hueclient.Connect(ctx, hueclient.Options{
+		BridgeIP:     "192.168.1.2",
+		BridgeID:     "ECB5FAFFFE123456",
+		APIKey:       "your-application-key",
+		CABundlePath: "/etc/hue-lighter/cacert_bundle.pem",
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	lights, err := client.GetAllLights()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, light := range lights.Data {
+		fmt.Println(light.ID, light.Meta.Name)
+	}
+}
+
+func ExampleNewLightUpdate() {
+	update, err := hueclient.NewLightUpdate().On(true).Brightness(80).ColorTemperature(350).TransitionMs(400).Build()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	body, _ := json.Marshal(update)
+	fmt.Println(string(body))
+	// Output: {"on":{"on":true},"dimming":{"brightness":80},"color_temperature":{"mirek":350},"dynamics":{"duration":400}}
+}
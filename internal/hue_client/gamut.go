@@ -0,0 +1,120 @@
+package hueclient
+
+import "math"
+
+// GamutType is the color gamut class of a light as reported by the bridge.
+type GamutType string
+
+const (
+	GamutTypeA     GamutType = "A"
+	GamutTypeB     GamutType = "B"
+	GamutTypeC     GamutType = "C"
+	GamutTypeOther GamutType = "other"
+)
+
+// ColorGamut is the triangle in the CIE XY color space a light can reproduce.
+type ColorGamut struct {
+	Red   ColorXY `json:"red"`
+	Green ColorXY `json:"green"`
+	Blue  ColorXY `json:"blue"`
+}
+
+// Color gamuts of the Hue light generations, published by Philips.
+var (
+	// GamutA of older LivingColors, Bloom and LightStrips
+	GamutA = ColorGamut{Red: ColorXY{X: 0.704, Y: 0.296}, Green: ColorXY{X: 0.2151, Y: 0.7106}, Blue: ColorXY{X: 0.138, Y: 0.08}}
+	// GamutB of the first generation Hue bulbs
+	GamutB = ColorGamut{Red: ColorXY{X: 0.675, Y: 0.322}, Green: ColorXY{X: 0.409, Y: 0.518}, Blue: ColorXY{X: 0.167, Y: 0.04}}
+	// GamutC of the current Hue lights
+	GamutC = ColorGamut{Red: ColorXY{X: 0.6915, Y: 0.3083}, Green: ColorXY{X: 0.17, Y: 0.7}, Blue: ColorXY{X: 0.1532, Y: 0.0475}}
+)
+
+// GamutOf returns the color gamut of the light, the gamut reported by the bridge
+// takes precedence over its gamut type. Defaults to GamutC when unknown.
+func GamutOf(light *LightListItem) ColorGamut {
+	if light == nil || light.Color == nil {
+		return GamutC
+	}
+	if light.Color.Gamut != nil {
+		return *light.Color.Gamut
+	}
+
+	switch light.Color.GamutType {
+	case GamutTypeA:
+		return GamutA
+	case GamutTypeB:
+		return GamutB
+	default:
+		return GamutC
+	}
+}
+
+// Contains reports whether the point is within the gamut triangle, including its edges.
+func (g ColorGamut) Contains(p ColorXY) bool {
+	d1 := cross(g.Red, g.Green, p)
+	d2 := cross(g.Green, g.Blue, p)
+	d3 := cross(g.Blue, g.Red, p)
+
+	hasNegative := d1 < 0 || d2 < 0 || d3 < 0
+	hasPositive := d1 > 0 || d2 > 0 || d3 > 0
+	return !(hasNegative && hasPositive)
+}
+
+// Clamp projects a point outside of the gamut onto the closest point of the
+// triangle edges, points within the gamut are returned unchanged.
+func (g ColorGamut) Clamp(p ColorXY) ColorXY {
+	if g.Contains(p) {
+		return p
+	}
+
+	closest := closestOnSegment(g.Red, g.Green, p)
+	for _, candidate := range []ColorXY{closestOnSegment(g.Green, g.Blue, p), closestOnSegment(g.Blue, g.Red, p)} {
+		if distance(candidate, p) < distance(closest, p) {
+			closest = candidate
+		}
+	}
+	return closest
+}
+
+// cross is the z component of the cross product of a->b and a->p, its sign
+// tells on which side of the line a->b the point p is.
+func cross(a ColorXY, b ColorXY, p ColorXY) float64 {
+	return float64(b.X-a.X)*float64(p.Y-a.Y) - float64(b.Y-a.Y)*float64(p.X-a.X)
+}
+
+func closestOnSegment(a ColorXY, b ColorXY, p ColorXY) ColorXY {
+	abX, abY := float64(b.X-a.X), float64(b.Y-a.Y)
+	t := (float64(p.X-a.X)*abX + float64(p.Y-a.Y)*abY) / (abX*abX + abY*abY)
+	t = math.Max(0, math.Min(1, t))
+	return ColorXY{X: float32(float64(a.X) + t*abX), Y: float32(float64(a.Y) + t*abY)}
+}
+
+func distance(a ColorXY, b ColorXY) float64 {
+	return math.Hypot(float64(a.X-b.X), float64(a.Y-b.Y))
+}
+
+// ColorXYFromRGB converts an sRGB color to its CIE XY position with the wide
+// gamut conversion recommended by Philips, black is mapped to the D65 white point.
+// The result may be outside of the gamut of a light, see ColorGamut.Clamp.
+func ColorXYFromRGB(r uint8, g uint8, b uint8) ColorXY {
+	red, green, blue := linearRGB(r), linearRGB(g), linearRGB(b)
+
+	x := red*0.664511 + green*0.154324 + blue*0.162028
+	y := red*0.283881 + green*0.668433 + blue*0.047685
+	z := red*0.000088 + green*0.072310 + blue*0.986039
+
+	sum := x + y + z
+	if sum == 0 {
+		return ColorXY{X: 0.3127, Y: 0.329}
+	}
+	return ColorXY{X: float32(x / sum), Y: float32(y / sum)}
+}
+
+// linearRGB removes the gamma correction of an sRGB channel.
+func linearRGB(channel uint8) float64 {
+	value := float64(channel) / 255
+	if value > 0.04045 {
+		return math.Pow((value+0.055)/1.055, 2.4)
+	}
+	return value / 12.92
+}
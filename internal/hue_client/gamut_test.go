@@ -0,0 +1,111 @@
+package hueclient
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"com.github.yveskaufmann/hue-lighter/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestColorGamut_Clamp(t *testing.T) {
+	tests := []struct {
+		name           string
+		gamut          ColorGamut
+		point          ColorXY
+		expectedInside bool
+		expected       ColorXY
+	}{
+		{name: "gamut A keeps white", gamut: GamutA, point: ColorXY{X: 0.3127, Y: 0.329}, expectedInside: true, expected: ColorXY{X: 0.3127, Y: 0.329}},
+		{name: "gamut A keeps its red corner", gamut: GamutA, point: GamutA.Red, expectedInside: true, expected: GamutA.Red},
+		{name: "gamut A clamps beyond its red corner", gamut: GamutA, point: ColorXY{X: 0.75, Y: 0.25}, expected: GamutA.Red},
+		{name: "gamut A clamps to the red-blue edge", gamut: GamutA, point: ColorXY{X: 0.4, Y: 0.1}, expected: ColorXY{X: 0.37336, Y: 0.16982}},
+		{name: "gamut B keeps warm white", gamut: GamutB, point: ColorXY{X: 0.4, Y: 0.35}, expectedInside: true, expected: ColorXY{X: 0.4, Y: 0.35}},
+		{name: "gamut B clamps daylight white to the green-blue edge", gamut: GamutB, point: ColorXY{X: 0.3127, Y: 0.329}, expected: ColorXY{X: 0.31319, Y: 0.32875}},
+		{name: "gamut B clamps deep green to the green corner", gamut: GamutB, point: ColorXY{X: 0.17, Y: 0.7}, expected: GamutB.Green},
+		{name: "gamut B clamps beyond its blue corner", gamut: GamutB, point: ColorXY{X: 0.15, Y: 0.02}, expected: GamutB.Blue},
+		{name: "gamut C keeps deep green", gamut: GamutC, point: ColorXY{X: 0.2, Y: 0.65}, expectedInside: true, expected: ColorXY{X: 0.2, Y: 0.65}},
+		{name: "gamut C clamps to the red-green edge", gamut: GamutC, point: ColorXY{X: 0.5, Y: 0.6}, expected: ColorXY{X: 0.42900, Y: 0.50547}},
+		{name: "gamut C clamps beyond its blue corner", gamut: GamutC, point: ColorXY{X: 0.1, Y: 0.0}, expected: GamutC.Blue},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedInside, tt.gamut.Contains(tt.point))
+
+			clamped := tt.gamut.Clamp(tt.point)
+
+			assert.InDelta(t, tt.expected.X, clamped.X, 0.0001)
+			assert.InDelta(t, tt.expected.Y, clamped.Y, 0.0001)
+			assert.True(t, tt.gamut.Contains(clamped) || distance(clamped, tt.expected) < 0.0001)
+		})
+	}
+}
+
+func TestGamutOf(t *testing.T) {
+	reported := ColorGamut{Red: ColorXY{X: 0.6, Y: 0.3}, Green: ColorXY{X: 0.3, Y: 0.6}, Blue: ColorXY{X: 0.15, Y: 0.06}}
+
+	tests := []struct {
+		name     string
+		light    *LightListItem
+		expected ColorGamut
+	}{
+		{name: "defaults to gamut C for unknown light", light: nil, expected: GamutC},
+		{name: "defaults to gamut C without color", light: &LightListItem{}, expected: GamutC},
+		{name: "gamut A by type", light: &LightListItem{Color: &LightColor{GamutType: GamutTypeA}}, expected: GamutA},
+		{name: "gamut B by type", light: &LightListItem{Color: &LightColor{GamutType: GamutTypeB}}, expected: GamutB},
+		{name: "gamut C for other types", light: &LightListItem{Color: &LightColor{GamutType: GamutTypeOther}}, expected: GamutC},
+		{name: "reported gamut takes precedence", light: &LightListItem{Color: &LightColor{Gamut: &reported, GamutType: GamutTypeA}}, expected: reported},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, GamutOf(tt.light))
+		})
+	}
+}
+
+func TestColorXYFromRGB(t *testing.T) {
+	tests := []struct {
+		name     string
+		r, g, b  uint8
+		expected ColorXY
+	}{
+		{name: "white", r: 255, g: 255, b: 255, expected: ColorXY{X: 0.3227, Y: 0.329}},
+		{name: "red", r: 255, expected: ColorXY{X: 0.7006, Y: 0.2993}},
+		{name: "black", expected: ColorXY{X: 0.3127, Y: 0.329}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			xy := ColorXYFromRGB(tt.r, tt.g, tt.b)
+
+			assert.InDelta(t, tt.expected.X, xy.X, 0.001)
+			assert.InDelta(t, tt.expected.Y, xy.Y, 0.001)
+		})
+	}
+}
+
+func TestClient_SetColorXYById(t *testing.T) {
+	// The same response serves the light read and the update
+	server, recorder := testutils.MockHueBridgeRecorder(http.StatusOK, map[string]interface{}{
+		"data": []map[string]interface{}{{"id": "light-1", "rid": "light-1", "rtype": "light", "color": map[string]interface{}{"gamut_type": "A"}}},
+	})
+	defer server.Close()
+	client := newTestClient(t, server)
+
+	err := client.SetColorXYById("light-1", ColorXY{X: 0.75, Y: 0.25})
+
+	require.NoError(t, err)
+	requests := recorder.Requests()
+	require.Len(t, requests, 2)
+	assert.Equal(t, http.MethodGet, requests[0].Method)
+	assert.Equal(t, http.MethodPut, requests[1].Method)
+
+	var update LightBodyUpdate
+	require.NoError(t, json.Unmarshal([]byte(requests[1].Body), &update))
+	require.NotNil(t, update.Color)
+	assert.Equal(t, GamutA.Red, *update.Color.XY)
+}
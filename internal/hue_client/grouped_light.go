@@ -0,0 +1,120 @@
+package hueclient
+
+import (
+	"errors"
+	"net/http"
+)
+
+// GroupedLight controls all lights of its owner, e.g. a room, a zone or the
+// bridge_home which contains all lights of the bridge.
+type GroupedLight struct {
+	ID      string             `json:"id"`
+	Type    string             `json:"type,omitempty"`
+	Owner   DeviceOwner        `json:"owner"`
+	On      *LightOnState      `json:"on,omitempty"`
+	Dimming *LightDimmingState `json:"dimming,omitempty"`
+}
+
+type GroupedLightList struct {
+	Data   []GroupedLight `json:"data,omitempty"`
+	Errors []struct {
+		Description string `json:"description,omitempty"`
+	} `json:"errors,omitempty"`
+}
+
+func (c *Client) GetAllGroupedLights() (*GroupedLightList, error) {
+	var groups GroupedLightList
+	err := c.doRequest("clip/v2/resource/grouped_light", http.MethodGet, nil, &groups)
+	if err != nil {
+		return nil, newOperationError(ErrPrefixGetGroupedLights, "", err)
+	}
+
+	if len(groups.Errors) > 0 {
+		return nil, newOperationError(ErrPrefixGetGroupedLights, "", errors.New(groups.Errors[0].Description))
+	}
+
+	return &groups, nil
+}
+
+// GetHomeGroupedLightID returns the ID of the grouped light owned by the bridge_home,
+// it contains all lights of the bridge. ErrHomeGroupedLightNotFound is returned if
+// the bridge has none.
+func (c *Client) GetHomeGroupedLightID() (string, error) {
+	groups, err := c.GetAllGroupedLights()
+	if err != nil {
+		return "", err
+	}
+
+	for _, group := range groups.Data {
+		if group.Owner.RType == ReferenceTypeBridgeHome {
+			return group.ID, nil
+		}
+	}
+
+	return "", newOperationError(ErrPrefixGetGroupedLights, "", ErrHomeGroupedLightNotFound)
+}
+
+// TurnHomeOn turns on all lights of the bridge with a single request.
+func (c *Client) TurnHomeOn() error {
+	return c.setHomeOn(true)
+}
+
+// TurnHomeOff turns off all lights of the bridge with a single request.
+func (c *Client) TurnHomeOff() error {
+	return c.setHomeOn(false)
+}
+
+func (c *Client) setHomeOn(on bool) error {
+	id, err := c.GetHomeGroupedLightID()
+	if err != nil {
+		return err
+	}
+
+	_, err = c.UpdateGroupedLightById(id, &LightBodyUpdate{On: &LightOnState{On: on}})
+	return err
+}
+
+// UpdateGroupedLightById updates all lights of a grouped_light resource with a single request.
+// Only the group related fields of the update (on, dimming, color, color_temperature,
+// dynamics, alert, signaling) are supported by the bridge.
+func (c *Client) UpdateGroupedLightById(id string, update *LightBodyUpdate) (*ResourceIdentifier, error) {
+	var updateResp LightUpdateResponse
+	err := c.doRequest("clip/v2/resource/grouped_light/"+id, http.MethodPut, update, &updateResp)
+	if err != nil {
+		return nil, newOperationError(ErrPrefixUpdateGroupedLight, id, err)
+	}
+
+	if err := updateResp.err(); err != nil {
+		var partial *PartialFailureError
+		if errors.As(err, &partial) && c.lightCache != nil {
+			// Some of the lights were updated.
+			c.lightCache.invalidateAll()
+		}
+		return nil, newOperationError(ErrPrefixUpdateGroupedLight, id, err)
+	}
+
+	if err := c.verifyIdentity(id, updateResp.Data); err != nil {
+		return nil, newOperationError(ErrPrefixUpdateGroupedLight, id, err)
+	}
+
+	// The grouped light may contain any of the cached lights.
+	if c.lightCache != nil {
+		c.lightCache.invalidateAll()
+	}
+
+	if len(updateResp.Data) == 0 {
+		return nil, nil
+	}
+
+	return &updateResp.Data[0], nil
+}
+
+func (c *Client) TurnOffGroupedLightById(id string) error {
+	update := &LightBodyUpdate{
+		On: &LightOnState{
+			On: false,
+		},
+	}
+	_, err := c.UpdateGroupedLightById(id, update)
+	return err
+}
@@ -0,0 +1,164 @@
+package hueclient
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"com.github.yveskaufmann/hue-lighter/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_TurnOffGroupedLightById(t *testing.T) {
+	server, recorder := testutils.MockHueBridgeRecorder(200, map[string]interface{}{
+		"data": []map[string]interface{}{{"rid": "group-1", "rtype": "grouped_light"}},
+	})
+	defer server.Close()
+
+	err := newTestClient(t, server).TurnOffGroupedLightById("group-1")
+
+	require.NoError(t, err)
+	requests := recorder.Requests()
+	require.Len(t, requests, 1)
+	assert.Equal(t, http.MethodPut, requests[0].Method)
+	assert.Equal(t, "/clip/v2/resource/grouped_light/group-1", requests[0].Path)
+	assert.JSONEq(t, `{"on":{"on":false}}`, string(requests[0].Body))
+}
+
+func TestClient_UpdateGroupedLightById_Error(t *testing.T) {
+	server := testutils.MockHueBridgeResponse(200, map[string]interface{}{
+		"errors": []map[string]interface{}{{"description": "resource not found"}},
+	})
+	defer server.Close()
+
+	err := newTestClient(t, server).TurnOffGroupedLightById("group-1")
+
+	require.Error(t, err)
+	assert.Equal(t, `hue: update grouped light "group-1": resource not found`, err.Error())
+}
+
+func TestClient_UpdateGroupedLightById_MultiStatus(t *testing.T) {
+	server := testutils.MockHueBridgeResponse(http.StatusMultiStatus, map[string]interface{}{
+		"data": []map[string]interface{}{{"rid": "group-1", "rtype": "grouped_light"}},
+		"errors": []map[string]interface{}{
+			{"description": "device (light-2) is \"soft off\", command (.on) may not have effect"},
+			{"description": "device (light-3) is unreachable"},
+		},
+	})
+	defer server.Close()
+
+	_, err := newTestClient(t, server).UpdateGroupedLightById("group-1", &LightBodyUpdate{On: &LightOnState{On: true}})
+
+	var partial *PartialFailureError
+	require.ErrorAs(t, err, &partial)
+	assert.Equal(t, []ResourceIdentifier{{RID: "group-1", RType: "grouped_light"}}, partial.Applied)
+	assert.Len(t, partial.Errors, 2)
+	assert.EqualError(t, err, `hue: update grouped light "group-1": partially applied to 1 resource(s): `+
+		`device (light-2) is "soft off", command (.on) may not have effect; device (light-3) is unreachable`)
+}
+
+// newHomeTestServer serves the given grouped lights and accepts updates of them.
+func newHomeTestServer(t *testing.T, groups []map[string]interface{}) (*httptest.Server, *[]string) {
+	t.Helper()
+
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, strings.TrimSpace(r.Method+" "+r.URL.Path+" "+string(body)))
+
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodGet {
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": groups})
+			return
+		}
+		id := strings.TrimPrefix(r.URL.Path, "/clip/v2/resource/grouped_light/")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": []map[string]interface{}{{"rid": id, "rtype": "grouped_light"}},
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &requests
+}
+
+func TestClient_GetHomeGroupedLightID(t *testing.T) {
+	tests := []struct {
+		name        string
+		groups      []map[string]interface{}
+		expectedID  string
+		expectedErr error
+	}{
+		{
+			name: "resolves grouped light owned by bridge home",
+			groups: []map[string]interface{}{
+				{"id": "group-room", "owner": map[string]interface{}{"rid": "room-1", "rtype": "room"}},
+				{"id": "group-home", "owner": map[string]interface{}{"rid": "home-1", "rtype": "bridge_home"}},
+			},
+			expectedID: "group-home",
+		},
+		{
+			name: "fails without bridge home group",
+			groups: []map[string]interface{}{
+				{"id": "group-room", "owner": map[string]interface{}{"rid": "room-1", "rtype": "room"}},
+			},
+			expectedErr: ErrHomeGroupedLightNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _ := newHomeTestServer(t, tt.groups)
+
+			id, err := newTestClient(t, server).GetHomeGroupedLightID()
+
+			if tt.expectedErr != nil {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedID, id)
+		})
+	}
+}
+
+func TestClient_TurnHomeOnOff(t *testing.T) {
+	groups := []map[string]interface{}{
+		{"id": "group-home", "owner": map[string]interface{}{"rid": "home-1", "rtype": "bridge_home"}},
+	}
+
+	tests := []struct {
+		name         string
+		toggle       func(c *Client) error
+		expectedBody string
+	}{
+		{
+			name:         "turns home on",
+			toggle:       (*Client).TurnHomeOn,
+			expectedBody: `{"on":{"on":true}}`,
+		},
+		{
+			name:         "turns home off",
+			toggle:       (*Client).TurnHomeOff,
+			expectedBody: `{"on":{"on":false}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := newHomeTestServer(t, groups)
+
+			err := tt.toggle(newTestClient(t, server))
+
+			require.NoError(t, err)
+			assert.Equal(t, []string{
+				"GET /clip/v2/resource/grouped_light",
+				"PUT /clip/v2/resource/grouped_light/group-home " + tt.expectedBody,
+			}, *requests)
+		})
+	}
+}
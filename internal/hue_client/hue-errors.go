@@ -1,6 +1,131 @@
 package hueclient
 
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
 const (
+	// HueErrorTypeUnauthorizedUser indicates that the v1 API rejected the API key of the request
+	HueErrorTypeUnauthorizedUser = 1
 	// HueErrorTypeLinkButtonNotPressed indicates that the link button on the bridge was not pressed
 	HueErrorTypeLinkButtonNotPressed = 101
 )
+
+// Stable prefixes of errors returned by the client, they are independent of the
+// bridge locale and can be relied on when grepping logs or asserting errors.
+const (
+	ErrPrefixGetLights          = "hue: get lights"
+	ErrPrefixGetGroupedLights   = "hue: get grouped lights"
+	ErrPrefixGetLight           = "hue: get light"
+	ErrPrefixUpdateLight        = "hue: update light"
+	ErrPrefixUpdateGroupedLight = "hue: update grouped light"
+	ErrPrefixRecallScene        = "hue: recall scene"
+	ErrPrefixRecallSmartScene   = "hue: recall smart scene"
+	ErrPrefixRegisterDevice     = "hue: register device"
+	ErrPrefixListRegistrations  = "hue: list registrations"
+	ErrPrefixRemoveRegistration = "hue: remove registration"
+	ErrPrefixGetBridgeTime      = "hue: get bridge time"
+	ErrPrefixEventStream        = "hue: event stream"
+	ErrPrefixProbeBridge        = "hue: probe bridge"
+)
+
+// StatusError is returned if the bridge answered with a non 2xx status code.
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+// newStatusError reads the body of the failed response into a StatusError.
+func newStatusError(resp *http.Response) *StatusError {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxStatusErrorBody))
+	return &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
+}
+
+// maxStatusErrorBody limits the response body kept in a StatusError.
+const maxStatusErrorBody = 1024
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("request failed with status code: %d, response: %s", e.StatusCode, e.Body)
+}
+
+// ErrHomeGroupedLightNotFound is returned if the bridge has no grouped_light owned by the bridge_home.
+var ErrHomeGroupedLightNotFound = errors.New("no grouped light of the bridge home found")
+
+// ErrNoBridgesFound is returned if the discovery did not find any bridge.
+var ErrNoBridgesFound = errors.New("no Hue Bridges found")
+
+// ErrDiscoveryTimeout is returned if the mDNS discovery found no bridge within its timeout.
+var ErrDiscoveryTimeout = errors.New("discovery timeout")
+
+// ErrBridgeCertificateMismatch is returned if the bridge certificate was not issued for the bridge ID.
+var ErrBridgeCertificateMismatch = errors.New("bridge certificate mismatch")
+
+// ErrFingerprintMismatch is returned if the bridge certificate does not match the pinned fingerprint.
+var ErrFingerprintMismatch = errors.New("certificate fingerprint mismatch")
+
+// ErrNotHueBridge is returned if the host answered with an HTML page instead of
+// JSON, e.g. a captive portal, a router or another device at the configured IP.
+var ErrNotHueBridge = errors.New("not a Hue bridge")
+
+// ErrBridgeNotFound is returned if the discovery did not find the bridge with the requested ID.
+var ErrBridgeNotFound = errors.New("bridge not found")
+
+// ErrBridgeFactoryNew is returned if the bridge has not completed its initial
+// setup in the Hue app, registering a device at it fails.
+var ErrBridgeFactoryNew = errors.New("bridge is factory-new")
+
+// ErrUnsupportedCapability is returned if an update requires a capability the light
+// does not support, e.g. setting a color of a white ambiance light.
+var ErrUnsupportedCapability = errors.New("light does not support")
+
+// ErrBrightnessMismatch is returned by SetBrightnessVerified if the light did not
+// apply the requested brightness.
+var ErrBrightnessMismatch = errors.New("brightness not applied")
+
+// ErrIdentityMismatch is returned in strict identity mode if the bridge did not
+// confirm the update of the requested resource.
+var ErrIdentityMismatch = errors.New("response identity does not match requested resource")
+
+// PartialFailureError is returned if the bridge applied an update only to some of
+// the resources, e.g. a 207 Multi-Status response to a grouped light update which
+// contains both the updated resources and errors.
+type PartialFailureError struct {
+	// Applied are the resources the bridge confirmed as updated
+	Applied []ResourceIdentifier
+	// Errors are the descriptions of the failed parts of the update
+	Errors []string
+}
+
+func (e *PartialFailureError) Error() string {
+	return fmt.Sprintf("partially applied to %d resource(s): %s", len(e.Applied), strings.Join(e.Errors, "; "))
+}
+
+// OperationError wraps the cause of a failed client operation, its message has
+// the form `<prefix> "<resource id>": <cause>` or `<prefix>: <cause>` if the
+// operation does not target a single resource.
+type OperationError struct {
+	// Prefix is one of the ErrPrefix constants
+	Prefix string
+	// ID of the targeted resource, empty if the operation has no target
+	ID  string
+	Err error
+}
+
+func newOperationError(prefix string, id string, err error) *OperationError {
+	return &OperationError{Prefix: prefix, ID: id, Err: err}
+}
+
+func (e *OperationError) Error() string {
+	if e.ID == "" {
+		return fmt.Sprintf("%s: %v", e.Prefix, e.Err)
+	}
+	return fmt.Sprintf("%s %q: %v", e.Prefix, e.ID, e.Err)
+}
+
+func (e *OperationError) Unwrap() error {
+	return e.Err
+}
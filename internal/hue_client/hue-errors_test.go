@@ -0,0 +1,170 @@
+package hueclient
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"com.github.yveskaufmann/hue-lighter/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOperationError_Error(t *testing.T) {
+	cause := errors.New("device unreachable")
+
+	assert.Equal(t, `hue: update light "light-1": device unreachable`, newOperationError(ErrPrefixUpdateLight, "light-1", cause).Error())
+	assert.Equal(t, `hue: get lights: device unreachable`, newOperationError(ErrPrefixGetLights, "", cause).Error())
+	assert.ErrorIs(t, newOperationError(ErrPrefixGetLight, "light-1", cause), cause)
+}
+
+func TestClient_ErrorPrefixes(t *testing.T) {
+	hueErrors := map[string]interface{}{
+		"errors": []map[string]interface{}{{"description": "Kein Zugriff"}},
+	}
+
+	tests := []struct {
+		name           string
+		statusCode     int
+		response       interface{}
+		call           func(c *Client) error
+		expectedPrefix string
+	}{
+		{
+			name:       "get all lights with HTTP error",
+			statusCode: 503,
+			call: func(c *Client) error {
+				_, err := c.GetAllLights()
+				return err
+			},
+			expectedPrefix: ErrPrefixGetLights + ": ",
+		},
+		{
+			name:       "get light with bridge error",
+			statusCode: 200,
+			response:   hueErrors,
+			call: func(c *Client) error {
+				_, err := c.GetOneLightById("light-1")
+				return err
+			},
+			expectedPrefix: ErrPrefixGetLight + ` "light-1": `,
+		},
+		{
+			name:       "update light with HTTP error",
+			statusCode: 500,
+			call: func(c *Client) error {
+				return c.TurnOnLightById("light-1")
+			},
+			expectedPrefix: ErrPrefixUpdateLight + ` "light-1": `,
+		},
+		{
+			name:       "update light with bridge error",
+			statusCode: 200,
+			response:   hueErrors,
+			call: func(c *Client) error {
+				return c.TurnOffLightById("light-2")
+			},
+			expectedPrefix: ErrPrefixUpdateLight + ` "light-2": Kein Zugriff`,
+		},
+		{
+			name:       "register device with HTTP error",
+			statusCode: 500,
+			call: func(c *Client) error {
+				_, err := c.RegisterDevice("test-device")
+				return err
+			},
+			expectedPrefix: ErrPrefixRegisterDevice + ": ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := testutils.MockHueBridgeResponse(tt.statusCode, tt.response)
+			defer server.Close()
+
+			err := tt.call(newTestClient(t, server))
+
+			require.Error(t, err)
+			assert.True(t, strings.HasPrefix(err.Error(), tt.expectedPrefix),
+				"expected error %q to start with %q", err.Error(), tt.expectedPrefix)
+
+			var opErr *OperationError
+			assert.ErrorAs(t, err, &opErr)
+		})
+	}
+}
+
+func TestClient_ErrorsWrapCauses(t *testing.T) {
+	t.Run("invalid response body wraps json error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"data": [`))
+		}))
+		defer server.Close()
+
+		_, err := newTestClient(t, server).GetAllLights()
+
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	})
+
+	t.Run("missing CA bundle wraps not exist error", func(t *testing.T) {
+		_, err := NewBridgeTLSConfig("ecb5fafffe123456", filepath.Join(t.TempDir(), "missing.pem"))
+
+		assert.ErrorIs(t, err, fs.ErrNotExist)
+	})
+
+	t.Run("unknown CA wraps x509 error", func(t *testing.T) {
+		root := newTestCert(t, "Root CA", true, nil)
+		leaf := newTestCert(t, "ecb5fafffe123456", false, root)
+
+		verify := createCustomCertVerifier("ecb5fafffe123456", x509.NewCertPool(), nil)
+		err := verify([][]byte{leaf.raw}, nil)
+
+		var unknownAuthority x509.UnknownAuthorityError
+		assert.ErrorAs(t, err, &unknownAuthority)
+	})
+}
+
+func TestBridgeDiscoveryService_ErrorsWrapCauses(t *testing.T) {
+	t.Run("failed discovery endpoint returns status error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte("rate limited"))
+		}))
+		defer server.Close()
+
+		service := NewBridgeDiscoveryService(nil, WithMDNSTimeout(50*time.Millisecond))
+		service.lookupType = slowLookupType
+		service.endpointURL = server.URL
+
+		_, err := service.DiscoverFirstBridgeCtx(context.Background())
+
+		var statusErr *StatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusTooManyRequests, statusErr.StatusCode)
+		assert.Equal(t, "rate limited", statusErr.Body)
+		assert.ErrorIs(t, err, ErrDiscoveryTimeout)
+	})
+
+	t.Run("locating a missing bridge returns sentinel", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`[{"id": "ecb5fafffe123456", "internalipaddress": "192.168.1.2"}]`))
+		}))
+		defer server.Close()
+
+		service := NewBridgeDiscoveryService(nil)
+		service.lookupType = slowLookupType
+		service.endpointURL = server.URL
+
+		_, err := service.LocateBridge(context.Background(), "ecb5fafffe654321")
+
+		assert.ErrorIs(t, err, ErrBridgeNotFound)
+	})
+}
@@ -1,45 +1,106 @@
 package hueclient
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"time"
 )
 
 func (c *Client) GetAllLights() (*LightList, error) {
 	var lights LightList
 	err := c.doRequest("clip/v2/resource/light", http.MethodGet, nil, &lights)
 	if err != nil {
-		return nil, err
+		return nil, newOperationError(ErrPrefixGetLights, "", err)
 	}
 	return &lights, nil
 }
 
+// GetLightsByOwner returns all lights owned by the resource with the given RID.
+// The bridge reports the device of a light as its owner, rooms and zones reference
+// devices as children and therefore do not own lights directly.
+func (c *Client) GetLightsByOwner(rid string) ([]LightListItem, error) {
+	lights, err := c.GetAllLights()
+	if err != nil {
+		return nil, err
+	}
+
+	var owned []LightListItem
+	for _, light := range lights.Data {
+		if light.Owner.RID == rid {
+			owned = append(owned, light)
+		}
+	}
+	return owned, nil
+}
+
+// GetOneLightById fetches a single light, it is served from the light cache when
+// the client was created WithLightCache.
 func (c *Client) GetOneLightById(id string) (*LightListItem, error) {
+	if c.lightCache != nil {
+		if light, ok := c.lightCache.get(id); ok {
+			return light, nil
+		}
+	}
+
 	var lights LightList
 	err := c.doRequest("clip/v2/resource/light/"+id, http.MethodGet, nil, &lights)
 	if err != nil {
-		return nil, err
+		return nil, newOperationError(ErrPrefixGetLight, id, err)
 	}
 
 	if len(lights.Errors) > 0 {
-		return nil, fmt.Errorf("failed to fetch light by id = %q due to: %s", id, lights.Errors[0].Description)
+		return nil, newOperationError(ErrPrefixGetLight, id, errors.New(lights.Errors[0].Description))
 	}
 
 	if len(lights.Data) == 0 {
 		return nil, nil
 	}
+
+	if c.lightCache != nil {
+		c.lightCache.set(id, &lights.Data[0])
+	}
 	return &lights.Data[0], nil
 }
 
+// UpdateOneLightById updates the light through the CLIP v2 API. Depending on the
+// light API of the client, on/off, brightness and transition updates are sent to
+// the v1 API instead, see WithLightAPI.
 func (c *Client) UpdateOneLightById(id string, lightUpdate *LightBodyUpdate) (*ResourceIdentifier, error) {
+	// The bridge answers invalid combinations with an opaque 400.
+	if err := lightUpdate.Validate(); err != nil {
+		return nil, newOperationError(ErrPrefixUpdateLight, id, err)
+	}
+
+	if c.lightAPI == LightAPIV1 {
+		return c.updateLightV1(id, lightUpdate)
+	}
+
+	identifier, err := c.updateLightV2(id, lightUpdate)
+	if err != nil && c.lightAPI == LightAPIAuto && isUnsupportedByV2(err) {
+		c.logger.WithError(err).Warnf("CLIP v2 update of light %q is not supported, falling back to the v1 API", id)
+		return c.updateLightV1(id, lightUpdate)
+	}
+	return identifier, err
+}
+
+func (c *Client) updateLightV2(id string, lightUpdate *LightBodyUpdate) (*ResourceIdentifier, error) {
 	var lightUpdateResp LightUpdateResponse
 	err := c.doRequest("clip/v2/resource/light/"+id, http.MethodPut, lightUpdate, &lightUpdateResp)
 	if err != nil {
-		return nil, fmt.Errorf("failed to update light by id = %q: %w", id, err)
+		return nil, newOperationError(ErrPrefixUpdateLight, id, err)
+	}
+
+	if err := lightUpdateResp.err(); err != nil {
+		return nil, newOperationError(ErrPrefixUpdateLight, id, err)
 	}
 
-	if len(lightUpdateResp.Errors) > 0 {
-		return nil, fmt.Errorf("failed to update light by id = %q due to: %s", id, lightUpdateResp.Errors[0].Description)
+	if err := c.verifyIdentity(id, lightUpdateResp.Data); err != nil {
+		return nil, newOperationError(ErrPrefixUpdateLight, id, err)
+	}
+
+	if c.lightCache != nil {
+		c.lightCache.invalidate(id)
 	}
 
 	if len(lightUpdateResp.Data) == 0 {
@@ -49,22 +110,211 @@ func (c *Client) UpdateOneLightById(id string, lightUpdate *LightBodyUpdate) (*R
 	return &lightUpdateResp.Data[0], nil
 }
 
-func (c *Client) TurnOnLightById(id string) error {
+// SwitchLightById turns the light on or off and returns the resource the bridge
+// confirmed as updated, so that callers can verify which light was switched.
+func (c *Client) SwitchLightById(id string, on bool) (*ResourceIdentifier, error) {
 	lightUpdate := &LightBodyUpdate{
 		On: &LightOnState{
-			On: true,
+			On: on,
 		},
 	}
-	_, err := c.UpdateOneLightById(id, lightUpdate)
+	return c.UpdateOneLightById(id, lightUpdate)
+}
+
+func (c *Client) TurnOnLightById(id string) error {
+	_, err := c.SwitchLightById(id, true)
 	return err
 }
 
 func (c *Client) TurnOffLightById(id string) error {
+	_, err := c.SwitchLightById(id, false)
+	return err
+}
+
+// SetBrightnessById sets the brightness of a light in percent (0, 100], capped
+// to the maximum brightness of WithMaxBrightness.
+func (c *Client) SetBrightnessById(id string, brightness float32) error {
+	if brightness <= 0 || brightness > 100 {
+		return newOperationError(ErrPrefixUpdateLight, id, fmt.Errorf("brightness %.2f out of range (0, 100]", brightness))
+	}
+
 	lightUpdate := &LightBodyUpdate{
-		On: &LightOnState{
-			On: false,
+		Dimming: &LightDimmingState{
+			Brightness: c.capBrightness(brightness),
 		},
 	}
 	_, err := c.UpdateOneLightById(id, lightUpdate)
 	return err
 }
+
+// DefaultBrightnessTolerance is the difference in percentage points between the
+// requested and the applied brightness accepted by SetBrightnessVerified.
+const DefaultBrightnessTolerance float32 = 1
+
+// SetBrightnessVerified sets the brightness like SetBrightnessById and reads the
+// light again to verify that the bridge applied it. It fails with ErrBrightnessMismatch
+// if the applied brightness differs by more than tolerance percentage points, e.g.
+// because the bulb clamped it to its minimum dim level.
+func (c *Client) SetBrightnessVerified(id string, brightness float32, tolerance float32) error {
+	if err := c.SetBrightnessById(id, brightness); err != nil {
+		return err
+	}
+
+	// The update invalidated the cached light, it is read from the bridge.
+	light, err := c.GetOneLightById(id)
+	if err != nil {
+		return err
+	}
+	if light == nil || light.Dimming == nil {
+		return newOperationError(ErrPrefixUpdateLight, id, fmt.Errorf("%w %s", ErrUnsupportedCapability, CapabilityDimming))
+	}
+
+	brightness = c.capBrightness(brightness)
+	applied := light.Dimming.Brightness
+	if diff := applied - brightness; diff > tolerance || -diff > tolerance {
+		return newOperationError(ErrPrefixUpdateLight, id, fmt.Errorf("%w: requested %.2f, applied %.2f", ErrBrightnessMismatch, brightness, applied))
+	}
+	return nil
+}
+
+// SetColorTemperatureById sets the color temperature of a light in mirek [MinMirek, MaxMirek].
+// A value outside of the range of a cached light is clamped to it, instead of leaving
+// the clamping to the bridge. It fails with ErrUnsupportedCapability without sending
+// the update if the cached light does not support color temperatures.
+func (c *Client) SetColorTemperatureById(id string, mirek int) error {
+	if mirek < MinMirek || mirek > MaxMirek {
+		return newOperationError(ErrPrefixUpdateLight, id, fmt.Errorf("mirek %d out of range [%d, %d]", mirek, MinMirek, MaxMirek))
+	}
+
+	mirek, err := c.clampMirekToLight(id, mirek)
+	if err != nil {
+		return err
+	}
+
+	lightUpdate := &LightBodyUpdate{
+		ColorTemperature: &LightColorTemperature{
+			Mirek: &mirek,
+		},
+	}
+	_, err = c.UpdateOneLightById(id, lightUpdate)
+	return err
+}
+
+// SetColorTemperatureKelvinById sets the color temperature of a light in Kelvin
+// [MinKelvin, MaxKelvin], e.g. 2700 for warm white. It is converted to mirek and
+// clamped to the mirek range, e.g. 6500K to MinMirek.
+func (c *Client) SetColorTemperatureKelvinById(id string, kelvin int) error {
+	if kelvin < MinKelvin || kelvin > MaxKelvin {
+		return newOperationError(ErrPrefixUpdateLight, id, fmt.Errorf("kelvin %d out of range [%d, %d]", kelvin, MinKelvin, MaxKelvin))
+	}
+
+	return c.SetColorTemperatureById(id, KelvinToMirek(kelvin))
+}
+
+// SetColorXYById sets the color of the light, a position outside of the color
+// gamut of the light is clamped to the closest color the light can reproduce,
+// instead of leaving the clamping to the bridge. It fails with ErrUnsupportedCapability
+// without sending the update if the light does not support colors.
+func (c *Client) SetColorXYById(id string, xy ColorXY) error {
+	light, err := c.GetOneLightById(id)
+	if err != nil {
+		return err
+	}
+	if light != nil && !light.Supports(CapabilityColor) {
+		return newOperationError(ErrPrefixUpdateLight, id, fmt.Errorf("%w %s", ErrUnsupportedCapability, CapabilityColor))
+	}
+
+	clamped := GamutOf(light).Clamp(xy)
+	if clamped != xy {
+		c.logger.WithField("light", id).Debugf("Clamped color %v to %v within the gamut of the light", xy, clamped)
+	}
+
+	_, err = c.UpdateOneLightById(id, &LightBodyUpdate{Color: &LightColor{XY: &clamped}})
+	return err
+}
+
+// SignalLightById lets a light signal for the given duration, e.g. to blink blue as a notification.
+// SignalTypeOnOffColor requires one color and SignalTypeAlternating two colors, the other
+// signals must not have colors. SignalTypeNoSignal stops an active signal.
+func (c *Client) SignalLightById(id string, signal SignalType, colors []LightColor, duration time.Duration) error {
+	if err := validateSignal(signal, colors, duration); err != nil {
+		return newOperationError(ErrPrefixUpdateLight, id, err)
+	}
+
+	lightUpdate := &LightBodyUpdate{
+		Signaling: &Signaling{
+			Signal:   signal,
+			Duration: int(duration.Milliseconds()),
+			Colors:   colors,
+		},
+	}
+	_, err := c.UpdateOneLightById(id, lightUpdate)
+	return err
+}
+
+func validateSignal(signal SignalType, colors []LightColor, duration time.Duration) error {
+	expectedColors := 0
+	switch signal {
+	case SignalTypeNoSignal:
+		if len(colors) > 0 {
+			return fmt.Errorf("signal %q does not support colors", signal)
+		}
+		return nil
+	case SignalTypeOnOff:
+		expectedColors = 0
+	case SignalTypeOnOffColor:
+		expectedColors = 1
+	case SignalTypeAlternating:
+		expectedColors = 2
+	default:
+		return fmt.Errorf("unsupported signal %q", signal)
+	}
+
+	if expectedColors == 0 && len(colors) > 0 {
+		return fmt.Errorf("signal %q does not support colors", signal)
+	}
+
+	if len(colors) != expectedColors {
+		return fmt.Errorf("signal %q requires %d color(s), got %d", signal, expectedColors, len(colors))
+	}
+
+	for _, color := range colors {
+		if color.XY == nil {
+			return fmt.Errorf("signal %q requires colors with xy position", signal)
+		}
+	}
+
+	if duration <= 0 || duration > MaxSignalDuration {
+		return fmt.Errorf("signal duration %s out of range (0, %s]", duration, MaxSignalDuration)
+	}
+
+	return nil
+}
+
+// SetGradientById sets the colors of a gradient light, e.g. a gradient lightstrip.
+// Between MinGradientPoints and MaxGradientPoints colors are accepted, an empty mode
+// keeps the current mode of the light.
+func (c *Client) SetGradientById(id string, points []LightColor, mode GradientMode) error {
+	if len(points) < MinGradientPoints || len(points) > MaxGradientPoints {
+		return newOperationError(ErrPrefixUpdateLight, id, fmt.Errorf("gradient requires %d to %d points, got %d", MinGradientPoints, MaxGradientPoints, len(points)))
+	}
+
+	gradient := &Gradient{}
+	for _, point := range points {
+		if point.XY == nil {
+			return newOperationError(ErrPrefixUpdateLight, id, errors.New("gradient points require colors with xy position"))
+		}
+		gradient.Points = append(gradient.Points, GradientPoint{Color: point})
+	}
+
+	switch mode {
+	case "":
+	case GradientModeInterpolatedPalette, GradientModeInterpolatedPaletteMirror, GradientModeRandomPixelated, GradientModeSegmentedPalette:
+		gradient.Mode = &mode
+	default:
+		return newOperationError(ErrPrefixUpdateLight, id, fmt.Errorf("unsupported gradient mode %q", mode))
+	}
+
+	_, err := c.UpdateOneLightById(id, &LightBodyUpdate{Gradient: gradient})
+	return err
+}
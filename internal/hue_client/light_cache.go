@@ -0,0 +1,71 @@
+package hueclient
+
+import (
+	"sync"
+	"time"
+)
+
+// lightCache keeps the lights read by GetOneLightById for a short time, so that
+// repeated reads of the same light do not hit the bridge each time.
+type lightCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	now     func() time.Time
+	entries map[string]lightCacheEntry
+}
+
+type lightCacheEntry struct {
+	light     LightListItem
+	expiresAt time.Time
+}
+
+func newLightCache(ttl time.Duration) *lightCache {
+	return &lightCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]lightCacheEntry),
+	}
+}
+
+// get returns a copy of the cached light or false if it is missing or expired.
+func (c *lightCache) get(id string) (*LightListItem, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[id]
+	if !ok {
+		return nil, false
+	}
+
+	if !c.now().Before(entry.expiresAt) {
+		delete(c.entries, id)
+		return nil, false
+	}
+
+	light := entry.light
+	return &light, true
+}
+
+func (c *lightCache) set(id string, light *LightListItem) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[id] = lightCacheEntry{
+		light:     *light,
+		expiresAt: c.now().Add(c.ttl),
+	}
+}
+
+func (c *lightCache) invalidate(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, id)
+}
+
+func (c *lightCache) invalidateAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = make(map[string]lightCacheEntry)
+}
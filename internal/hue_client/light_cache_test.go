@@ -0,0 +1,152 @@
+package hueclient
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"com.github.yveskaufmann/hue-lighter/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func countRequests(recorder *testutils.RequestRecorder, method string, path string) int {
+	count := 0
+	for _, req := range recorder.Requests() {
+		if req.Method == method && req.Path == path {
+			count++
+		}
+	}
+	return count
+}
+
+func newCachedTestClient(t *testing.T, ttl time.Duration) (*Client, *testutils.RequestRecorder, *time.Time) {
+	t.Helper()
+
+	server, recorder := testutils.MockHueBridgeRecorder(http.StatusOK, map[string]interface{}{
+		"data": []map[string]interface{}{
+			{"id": "light-1", "type": "light", "on": map[string]interface{}{"on": true}},
+		},
+	})
+	t.Cleanup(server.Close)
+
+	now := time.Date(2025, 1, 1, 20, 0, 0, 0, time.UTC)
+	client := newTestClient(t, server)
+	client.lightCache = newLightCache(ttl)
+	client.lightCache.now = func() time.Time { return now }
+
+	return client, recorder, &now
+}
+
+func TestClient_GetOneLightById_Cache(t *testing.T) {
+	tests := []struct {
+		name             string
+		advance          time.Duration
+		expectedRequests int
+	}{
+		{
+			name:             "serves repeated reads from cache",
+			advance:          time.Second,
+			expectedRequests: 1,
+		},
+		{
+			name:             "fetches light again after ttl expired",
+			advance:          5 * time.Second,
+			expectedRequests: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, recorder, now := newCachedTestClient(t, 5*time.Second)
+
+			first, err := client.GetOneLightById("light-1")
+			require.NoError(t, err)
+
+			*now = now.Add(tt.advance)
+
+			second, err := client.GetOneLightById("light-1")
+			require.NoError(t, err)
+
+			assert.Equal(t, first, second)
+			assert.Equal(t, tt.expectedRequests, countRequests(recorder, http.MethodGet, "/clip/v2/resource/light/light-1"))
+		})
+	}
+}
+
+func TestClient_GetOneLightById_CacheIsKeyedByID(t *testing.T) {
+	client, recorder, _ := newCachedTestClient(t, time.Minute)
+
+	_, err := client.GetOneLightById("light-1")
+	require.NoError(t, err)
+	_, err = client.GetOneLightById("light-2")
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, countRequests(recorder, http.MethodGet, "/clip/v2/resource/light/light-1"))
+	assert.Equal(t, 1, countRequests(recorder, http.MethodGet, "/clip/v2/resource/light/light-2"))
+}
+
+func TestClient_GetOneLightById_CacheInvalidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		update func(client *Client) error
+	}{
+		{
+			name:   "invalidates light on update",
+			update: func(client *Client) error { return client.TurnOffLightById("light-1") },
+		},
+		{
+			name:   "invalidates all lights on grouped light update",
+			update: func(client *Client) error { return client.TurnOffGroupedLightById("group-1") },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, recorder, _ := newCachedTestClient(t, time.Minute)
+
+			_, err := client.GetOneLightById("light-1")
+			require.NoError(t, err)
+
+			require.NoError(t, tt.update(client))
+
+			_, err = client.GetOneLightById("light-1")
+			require.NoError(t, err)
+
+			assert.Equal(t, 2, countRequests(recorder, http.MethodGet, "/clip/v2/resource/light/light-1"))
+		})
+	}
+}
+
+func TestClient_GetOneLightById_KeepsCacheOnFailedUpdate(t *testing.T) {
+	client, _, _ := newCachedTestClient(t, time.Minute)
+
+	cached := &LightListItem{ID: "light-1", On: LightOnState{On: true}}
+	client.lightCache.set("light-1", cached)
+
+	failingServer := testutils.MockHueBridgeResponse(http.StatusInternalServerError, nil)
+	defer failingServer.Close()
+	client.baseURL = failingServer.URL
+
+	require.Error(t, client.TurnOffLightById("light-1"))
+
+	light, err := client.GetOneLightById("light-1")
+	require.NoError(t, err)
+	assert.Equal(t, cached, light)
+}
+
+func TestClient_GetOneLightById_WithoutCache(t *testing.T) {
+	server, recorder := testutils.MockHueBridgeRecorder(http.StatusOK, map[string]interface{}{
+		"data": []map[string]interface{}{{"id": "light-1", "type": "light"}},
+	})
+	defer server.Close()
+
+	client := newTestClient(t, server)
+
+	for i := 0; i < 3; i++ {
+		_, err := client.GetOneLightById("light-1")
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, 3, countRequests(recorder, http.MethodGet, "/clip/v2/resource/light/light-1"))
+}
@@ -0,0 +1,92 @@
+package hueclient
+
+import "fmt"
+
+// LightCapability is a feature a light may support, see LightListItem.Supports.
+type LightCapability string
+
+const (
+	// CapabilityDimming is supported by lights with a brightness.
+	CapabilityDimming LightCapability = "dimming"
+	// CapabilityColorTemperature is supported by white ambiance and color lights.
+	CapabilityColorTemperature LightCapability = "color_temperature"
+	// CapabilityColor is supported by lights which can show a CIE XY color.
+	CapabilityColor LightCapability = "color"
+)
+
+// LightColorTemperatureState is the color temperature of a light as reported by the bridge.
+type LightColorTemperatureState struct {
+	// Mirek is nil if the light currently shows a color instead of a color temperature
+	Mirek *int `json:"mirek,omitempty"`
+	// MirekValid is false if Mirek is out of the range of the light
+	MirekValid  bool         `json:"mirek_valid"`
+	MirekSchema *MirekSchema `json:"mirek_schema,omitempty"`
+}
+
+// MirekSchema is the color temperature range supported by a light.
+type MirekSchema struct {
+	Minimum int `json:"mirek_minimum"`
+	Maximum int `json:"mirek_maximum"`
+}
+
+// Supports reports whether the light supports the capability. The bridge only
+// reports the dimming, color_temperature and color objects of a light if the
+// light supports them.
+func (l *LightListItem) Supports(capability LightCapability) bool {
+	switch capability {
+	case CapabilityDimming:
+		return l.Dimming != nil
+	case CapabilityColorTemperature:
+		return l.ColorTemperature != nil
+	case CapabilityColor:
+		return l.Color != nil
+	default:
+		return false
+	}
+}
+
+// MirekRange returns the color temperature range of the light, [MinMirek, MaxMirek]
+// if the light does not report its range. It reports false if the light does not
+// support color temperatures.
+func (l *LightListItem) MirekRange() (int, int, bool) {
+	if l.ColorTemperature == nil {
+		return 0, 0, false
+	}
+	if schema := l.ColorTemperature.MirekSchema; schema != nil && schema.Minimum > 0 && schema.Maximum >= schema.Minimum {
+		return schema.Minimum, schema.Maximum, true
+	}
+	return MinMirek, MaxMirek, true
+}
+
+// MinDimLevel returns the lowest brightness percentage supported by the light,
+// zero if the light does not report it or does not support dimming.
+func (l *LightListItem) MinDimLevel() float32 {
+	if l.Dimming == nil {
+		return 0
+	}
+	return l.Dimming.MinDimLevel
+}
+
+// clampMirekToLight clamps mirek to the color temperature range of the light if it
+// is in the light cache, see WithLightCache. The light is not read from the bridge
+// for this, the bridge clamps the value of an uncached light itself. It fails with
+// ErrUnsupportedCapability if the cached light does not support color temperatures.
+func (c *Client) clampMirekToLight(id string, mirek int) (int, error) {
+	if c.lightCache == nil {
+		return mirek, nil
+	}
+	light, ok := c.lightCache.get(id)
+	if !ok {
+		return mirek, nil
+	}
+
+	minimum, maximum, ok := light.MirekRange()
+	if !ok {
+		return 0, newOperationError(ErrPrefixUpdateLight, id, fmt.Errorf("%w %s", ErrUnsupportedCapability, CapabilityColorTemperature))
+	}
+	if clamped := min(max(mirek, minimum), maximum); clamped != mirek {
+		c.logger.WithField("light", id).Debugf("Clamped color temperature %d to %d mirek within the range of the light", mirek, clamped)
+		return clamped, nil
+	}
+	return mirek, nil
+}
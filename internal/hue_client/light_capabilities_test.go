@@ -0,0 +1,181 @@
+package hueclient
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"com.github.yveskaufmann/hue-lighter/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLightListItem_Capabilities(t *testing.T) {
+	tests := []struct {
+		name                string
+		body                string
+		expectedSupports    map[LightCapability]bool
+		expectedMirekRange  []int
+		expectedMinDimLevel float32
+	}{
+		{
+			name: "color light",
+			body: `{
+				"id": "light-1",
+				"on": {"on": true},
+				"dimming": {"brightness": 80, "min_dim_level": 0.2},
+				"color_temperature": {"mirek": null, "mirek_valid": false, "mirek_schema": {"mirek_minimum": 153, "mirek_maximum": 454}},
+				"color": {"xy": {"x": 0.4, "y": 0.4}, "gamut_type": "C"}
+			}`,
+			expectedSupports: map[LightCapability]bool{
+				CapabilityDimming:          true,
+				CapabilityColorTemperature: true,
+				CapabilityColor:            true,
+			},
+			expectedMirekRange:  []int{153, 454},
+			expectedMinDimLevel: 0.2,
+		},
+		{
+			name: "dimmable-only light",
+			body: `{
+				"id": "light-2",
+				"on": {"on": false},
+				"dimming": {"brightness": 100, "min_dim_level": 2}
+			}`,
+			expectedSupports: map[LightCapability]bool{
+				CapabilityDimming:          true,
+				CapabilityColorTemperature: false,
+				CapabilityColor:            false,
+			},
+			expectedMinDimLevel: 2,
+		},
+		{
+			name: "plug",
+			body: `{"id": "plug-1", "on": {"on": true}}`,
+			expectedSupports: map[LightCapability]bool{
+				CapabilityDimming:          false,
+				CapabilityColorTemperature: false,
+				CapabilityColor:            false,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var light LightListItem
+			require.NoError(t, json.Unmarshal([]byte(tt.body), &light))
+
+			for capability, expected := range tt.expectedSupports {
+				assert.Equal(t, expected, light.Supports(capability), capability)
+			}
+			assert.False(t, light.Supports("effects"))
+
+			minMirek, maxMirek, ok := light.MirekRange()
+			if tt.expectedMirekRange == nil {
+				assert.False(t, ok)
+			} else {
+				assert.True(t, ok)
+				assert.Equal(t, tt.expectedMirekRange, []int{minMirek, maxMirek})
+			}
+			assert.Equal(t, tt.expectedMinDimLevel, light.MinDimLevel())
+		})
+	}
+}
+
+func TestLightListItem_MirekRange_DefaultsWithoutSchema(t *testing.T) {
+	light := LightListItem{ColorTemperature: &LightColorTemperatureState{}}
+
+	minMirek, maxMirek, ok := light.MirekRange()
+
+	assert.True(t, ok)
+	assert.Equal(t, MinMirek, minMirek)
+	assert.Equal(t, MaxMirek, maxMirek)
+}
+
+func TestClient_SetColorXYById_RejectsLightWithoutColor(t *testing.T) {
+	server, recorder := testutils.MockHueBridgeRecorder(http.StatusOK, map[string]interface{}{
+		"data": []map[string]interface{}{{"id": "light-1", "dimming": map[string]interface{}{"brightness": 100}}},
+	})
+	defer server.Close()
+	client := newTestClient(t, server)
+
+	err := client.SetColorXYById("light-1", ColorXY{X: 0.3, Y: 0.3})
+
+	require.ErrorIs(t, err, ErrUnsupportedCapability)
+	assert.EqualError(t, err, `hue: update light "light-1": light does not support color`)
+	require.Len(t, recorder.Requests(), 1, "no update is sent")
+	assert.Equal(t, http.MethodGet, recorder.Requests()[0].Method)
+}
+
+func TestClient_SetColorTemperatureById_ChecksCapability(t *testing.T) {
+	tests := []struct {
+		name         string
+		light        map[string]interface{}
+		mirek        int
+		expectedBody string
+		expectedErr  error
+	}{
+		{
+			name:         "clamps to the range of the light",
+			light:        map[string]interface{}{"color_temperature": map[string]interface{}{"mirek_schema": map[string]interface{}{"mirek_minimum": 200, "mirek_maximum": 454}}},
+			mirek:        500,
+			expectedBody: `{"color_temperature":{"mirek":454}}`,
+		},
+		{
+			name:         "keeps a value within the range",
+			light:        map[string]interface{}{"color_temperature": map[string]interface{}{"mirek_schema": map[string]interface{}{"mirek_minimum": 200, "mirek_maximum": 454}}},
+			mirek:        300,
+			expectedBody: `{"color_temperature":{"mirek":300}}`,
+		},
+		{
+			name:        "rejects a light without color temperature",
+			light:       map[string]interface{}{"dimming": map[string]interface{}{"brightness": 100}},
+			mirek:       300,
+			expectedErr: ErrUnsupportedCapability,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.light["id"] = "light-1"
+			server, recorder := testutils.MockHueBridgeRecorder(http.StatusOK, map[string]interface{}{
+				"data": []map[string]interface{}{tt.light},
+			})
+			defer server.Close()
+			client := newTestClient(t, server)
+			client.lightCache = newLightCache(time.Minute)
+			_, err := client.GetOneLightById("light-1")
+			require.NoError(t, err)
+
+			err = client.SetColorTemperatureById("light-1", tt.mirek)
+
+			requests := recorder.Requests()
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, err, tt.expectedErr)
+				assert.EqualError(t, err, `hue: update light "light-1": light does not support color_temperature`)
+				require.Len(t, requests, 1, "no update is sent")
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, requests, 2)
+			assert.Equal(t, http.MethodPut, requests[1].Method)
+			assert.JSONEq(t, tt.expectedBody, string(requests[1].Body))
+		})
+	}
+}
+
+func TestClient_SetColorTemperatureById_UncachedLight(t *testing.T) {
+	server, recorder := testutils.MockHueBridgeRecorder(http.StatusOK, map[string]interface{}{
+		"data": []map[string]interface{}{{"id": "light-1", "rid": "light-1", "rtype": "light"}},
+	})
+	defer server.Close()
+
+	err := newTestClient(t, server).SetColorTemperatureById("light-1", 500)
+
+	require.NoError(t, err)
+	requests := recorder.Requests()
+	require.Len(t, requests, 1, "the light is not read for the update")
+	assert.Equal(t, http.MethodPut, requests[0].Method)
+	assert.JSONEq(t, `{"color_temperature":{"mirek":500}}`, string(requests[0].Body))
+}
@@ -1,5 +1,12 @@
 package hueclient
 
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
 type LightFunction string
 
 const (
@@ -26,7 +33,9 @@ type LightOnState struct {
 }
 
 type LightDimmingState struct {
-	Dimming     float32 `json:"dimming,omitempty"`
+	// Brightness percentage, 0 is not allowed by the bridge, use on/off instead
+	Brightness float32 `json:"brightness,omitempty"`
+	// Lowest brightness percentage supported by the light, read-only
 	MinDimLevel float32 `json:"min_dim_level,omitempty"`
 }
 
@@ -35,6 +44,28 @@ type LightDimmingDeltaState struct {
 	BrightnessDelta *float64 `json:"brightness_delta,omitempty"`
 }
 
+// Color temperature range in mirek supported by the Hue API, the range of a
+// specific light may be narrower.
+const (
+	MinMirek = 153
+	MaxMirek = 500
+)
+
+// Color temperature range in Kelvin accepted by SetColorTemperatureKelvinById,
+// values outside of the mirek range are clamped to it.
+const (
+	MinKelvin = 1000
+	MaxKelvin = 10000
+)
+
+// KelvinToMirek converts a color temperature in Kelvin to mirek, clamped to [MinMirek, MaxMirek].
+// Kelvin outside of [MinKelvin, MaxKelvin], including zero, is clamped to that range first.
+func KelvinToMirek(kelvin int) int {
+	kelvin = max(MinKelvin, min(MaxKelvin, kelvin))
+	mirek := 1_000_000 / kelvin
+	return max(MinMirek, min(MaxMirek, mirek))
+}
+
 type LightColorTemperature struct {
 	Mirek *int `json:"mirek,omitempty"`
 }
@@ -54,10 +85,22 @@ type LightColorTemperatureDelta struct {
 
 type LightColor struct {
 	// CIE XY gamut position
-	XY *struct {
-		X float32 `json:"x,omitempty"`
-		Y float32 `json:"y,omitempty"`
-	} `json:"xy,omitempty"`
+	XY *ColorXY `json:"xy,omitempty"`
+	// Color gamut of the light, read-only
+	Gamut *ColorGamut `json:"gamut,omitempty"`
+	// Gamut class of the light, read-only
+	GamutType GamutType `json:"gamut_type,omitempty"`
+}
+
+// ColorXY is a position in the CIE XY color space.
+type ColorXY struct {
+	X float32 `json:"x,omitempty"`
+	Y float32 `json:"y,omitempty"`
+}
+
+// NewLightColorXY creates a color from a CIE XY position.
+func NewLightColorXY(x float32, y float32) LightColor {
+	return LightColor{XY: &ColorXY{X: x, Y: y}}
 }
 
 type Dynamics struct {
@@ -83,6 +126,9 @@ const (
 	SignalTypeAlternating SignalType = "alternating"
 )
 
+// MaxSignalDuration is the longest signaling duration accepted by the bridge.
+const MaxSignalDuration = 65534 * time.Second
+
 type Signaling struct {
 	// Signal to set the light to
 	Signal SignalType `json:"signal,omitempty"`
@@ -103,11 +149,19 @@ const (
 	GradientModeSegmentedPalette          GradientMode = "segmented_palette"
 )
 
+// Number of gradient points a gradient light accepts in a single update.
+const (
+	MinGradientPoints = 2
+	MaxGradientPoints = 5
+)
+
 type Gradient struct {
-	Points []struct {
-		Color LightColor `json:"color,omitempty"`
-	} `json:"points,omitempty"`
-	Mode *GradientMode `json:"mode,omitempty"`
+	Points []GradientPoint `json:"points,omitempty"`
+	Mode   *GradientMode   `json:"mode,omitempty"`
+}
+
+type GradientPoint struct {
+	Color LightColor `json:"color,omitempty"`
 }
 
 type EffectType string
@@ -203,30 +257,30 @@ type TimedEffects struct {
 
 // Powerup configuration
 type Powerup struct {
-	Preset    PowerupPreset     `json:"preset"`
-	On        *PowerupOn        `json:"on,omitempty"`
-	Dimming   *PowerupDimming   `json:"dimming,omitempty"`
-	Color     *PowerupColor     `json:"color,omitempty"`
-	ColorTemp *PowerupColorTemp `json:"color_temperature,omitempty"`
+	Preset  PowerupPreset   `json:"preset"`
+	On      *PowerupOn      `json:"on,omitempty"`
+	Dimming *PowerupDimming `json:"dimming,omitempty"`
+	Color   *PowerupColor   `json:"color,omitempty"`
 }
 
 type PowerupOn struct {
 	Mode PowerupOnMode `json:"mode"`
-	On   *struct {
-		On bool `json:"on"`
-	} `json:"on,omitempty"`
+	On   *LightOnState `json:"on,omitempty"`
 }
 
 type PowerupDimming struct {
 	Mode    PowerupDimmingMode `json:"mode"`
-	Dimming *struct {
-		Brightness float64 `json:"brightness"`
-	} `json:"dimming,omitempty"`
+	Dimming *PowerupBrightness `json:"dimming,omitempty"`
+}
+
+type PowerupBrightness struct {
+	Brightness float64 `json:"brightness"`
 }
 
 type PowerupColor struct {
-	Mode  PowerupColorMode `json:"mode"`
-	Color *LightColor      `json:"color,omitempty"`
+	Mode      PowerupColorMode  `json:"mode"`
+	ColorTemp *PowerupColorTemp `json:"color_temperature,omitempty"`
+	Color     *LightColor       `json:"color,omitempty"`
 }
 
 type PowerupColorTemp struct {
@@ -260,6 +314,10 @@ type LightListItem struct {
 	On           LightOnState            `json:"on,omitempty"`
 	Dimming      *LightDimmingState      `json:"dimming,omitempty"`
 	DimmingDelta *LightDimmingDeltaState `json:"dimming_delta,omitempty"`
+	// ColorTemperature is nil for lights without color temperature support
+	ColorTemperature *LightColorTemperatureState `json:"color_temperature,omitempty"`
+	// Color is nil for lights without color support
+	Color *LightColor `json:"color,omitempty"`
 }
 
 type LightBodyUpdate struct {
@@ -282,6 +340,27 @@ type LightBodyUpdate struct {
 	ContentConfiguration  *ContentConfiguration       `json:"content_configuration,omitempty"`
 }
 
+// Validate reports the combinations of fields which the bridge rejects, e.g. an
+// absolute and a relative brightness, as an error wrapping ErrInvalidLightUpdate.
+func (u *LightBodyUpdate) Validate() error {
+	exclusive := []struct {
+		set    bool
+		fields string
+	}{
+		{u.Color != nil && u.ColorTemperature != nil, "color and color temperature"},
+		{u.Dimming != nil && u.DimmingDelta != nil, "dimming and dimming delta"},
+		{u.ColorTemperature != nil && u.ColorTemperatureDelta != nil, "color temperature and color temperature delta"},
+	}
+
+	var errs []error
+	for _, e := range exclusive {
+		if e.set {
+			errs = append(errs, fmt.Errorf("%w: %s are mutually exclusive", ErrInvalidLightUpdate, e.fields))
+		}
+	}
+	return errors.Join(errs...)
+}
+
 type LightUpdateResponse struct {
 	Data   []ResourceIdentifier `json:"data,omitempty"`
 	Errors []struct {
@@ -289,9 +368,53 @@ type LightUpdateResponse struct {
 	} `json:"errors,omitempty"`
 }
 
+// err returns the error of the response, a PartialFailureError if the bridge
+// reported both updated resources and errors.
+func (r *LightUpdateResponse) err() error {
+	if len(r.Errors) == 0 {
+		return nil
+	}
+	if len(r.Data) == 0 {
+		return errors.New(r.Errors[0].Description)
+	}
+
+	partial := &PartialFailureError{Applied: r.Data}
+	for _, e := range r.Errors {
+		partial.Errors = append(partial.Errors, e.Description)
+	}
+	return partial
+}
+
 type LightList struct {
 	Data   []LightListItem `json:"data,omitempty"`
 	Errors []struct {
 		Description string `json:"description,omitempty"`
 	} `json:"errors,omitempty"`
 }
+
+// FindByID returns the light with the given ID or nil if the list does not contain it.
+func (l *LightList) FindByID(id string) *LightListItem {
+	for i := range l.Data {
+		if l.Data[i].ID == id {
+			return &l.Data[i]
+		}
+	}
+	return nil
+}
+
+// FindByName returns the light whose metadata name matches the given name case-insensitively.
+// It returns false if no light or more than one light has that name, since an ambiguous
+// name can't be resolved to a single light.
+func (l *LightList) FindByName(name string) (*LightListItem, bool) {
+	var found *LightListItem
+	for i := range l.Data {
+		if !strings.EqualFold(l.Data[i].Meta.Name, name) {
+			continue
+		}
+		if found != nil {
+			return nil, false
+		}
+		found = &l.Data[i]
+	}
+	return found, found != nil
+}
@@ -0,0 +1,223 @@
+package hueclient
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLightList(names map[string]string) *LightList {
+	list := &LightList{}
+	for _, id := range []string{"light-1", "light-2", "light-3"} {
+		if name, ok := names[id]; ok {
+			list.Data = append(list.Data, LightListItem{ID: id, Meta: LightMeta{Name: name}})
+		}
+	}
+	return list
+}
+
+func TestLightList_FindByID(t *testing.T) {
+	list := newTestLightList(map[string]string{"light-1": "Desk", "light-2": "Shelf"})
+
+	light := list.FindByID("light-2")
+	require.NotNil(t, light)
+	assert.Equal(t, "Shelf", light.Meta.Name)
+
+	// The returned light must point into the list rather than to a copy
+	assert.Same(t, &list.Data[1], light)
+
+	assert.Nil(t, list.FindByID("light-3"))
+	assert.Nil(t, (&LightList{}).FindByID("light-1"))
+}
+
+func TestLightList_FindByName(t *testing.T) {
+	tests := []struct {
+		name      string
+		lights    map[string]string
+		search    string
+		wantID    string
+		wantFound bool
+	}{
+		{
+			name:      "finds light by exact name",
+			lights:    map[string]string{"light-1": "Desk", "light-2": "Shelf"},
+			search:    "Shelf",
+			wantID:    "light-2",
+			wantFound: true,
+		},
+		{
+			name:      "matches case-insensitively",
+			lights:    map[string]string{"light-1": "Office Hue Play Left"},
+			search:    "office hue play LEFT",
+			wantID:    "light-1",
+			wantFound: true,
+		},
+		{
+			name:      "returns false when no light matches",
+			lights:    map[string]string{"light-1": "Desk"},
+			search:    "Kitchen",
+			wantFound: false,
+		},
+		{
+			name:      "returns false when multiple lights match",
+			lights:    map[string]string{"light-1": "Desk", "light-2": "desk", "light-3": "Shelf"},
+			search:    "Desk",
+			wantFound: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list := newTestLightList(tt.lights)
+
+			light, found := list.FindByName(tt.search)
+
+			assert.Equal(t, tt.wantFound, found)
+			if tt.wantFound {
+				require.NotNil(t, light)
+				assert.Equal(t, tt.wantID, light.ID)
+			} else {
+				assert.Nil(t, light)
+			}
+		})
+	}
+}
+
+func TestLightUpdateResponse_Err(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		wantErr     bool
+		wantPartial bool
+		errMsg      string
+	}{
+		{
+			name: "no errors",
+			body: `{"data":[{"rid":"light-1","rtype":"light"}]}`,
+		},
+		{
+			name:    "only errors",
+			body:    `{"errors":[{"description":"resource not found"},{"description":"bridge busy"}]}`,
+			wantErr: true,
+			errMsg:  "resource not found",
+		},
+		{
+			name:        "data and errors of a multi-status response",
+			body:        `{"data":[{"rid":"light-1","rtype":"light"}],"errors":[{"description":"device (light-2) is unreachable"}]}`,
+			wantErr:     true,
+			wantPartial: true,
+			errMsg:      "partially applied to 1 resource(s): device (light-2) is unreachable",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp LightUpdateResponse
+			require.NoError(t, json.Unmarshal([]byte(tt.body), &resp))
+
+			err := resp.err()
+
+			if !tt.wantErr {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.errMsg)
+			var partial *PartialFailureError
+			assert.Equal(t, tt.wantPartial, errors.As(err, &partial))
+		})
+	}
+}
+
+func TestLightBodyUpdate_Validate(t *testing.T) {
+	mirek, mirekDelta, brightnessDelta := 350, 50, 10.0
+	color := NewLightColorXY(0.5, 0.4)
+
+	tests := []struct {
+		name    string
+		update  *LightBodyUpdate
+		wantErr bool
+		errMsg  string
+	}{
+		{
+			name:   "accepts an update without conflicting fields",
+			update: &LightBodyUpdate{On: &LightOnState{On: true}, Dimming: &LightDimmingState{Brightness: 80}, Color: &color},
+		},
+		{
+			name:   "accepts an empty update",
+			update: &LightBodyUpdate{},
+		},
+		{
+			name:    "rejects color and color temperature",
+			update:  &LightBodyUpdate{Color: &color, ColorTemperature: &LightColorTemperature{Mirek: &mirek}},
+			wantErr: true,
+			errMsg:  "invalid light update: color and color temperature are mutually exclusive",
+		},
+		{
+			name: "rejects dimming and dimming delta",
+			update: &LightBodyUpdate{
+				Dimming:      &LightDimmingState{Brightness: 80},
+				DimmingDelta: &LightDimmingDeltaState{Action: "up", BrightnessDelta: &brightnessDelta},
+			},
+			wantErr: true,
+			errMsg:  "invalid light update: dimming and dimming delta are mutually exclusive",
+		},
+		{
+			name: "rejects color temperature and color temperature delta",
+			update: &LightBodyUpdate{
+				ColorTemperature:      &LightColorTemperature{Mirek: &mirek},
+				ColorTemperatureDelta: &LightColorTemperatureDelta{Action: ColorTemperatureActionUp, MirekDelta: &mirekDelta},
+			},
+			wantErr: true,
+			errMsg:  "invalid light update: color temperature and color temperature delta are mutually exclusive",
+		},
+		{
+			name: "reports every conflict",
+			update: &LightBodyUpdate{
+				Color:            &color,
+				ColorTemperature: &LightColorTemperature{Mirek: &mirek},
+				Dimming:          &LightDimmingState{Brightness: 80},
+				DimmingDelta:     &LightDimmingDeltaState{Action: "up", BrightnessDelta: &brightnessDelta},
+			},
+			wantErr: true,
+			errMsg: "invalid light update: color and color temperature are mutually exclusive\n" +
+				"invalid light update: dimming and dimming delta are mutually exclusive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.update.Validate()
+
+			if !tt.wantErr {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrInvalidLightUpdate)
+			assert.EqualError(t, err, tt.errMsg)
+		})
+	}
+}
+
+func TestKelvinToMirek(t *testing.T) {
+	tests := []struct {
+		name     string
+		kelvin   int
+		expected int
+	}{
+		{name: "warm white", kelvin: 2700, expected: 370},
+		{name: "clamped to MinMirek", kelvin: 6500, expected: MinMirek},
+		{name: "clamped to MaxMirek", kelvin: 1500, expected: MaxMirek},
+		{name: "zero does not panic", kelvin: 0, expected: MaxMirek},
+		{name: "negative", kelvin: -2700, expected: MaxMirek},
+		{name: "above MaxKelvin", kelvin: 50000, expected: MinMirek},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, KelvinToMirek(tt.kelvin))
+		})
+	}
+}
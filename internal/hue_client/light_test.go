@@ -0,0 +1,558 @@
+package hueclient
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"com.github.yveskaufmann/hue-lighter/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_UpdateOneLightById_StrictIdentity(t *testing.T) {
+	tests := []struct {
+		name           string
+		strictIdentity bool
+		response       interface{}
+		wantErr        bool
+		expectedErr    string
+		expectedRID    string
+	}{
+		{
+			name:           "accepts matching identity in strict mode",
+			strictIdentity: true,
+			response:       map[string]interface{}{"data": []map[string]interface{}{{"rid": "light-1", "rtype": "light"}}},
+			expectedRID:    "light-1",
+		},
+		{
+			name:           "rejects mismatched identity in strict mode",
+			strictIdentity: true,
+			response:       map[string]interface{}{"data": []map[string]interface{}{{"rid": "light-2", "rtype": "light"}}},
+			wantErr:        true,
+			expectedErr:    `hue: update light "light-1": response identity does not match requested resource: got "light-2"`,
+		},
+		{
+			name:           "rejects empty response in strict mode",
+			strictIdentity: true,
+			response:       map[string]interface{}{"data": []map[string]interface{}{}},
+			wantErr:        true,
+			expectedErr:    `hue: update light "light-1": response identity does not match requested resource: empty response`,
+		},
+		{
+			name:           "ignores mismatched identity by default",
+			strictIdentity: false,
+			response:       map[string]interface{}{"data": []map[string]interface{}{{"rid": "light-2", "rtype": "light"}}},
+			expectedRID:    "light-2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := testutils.MockHueBridgeResponse(http.StatusOK, tt.response)
+			defer server.Close()
+
+			client := newTestClient(t, server)
+			client.strictIdentity = tt.strictIdentity
+
+			identity, err := client.UpdateOneLightById("light-1", &LightBodyUpdate{On: &LightOnState{On: true}})
+
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, ErrIdentityMismatch)
+				assert.Equal(t, tt.expectedErr, err.Error())
+				assert.Nil(t, identity)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedRID, identity.RID)
+			assert.Equal(t, "light", identity.RType)
+		})
+	}
+}
+
+func TestClient_SwitchLightById(t *testing.T) {
+	tests := []struct {
+		name         string
+		on           bool
+		expectedBody string
+	}{
+		{
+			name:         "turns light on",
+			on:           true,
+			expectedBody: `{"on":{"on":true}}`,
+		},
+		{
+			name:         "turns light off",
+			on:           false,
+			expectedBody: `{"on":{"on":false}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, recorder := testutils.MockHueBridgeRecorder(http.StatusOK, map[string]interface{}{
+				"data": []map[string]interface{}{{"rid": "light-1", "rtype": "light"}},
+			})
+			defer server.Close()
+			client := newTestClient(t, server)
+
+			identity, err := client.SwitchLightById("light-1", tt.on)
+
+			require.NoError(t, err)
+			assert.Equal(t, &ResourceIdentifier{RID: "light-1", RType: "light"}, identity)
+			requests := recorder.Requests()
+			require.Len(t, requests, 1)
+			assert.Equal(t, "/clip/v2/resource/light/light-1", requests[0].Path)
+			assert.JSONEq(t, tt.expectedBody, string(requests[0].Body))
+		})
+	}
+
+	t.Run("returns v1 light as identifier", func(t *testing.T) {
+		server := testutils.MockHueBridgeResponse(http.StatusOK, []map[string]interface{}{
+			{"success": map[string]interface{}{"/lights/3/state/on": true}},
+		})
+		defer server.Close()
+		client := newTestClient(t, server)
+		client.lightAPI = LightAPIV1
+
+		identity, err := client.SwitchLightById("3", true)
+
+		require.NoError(t, err)
+		assert.Equal(t, &ResourceIdentifier{RID: "3", RType: "light"}, identity)
+	})
+
+	t.Run("returns no identifier on error", func(t *testing.T) {
+		server := testutils.MockHueBridgeResponse(http.StatusNotFound, map[string]interface{}{})
+		defer server.Close()
+		client := newTestClient(t, server)
+
+		identity, err := client.SwitchLightById("light-1", false)
+
+		require.Error(t, err)
+		assert.Nil(t, identity)
+	})
+}
+
+func TestClient_UpdateOneLightById_InvalidUpdate(t *testing.T) {
+	server, recorder := testutils.MockHueBridgeRecorder(http.StatusOK, map[string]interface{}{
+		"data": []map[string]interface{}{{"rid": "light-1", "rtype": "light"}},
+	})
+	defer server.Close()
+	mirek := 350
+	color := NewLightColorXY(0.5, 0.4)
+
+	_, err := newTestClient(t, server).UpdateOneLightById("light-1", &LightBodyUpdate{
+		Color:            &color,
+		ColorTemperature: &LightColorTemperature{Mirek: &mirek},
+	})
+
+	assert.ErrorIs(t, err, ErrInvalidLightUpdate)
+	assert.EqualError(t, err, `hue: update light "light-1": invalid light update: color and color temperature are mutually exclusive`)
+	assert.Empty(t, recorder.Requests())
+}
+
+func TestClient_GetLightsByOwner(t *testing.T) {
+	server := testutils.MockHueBridgeResponse(http.StatusOK, map[string]interface{}{
+		"data": []map[string]interface{}{
+			{"id": "light-1", "owner": map[string]interface{}{"rid": "device-1", "rtype": "device"}},
+			{"id": "light-2", "owner": map[string]interface{}{"rid": "device-2", "rtype": "device"}},
+			{"id": "light-3", "owner": map[string]interface{}{"rid": "device-1", "rtype": "device"}},
+		},
+	})
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		owner       string
+		expectedIDs []string
+	}{
+		{
+			name:        "returns all lights of owner",
+			owner:       "device-1",
+			expectedIDs: []string{"light-1", "light-3"},
+		},
+		{
+			name:        "returns single light of owner",
+			owner:       "device-2",
+			expectedIDs: []string{"light-2"},
+		},
+		{
+			name:        "returns no lights for unknown owner",
+			owner:       "device-3",
+			expectedIDs: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lights, err := newTestClient(t, server).GetLightsByOwner(tt.owner)
+
+			require.NoError(t, err)
+			var ids []string
+			for _, light := range lights {
+				assert.Equal(t, tt.owner, light.Owner.RID)
+				ids = append(ids, light.ID)
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+		})
+	}
+}
+
+func TestClient_GetLightsByOwner_Error(t *testing.T) {
+	server := testutils.MockHueBridgeResponse(http.StatusInternalServerError, nil)
+	defer server.Close()
+
+	lights, err := newTestClient(t, server).GetLightsByOwner("device-1")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), ErrPrefixGetLights)
+	assert.Nil(t, lights)
+}
+
+func TestClient_SignalLightById(t *testing.T) {
+	blue := NewLightColorXY(0.1532, 0.0475)
+	red := NewLightColorXY(0.6915, 0.3083)
+
+	tests := []struct {
+		name         string
+		signal       SignalType
+		colors       []LightColor
+		duration     time.Duration
+		wantErr      bool
+		expectedErr  string
+		expectedBody string
+	}{
+		{
+			name:         "sends on_off signal",
+			signal:       SignalTypeOnOff,
+			duration:     5 * time.Second,
+			expectedBody: `{"signaling":{"signal":"on_off","duration":5000}}`,
+		},
+		{
+			name:         "sends on_off_color signal with color",
+			signal:       SignalTypeOnOffColor,
+			colors:       []LightColor{blue},
+			duration:     2 * time.Second,
+			expectedBody: `{"signaling":{"signal":"on_off_color","duration":2000,"colors":[{"xy":{"x":0.1532,"y":0.0475}}]}}`,
+		},
+		{
+			name:         "sends alternating signal with colors",
+			signal:       SignalTypeAlternating,
+			colors:       []LightColor{blue, red},
+			duration:     time.Minute,
+			expectedBody: `{"signaling":{"signal":"alternating","duration":60000,"colors":[{"xy":{"x":0.1532,"y":0.0475}},{"xy":{"x":0.6915,"y":0.3083}}]}}`,
+		},
+		{
+			name:         "stops signal",
+			signal:       SignalTypeNoSignal,
+			expectedBody: `{"signaling":{"signal":"no_signal"}}`,
+		},
+		{
+			name:        "rejects on_off_color without color",
+			signal:      SignalTypeOnOffColor,
+			duration:    time.Second,
+			wantErr:     true,
+			expectedErr: `hue: update light "light-1": signal "on_off_color" requires 1 color(s), got 0`,
+		},
+		{
+			name:        "rejects alternating with single color",
+			signal:      SignalTypeAlternating,
+			colors:      []LightColor{blue},
+			duration:    time.Second,
+			wantErr:     true,
+			expectedErr: `hue: update light "light-1": signal "alternating" requires 2 color(s), got 1`,
+		},
+		{
+			name:        "rejects on_off with colors",
+			signal:      SignalTypeOnOff,
+			colors:      []LightColor{blue},
+			duration:    time.Second,
+			wantErr:     true,
+			expectedErr: `hue: update light "light-1": signal "on_off" does not support colors`,
+		},
+		{
+			name:        "rejects no_signal with colors",
+			signal:      SignalTypeNoSignal,
+			colors:      []LightColor{blue},
+			wantErr:     true,
+			expectedErr: `hue: update light "light-1": signal "no_signal" does not support colors`,
+		},
+		{
+			name:        "rejects color without xy position",
+			signal:      SignalTypeOnOffColor,
+			colors:      []LightColor{{}},
+			duration:    time.Second,
+			wantErr:     true,
+			expectedErr: `hue: update light "light-1": signal "on_off_color" requires colors with xy position`,
+		},
+		{
+			name:        "rejects missing duration",
+			signal:      SignalTypeOnOff,
+			wantErr:     true,
+			expectedErr: `hue: update light "light-1": signal duration 0s out of range (0, 18h12m14s]`,
+		},
+		{
+			name:        "rejects too long duration",
+			signal:      SignalTypeOnOff,
+			duration:    MaxSignalDuration + time.Second,
+			wantErr:     true,
+			expectedErr: `hue: update light "light-1": signal duration 18h12m15s out of range (0, 18h12m14s]`,
+		},
+		{
+			name:        "rejects unsupported signal",
+			signal:      SignalTypeIdentify,
+			duration:    time.Second,
+			wantErr:     true,
+			expectedErr: `hue: update light "light-1": unsupported signal "identify"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, recorder := testutils.MockHueBridgeRecorder(http.StatusOK, map[string]interface{}{
+				"data": []map[string]interface{}{{"rid": "light-1", "rtype": "light"}},
+			})
+			defer server.Close()
+
+			err := newTestClient(t, server).SignalLightById("light-1", tt.signal, tt.colors, tt.duration)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Equal(t, tt.expectedErr, err.Error())
+				assert.Empty(t, recorder.Requests())
+				return
+			}
+
+			require.NoError(t, err)
+			requests := recorder.Requests()
+			require.Len(t, requests, 1)
+			assert.Equal(t, http.MethodPut, requests[0].Method)
+			assert.Equal(t, "/clip/v2/resource/light/light-1", requests[0].Path)
+			assert.JSONEq(t, tt.expectedBody, string(requests[0].Body))
+		})
+	}
+}
+
+func TestClient_SetGradientById(t *testing.T) {
+	red := NewLightColorXY(0.6915, 0.3083)
+	green := NewLightColorXY(0.17, 0.7)
+	blue := NewLightColorXY(0.1532, 0.0475)
+
+	tests := []struct {
+		name         string
+		points       []LightColor
+		mode         GradientMode
+		wantErr      bool
+		expectedErr  string
+		expectedBody string
+	}{
+		{
+			name:         "sends 3-point gradient with mode",
+			points:       []LightColor{red, green, blue},
+			mode:         GradientModeInterpolatedPalette,
+			expectedBody: `{"gradient":{"points":[{"color":{"xy":{"x":0.6915,"y":0.3083}}},{"color":{"xy":{"x":0.17,"y":0.7}}},{"color":{"xy":{"x":0.1532,"y":0.0475}}}],"mode":"interpolated_palette"}}`,
+		},
+		{
+			name:         "omits empty mode",
+			points:       []LightColor{red, blue},
+			expectedBody: `{"gradient":{"points":[{"color":{"xy":{"x":0.6915,"y":0.3083}}},{"color":{"xy":{"x":0.1532,"y":0.0475}}}]}}`,
+		},
+		{
+			name:        "rejects empty points",
+			points:      nil,
+			wantErr:     true,
+			expectedErr: `hue: update light "light-1": gradient requires 2 to 5 points, got 0`,
+		},
+		{
+			name:        "rejects single point",
+			points:      []LightColor{red},
+			wantErr:     true,
+			expectedErr: `hue: update light "light-1": gradient requires 2 to 5 points, got 1`,
+		},
+		{
+			name:        "rejects too many points",
+			points:      []LightColor{red, green, blue, red, green, blue},
+			wantErr:     true,
+			expectedErr: `hue: update light "light-1": gradient requires 2 to 5 points, got 6`,
+		},
+		{
+			name:        "rejects point without xy position",
+			points:      []LightColor{red, {}},
+			wantErr:     true,
+			expectedErr: `hue: update light "light-1": gradient points require colors with xy position`,
+		},
+		{
+			name:        "rejects unsupported mode",
+			points:      []LightColor{red, blue},
+			mode:        "sparkle",
+			wantErr:     true,
+			expectedErr: `hue: update light "light-1": unsupported gradient mode "sparkle"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, recorder := testutils.MockHueBridgeRecorder(http.StatusOK, map[string]interface{}{
+				"data": []map[string]interface{}{{"rid": "light-1", "rtype": "light"}},
+			})
+			defer server.Close()
+
+			err := newTestClient(t, server).SetGradientById("light-1", tt.points, tt.mode)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Equal(t, tt.expectedErr, err.Error())
+				assert.Empty(t, recorder.Requests())
+				return
+			}
+
+			require.NoError(t, err)
+			requests := recorder.Requests()
+			require.Len(t, requests, 1)
+			assert.Equal(t, "/clip/v2/resource/light/light-1", requests[0].Path)
+			assert.JSONEq(t, tt.expectedBody, string(requests[0].Body))
+		})
+	}
+}
+
+func TestClient_SetColorTemperatureKelvinById(t *testing.T) {
+	tests := []struct {
+		name         string
+		kelvin       int
+		wantErr      bool
+		expectedErr  string
+		expectedBody string
+	}{
+		{
+			name:         "converts warm white",
+			kelvin:       2700,
+			expectedBody: `{"color_temperature":{"mirek":370}}`,
+		},
+		{
+			name:         "converts neutral white",
+			kelvin:       4000,
+			expectedBody: `{"color_temperature":{"mirek":250}}`,
+		},
+		{
+			name:         "converts daylight",
+			kelvin:       6500,
+			expectedBody: `{"color_temperature":{"mirek":153}}`,
+		},
+		{
+			name:         "clamps too warm temperature to max mirek",
+			kelvin:       1500,
+			expectedBody: `{"color_temperature":{"mirek":500}}`,
+		},
+		{
+			name:         "clamps too cold temperature to min mirek",
+			kelvin:       MaxKelvin,
+			expectedBody: `{"color_temperature":{"mirek":153}}`,
+		},
+		{
+			name:        "rejects too low kelvin",
+			kelvin:      999,
+			wantErr:     true,
+			expectedErr: `hue: update light "light-1": kelvin 999 out of range [1000, 10000]`,
+		},
+		{
+			name:        "rejects too high kelvin",
+			kelvin:      10001,
+			wantErr:     true,
+			expectedErr: `hue: update light "light-1": kelvin 10001 out of range [1000, 10000]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, recorder := testutils.MockHueBridgeRecorder(http.StatusOK, map[string]interface{}{
+				"data": []map[string]interface{}{{"id": "light-1", "rid": "light-1", "rtype": "light"}},
+			})
+			defer server.Close()
+
+			err := newTestClient(t, server).SetColorTemperatureKelvinById("light-1", tt.kelvin)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Equal(t, tt.expectedErr, err.Error())
+				assert.Empty(t, recorder.Requests())
+				return
+			}
+
+			require.NoError(t, err)
+			requests := recorder.Requests()
+			require.Len(t, requests, 1)
+			assert.Equal(t, http.MethodPut, requests[0].Method)
+			assert.Equal(t, "/clip/v2/resource/light/light-1", requests[0].Path)
+			assert.JSONEq(t, tt.expectedBody, string(requests[0].Body))
+		})
+	}
+}
+
+func TestClient_SetBrightnessVerified(t *testing.T) {
+	tests := []struct {
+		name        string
+		light       map[string]interface{}
+		brightness  float32
+		wantErr     bool
+		expectedErr string
+	}{
+		{
+			name:       "accepts applied brightness",
+			light:      map[string]interface{}{"id": "light-1", "dimming": map[string]interface{}{"brightness": 40}},
+			brightness: 40,
+		},
+		{
+			name:       "accepts brightness within tolerance",
+			light:      map[string]interface{}{"id": "light-1", "dimming": map[string]interface{}{"brightness": 40.39}},
+			brightness: 40,
+		},
+		{
+			name:        "rejects clamped brightness",
+			light:       map[string]interface{}{"id": "light-1", "dimming": map[string]interface{}{"brightness": 5, "min_dim_level": 5}},
+			brightness:  1,
+			wantErr:     true,
+			expectedErr: `hue: update light "light-1": brightness not applied: requested 1.00, applied 5.00`,
+		},
+		{
+			name:        "rejects light without dimming",
+			light:       map[string]interface{}{"id": "light-1"},
+			brightness:  40,
+			wantErr:     true,
+			expectedErr: `hue: update light "light-1": light does not support dimming`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, recorder := testutils.MockHueBridgeRecorder(http.StatusOK, map[string]interface{}{
+				"data": []map[string]interface{}{tt.light},
+			})
+			defer server.Close()
+
+			err := newTestClient(t, server).SetBrightnessVerified("light-1", tt.brightness, DefaultBrightnessTolerance)
+
+			if tt.wantErr {
+				assert.EqualError(t, err, tt.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+			requests := recorder.Requests()
+			require.Len(t, requests, 2)
+			assert.Equal(t, http.MethodPut, requests[0].Method)
+			assert.Equal(t, http.MethodGet, requests[1].Method)
+		})
+	}
+
+	t.Run("wraps ErrBrightnessMismatch", func(t *testing.T) {
+		server := testutils.MockHueBridgeResponse(http.StatusOK, map[string]interface{}{
+			"data": []map[string]interface{}{{"id": "light-1", "dimming": map[string]interface{}{"brightness": 5}}},
+		})
+		defer server.Close()
+
+		err := newTestClient(t, server).SetBrightnessVerified("light-1", 1, DefaultBrightnessTolerance)
+
+		assert.ErrorIs(t, err, ErrBrightnessMismatch)
+	})
+}
@@ -0,0 +1,115 @@
+package hueclient
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidLightUpdate is returned if a light update contains invalid values or
+// combinations of fields which the bridge would reject.
+var ErrInvalidLightUpdate = errors.New("invalid light update")
+
+// LightUpdateBuilder builds a LightBodyUpdate without the nested pointer structs,
+// e.g. NewLightUpdate().On(true).Brightness(80).ColorHex("#ffaa00").TransitionMs(400).Build().
+// Invalid values are collected and returned by Build.
+type LightUpdateBuilder struct {
+	update LightBodyUpdate
+	errs   []error
+}
+
+// NewLightUpdate starts an empty light update.
+func NewLightUpdate() *LightUpdateBuilder {
+	return &LightUpdateBuilder{}
+}
+
+// On turns the light on or off.
+func (b *LightUpdateBuilder) On(on bool) *LightUpdateBuilder {
+	b.update.On = &LightOnState{On: on}
+	return b
+}
+
+// Brightness sets the brightness percentage, it must be in (0, 100].
+func (b *LightUpdateBuilder) Brightness(brightness float32) *LightUpdateBuilder {
+	if brightness <= 0 || brightness > 100 {
+		b.invalid("brightness %v must be in (0, 100], use On(false) to turn the light off", brightness)
+		return b
+	}
+	b.update.Dimming = &LightDimmingState{Brightness: brightness}
+	return b
+}
+
+// ColorTemperature sets the color temperature in mirek, it must be in [MinMirek, MaxMirek].
+func (b *LightUpdateBuilder) ColorTemperature(mirek int) *LightUpdateBuilder {
+	if mirek < MinMirek || mirek > MaxMirek {
+		b.invalid("color temperature %d must be in [%d, %d] mirek", mirek, MinMirek, MaxMirek)
+		return b
+	}
+	b.update.ColorTemperature = &LightColorTemperature{Mirek: &mirek}
+	return b
+}
+
+// ColorXY sets the color as CIE XY position, both coordinates must be in [0, 1].
+func (b *LightUpdateBuilder) ColorXY(x float32, y float32) *LightUpdateBuilder {
+	if x < 0 || x > 1 || y < 0 || y > 1 {
+		b.invalid("color x %v and y %v must be in [0, 1]", x, y)
+		return b
+	}
+	color := NewLightColorXY(x, y)
+	b.update.Color = &color
+	return b
+}
+
+// ColorRGB sets the color from an sRGB color, see ColorXYFromRGB.
+func (b *LightUpdateBuilder) ColorRGB(red uint8, green uint8, blue uint8) *LightUpdateBuilder {
+	xy := ColorXYFromRGB(red, green, blue)
+	return b.ColorXY(xy.X, xy.Y)
+}
+
+// ColorHex sets the color from a hex sRGB color like "#ffaa00" or "#fa0".
+func (b *LightUpdateBuilder) ColorHex(hex string) *LightUpdateBuilder {
+	digits := strings.TrimPrefix(hex, "#")
+	if len(digits) == 3 {
+		digits = string([]byte{digits[0], digits[0], digits[1], digits[1], digits[2], digits[2]})
+	}
+
+	rgb, err := strconv.ParseUint(digits, 16, 32)
+	if len(digits) != 6 || err != nil {
+		b.invalid("color %q must be a hex color like #ffaa00", hex)
+		return b
+	}
+	return b.ColorRGB(uint8(rgb>>16), uint8(rgb>>8), uint8(rgb))
+}
+
+// TransitionMs sets the duration of the transition to the new state in milliseconds.
+func (b *LightUpdateBuilder) TransitionMs(milliseconds int) *LightUpdateBuilder {
+	if milliseconds < 0 {
+		b.invalid("transition %dms must not be negative", milliseconds)
+		return b
+	}
+	b.update.Dynamics = &Dynamics{Duration: &milliseconds}
+	return b
+}
+
+// Build returns the update, or an error wrapping ErrInvalidLightUpdate for every
+// invalid value and for combinations the bridge rejects.
+func (b *LightUpdateBuilder) Build() (*LightBodyUpdate, error) {
+	errs := b.errs
+	if err := b.update.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if b.update == (LightBodyUpdate{}) && len(errs) == 0 {
+		errs = append(errs, fmt.Errorf("%w: no field set", ErrInvalidLightUpdate))
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	update := b.update
+	return &update, nil
+}
+
+func (b *LightUpdateBuilder) invalid(format string, args ...any) {
+	b.errs = append(b.errs, fmt.Errorf("%w: "+format, append([]any{ErrInvalidLightUpdate}, args...)...))
+}
@@ -0,0 +1,120 @@
+package hueclient
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLightUpdateBuilder(t *testing.T) {
+	tests := []struct {
+		name         string
+		build        func() (*LightBodyUpdate, error)
+		expectedJSON string
+		wantErr      bool
+		errMsg       string
+	}{
+		{
+			name: "turns light on with brightness, color and transition",
+			build: func() (*LightBodyUpdate, error) {
+				return NewLightUpdate().On(true).Brightness(80).ColorXY(0.5, 0.4).TransitionMs(400).Build()
+			},
+			expectedJSON: `{"on":{"on":true},"dimming":{"brightness":80},"color":{"xy":{"x":0.5,"y":0.4}},"dynamics":{"duration":400}}`,
+		},
+		{
+			name: "sets color temperature",
+			build: func() (*LightBodyUpdate, error) {
+				return NewLightUpdate().ColorTemperature(350).Build()
+			},
+			expectedJSON: `{"color_temperature":{"mirek":350}}`,
+		},
+		{
+			name: "turns light off immediately",
+			build: func() (*LightBodyUpdate, error) {
+				return NewLightUpdate().On(false).TransitionMs(0).Build()
+			},
+			expectedJSON: `{"on":{"on":false},"dynamics":{"duration":0}}`,
+		},
+		{
+			name: "sets color from short hex",
+			build: func() (*LightBodyUpdate, error) {
+				return NewLightUpdate().ColorHex("#f00").Build()
+			},
+			expectedJSON: `{"color":{"xy":{"x":0.7006062,"y":0.299301}}}`,
+		},
+		{
+			name: "rejects empty update",
+			build: func() (*LightBodyUpdate, error) {
+				return NewLightUpdate().Build()
+			},
+			wantErr: true,
+			errMsg:  "invalid light update: no field set",
+		},
+		{
+			name: "rejects color and color temperature",
+			build: func() (*LightBodyUpdate, error) {
+				return NewLightUpdate().ColorHex("#ffaa00").ColorTemperature(350).Build()
+			},
+			wantErr: true,
+			errMsg:  "invalid light update: color and color temperature are mutually exclusive",
+		},
+		{
+			name: "rejects zero brightness",
+			build: func() (*LightBodyUpdate, error) {
+				return NewLightUpdate().Brightness(0).Build()
+			},
+			wantErr: true,
+			errMsg:  "invalid light update: brightness 0 must be in (0, 100], use On(false) to turn the light off",
+		},
+		{
+			name: "reports every invalid value",
+			build: func() (*LightBodyUpdate, error) {
+				return NewLightUpdate().On(true).ColorTemperature(600).ColorHex("orange").TransitionMs(-1).Build()
+			},
+			wantErr: true,
+			errMsg: "invalid light update: color temperature 600 must be in [153, 500] mirek\n" +
+				"invalid light update: color \"orange\" must be a hex color like #ffaa00\n" +
+				"invalid light update: transition -1ms must not be negative",
+		},
+		{
+			name: "rejects color outside of the CIE XY space",
+			build: func() (*LightBodyUpdate, error) {
+				return NewLightUpdate().ColorXY(1.2, 0.3).Build()
+			},
+			wantErr: true,
+			errMsg:  "invalid light update: color x 1.2 and y 0.3 must be in [0, 1]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			update, err := tt.build()
+
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidLightUpdate)
+				assert.EqualError(t, err, tt.errMsg)
+				assert.Nil(t, update)
+				return
+			}
+
+			require.NoError(t, err)
+			body, err := json.Marshal(update)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expectedJSON, string(body))
+		})
+	}
+}
+
+func TestLightUpdateBuilder_ColorHex(t *testing.T) {
+	expected, err := NewLightUpdate().ColorRGB(0xff, 0xaa, 0x00).Build()
+	require.NoError(t, err)
+
+	for _, hex := range []string{"#ffaa00", "ffaa00", "#FFAA00", "#fa0"} {
+		update, err := NewLightUpdate().ColorHex(hex).Build()
+
+		require.NoError(t, err, hex)
+		assert.Equal(t, expected, update, hex)
+	}
+}
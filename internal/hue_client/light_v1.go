@@ -0,0 +1,148 @@
+package hueclient
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// LightAPI selects the bridge API used to update lights.
+type LightAPI string
+
+const (
+	// LightAPIV2 updates lights through the CLIP v2 API, it is the default.
+	LightAPIV2 LightAPI = "v2"
+	// LightAPIV1 updates lights through the v1 API, for bridges with an old firmware.
+	LightAPIV1 LightAPI = "v1"
+	// LightAPIAuto uses the CLIP v2 API and falls back to the v1 API if the bridge
+	// does not support the v2 request.
+	LightAPIAuto LightAPI = "auto"
+)
+
+// WithLightAPI selects the API used to update lights, defaults to LightAPIV2. The v1
+// API only supports on/off, brightness and the transition duration of an update.
+func WithLightAPI(api LightAPI) ClientOption {
+	return func(o *clientOptions) {
+		o.lightAPI = api
+	}
+}
+
+// ErrUnsupportedByV1 is returned if a light update can not be expressed with the v1 API.
+var ErrUnsupportedByV1 = errors.New("update is not supported by the v1 API")
+
+// v1LightState is the body of a v1 light state update.
+type v1LightState struct {
+	On  *bool `json:"on,omitempty"`
+	Bri *int  `json:"bri,omitempty"`
+	// TransitionTime in multiples of 100ms
+	TransitionTime *int `json:"transitiontime,omitempty"`
+}
+
+type v1UpdateResponse []struct {
+	Success map[string]interface{} `json:"success,omitempty"`
+	Error   *struct {
+		Type        int    `json:"type"`
+		Address     string `json:"address"`
+		Description string `json:"description"`
+	} `json:"error,omitempty"`
+}
+
+// isUnsupportedByV2 reports whether the bridge rejected a CLIP v2 request because it
+// does not support the endpoint, e.g. on an old firmware.
+func isUnsupportedByV2(err error) bool {
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		return false
+	}
+	switch statusErr.StatusCode {
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return true
+	}
+	return false
+}
+
+// v1StateFromUpdate converts the update into a v1 light state, it fails if the
+// update contains fields other than on, dimming and the dynamics duration.
+func v1StateFromUpdate(update *LightBodyUpdate) (*v1LightState, error) {
+	supported := LightBodyUpdate{On: update.On, Dimming: update.Dimming, Dynamics: update.Dynamics}
+	if update.Dynamics != nil && update.Dynamics.Speed != nil {
+		return nil, ErrUnsupportedByV1
+	}
+	if *update != supported {
+		return nil, ErrUnsupportedByV1
+	}
+
+	state := &v1LightState{}
+	if update.On != nil {
+		state.On = &update.On.On
+	}
+	if update.Dimming != nil {
+		bri := v1Brightness(update.Dimming.Brightness)
+		state.Bri = &bri
+	}
+	if update.Dynamics != nil && update.Dynamics.Duration != nil {
+		transitionTime := int(math.Round(float64(*update.Dynamics.Duration) / 100))
+		state.TransitionTime = &transitionTime
+	}
+	return state, nil
+}
+
+// v1Brightness converts a brightness percentage to the v1 range [1, 254].
+func v1Brightness(percent float32) int {
+	bri := int(math.Round(float64(percent) * 254 / 100))
+	return min(max(bri, 1), 254)
+}
+
+// resolveV1LightID returns the v1 ID of the light, numeric IDs are v1 IDs already,
+// others are resolved with the id_v1 reported by the CLIP v2 API.
+func (c *Client) resolveV1LightID(id string) (string, error) {
+	if _, err := strconv.Atoi(id); err == nil {
+		return id, nil
+	}
+
+	light, err := c.GetOneLightById(id)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve v1 ID: %w", err)
+	}
+	if light == nil || !strings.HasPrefix(light.IDV1, "/lights/") {
+		return "", errors.New("failed to resolve v1 ID: light has no v1 ID, configure the numeric v1 ID instead")
+	}
+	return strings.TrimPrefix(light.IDV1, "/lights/"), nil
+}
+
+func (c *Client) updateLightV1(id string, lightUpdate *LightBodyUpdate) (*ResourceIdentifier, error) {
+	state, err := v1StateFromUpdate(lightUpdate)
+	if err != nil {
+		return nil, newOperationError(ErrPrefixUpdateLight, id, err)
+	}
+
+	v1ID, err := c.resolveV1LightID(id)
+	if err != nil {
+		return nil, newOperationError(ErrPrefixUpdateLight, id, err)
+	}
+
+	apiKey, err := c.apiKey()
+	if err != nil {
+		return nil, newOperationError(ErrPrefixUpdateLight, id, err)
+	}
+
+	var resp v1UpdateResponse
+	if err := c.doRequest(fmt.Sprintf("api/%s/lights/%s/state", apiKey, v1ID), http.MethodPut, state, &resp); err != nil {
+		return nil, newOperationError(ErrPrefixUpdateLight, id, err)
+	}
+
+	for _, result := range resp {
+		if result.Error != nil {
+			return nil, newOperationError(ErrPrefixUpdateLight, id, errors.New(result.Error.Description))
+		}
+	}
+
+	if c.lightCache != nil {
+		c.lightCache.invalidate(id)
+	}
+
+	return &ResourceIdentifier{RID: id, RType: string(ReferenceTypeLight)}, nil
+}
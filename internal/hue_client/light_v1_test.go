@@ -0,0 +1,173 @@
+package hueclient
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"com.github.yveskaufmann/hue-lighter/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestV1StateFromUpdate(t *testing.T) {
+	duration := 2500
+
+	tests := []struct {
+		name         string
+		update       *LightBodyUpdate
+		expectedBody string
+		expectedErr  error
+	}{
+		{
+			name:         "on",
+			update:       &LightBodyUpdate{On: &LightOnState{On: true}},
+			expectedBody: `{"on":true}`,
+		},
+		{
+			name:         "off with transition",
+			update:       &LightBodyUpdate{On: &LightOnState{On: false}, Dynamics: &Dynamics{Duration: &duration}},
+			expectedBody: `{"on":false,"transitiontime":25}`,
+		},
+		{
+			name:         "brightness in percent",
+			update:       &LightBodyUpdate{Dimming: &LightDimmingState{Brightness: 50}},
+			expectedBody: `{"bri":127}`,
+		},
+		{
+			name:         "lowest brightness",
+			update:       &LightBodyUpdate{Dimming: &LightDimmingState{Brightness: 0.1}},
+			expectedBody: `{"bri":1}`,
+		},
+		{
+			name:         "full brightness",
+			update:       &LightBodyUpdate{Dimming: &LightDimmingState{Brightness: 100}},
+			expectedBody: `{"bri":254}`,
+		},
+		{
+			name:        "color temperature is not supported",
+			update:      &LightBodyUpdate{ColorTemperature: &LightColorTemperature{}},
+			expectedErr: ErrUnsupportedByV1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state, err := v1StateFromUpdate(tt.update)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			body, err := json.Marshal(state)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expectedBody, string(body))
+		})
+	}
+}
+
+func TestClient_UpdateOneLightById_V1(t *testing.T) {
+	t.Run("sends v1 state update for numeric ID", func(t *testing.T) {
+		server, recorder := testutils.MockHueBridgeRecorder(200, []map[string]interface{}{
+			{"success": map[string]interface{}{"/lights/3/state/on": true}},
+		})
+		defer server.Close()
+		client := newTestClient(t, server)
+		client.lightAPI = LightAPIV1
+
+		err := client.TurnOnLightById("3")
+
+		require.NoError(t, err)
+		requests := recorder.Requests()
+		require.Len(t, requests, 1)
+		assert.Equal(t, http.MethodPut, requests[0].Method)
+		assert.Equal(t, "/api/test-api-key/lights/3/state", requests[0].Path)
+		assert.JSONEq(t, `{"on":true}`, string(requests[0].Body))
+		assert.Empty(t, requests[0].Header.Get("hue-application-key"))
+	})
+
+	t.Run("returns v1 error", func(t *testing.T) {
+		server := testutils.MockHueBridgeResponse(200, []map[string]interface{}{
+			{"error": map[string]interface{}{"type": 3, "address": "/lights/9", "description": "resource, /lights/9, not available"}},
+		})
+		defer server.Close()
+		client := newTestClient(t, server)
+		client.lightAPI = LightAPIV1
+
+		err := client.TurnOffLightById("9")
+
+		require.Error(t, err)
+		assert.Equal(t, `hue: update light "9": resource, /lights/9, not available`, err.Error())
+	})
+
+	t.Run("rejects updates not supported by v1", func(t *testing.T) {
+		server, recorder := testutils.MockHueBridgeRecorder(200, nil)
+		defer server.Close()
+		client := newTestClient(t, server)
+		client.lightAPI = LightAPIV1
+
+		err := client.SetColorTemperatureById("3", 300)
+
+		assert.ErrorIs(t, err, ErrUnsupportedByV1)
+		assert.Empty(t, recorder.Requests())
+	})
+}
+
+// newV1FallbackServer serves a bridge without CLIP v2 light updates, it reports
+// the v1 ID of the v2 light "light-1" and accepts v1 state updates.
+func newV1FallbackServer(t *testing.T) (*httptest.Server, *[]string) {
+	t.Helper()
+
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, strings.TrimSpace(r.Method+" "+r.URL.Path+" "+string(body)))
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/clip/v2/resource/light/light-1":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": []map[string]interface{}{{"id": "light-1", "id_v1": "/lights/3"}},
+			})
+		case r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, "/api/"):
+			json.NewEncoder(w).Encode([]map[string]interface{}{{"success": map[string]interface{}{}}})
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &requests
+}
+
+func TestClient_UpdateOneLightById_V1Fallback(t *testing.T) {
+	t.Run("falls back to v1 when v2 update is not supported", func(t *testing.T) {
+		server, requests := newV1FallbackServer(t)
+		client := newTestClient(t, server)
+		client.lightAPI = LightAPIAuto
+
+		err := client.SetBrightnessById("light-1", 100)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			`PUT /clip/v2/resource/light/light-1 {"dimming":{"brightness":100}}`,
+			"GET /clip/v2/resource/light/light-1",
+			`PUT /api/test-api-key/lights/3/state {"bri":254}`,
+		}, *requests)
+	})
+
+	t.Run("does not fall back with v2 light API", func(t *testing.T) {
+		server, requests := newV1FallbackServer(t)
+		client := newTestClient(t, server)
+
+		err := client.SetBrightnessById("light-1", 100)
+
+		var statusErr *StatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusMethodNotAllowed, statusErr.StatusCode)
+		assert.Len(t, *requests, 1)
+	})
+}
@@ -0,0 +1,16 @@
+package hueclient
+
+import (
+	"com.github.yveskaufmann/hue-lighter/internal/logging"
+	log "github.com/sirupsen/logrus"
+)
+
+// componentLogger tags the logger with the given component. A nil logger
+// results in a logger which discards all entries, so that the client can be
+// embedded without configuring logrus.
+func componentLogger(logger *log.Entry, component string) *log.Entry {
+	if logger == nil {
+		logger = logging.NewDiscardLogger()
+	}
+	return logger.WithField("component", component)
+}
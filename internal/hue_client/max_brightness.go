@@ -0,0 +1,29 @@
+package hueclient
+
+// WithMaxBrightness caps the brightness requested by SetBrightnessById and
+// SetBrightnessVerified to maxBrightness percent, for households which never
+// want the lights at full blast. Zero disables the cap.
+func WithMaxBrightness(maxBrightness float32) ClientOption {
+	return func(o *clientOptions) {
+		o.maxBrightness = maxBrightness
+	}
+}
+
+// SetMaxBrightness replaces the cap of WithMaxBrightness, e.g. after a config
+// reload. Zero disables the cap.
+func (c *Client) SetMaxBrightness(maxBrightness float32) {
+	c.maxBrightnessMu.Lock()
+	defer c.maxBrightnessMu.Unlock()
+	c.maxBrightness = maxBrightness
+}
+
+// capBrightness lowers the brightness to the configured maximum brightness.
+func (c *Client) capBrightness(brightness float32) float32 {
+	c.maxBrightnessMu.RLock()
+	defer c.maxBrightnessMu.RUnlock()
+
+	if c.maxBrightness > 0 && brightness > c.maxBrightness {
+		return c.maxBrightness
+	}
+	return brightness
+}
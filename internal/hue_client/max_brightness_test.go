@@ -0,0 +1,76 @@
+package hueclient
+
+import (
+	"net/http"
+	"testing"
+
+	"com.github.yveskaufmann/hue-lighter/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_SetBrightnessByIdCapsToMaxBrightness(t *testing.T) {
+	tests := []struct {
+		name         string
+		brightness   float32
+		expectedBody string
+	}{
+		{
+			name:         "requested 100% is capped",
+			brightness:   100,
+			expectedBody: `{"dimming":{"brightness":70}}`,
+		},
+		{
+			name:         "brightness below the cap is kept",
+			brightness:   40,
+			expectedBody: `{"dimming":{"brightness":40}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, recorder := testutils.MockHueBridgeRecorder(http.StatusOK, map[string]interface{}{
+				"data": []map[string]interface{}{{"rid": "light-1", "rtype": "light"}},
+			})
+			defer server.Close()
+
+			client := newTestClient(t, server)
+			client.maxBrightness = 70
+
+			require.NoError(t, client.SetBrightnessById("light-1", tt.brightness))
+
+			requests := recorder.Requests()
+			require.Len(t, requests, 1)
+			assert.JSONEq(t, tt.expectedBody, string(requests[0].Body))
+		})
+	}
+}
+
+func TestClient_SetBrightnessVerifiedComparesWithCappedBrightness(t *testing.T) {
+	server := testutils.MockHueBridgeResponse(http.StatusOK, map[string]interface{}{
+		"data": []map[string]interface{}{{"id": "light-1", "dimming": map[string]interface{}{"brightness": 70}}},
+	})
+	defer server.Close()
+
+	client := newTestClient(t, server)
+	client.maxBrightness = 70
+
+	assert.NoError(t, client.SetBrightnessVerified("light-1", 100, DefaultBrightnessTolerance))
+}
+
+func TestWithMaxBrightness(t *testing.T) {
+	options := clientOptions{}
+	WithMaxBrightness(80)(&options)
+
+	assert.Equal(t, float32(80), options.maxBrightness)
+}
+
+func TestClient_SetMaxBrightness(t *testing.T) {
+	client := &Client{maxBrightness: 70}
+
+	client.SetMaxBrightness(50)
+	assert.Equal(t, float32(50), client.capBrightness(100))
+
+	client.SetMaxBrightness(0)
+	assert.Equal(t, float32(100), client.capBrightness(100))
+}
@@ -0,0 +1,106 @@
+package hueclient
+
+import (
+	"errors"
+	"fmt"
+)
+
+// SetPowerupById configures what a light does when mains power returns, e.g. a custom
+// preset with `on` set to false keeps the light off after a power blip. The custom preset
+// requires the on behaviour, the other presets must not contain custom settings.
+func (c *Client) SetPowerupById(id string, powerup *Powerup) error {
+	if err := validatePowerup(powerup); err != nil {
+		return newOperationError(ErrPrefixUpdateLight, id, err)
+	}
+
+	_, err := c.UpdateOneLightById(id, &LightBodyUpdate{Powerup: powerup})
+	return err
+}
+
+func validatePowerup(powerup *Powerup) error {
+	if powerup == nil {
+		return errors.New("powerup is required")
+	}
+
+	switch powerup.Preset {
+	case PowerupPresetSafety, PowerupPresetPowerfail, PowerupPresetLastOnState:
+		if powerup.On != nil || powerup.Dimming != nil || powerup.Color != nil {
+			return fmt.Errorf("powerup preset %q does not accept on, dimming or color settings", powerup.Preset)
+		}
+		return nil
+	case PowerupPresetCustom:
+	default:
+		return fmt.Errorf("unsupported powerup preset %q", powerup.Preset)
+	}
+
+	if powerup.On == nil {
+		return fmt.Errorf("powerup preset %q requires on settings", powerup.Preset)
+	}
+
+	if err := validatePowerupOn(powerup.On); err != nil {
+		return err
+	}
+
+	if powerup.Dimming != nil {
+		if err := validatePowerupDimming(powerup.Dimming); err != nil {
+			return err
+		}
+	}
+
+	if powerup.Color != nil {
+		if err := validatePowerupColor(powerup.Color); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validatePowerupOn(on *PowerupOn) error {
+	switch on.Mode {
+	case PowerupOnModeOn:
+		if on.On == nil {
+			return fmt.Errorf("powerup on mode %q requires an on state", on.Mode)
+		}
+	case PowerupOnModeToggle, PowerupOnModePrevious:
+	default:
+		return fmt.Errorf("unsupported powerup on mode %q", on.Mode)
+	}
+	return nil
+}
+
+func validatePowerupDimming(dimming *PowerupDimming) error {
+	switch dimming.Mode {
+	case PowerupDimmingModeDimming:
+		if dimming.Dimming == nil {
+			return fmt.Errorf("powerup dimming mode %q requires a brightness", dimming.Mode)
+		}
+		if dimming.Dimming.Brightness <= 0 || dimming.Dimming.Brightness > 100 {
+			return fmt.Errorf("powerup brightness %.2f out of range (0, 100]", dimming.Dimming.Brightness)
+		}
+	case PowerupDimmingModePrevious:
+	default:
+		return fmt.Errorf("unsupported powerup dimming mode %q", dimming.Mode)
+	}
+	return nil
+}
+
+func validatePowerupColor(color *PowerupColor) error {
+	switch color.Mode {
+	case PowerupColorModeColorTemperature:
+		if color.ColorTemp == nil || color.ColorTemp.Mirek == nil {
+			return fmt.Errorf("powerup color mode %q requires a mirek value", color.Mode)
+		}
+		if mirek := *color.ColorTemp.Mirek; mirek < MinMirek || mirek > MaxMirek {
+			return fmt.Errorf("powerup mirek %d out of range [%d, %d]", mirek, MinMirek, MaxMirek)
+		}
+	case PowerupColorModeColor:
+		if color.Color == nil || color.Color.XY == nil {
+			return fmt.Errorf("powerup color mode %q requires a color with xy position", color.Mode)
+		}
+	case PowerupColorModePrevious:
+	default:
+		return fmt.Errorf("unsupported powerup color mode %q", color.Mode)
+	}
+	return nil
+}
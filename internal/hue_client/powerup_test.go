@@ -0,0 +1,139 @@
+package hueclient
+
+import (
+	"net/http"
+	"testing"
+
+	"com.github.yveskaufmann/hue-lighter/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_SetPowerupById(t *testing.T) {
+	mirek := 366
+	invalidMirek := 100
+
+	tests := []struct {
+		name         string
+		powerup      *Powerup
+		wantErr      bool
+		expectedErr  string
+		expectedBody string
+	}{
+		{
+			name:         "sends safety preset",
+			powerup:      &Powerup{Preset: PowerupPresetSafety},
+			expectedBody: `{"powerup":{"preset":"safety"}}`,
+		},
+		{
+			name:         "sends last on state preset",
+			powerup:      &Powerup{Preset: PowerupPresetLastOnState},
+			expectedBody: `{"powerup":{"preset":"last_on_state"}}`,
+		},
+		{
+			name: "sends custom preset keeping light off",
+			powerup: &Powerup{
+				Preset: PowerupPresetCustom,
+				On:     &PowerupOn{Mode: PowerupOnModeOn, On: &LightOnState{On: false}},
+			},
+			expectedBody: `{"powerup":{"preset":"custom","on":{"mode":"on","on":{"on":false}}}}`,
+		},
+		{
+			name: "sends custom preset with dimming and color temperature",
+			powerup: &Powerup{
+				Preset:  PowerupPresetCustom,
+				On:      &PowerupOn{Mode: PowerupOnModeOn, On: &LightOnState{On: true}},
+				Dimming: &PowerupDimming{Mode: PowerupDimmingModeDimming, Dimming: &PowerupBrightness{Brightness: 40}},
+				Color:   &PowerupColor{Mode: PowerupColorModeColorTemperature, ColorTemp: &PowerupColorTemp{Mirek: &mirek}},
+			},
+			expectedBody: `{"powerup":{"preset":"custom","on":{"mode":"on","on":{"on":true}},"dimming":{"mode":"dimming","dimming":{"brightness":40}},"color":{"mode":"color_temperature","color_temperature":{"mirek":366}}}}`,
+		},
+		{
+			name:        "rejects missing powerup",
+			powerup:     nil,
+			wantErr:     true,
+			expectedErr: `hue: update light "light-1": powerup is required`,
+		},
+		{
+			name:        "rejects unsupported preset",
+			powerup:     &Powerup{Preset: "sometimes"},
+			wantErr:     true,
+			expectedErr: `hue: update light "light-1": unsupported powerup preset "sometimes"`,
+		},
+		{
+			name: "rejects safety preset with custom settings",
+			powerup: &Powerup{
+				Preset: PowerupPresetSafety,
+				On:     &PowerupOn{Mode: PowerupOnModeOn, On: &LightOnState{On: false}},
+			},
+			wantErr:     true,
+			expectedErr: `hue: update light "light-1": powerup preset "safety" does not accept on, dimming or color settings`,
+		},
+		{
+			name:        "rejects custom preset without on settings",
+			powerup:     &Powerup{Preset: PowerupPresetCustom},
+			wantErr:     true,
+			expectedErr: `hue: update light "light-1": powerup preset "custom" requires on settings`,
+		},
+		{
+			name:        "rejects on mode without on state",
+			powerup:     &Powerup{Preset: PowerupPresetCustom, On: &PowerupOn{Mode: PowerupOnModeOn}},
+			wantErr:     true,
+			expectedErr: `hue: update light "light-1": powerup on mode "on" requires an on state`,
+		},
+		{
+			name: "rejects dimming mode without brightness",
+			powerup: &Powerup{
+				Preset:  PowerupPresetCustom,
+				On:      &PowerupOn{Mode: PowerupOnModePrevious},
+				Dimming: &PowerupDimming{Mode: PowerupDimmingModeDimming},
+			},
+			wantErr:     true,
+			expectedErr: `hue: update light "light-1": powerup dimming mode "dimming" requires a brightness`,
+		},
+		{
+			name: "rejects color temperature out of range",
+			powerup: &Powerup{
+				Preset: PowerupPresetCustom,
+				On:     &PowerupOn{Mode: PowerupOnModePrevious},
+				Color:  &PowerupColor{Mode: PowerupColorModeColorTemperature, ColorTemp: &PowerupColorTemp{Mirek: &invalidMirek}},
+			},
+			wantErr:     true,
+			expectedErr: `hue: update light "light-1": powerup mirek 100 out of range [153, 500]`,
+		},
+		{
+			name: "rejects color mode without color",
+			powerup: &Powerup{
+				Preset: PowerupPresetCustom,
+				On:     &PowerupOn{Mode: PowerupOnModePrevious},
+				Color:  &PowerupColor{Mode: PowerupColorModeColor},
+			},
+			wantErr:     true,
+			expectedErr: `hue: update light "light-1": powerup color mode "color" requires a color with xy position`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, recorder := testutils.MockHueBridgeRecorder(http.StatusOK, map[string]interface{}{
+				"data": []map[string]interface{}{{"rid": "light-1", "rtype": "light"}},
+			})
+			defer server.Close()
+
+			err := newTestClient(t, server).SetPowerupById("light-1", tt.powerup)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Equal(t, tt.expectedErr, err.Error())
+				assert.Empty(t, recorder.Requests())
+				return
+			}
+
+			require.NoError(t, err)
+			requests := recorder.Requests()
+			require.Len(t, requests, 1)
+			assert.Equal(t, "/clip/v2/resource/light/light-1", requests[0].Path)
+			assert.JSONEq(t, tt.expectedBody, string(requests[0].Body))
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package hueclient
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ProvidedAPIKey returns the API key provided by `HUE_API_KEY`, or the configured
+// one if the variable is not set. An empty key means none was provided.
+func ProvidedAPIKey(configured string) string {
+	if apiKey := strings.TrimSpace(os.Getenv("HUE_API_KEY")); apiKey != "" {
+		return apiKey
+	}
+	return strings.TrimSpace(configured)
+}
+
+// SeedAPIKey stores an API key obtained elsewhere, e.g. from another tool, as the
+// key of the device at the bridge, so that the device does not need to register
+// with the link button. A different stored key is replaced by the provided one.
+// It reports whether the store was changed.
+func SeedAPIKey(store APIKeyStore, bridgeID string, deviceName string, apiKey string) (bool, error) {
+	if apiKey == "" {
+		return false, nil
+	}
+
+	identity := fmt.Sprintf("%s#%s", bridgeID, deviceName)
+	if key, _ := store.Get(identity); key == apiKey {
+		return false, nil
+	}
+
+	if err := store.Set(identity, apiKey); err != nil {
+		return false, fmt.Errorf("failed to store provided API key for bridge %s: %w", bridgeID, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,94 @@
+package hueclient
+
+import (
+	"errors"
+	"testing"
+
+	"com.github.yveskaufmann/hue-lighter/internal/logging"
+	"com.github.yveskaufmann/hue-lighter/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvidedAPIKey(t *testing.T) {
+	tests := []struct {
+		name       string
+		env        string
+		configured string
+		expected   string
+	}{
+		{name: "none provided"},
+		{name: "configured key", configured: " config-key ", expected: "config-key"},
+		{name: "environment takes precedence", env: "env-key", configured: "config-key", expected: "env-key"},
+		{name: "blank environment falls back to configured key", env: "  ", configured: "config-key", expected: "config-key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer testutils.SetEnv(t, "HUE_API_KEY", tt.env)()
+
+			assert.Equal(t, tt.expected, ProvidedAPIKey(tt.configured))
+		})
+	}
+}
+
+func TestSeedAPIKey(t *testing.T) {
+	tests := []struct {
+		name           string
+		storedKeys     map[string]string
+		apiKey         string
+		expectedSeeded bool
+		expectedKeys   map[string]string
+	}{
+		{
+			name:           "stores provided key",
+			storedKeys:     map[string]string{},
+			apiKey:         "provided-key",
+			expectedSeeded: true,
+			expectedKeys:   map[string]string{"ECB5FAFFFE123456#office": "provided-key"},
+		},
+		{
+			name:           "replaces a different stored key",
+			storedKeys:     map[string]string{"ECB5FAFFFE123456#office": "old-key", "ECB5FAFFFE123456#kitchen": "kitchen-key"},
+			apiKey:         "provided-key",
+			expectedSeeded: true,
+			expectedKeys:   map[string]string{"ECB5FAFFFE123456#office": "provided-key", "ECB5FAFFFE123456#kitchen": "kitchen-key"},
+		},
+		{
+			name:         "keeps the store if the key is already stored",
+			storedKeys:   map[string]string{"ECB5FAFFFE123456#office": "provided-key"},
+			apiKey:       "provided-key",
+			expectedKeys: map[string]string{"ECB5FAFFFE123456#office": "provided-key"},
+		},
+		{
+			name:         "keeps the store without provided key",
+			storedKeys:   map[string]string{"ECB5FAFFFE123456#office": "old-key"},
+			expectedKeys: map[string]string{"ECB5FAFFFE123456#office": "old-key"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewInMemoryAPIKeyStore(logging.NewDiscardLogger())
+			for identity, apiKey := range tt.storedKeys {
+				require.NoError(t, store.Set(identity, apiKey))
+			}
+
+			seeded, err := SeedAPIKey(store, "ECB5FAFFFE123456", "office", tt.apiKey)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedSeeded, seeded)
+			assert.Equal(t, tt.expectedKeys, store.snapshot())
+		})
+	}
+
+	t.Run("fails when API key cannot be stored", func(t *testing.T) {
+		store := newMockAPIKeyStore()
+		store.setErr = errors.New("read-only file system")
+
+		seeded, err := SeedAPIKey(store, "ECB5FAFFFE123456", "office", "provided-key")
+
+		assert.False(t, seeded)
+		assert.EqualError(t, err, "failed to store provided API key for bridge ECB5FAFFFE123456: read-only file system")
+	})
+}
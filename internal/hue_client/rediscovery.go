@@ -0,0 +1,102 @@
+package hueclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultRediscoveryThreshold is the number of consecutive connection failures
+// after which the bridge is re-discovered.
+const DefaultRediscoveryThreshold = 3
+
+// BridgeLocator returns the current IP of the bridge with the given ID, see
+// BridgeDiscoveryService.LocateBridge.
+type BridgeLocator func(ctx context.Context, bridgeID string) (string, error)
+
+// WithRediscovery re-discovers the bridge after threshold consecutive connection
+// failures and switches the client to the new IP, e.g. after the bridge got a new
+// DHCP lease. The failed request is retried once with the new IP.
+func WithRediscovery(locate BridgeLocator, threshold int) ClientOption {
+	return func(o *clientOptions) {
+		o.rediscovery = &rediscovery{locate: locate, threshold: threshold}
+	}
+}
+
+type rediscovery struct {
+	locate    BridgeLocator
+	threshold int
+	failures  atomic.Int32
+	// mu serializes the re-discovery of concurrent requests
+	mu sync.Mutex
+}
+
+func (c *Client) getBaseURL() string {
+	c.baseURLMu.RLock()
+	defer c.baseURLMu.RUnlock()
+	return c.baseURL
+}
+
+func (c *Client) setBaseURL(baseURL string) {
+	c.baseURLMu.Lock()
+	defer c.baseURLMu.Unlock()
+	c.baseURL = baseURL
+}
+
+// isConnectionFailure reports whether the bridge could not be reached, unlike
+// e.g. TLS errors a new IP of the bridge may resolve them.
+func isConnectionFailure(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr)
+}
+
+// resetConnectionFailures is called after the bridge answered a request.
+func (c *Client) resetConnectionFailures() {
+	if c.rediscovery != nil {
+		c.rediscovery.failures.Store(0)
+	}
+}
+
+// relocateBridge counts a connection failure to failedBaseURL and re-discovers
+// the bridge once the threshold is reached. It reports whether the base URL
+// changed, so that the failed request is worth retrying.
+func (c *Client) relocateBridge(failedBaseURL string) bool {
+	r := c.rediscovery
+	if r == nil {
+		return false
+	}
+
+	if int(r.failures.Add(1)) < r.threshold {
+		return false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Another request already found the bridge while this one waited.
+	if c.getBaseURL() != failedBaseURL {
+		return true
+	}
+
+	c.logger.Warnf("Bridge %s unreachable at %s, re-discovering it", c.bridgeID, failedBaseURL)
+	r.failures.Store(0)
+
+	ip, err := r.locate(context.Background(), c.bridgeID)
+	if err != nil {
+		c.logger.WithError(err).Warn("Failed to re-discover bridge")
+		return false
+	}
+
+	baseURL := fmt.Sprintf("https://%s", ip)
+	if baseURL == failedBaseURL {
+		c.logger.Infof("Bridge %s is still at %s", c.bridgeID, failedBaseURL)
+		return false
+	}
+
+	c.setBaseURL(baseURL)
+	c.logger.Infof("Bridge %s moved from %s to %s", c.bridgeID, failedBaseURL, baseURL)
+	return true
+}
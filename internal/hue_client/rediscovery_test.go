@@ -0,0 +1,107 @@
+package hueclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newMovedBridge returns the URL of a bridge IP which refuses connections and a
+// bridge serving the lights at its new IP.
+func newMovedBridge(t *testing.T) (string, *httptest.Server) {
+	t.Helper()
+
+	stale := httptest.NewServer(http.NotFoundHandler())
+	stale.Close()
+
+	moved := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data": [{"id": "light-1"}]}`))
+	}))
+	t.Cleanup(moved.Close)
+
+	return stale.URL, moved
+}
+
+func TestClient_Rediscovery(t *testing.T) {
+	t.Run("switches to re-discovered IP and retries the request", func(t *testing.T) {
+		staleURL, moved := newMovedBridge(t)
+		client := newTestClient(t, moved)
+		client.baseURL = staleURL
+
+		var locatedIDs []string
+		client.rediscovery = &rediscovery{
+			threshold: 2,
+			locate: func(ctx context.Context, bridgeID string) (string, error) {
+				locatedIDs = append(locatedIDs, bridgeID)
+				return strings.TrimPrefix(moved.URL, "https://"), nil
+			},
+		}
+
+		_, err := client.GetAllLights()
+		require.Error(t, err)
+		assert.Empty(t, locatedIDs, "re-discovery before threshold")
+
+		lights, err := client.GetAllLights()
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"bridge-123"}, locatedIDs)
+		assert.Equal(t, moved.URL, client.getBaseURL())
+		require.Len(t, lights.Data, 1)
+		assert.Equal(t, "light-1", lights.Data[0].ID)
+	})
+
+	t.Run("keeps IP when re-discovery fails", func(t *testing.T) {
+		staleURL, moved := newMovedBridge(t)
+		client := newTestClient(t, moved)
+		client.baseURL = staleURL
+		client.rediscovery = &rediscovery{
+			threshold: 1,
+			locate: func(ctx context.Context, bridgeID string) (string, error) {
+				return "", ErrBridgeNotFound
+			},
+		}
+
+		_, err := client.GetAllLights()
+
+		require.Error(t, err)
+		assert.True(t, isConnectionFailure(err))
+		assert.Equal(t, staleURL, client.getBaseURL())
+	})
+
+	t.Run("does not re-discover without option", func(t *testing.T) {
+		staleURL, moved := newMovedBridge(t)
+		client := newTestClient(t, moved)
+		client.baseURL = staleURL
+
+		_, err := client.GetAllLights()
+
+		require.Error(t, err)
+		assert.Equal(t, staleURL, client.getBaseURL())
+	})
+
+	t.Run("successful request resets the failure count", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"data": []}`))
+		}))
+		defer server.Close()
+		client := newTestClient(t, server)
+		client.rediscovery = &rediscovery{threshold: 2}
+		client.rediscovery.failures.Store(1)
+
+		_, err := client.GetAllLights()
+
+		require.NoError(t, err)
+		assert.Zero(t, client.rediscovery.failures.Load())
+	})
+}
+
+func TestIsConnectionFailure(t *testing.T) {
+	assert.False(t, isConnectionFailure(errors.New("request failed")))
+	assert.False(t, isConnectionFailure(&StatusError{StatusCode: http.StatusNotFound}))
+}
@@ -1,9 +1,23 @@
 package hueclient
 
-import "fmt"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
 
 const APP_NAME = "hue-lighter"
 
+// Limits of the devicetype `<app name>#<device name>` accepted by the bridge.
+const (
+	MaxDeviceTypeLength = 40
+	MaxAppNameLength    = 20
+)
+
 type DeviceRegistrationRequest struct {
 	DeviceType        string `json:"devicetype"`
 	GenerateClientKey *bool  `json:"generateclientkey"`
@@ -33,22 +47,99 @@ func (r *DeviceRegistrationResponse) ToError() error {
 	return fmt.Errorf("type %d: %s", r.Error.Type, r.Error.Description)
 }
 
+// Retries of the registration request if it did not reach the bridge, the backoff doubles with every retry.
+const (
+	registrationRetries      = 2
+	registrationRetryBackoff = time.Second
+)
+
+// RegisterDeviceWithRetry registers the device like RegisterDevice and retries the request
+// up to registrationRetries times if it did not reach the bridge, see isDialFailure.
+// The registration is not idempotent: once the request was sent, the bridge may have
+// created the user even if the response was lost, so a closed connection or a server
+// error is returned without retrying, as is an error response of the bridge, e.g. the
+// link button not pressed. The wait before a retry ends when ctx is done.
+func (c *Client) RegisterDeviceWithRetry(ctx context.Context, name string) (*DeviceRegistrationResponse, error) {
+	backoff := c.registrationBackoff
+	if backoff <= 0 {
+		backoff = registrationRetryBackoff
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.RegisterDevice(name)
+		if err == nil || attempt == registrationRetries || !isDialFailure(err) {
+			return resp, err
+		}
+
+		c.logger.WithError(err).Warnf("Registration request did not reach the bridge, retrying in %s", backoff)
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("%w: %w", err, ctx.Err())
+		}
+		backoff *= 2
+	}
+}
+
+// isDialFailure reports whether the connection to the bridge could not be
+// established, i.e. the request was never sent.
+func isDialFailure(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// RegisterDevice makes a single registration request, see RegisterDeviceWithRetry.
 func (c *Client) RegisterDevice(name string) (*DeviceRegistrationResponse, error) {
+	deviceType, err := FormatDeviceType(c.appName, name)
+	if err != nil {
+		return nil, newOperationError(ErrPrefixRegisterDevice, "", err)
+	}
+
 	reqBody := DeviceRegistrationRequest{
-		DeviceType:        FormatDeviceType(name),
+		DeviceType:        deviceType,
 		GenerateClientKey: &[]bool{true}[0],
 	}
 
 	var resp []DeviceRegistrationResponse
-	err := c.doRequest("/api", "POST", reqBody, &resp)
+	err = c.doRequest("/api", "POST", reqBody, &resp)
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to register device: %w", err)
+		return nil, newOperationError(ErrPrefixRegisterDevice, "", err)
+	}
+
+	if len(resp) == 0 {
+		return nil, newOperationError(ErrPrefixRegisterDevice, "", errors.New("bridge returned an empty registration response"))
 	}
 
 	return &resp[0], nil
 }
 
-func FormatDeviceType(name string) string {
-	return fmt.Sprintf("%s#%s", APP_NAME, name)
+// FormatDeviceType builds the devicetype `<app name>#<device name>` used to register at
+// the bridge. An empty app name defaults to APP_NAME, the device name is truncated
+// so that the devicetype does not exceed MaxDeviceTypeLength.
+func FormatDeviceType(appName string, name string) (string, error) {
+	if appName == "" {
+		appName = APP_NAME
+	}
+
+	if utf8.RuneCountInString(appName) > MaxAppNameLength {
+		return "", fmt.Errorf("app name %q exceeds %d characters", appName, MaxAppNameLength)
+	}
+
+	if strings.Contains(appName, "#") {
+		return "", fmt.Errorf("app name %q must not contain '#'", appName)
+	}
+
+	if name == "" {
+		return "", errors.New("device name is required")
+	}
+
+	maxNameLength := MaxDeviceTypeLength - utf8.RuneCountInString(appName) - 1
+	if runes := []rune(name); len(runes) > maxNameLength {
+		name = string(runes[:maxNameLength])
+	}
+
+	return fmt.Sprintf("%s#%s", appName, name), nil
 }
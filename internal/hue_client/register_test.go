@@ -0,0 +1,289 @@
+package hueclient
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"com.github.yveskaufmann/hue-lighter/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_RegisterDevice(t *testing.T) {
+	tests := []struct {
+		name             string
+		response         interface{}
+		wantErr          bool
+		expectedErr      string
+		expectedUsername string
+		expectedHasError bool
+	}{
+		{
+			name: "returns registered credentials",
+			response: []map[string]interface{}{
+				{"success": map[string]interface{}{"username": "api-key", "clientkey": "client-key"}},
+			},
+			expectedUsername: "api-key",
+		},
+		{
+			name: "returns bridge error response",
+			response: []map[string]interface{}{
+				{"error": map[string]interface{}{"type": HueErrorTypeLinkButtonNotPressed, "address": "", "description": "link button not pressed"}},
+			},
+			expectedHasError: true,
+		},
+		{
+			name:        "fails on empty response",
+			response:    []map[string]interface{}{},
+			wantErr:     true,
+			expectedErr: ErrPrefixRegisterDevice + ": bridge returned an empty registration response",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := testutils.MockHueBridgeResponse(http.StatusOK, tt.response)
+			defer server.Close()
+
+			client := newTestClient(t, server)
+
+			var resp *DeviceRegistrationResponse
+			var err error
+			require.NotPanics(t, func() {
+				resp, err = client.RegisterDevice("test-device")
+			})
+
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Equal(t, tt.expectedErr, err.Error())
+				assert.Nil(t, resp)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedHasError, resp.HasError())
+			if tt.expectedUsername != "" {
+				assert.Equal(t, tt.expectedUsername, resp.Success.Username)
+			}
+		})
+	}
+}
+
+func TestFormatDeviceType(t *testing.T) {
+	tests := []struct {
+		name        string
+		appName     string
+		deviceName  string
+		expected    string
+		wantErr     bool
+		expectedErr string
+	}{
+		{
+			name:       "defaults app name",
+			deviceName: "office",
+			expected:   "hue-lighter#office",
+		},
+		{
+			name:       "uses configured app name",
+			appName:    "hue-lighter-desk",
+			deviceName: "office",
+			expected:   "hue-lighter-desk#office",
+		},
+		{
+			name:       "keeps devicetype at length limit",
+			appName:    "hue-lighter",
+			deviceName: "Hue Lighter Automation 12345",
+			expected:   "hue-lighter#Hue Lighter Automation 12345",
+		},
+		{
+			name:       "truncates device name to length limit",
+			appName:    "hue-lighter",
+			deviceName: "Hue Lighter Automation in the Office",
+			expected:   "hue-lighter#Hue Lighter Automation in th",
+		},
+		{
+			name:       "truncates device name by characters",
+			appName:    "abcdefghijklmnopqrst",
+			deviceName: "Büro Büro Büro Büro Büro",
+			expected:   "abcdefghijklmnopqrst#Büro Büro Büro Büro",
+		},
+		{
+			name:        "rejects too long app name",
+			appName:     "abcdefghijklmnopqrstu",
+			deviceName:  "office",
+			wantErr:     true,
+			expectedErr: `app name "abcdefghijklmnopqrstu" exceeds 20 characters`,
+		},
+		{
+			name:        "rejects app name with separator",
+			appName:     "hue#lighter",
+			deviceName:  "office",
+			wantErr:     true,
+			expectedErr: `app name "hue#lighter" must not contain '#'`,
+		},
+		{
+			name:        "rejects empty device name",
+			wantErr:     true,
+			expectedErr: "device name is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deviceType, err := FormatDeviceType(tt.appName, tt.deviceName)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Equal(t, tt.expectedErr, err.Error())
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, deviceType)
+			assert.LessOrEqual(t, utf8.RuneCountInString(deviceType), MaxDeviceTypeLength)
+		})
+	}
+}
+
+func TestClient_RegisterDevice_AppName(t *testing.T) {
+	server, recorder := testutils.MockHueBridgeRecorder(http.StatusOK, []map[string]interface{}{
+		{"success": map[string]interface{}{"username": "api-key"}},
+	})
+	defer server.Close()
+
+	client := newTestClient(t, server)
+	client.appName = "hue-lighter-desk"
+
+	_, err := client.RegisterDevice("office")
+
+	require.NoError(t, err)
+	requests := recorder.Requests()
+	require.Len(t, requests, 1)
+	assert.JSONEq(t, `{"devicetype":"hue-lighter-desk#office","generateclientkey":true}`, string(requests[0].Body))
+}
+
+func TestClient_RegisterDeviceWithRetry_Retries(t *testing.T) {
+	registered := `[{"success": {"username": "api-key", "clientkey": "client-key"}}]`
+
+	t.Run("retries when the bridge could not be reached", func(t *testing.T) {
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			w.Write([]byte(registered))
+		}))
+		defer server.Close()
+
+		client := newTestClient(t, server)
+		client.registrationBackoff = time.Millisecond
+		var dials atomic.Int32
+		transport := client.client.Transport
+		client.client.Transport = roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if dials.Add(1) == 1 {
+				return nil, &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+			}
+			return transport.RoundTrip(req)
+		})
+
+		resp, err := client.RegisterDeviceWithRetry(context.Background(), "test-device")
+
+		require.NoError(t, err)
+		assert.Equal(t, "api-key", resp.Success.Username)
+		assert.Equal(t, int32(1), requests.Load())
+	})
+
+	tests := []struct {
+		name        string
+		attempt     func(w http.ResponseWriter)
+		expectedErr string
+	}{
+		{
+			name: "does not retry after the connection was closed",
+			attempt: func(w http.ResponseWriter) {
+				if conn, _, err := w.(http.Hijacker).Hijack(); err == nil {
+					conn.Close()
+				}
+			},
+			expectedErr: "EOF",
+		},
+		{
+			name: "does not retry after a server error",
+			attempt: func(w http.ResponseWriter) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			},
+			expectedErr: "status code: 503",
+		},
+		{
+			name: "does not retry a client error",
+			attempt: func(w http.ResponseWriter) {
+				w.WriteHeader(http.StatusBadRequest)
+			},
+			expectedErr: "status code: 400",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// The bridge may have registered the user, a retry would register a second one
+				requests.Add(1)
+				tt.attempt(w)
+			}))
+			defer server.Close()
+
+			client := newTestClient(t, server)
+			client.registrationBackoff = time.Millisecond
+
+			_, err := client.RegisterDeviceWithRetry(context.Background(), "test-device")
+
+			assert.ErrorContains(t, err, tt.expectedErr)
+			assert.Equal(t, int32(1), requests.Load())
+		})
+	}
+
+	t.Run("gives up after the retries", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		defer server.Close()
+		client := newTestClient(t, server)
+		client.registrationBackoff = time.Millisecond
+		var dials atomic.Int32
+		client.client.Transport = refusingTransport(&dials)
+
+		_, err := client.RegisterDeviceWithRetry(context.Background(), "test-device")
+
+		assert.True(t, isDialFailure(err))
+		assert.Equal(t, int32(1+registrationRetries), dials.Load())
+	})
+
+	t.Run("stops waiting when the context is cancelled", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		defer server.Close()
+		client := newTestClient(t, server)
+		client.registrationBackoff = time.Hour
+		var dials atomic.Int32
+		client.client.Transport = refusingTransport(&dials)
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(10*time.Millisecond, cancel)
+
+		_, err := client.RegisterDeviceWithRetry(ctx, "test-device")
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.True(t, isDialFailure(err))
+		assert.Equal(t, int32(1), dials.Load())
+	})
+}
+
+// refusingTransport fails every request as if the bridge refused the connection.
+func refusingTransport(dials *atomic.Int32) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		dials.Add(1)
+		return nil, &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+	})
+}
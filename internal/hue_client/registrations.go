@@ -0,0 +1,97 @@
+package hueclient
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Registration is an application registered at the bridge, e.g. a device of this
+// app, the Hue app or another integration.
+type Registration struct {
+	// Username is the API key of the registration, it must not be logged
+	Username string `json:"-"`
+	// Name is the devicetype of the registration, `<app name>#<device name>`
+	Name     string    `json:"name"`
+	Created  time.Time `json:"created"`
+	LastUsed time.Time `json:"last_used"`
+}
+
+// whitelistConfig is the part of the v1 bridge config listing the registrations,
+// the CLIP v2 API does not expose them.
+type whitelistConfig struct {
+	Whitelist map[string]struct {
+		Name        string `json:"name"`
+		CreateDate  string `json:"create date"`
+		LastUseDate string `json:"last use date"`
+	} `json:"whitelist"`
+}
+
+// ListRegistrations returns the applications registered at the bridge sorted by
+// name and creation date.
+func (c *Client) ListRegistrations() ([]Registration, error) {
+	apiKey, err := c.apiKey()
+	if err != nil {
+		return nil, newOperationError(ErrPrefixListRegistrations, "", err)
+	}
+
+	var config whitelistConfig
+	if err := c.doRequest("api/"+apiKey+"/config", http.MethodGet, nil, &config); err != nil {
+		return nil, newOperationError(ErrPrefixListRegistrations, "", err)
+	}
+	if config.Whitelist == nil {
+		return nil, newOperationError(ErrPrefixListRegistrations, "", errors.New("bridge config contains no registrations, the API key may be unauthorized"))
+	}
+
+	registrations := []Registration{}
+	for username, entry := range config.Whitelist {
+		// Dates the bridge did not record are kept as zero time
+		created, _ := time.ParseInLocation(bridgeTimeLayout, entry.CreateDate, time.UTC)
+		lastUsed, _ := time.ParseInLocation(bridgeTimeLayout, entry.LastUseDate, time.UTC)
+		registrations = append(registrations, Registration{Username: username, Name: entry.Name, Created: created, LastUsed: lastUsed})
+	}
+
+	sort.Slice(registrations, func(i, j int) bool {
+		if registrations[i].Name != registrations[j].Name {
+			return registrations[i].Name < registrations[j].Name
+		}
+		return registrations[i].Created.Before(registrations[j].Created)
+	})
+	return registrations, nil
+}
+
+// RemoveRegistration deletes the registration from the bridge, its API key stops
+// working. Bridges with a recent firmware reject the removal through the local
+// API, the registration can then only be removed in the Hue account.
+func (c *Client) RemoveRegistration(registration Registration) error {
+	apiKey, err := c.apiKey()
+	if err != nil {
+		return newOperationError(ErrPrefixRemoveRegistration, registration.Name, err)
+	}
+	if registration.Username == apiKey {
+		return newOperationError(ErrPrefixRemoveRegistration, registration.Name, errors.New("the registration is used by this client"))
+	}
+
+	// The success of a deletion is a message instead of the changed attributes
+	var resp []struct {
+		Error *struct {
+			Type        int    `json:"type"`
+			Description string `json:"description"`
+		} `json:"error,omitempty"`
+	}
+	if err := c.doRequest("api/"+apiKey+"/config/whitelist/"+registration.Username, http.MethodDelete, nil, &resp); err != nil {
+		return newOperationError(ErrPrefixRemoveRegistration, registration.Name, err)
+	}
+	for _, item := range resp {
+		if item.Error != nil {
+			// The address contains the API key of the registration
+			description := strings.ReplaceAll(item.Error.Description, registration.Username, "<api key>")
+			return newOperationError(ErrPrefixRemoveRegistration, registration.Name, fmt.Errorf("type %d: %s", item.Error.Type, description))
+		}
+	}
+
+	return nil
+}
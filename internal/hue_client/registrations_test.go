@@ -0,0 +1,109 @@
+package hueclient
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"com.github.yveskaufmann/hue-lighter/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_ListRegistrations(t *testing.T) {
+	server, recorder := testutils.MockHueBridgeRecorder(http.StatusOK, map[string]interface{}{
+		"UTC": "2025-01-10T18:00:00",
+		"whitelist": map[string]interface{}{
+			"test-api-key": map[string]interface{}{
+				"name": "hue-lighter#test-device", "create date": "2024-03-01T10:00:00", "last use date": "2025-01-10T17:59:00",
+			},
+			"old-api-key": map[string]interface{}{
+				"name": "hue-lighter#test-device", "create date": "2023-05-01T10:00:00", "last use date": "2023-06-01T08:00:00",
+			},
+			"app-api-key": map[string]interface{}{
+				"name": "Hue 5#iPhone", "create date": "2022-01-01T00:00:00", "last use date": "",
+			},
+		},
+	})
+	defer server.Close()
+
+	registrations, err := newTestClient(t, server).ListRegistrations()
+
+	require.NoError(t, err)
+	assert.Equal(t, []Registration{
+		{Username: "app-api-key", Name: "Hue 5#iPhone", Created: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{Username: "old-api-key", Name: "hue-lighter#test-device", Created: time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC), LastUsed: time.Date(2023, 6, 1, 8, 0, 0, 0, time.UTC)},
+		{Username: "test-api-key", Name: "hue-lighter#test-device", Created: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC), LastUsed: time.Date(2025, 1, 10, 17, 59, 0, 0, time.UTC)},
+	}, registrations)
+	requests := recorder.Requests()
+	require.Len(t, requests, 1)
+	assert.Equal(t, http.MethodGet, requests[0].Method)
+	assert.Equal(t, "/api/test-api-key/config", requests[0].Path)
+}
+
+func TestClient_ListRegistrations_Unauthorized(t *testing.T) {
+	// An unauthorized key only gets the public part of the config
+	server := testutils.MockHueBridgeResponse(http.StatusOK, map[string]interface{}{"name": "Philips hue"})
+	defer server.Close()
+
+	_, err := newTestClient(t, server).ListRegistrations()
+
+	assert.EqualError(t, err, "hue: list registrations: bridge config contains no registrations, the API key may be unauthorized")
+}
+
+func TestClient_RemoveRegistration(t *testing.T) {
+	tests := []struct {
+		name         string
+		registration Registration
+		response     interface{}
+		wantRequest  bool
+		wantErr      bool
+		errMsg       string
+	}{
+		{
+			name:         "removes registration",
+			registration: Registration{Username: "old-api-key", Name: "hue-lighter#test-device"},
+			response:     []map[string]interface{}{{"success": "/config/whitelist/old-api-key deleted"}},
+			wantRequest:  true,
+		},
+		{
+			name:         "reports the rejection of the bridge without the API key",
+			registration: Registration{Username: "old-api-key", Name: "hue-lighter#test-device"},
+			response: []map[string]interface{}{{"error": map[string]interface{}{
+				"type": 1, "address": "/config/whitelist/old-api-key", "description": "unauthorized user old-api-key",
+			}}},
+			wantRequest: true,
+			wantErr:     true,
+			errMsg:      `hue: remove registration "hue-lighter#test-device": type 1: unauthorized user <api key>`,
+		},
+		{
+			name:         "refuses to remove the own registration",
+			registration: Registration{Username: "test-api-key", Name: "hue-lighter#test-device"},
+			wantErr:      true,
+			errMsg:       `hue: remove registration "hue-lighter#test-device": the registration is used by this client`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, recorder := testutils.MockHueBridgeRecorder(http.StatusOK, tt.response)
+			defer server.Close()
+
+			err := newTestClient(t, server).RemoveRegistration(tt.registration)
+
+			if tt.wantErr {
+				assert.EqualError(t, err, tt.errMsg)
+			} else {
+				assert.NoError(t, err)
+			}
+			requests := recorder.Requests()
+			if !tt.wantRequest {
+				assert.Empty(t, requests)
+				return
+			}
+			require.Len(t, requests, 1)
+			assert.Equal(t, http.MethodDelete, requests[0].Method)
+			assert.Equal(t, "/api/test-api-key/config/whitelist/old-api-key", requests[0].Path)
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package hueclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// DeviceRegistrar registers the device at the bridge and stores the new API key,
+// e.g. the RegisterDevice method of the device registration service.
+type DeviceRegistrar func() error
+
+// WithReregistration registers the device again once the bridge rejects the
+// stored API key, e.g. after the app was deleted in the Hue app. The stored key
+// is removed, register is called and the rejected request is retried with the
+// new key. A failed registration is not repeated, the requests fail as unauthorized.
+func WithReregistration(register DeviceRegistrar) ClientOption {
+	return func(o *clientOptions) {
+		o.reregistration = &reregistration{register: register}
+	}
+}
+
+type reregistration struct {
+	register DeviceRegistrar
+	// mu serializes the re-registration of concurrent requests
+	mu     sync.Mutex
+	failed bool
+}
+
+// isUnauthorized reports whether the bridge rejected apiKey, the key the request
+// at path was sent with. The CLIP v2 API answers with 401 or 403, the v1 API,
+// which carries the key in the path, answers with 200 and an error of type 1.
+func isUnauthorized(response *http.Response, path string, apiKey string) bool {
+	if response.StatusCode == http.StatusUnauthorized || response.StatusCode == http.StatusForbidden {
+		return true
+	}
+	if apiKey == "" || !isV1APIKeyPath(path, apiKey) || response.StatusCode != http.StatusOK {
+		return false
+	}
+	return hasV1UnauthorizedError(response)
+}
+
+// isV1APIKeyPath reports whether path is a v1 API path carrying apiKey, e.g. `api/<key>/lights`.
+func isV1APIKeyPath(path string, apiKey string) bool {
+	return path == "api/"+apiKey || strings.HasPrefix(path, "api/"+apiKey+"/")
+}
+
+// hasV1UnauthorizedError reports whether the v1 response contains an unauthorized
+// user error. The body is restored, so that the response can still be decoded.
+func hasV1UnauthorizedError(response *http.Response) bool {
+	body, err := io.ReadAll(response.Body)
+	response.Body.Close()
+	response.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+
+	var results []struct {
+		Error *struct {
+			Type int `json:"type"`
+		} `json:"error,omitempty"`
+	}
+	if err := json.Unmarshal(body, &results); err != nil {
+		return false
+	}
+	for _, result := range results {
+		if result.Error != nil && result.Error.Type == HueErrorTypeUnauthorizedUser {
+			return true
+		}
+	}
+	return false
+}
+
+// replaceV1APIKeyPath replaces the rejected API key in a v1 API path with the new
+// one, other paths are returned unchanged.
+func replaceV1APIKeyPath(path string, rejectedAPIKey string, apiKey string) string {
+	if rejectedAPIKey == "" || !isV1APIKeyPath(path, rejectedAPIKey) {
+		return path
+	}
+	return "api/" + apiKey + strings.TrimPrefix(path, "api/"+rejectedAPIKey)
+}
+
+// reregisterDevice replaces the rejected API key with the key of a new
+// registration. It reports whether the stored key changed, so that the rejected
+// request is worth retrying.
+func (c *Client) reregisterDevice(rejectedAPIKey string) bool {
+	r := c.reregistration
+	if r == nil || rejectedAPIKey == "" {
+		return false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Another request already registered the device again while this one waited.
+	if apiKey, _ := c.apiKey(); apiKey != "" && apiKey != rejectedAPIKey {
+		return true
+	}
+	if r.failed {
+		return false
+	}
+
+	c.logger.Warnf("Bridge %s rejected the API key of device %q, the app was probably removed from the bridge, registering again", c.bridgeID, c.deviceName)
+
+	if err := c.apiKeyStore.Remove(c.apiKeyIdentity()); err != nil {
+		c.logger.WithError(err).Warn("Failed to remove the rejected API key")
+		r.failed = true
+		return false
+	}
+
+	if err := r.register(); err != nil {
+		c.logger.WithError(err).Error("Failed to register the device again, register it manually and restart hue-lighter")
+		r.failed = true
+		return false
+	}
+
+	c.logger.Infof("Registered device %q at bridge %s again", c.deviceName, c.bridgeID)
+	return true
+}
+
+func (c *Client) apiKeyIdentity() string {
+	return fmt.Sprintf("%s#%s", c.bridgeID, c.deviceName)
+}
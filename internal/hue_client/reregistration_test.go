@@ -0,0 +1,121 @@
+package hueclient
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRevokingBridge returns a bridge which only accepts the given API key.
+func newRevokingBridge(t *testing.T, validAPIKey string) (*httptest.Server, *[]string) {
+	t.Helper()
+
+	var usedKeys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiKey := r.Header.Get("hue-application-key")
+		usedKeys = append(usedKeys, apiKey)
+		if apiKey != validAPIKey {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errors": [{"description": "unauthorized user"}]}`))
+			return
+		}
+		w.Write([]byte(`{"data": [{"id": "light-1"}]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &usedKeys
+}
+
+func TestClient_Reregistration(t *testing.T) {
+	t.Run("registers again and retries the request", func(t *testing.T) {
+		server, usedKeys := newRevokingBridge(t, "new-api-key")
+		client := newTestClient(t, server)
+		registrations := 0
+		client.reregistration = &reregistration{register: func() error {
+			registrations++
+			_, err := client.apiKey()
+			assert.ErrorIs(t, err, ErrMissingAPIKey, "rejected key is removed before registering")
+			return client.apiKeyStore.Set("bridge-123#test-device", "new-api-key")
+		}}
+
+		lights, err := client.GetAllLights()
+
+		require.NoError(t, err)
+		require.Len(t, lights.Data, 1)
+		assert.Equal(t, 1, registrations)
+		assert.Equal(t, []string{"test-api-key", "new-api-key"}, *usedKeys)
+	})
+
+	t.Run("does not register again after a failed registration", func(t *testing.T) {
+		server, _ := newRevokingBridge(t, "new-api-key")
+		client := newTestClient(t, server)
+		registrations := 0
+		client.reregistration = &reregistration{register: func() error {
+			registrations++
+			return errors.New("link button not pressed")
+		}}
+
+		_, err := client.GetAllLights()
+		var statusErr *StatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusForbidden, statusErr.StatusCode)
+
+		_, err = client.GetAllLights()
+		require.Error(t, err)
+		assert.Equal(t, 1, registrations)
+	})
+
+	t.Run("does not register again without option", func(t *testing.T) {
+		server, usedKeys := newRevokingBridge(t, "new-api-key")
+		client := newTestClient(t, server)
+
+		_, err := client.GetAllLights()
+
+		var statusErr *StatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusForbidden, statusErr.StatusCode)
+		assert.Equal(t, []string{"test-api-key"}, *usedKeys)
+		apiKey, err := client.apiKey()
+		require.NoError(t, err)
+		assert.Equal(t, "test-api-key", apiKey)
+	})
+}
+
+func TestClient_Reregistration_V1(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		// The v1 API reports a rejected API key with status 200
+		if r.URL.Path != "/api/new-api-key/lights/3/state" {
+			w.Write([]byte(`[{"error": {"type": 1, "address": "/lights/3/state", "description": "unauthorized user"}}]`))
+			return
+		}
+		w.Write([]byte(`[{"success": {"/lights/3/state/on": true}}]`))
+	}))
+	t.Cleanup(server.Close)
+
+	client := newTestClient(t, server)
+	client.lightAPI = LightAPIV1
+	registrations := 0
+	client.reregistration = &reregistration{register: func() error {
+		registrations++
+		return client.apiKeyStore.Set("bridge-123#test-device", "new-api-key")
+	}}
+
+	err := client.TurnOnLightById("3")
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, registrations)
+	assert.Equal(t, []string{"/api/test-api-key/lights/3/state", "/api/new-api-key/lights/3/state"}, paths)
+}
+
+func TestReplaceV1APIKeyPath(t *testing.T) {
+	assert.Equal(t, "api/new/lights/3/state", replaceV1APIKeyPath("api/old/lights/3/state", "old", "new"))
+	assert.Equal(t, "api/new", replaceV1APIKeyPath("api/old", "old", "new"))
+	assert.Equal(t, "api/0/config", replaceV1APIKeyPath("api/0/config", "old", "new"))
+	assert.Equal(t, "clip/v2/resource/light", replaceV1APIKeyPath("clip/v2/resource/light", "old", "new"))
+}
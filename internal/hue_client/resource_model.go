@@ -1,6 +1,10 @@
 package hueclient
 
 type ResourceIdentifier struct {
+	// RID is the id of the referenced resource
+	RID string `json:"rid,omitempty"`
+	// RType is the type of the referenced resource, e.g. light
+	RType  string `json:"rtype,omitempty"`
 	Action struct {
 		Identity string `json:"identity,omitempty"`
 	} `json:"action,omitempty"`
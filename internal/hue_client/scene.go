@@ -0,0 +1,50 @@
+package hueclient
+
+import (
+	"net/http"
+	"time"
+)
+
+// SceneRecallActive sets the lights of a scene to their scene state.
+const SceneRecallActive = "active"
+
+// SceneRecall is the body of a scene recall request.
+type SceneRecall struct {
+	Recall struct {
+		Action string `json:"action"`
+		// Duration of the transition in milliseconds, not set uses the default of the bridge
+		Duration *int `json:"duration,omitempty"`
+	} `json:"recall"`
+}
+
+// RecallSceneById sets the lights of the scene resource to their scene state,
+// transitioning within the given duration, zero uses the default of the bridge.
+func (c *Client) RecallSceneById(id string, duration time.Duration) error {
+	var recall SceneRecall
+	recall.Recall.Action = SceneRecallActive
+	if duration > 0 {
+		milliseconds := int(duration.Milliseconds())
+		recall.Recall.Duration = &milliseconds
+	}
+
+	var updateResp LightUpdateResponse
+	err := c.doRequest("clip/v2/resource/scene/"+id, http.MethodPut, &recall, &updateResp)
+	if err != nil {
+		return newOperationError(ErrPrefixRecallScene, id, err)
+	}
+
+	if err := updateResp.err(); err != nil {
+		return newOperationError(ErrPrefixRecallScene, id, err)
+	}
+
+	if err := c.verifyIdentity(id, updateResp.Data); err != nil {
+		return newOperationError(ErrPrefixRecallScene, id, err)
+	}
+
+	// The scene switches lights which may be cached.
+	if c.lightCache != nil {
+		c.lightCache.invalidateAll()
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package hueclient
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"com.github.yveskaufmann/hue-lighter/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_RecallSceneById(t *testing.T) {
+	tests := []struct {
+		name         string
+		duration     time.Duration
+		expectedBody string
+	}{
+		{
+			name:         "recalls scene with the default transition",
+			expectedBody: `{"recall":{"action":"active"}}`,
+		},
+		{
+			name:         "recalls scene with a transition",
+			duration:     2 * time.Second,
+			expectedBody: `{"recall":{"action":"active","duration":2000}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, recorder := testutils.MockHueBridgeRecorder(http.StatusOK, map[string]interface{}{
+				"data": []map[string]interface{}{{"rid": "scene-1", "rtype": "scene"}},
+			})
+			defer server.Close()
+
+			err := newTestClient(t, server).RecallSceneById("scene-1", tt.duration)
+
+			require.NoError(t, err)
+			requests := recorder.Requests()
+			require.Len(t, requests, 1)
+			assert.Equal(t, http.MethodPut, requests[0].Method)
+			assert.Equal(t, "/clip/v2/resource/scene/scene-1", requests[0].Path)
+			assert.JSONEq(t, tt.expectedBody, string(requests[0].Body))
+		})
+	}
+}
+
+func TestClient_RecallSceneById_Error(t *testing.T) {
+	server := testutils.MockHueBridgeResponse(200, map[string]interface{}{
+		"errors": []map[string]interface{}{{"description": "resource not found"}},
+	})
+	defer server.Close()
+
+	err := newTestClient(t, server).RecallSceneById("scene-1", 0)
+
+	assert.EqualError(t, err, `hue: recall scene "scene-1": resource not found`)
+}
@@ -0,0 +1,58 @@
+package hueclient
+
+import "net/http"
+
+// SmartSceneRecallAction is the action of a smart scene recall.
+type SmartSceneRecallAction string
+
+const (
+	// SmartSceneActivate starts the smart scene, the bridge then switches its
+	// lights through the time slots of the scene.
+	SmartSceneActivate SmartSceneRecallAction = "activate"
+	// SmartSceneDeactivate stops the smart scene, its lights keep their state.
+	SmartSceneDeactivate SmartSceneRecallAction = "deactivate"
+)
+
+// SmartSceneRecall is the body of a smart scene recall request.
+type SmartSceneRecall struct {
+	Recall struct {
+		Action SmartSceneRecallAction `json:"action"`
+	} `json:"recall"`
+}
+
+// RecallSmartSceneById activates the smart_scene resource, the time based
+// progression of its states is run by the bridge.
+func (c *Client) RecallSmartSceneById(id string) error {
+	return c.recallSmartScene(id, SmartSceneActivate)
+}
+
+// DeactivateSmartSceneById stops the smart_scene resource.
+func (c *Client) DeactivateSmartSceneById(id string) error {
+	return c.recallSmartScene(id, SmartSceneDeactivate)
+}
+
+func (c *Client) recallSmartScene(id string, action SmartSceneRecallAction) error {
+	var recall SmartSceneRecall
+	recall.Recall.Action = action
+
+	var updateResp LightUpdateResponse
+	err := c.doRequest("clip/v2/resource/smart_scene/"+id, http.MethodPut, &recall, &updateResp)
+	if err != nil {
+		return newOperationError(ErrPrefixRecallSmartScene, id, err)
+	}
+
+	if err := updateResp.err(); err != nil {
+		return newOperationError(ErrPrefixRecallSmartScene, id, err)
+	}
+
+	if err := c.verifyIdentity(id, updateResp.Data); err != nil {
+		return newOperationError(ErrPrefixRecallSmartScene, id, err)
+	}
+
+	// The scene switches lights which may be cached.
+	if c.lightCache != nil {
+		c.lightCache.invalidateAll()
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package hueclient
+
+import (
+	"net/http"
+	"testing"
+
+	"com.github.yveskaufmann/hue-lighter/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_RecallSmartSceneById(t *testing.T) {
+	tests := []struct {
+		name         string
+		recall       func(c *Client, id string) error
+		expectedBody string
+	}{
+		{
+			name:         "activates smart scene",
+			recall:       (*Client).RecallSmartSceneById,
+			expectedBody: `{"recall":{"action":"activate"}}`,
+		},
+		{
+			name:         "deactivates smart scene",
+			recall:       (*Client).DeactivateSmartSceneById,
+			expectedBody: `{"recall":{"action":"deactivate"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, recorder := testutils.MockHueBridgeRecorder(http.StatusOK, map[string]interface{}{
+				"data": []map[string]interface{}{{"rid": "scene-1", "rtype": "smart_scene"}},
+			})
+			defer server.Close()
+
+			err := tt.recall(newTestClient(t, server), "scene-1")
+
+			require.NoError(t, err)
+			requests := recorder.Requests()
+			require.Len(t, requests, 1)
+			assert.Equal(t, http.MethodPut, requests[0].Method)
+			assert.Equal(t, "/clip/v2/resource/smart_scene/scene-1", requests[0].Path)
+			assert.Equal(t, "test-api-key", requests[0].Header.Get("hue-application-key"))
+			assert.JSONEq(t, tt.expectedBody, string(requests[0].Body))
+		})
+	}
+}
+
+func TestClient_RecallSmartSceneById_Error(t *testing.T) {
+	server := testutils.MockHueBridgeResponse(200, map[string]interface{}{
+		"errors": []map[string]interface{}{{"description": "resource not found"}},
+	})
+	defer server.Close()
+
+	err := newTestClient(t, server).RecallSmartSceneById("scene-1")
+
+	assert.EqualError(t, err, `hue: recall smart scene "scene-1": resource not found`)
+}
@@ -5,6 +5,8 @@ import (
 	"crypto/x509"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 )
 
@@ -12,6 +14,38 @@ import (
 // It matches the signature required by tls.Config's VerifyPeerCertificate field.
 type VerifyPeerCertificate func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error
 
+// TLSOption configures optional behaviour of the bridge TLS config.
+type TLSOption func(*tlsOptions)
+
+type tlsOptions struct {
+	pin      *CertificatePin
+	insecure bool
+}
+
+// WithCertificatePin additionally verifies the bridge certificate against the given pin.
+func WithCertificatePin(pin *CertificatePin) TLSOption {
+	return func(o *tlsOptions) {
+		o.pin = pin
+	}
+}
+
+// WithInsecureSkipCAVerification skips the verification of the certificate chain against
+// the CA bundle, the bridge ID is still verified against the certificate CN/SAN.
+// This allows getting started without the Philips CA bundle but does not protect against
+// an attacker presenting a self-signed certificate for the bridge ID.
+func WithInsecureSkipCAVerification() TLSOption {
+	return func(o *tlsOptions) {
+		o.insecure = true
+	}
+}
+
+// InsecureTLSAllowed reports whether `HUE_ALLOW_INSECURE_TLS` opts into connecting
+// without CA verification if the CA bundle is missing.
+func InsecureTLSAllowed() bool {
+	allowed, err := strconv.ParseBool(strings.TrimSpace(os.Getenv("HUE_ALLOW_INSECURE_TLS")))
+	return err == nil && allowed
+}
+
 // NewBridgeTLSConfig creates a tls.Config for connecting to a Philips Hue bridge to
 // support accessing its API over HTTPS.
 //
@@ -20,66 +54,166 @@ type VerifyPeerCertificate func(rawCerts [][]byte, verifiedChains [][]*x509.Cert
 //
 // Parameters:
 //   - bridgeId: the expected bridge identifier (CN/SAN).
-//   - certPath: absolute path to the CA bundle PEM file.
-func NewBridgeTLSConfig(bridgeId string, certPath string) (*tls.Config, error) {
-	x509CertsBytes, err := os.ReadFile(certPath)
-	if err != nil {
-		return nil, fmt.Errorf("tlsConfig creation error: failed to read x509 certs from %s: %v", certPath, err)
+//   - certPath: CA bundle PEM file, directory of `.pem` files or a list of both
+//     separated by the OS path list separator (colon on Linux). It is ignored if
+//     `HUE_CA_CERTS_PEM` contains the CA bundle, see InlineCABundlePath.
+//   - opts: optional settings such as a certificate fingerprint pin.
+func NewBridgeTLSConfig(bridgeId string, certPath string, opts ...TLSOption) (*tls.Config, error) {
+	options := tlsOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	// Philips Hue API is providing the bridge ID in uppercase, but within certificates it is lowercased.
+	bridgeId = strings.ToLower(bridgeId)
+
+	if options.insecure {
+		return &tls.Config{
+			InsecureSkipVerify:    true,
+			ServerName:            bridgeId,
+			VerifyPeerCertificate: createCustomCertVerifier(bridgeId, nil, options.pin),
+		}, nil
 	}
 
 	caCertPool, err := x509.SystemCertPool()
 	if err != nil {
-		return nil, fmt.Errorf("tlsConfig creation error: failed to get system cert pool: %v", err)
+		return nil, fmt.Errorf("tlsConfig creation error: failed to get system cert pool: %w", err)
 	}
 
-	if ok := caCertPool.AppendCertsFromPEM(x509CertsBytes); !ok {
-		return nil, fmt.Errorf("tlsConfig creation error: failed to append x509 certs to cert pool")
+	if inlineBundle := inlineCABundle(); inlineBundle != nil {
+		if ok := caCertPool.AppendCertsFromPEM(inlineBundle); !ok {
+			return nil, fmt.Errorf("tlsConfig creation error: failed to append x509 certs from %s to cert pool", InlineCABundlePath)
+		}
+		return newBridgeTLSConfig(bridgeId, caCertPool, options.pin), nil
 	}
 
-	// Philips Hue API is providing the bridge ID in uppercase, but within certificates it is lowercased.
-	bridgeId = strings.ToLower(bridgeId)
+	bundleFiles, err := expandCABundlePaths(certPath)
+	if err != nil {
+		return nil, fmt.Errorf("tlsConfig creation error: %w", err)
+	}
 
-	config := &tls.Config{
+	for _, bundleFile := range bundleFiles {
+		x509CertsBytes, err := os.ReadFile(bundleFile)
+		if err != nil {
+			return nil, fmt.Errorf("tlsConfig creation error: failed to read x509 certs from %s: %w", bundleFile, err)
+		}
+
+		if ok := caCertPool.AppendCertsFromPEM(x509CertsBytes); !ok {
+			return nil, fmt.Errorf("tlsConfig creation error: failed to append x509 certs from %s to cert pool", bundleFile)
+		}
+	}
+
+	return newBridgeTLSConfig(bridgeId, caCertPool, options.pin), nil
+}
+
+// newBridgeTLSConfig verifies the bridge certificate against the given CA pool.
+func newBridgeTLSConfig(bridgeId string, caCertPool *x509.CertPool, pin *CertificatePin) *tls.Config {
+	return &tls.Config{
 		// Standard verification must be disabled here; otherwise, our custom verification logic will not be used.
 		InsecureSkipVerify:    true,
 		RootCAs:               caCertPool,
 		ServerName:            bridgeId,
-		VerifyPeerCertificate: createCustomCertVerifier(bridgeId, caCertPool),
+		VerifyPeerCertificate: createCustomCertVerifier(bridgeId, caCertPool, pin),
 	}
+}
+
+// InlineCABundlePath is returned by ResolveCABundlePath if `HUE_CA_CERTS_PEM`
+// contains the PEM encoded CA bundle, e.g. when the bundle is passed as a secret
+// in container deployments instead of a file.
+const InlineCABundlePath = "$HUE_CA_CERTS_PEM"
 
-	return config, nil
+// inlineCABundle returns the CA bundle of `HUE_CA_CERTS_PEM`, nil if it is not set.
+func inlineCABundle() []byte {
+	bundle := strings.TrimSpace(os.Getenv("HUE_CA_CERTS_PEM"))
+	if bundle == "" {
+		return nil
+	}
+	return []byte(bundle)
 }
 
-// ResolveCABundlePath resolves the CA bundle path using `HUE_CA_CERTS_PATH`
-// or the default installed location and verifies that the file exists.
+// DefaultCABundlePath is the CA bundle location of the installed service.
+const DefaultCABundlePath = "/etc/hue-lighter/cacert_bundle.pem"
+
+// ResolveCABundlePath resolves the CA bundle path using `HUE_CA_CERTS_PATH`,
+// the given default path or DefaultCABundlePath, in that order, and verifies
+// that every listed file or directory exists. `HUE_CA_CERTS_PATH` may contain a colon-separated list of
+// files and directories, which allows trusting old and new CAs during a rotation.
+// If `HUE_CA_CERTS_PEM` is set, it takes precedence and InlineCABundlePath is returned
+// once it contains at least one certificate.
 // Returned path may be used by build/install processes or for logging.
-func ResolveCABundlePath() (string, error) {
+func ResolveCABundlePath(defaultPath string) (string, error) {
+	if inlineBundle := inlineCABundle(); inlineBundle != nil {
+		if ok := x509.NewCertPool().AppendCertsFromPEM(inlineBundle); !ok {
+			return "", fmt.Errorf("%s contains no PEM encoded certificate", InlineCABundlePath)
+		}
+		return InlineCABundlePath, nil
+	}
+
 	certPath := os.Getenv("HUE_CA_CERTS_PATH")
 	if certPath == "" {
-		certPath = "/etc/hue-lighter/cacert_bundle.pem"
-	}
-
-	if _, err := os.Stat(certPath); err != nil {
-		if os.IsNotExist(err) {
-			return "", fmt.Errorf(
-				"CA bundle not found at %s. Obtain the Philips Hue CA bundle from "+
-					"https://developers.meethue.com/develop/application-design-guidance/using-https/ "+
-					"and place it at configs/certs/cacert_bundle.pem (for building) or "+
-					"/etc/hue-lighter/cacert_bundle.pem (for installed service), see README.md "+
-					"for instructions",
-				certPath,
-			)
+		certPath = defaultPath
+	}
+	if certPath == "" {
+		certPath = DefaultCABundlePath
+	}
+
+	for _, path := range filepath.SplitList(certPath) {
+		if _, err := os.Stat(path); err != nil {
+			if os.IsNotExist(err) {
+				return "", fmt.Errorf(
+					"CA bundle not found at %s. Obtain the Philips Hue CA bundle from "+
+						"https://developers.meethue.com/develop/application-design-guidance/using-https/ "+
+						"and place it at configs/certs/cacert_bundle.pem (for building) or "+
+						"/etc/hue-lighter/cacert_bundle.pem (for installed service), see README.md "+
+						"for instructions",
+					path,
+				)
+			}
+			return "", fmt.Errorf("failed to access CA bundle %s: %w", path, err)
 		}
-		return "", fmt.Errorf("failed to access CA bundle %s: %v", certPath, err)
 	}
 
 	return certPath, nil
 }
 
+// expandCABundlePaths splits the given path list and replaces every directory
+// with the `.pem` files it contains.
+func expandCABundlePaths(certPath string) ([]string, error) {
+	paths := filepath.SplitList(certPath)
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no CA bundle path provided")
+	}
+
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read x509 certs from %s: %w", path, err)
+		}
+
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		pemFiles, err := filepath.Glob(filepath.Join(path, "*.pem"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list x509 certs in %s: %w", path, err)
+		}
+		if len(pemFiles) == 0 {
+			return nil, fmt.Errorf("no .pem files found in CA bundle directory %s", path)
+		}
+		files = append(files, pemFiles...)
+	}
+
+	return files, nil
+}
+
 // createCustomCertVerifier returns VerifyPeerCertificate function that validates
 // the server certificate against the provided root CAs and allows CN fallback
-// if SAN is missing.
-func createCustomCertVerifier(expectedServerName string, rootCAs *x509.CertPool) VerifyPeerCertificate {
+// if SAN is missing. If pin is not nil, the certificate fingerprint is verified as well.
+// The chain verification is skipped if rootCAs is nil, see WithInsecureSkipCAVerification.
+func createCustomCertVerifier(expectedServerName string, rootCAs *x509.CertPool, pin *CertificatePin) VerifyPeerCertificate {
 	// The cert provided by the Hue Bridge uses a self-signed certificate and
 	// is missing proper SAN entries. They are signed with CN set to the bridge ID only.
 	// However, Go's TLS library requires SAN to be set for hostname verification - every certificate
@@ -104,21 +238,18 @@ func createCustomCertVerifier(expectedServerName string, rootCAs *x509.CertPool)
 
 		cert, err := x509.ParseCertificate(rawCerts[0])
 		if err != nil {
-			return fmt.Errorf("failed to parse server certificate: %v", err)
+			return fmt.Errorf("failed to parse server certificate: %w", err)
 		}
 
 		// Validate the chain
-		opts := x509.VerifyOptions{
-			Roots:         rootCAs,
-			Intermediates: x509.NewCertPool(),
-		}
-		for _, ic := range rawCerts[1:] {
-			if c, err := x509.ParseCertificate(ic); err == nil {
-				opts.Intermediates.AddCert(c)
+		if rootCAs != nil {
+			opts := x509.VerifyOptions{
+				Roots:         rootCAs,
+				Intermediates: parseIntermediates(rawCerts[1:]),
+			}
+			if _, err := cert.Verify(opts); err != nil {
+				return fmt.Errorf("certificate verification failed: %w", err)
 			}
-		}
-		if _, err := cert.Verify(opts); err != nil {
-			return fmt.Errorf("certificate verification failed: %v", err)
 		}
 
 		if len(cert.DNSNames) > 0 {
@@ -130,15 +261,35 @@ func createCustomCertVerifier(expectedServerName string, rootCAs *x509.CertPool)
 				}
 			}
 			if !found {
-				return fmt.Errorf("server name %s not found in certificate SANs", expectedServerName)
+				return fmt.Errorf("%w: server name %s not found in certificate SANs", ErrBridgeCertificateMismatch, expectedServerName)
 			}
 		} else if cert.Subject.CommonName != "" {
 			if cert.Subject.CommonName != expectedServerName {
-				return fmt.Errorf("server certificate CN %s does not match expected %s", cert.Subject.CommonName, expectedServerName)
+				return fmt.Errorf("%w: server certificate CN %s does not match expected %s", ErrBridgeCertificateMismatch, cert.Subject.CommonName, expectedServerName)
 			}
 		} else {
-			return fmt.Errorf("certificate has neither SANs nor CN for hostname verification")
+			return fmt.Errorf("%w: certificate has neither SANs nor CN for hostname verification", ErrBridgeCertificateMismatch)
+		}
+
+		if pin != nil {
+			return pin.verify(cert)
 		}
 		return nil
 	}
 }
+
+// parseIntermediates builds a cert pool from the intermediate certificates presented
+// by the peer. Certificates which fail to parse are skipped rather than failing the
+// handshake, if one of them was actually required the chain verification will reject
+// the leaf anyway.
+func parseIntermediates(rawCerts [][]byte) *x509.CertPool {
+	intermediates := x509.NewCertPool()
+	for _, rawCert := range rawCerts {
+		cert, err := x509.ParseCertificate(rawCert)
+		if err != nil {
+			continue
+		}
+		intermediates.AddCert(cert)
+	}
+	return intermediates
+}
@@ -0,0 +1,390 @@
+package hueclient
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"com.github.yveskaufmann/hue-lighter/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	raw  []byte
+}
+
+// newTestCert creates a certificate with the given common name. When parent is nil
+// the certificate is self-signed, otherwise it is signed by the parent.
+func newTestCert(t *testing.T, commonName string, isCA bool, parent *testCert) *testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+	if isCA {
+		template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+	} else {
+		template.KeyUsage = x509.KeyUsageDigitalSignature
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	}
+
+	signerCert, signerKey := template, key
+	if parent != nil {
+		signerCert, signerKey = parent.cert, parent.key
+	}
+
+	raw, err := x509.CreateCertificate(rand.Reader, template, signerCert, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(raw)
+	require.NoError(t, err)
+
+	return &testCert{cert: cert, key: key, raw: raw}
+}
+
+// writeTestCertPEM writes the PEM encoded certificate to dir/name and returns the path.
+func writeTestCertPEM(t *testing.T, dir string, name string, cert *testCert) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.raw})
+	require.NoError(t, os.WriteFile(path, data, 0644))
+	return path
+}
+
+func TestCreateCustomCertVerifier_IntermediateChain(t *testing.T) {
+	const bridgeID = "ecb5fafffe123456"
+
+	root := newTestCert(t, "Test Root CA", true, nil)
+	intermediate := newTestCert(t, "Test Intermediate CA", true, root)
+	leaf := newTestCert(t, bridgeID, false, intermediate)
+
+	untrustedRoot := newTestCert(t, "Untrusted Root CA", true, nil)
+	untrustedIntermediate := newTestCert(t, "Untrusted Intermediate CA", true, untrustedRoot)
+	untrustedLeaf := newTestCert(t, bridgeID, false, untrustedIntermediate)
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(root.cert)
+
+	tests := []struct {
+		name     string
+		rawCerts [][]byte
+		wantErr  string
+	}{
+		{
+			name:     "accepts leaf signed by intermediate of trusted root",
+			rawCerts: [][]byte{leaf.raw, intermediate.raw},
+		},
+		{
+			name:     "skips intermediates which fail to parse",
+			rawCerts: [][]byte{leaf.raw, []byte("not a certificate"), intermediate.raw},
+		},
+		{
+			name:     "rejects leaf when intermediate is missing",
+			rawCerts: [][]byte{leaf.raw},
+			wantErr:  "certificate verification failed",
+		},
+		{
+			name:     "rejects leaf when only intermediate is unparsable",
+			rawCerts: [][]byte{leaf.raw, []byte("not a certificate")},
+			wantErr:  "certificate verification failed",
+		},
+		{
+			name:     "rejects chain of untrusted root",
+			rawCerts: [][]byte{untrustedLeaf.raw, untrustedIntermediate.raw},
+			wantErr:  "certificate verification failed",
+		},
+		{
+			name:     "rejects unparsable leaf",
+			rawCerts: [][]byte{[]byte("not a certificate"), intermediate.raw},
+			wantErr:  "failed to parse server certificate",
+		},
+		{
+			name:     "rejects empty chain",
+			rawCerts: nil,
+			wantErr:  "no server certificate provided",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verify := createCustomCertVerifier(bridgeID, rootCAs, nil)
+
+			err := verify(tt.rawCerts, nil)
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCreateCustomCertVerifier_RejectsWrongBridgeID(t *testing.T) {
+	root := newTestCert(t, "Test Root CA", true, nil)
+	intermediate := newTestCert(t, "Test Intermediate CA", true, root)
+	leaf := newTestCert(t, "ecb5fafffe000000", false, intermediate)
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(root.cert)
+
+	verify := createCustomCertVerifier("ecb5fafffe123456", rootCAs, nil)
+	err := verify([][]byte{leaf.raw, intermediate.raw}, nil)
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrBridgeCertificateMismatch)
+	assert.Contains(t, err.Error(), "does not match expected")
+}
+
+func TestNewBridgeTLSConfig_CABundleDirectory(t *testing.T) {
+	const bridgeID = "ECB5FAFFFE123456"
+
+	oldRoot := newTestCert(t, "Old Root CA", true, nil)
+	newRoot := newTestCert(t, "New Root CA", true, nil)
+	oldLeaf := newTestCert(t, "ecb5fafffe123456", false, oldRoot)
+	newLeaf := newTestCert(t, "ecb5fafffe123456", false, newRoot)
+	untrustedLeaf := newTestCert(t, "ecb5fafffe123456", false, newTestCert(t, "Untrusted Root CA", true, nil))
+
+	bundleDir := t.TempDir()
+	writeTestCertPEM(t, bundleDir, "old.pem", oldRoot)
+	writeTestCertPEM(t, bundleDir, "new.pem", newRoot)
+	require.NoError(t, os.WriteFile(filepath.Join(bundleDir, "README.txt"), []byte("ignored"), 0644))
+
+	tlsConfig, err := NewBridgeTLSConfig(bridgeID, bundleDir)
+	require.NoError(t, err)
+
+	assert.NoError(t, tlsConfig.VerifyPeerCertificate([][]byte{oldLeaf.raw}, nil))
+	assert.NoError(t, tlsConfig.VerifyPeerCertificate([][]byte{newLeaf.raw}, nil))
+	assert.Error(t, tlsConfig.VerifyPeerCertificate([][]byte{untrustedLeaf.raw}, nil))
+}
+
+func TestNewBridgeTLSConfig_CABundleList(t *testing.T) {
+	oldRoot := newTestCert(t, "Old Root CA", true, nil)
+	newRoot := newTestCert(t, "New Root CA", true, nil)
+	oldLeaf := newTestCert(t, "ecb5fafffe123456", false, oldRoot)
+	newLeaf := newTestCert(t, "ecb5fafffe123456", false, newRoot)
+
+	oldPath := writeTestCertPEM(t, t.TempDir(), "old.pem", oldRoot)
+	newDir := t.TempDir()
+	writeTestCertPEM(t, newDir, "new.pem", newRoot)
+
+	tlsConfig, err := NewBridgeTLSConfig("ecb5fafffe123456", oldPath+string(filepath.ListSeparator)+newDir)
+	require.NoError(t, err)
+
+	assert.NoError(t, tlsConfig.VerifyPeerCertificate([][]byte{oldLeaf.raw}, nil))
+	assert.NoError(t, tlsConfig.VerifyPeerCertificate([][]byte{newLeaf.raw}, nil))
+}
+
+func TestNewBridgeTLSConfig_EmptyCABundleDirectory(t *testing.T) {
+	_, err := NewBridgeTLSConfig("ecb5fafffe123456", t.TempDir())
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no .pem files found")
+}
+
+func TestNewBridgeTLSConfig_InlineCABundle(t *testing.T) {
+	oldRoot := newTestCert(t, "Old Root CA", true, nil)
+	newRoot := newTestCert(t, "New Root CA", true, nil)
+	oldLeaf := newTestCert(t, "ecb5fafffe123456", false, oldRoot)
+	newLeaf := newTestCert(t, "ecb5fafffe123456", false, newRoot)
+	untrustedLeaf := newTestCert(t, "ecb5fafffe123456", false, newTestCert(t, "Untrusted Root CA", true, nil))
+
+	bundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: oldRoot.raw})
+	bundle = append(bundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: newRoot.raw})...)
+	defer testutils.SetEnv(t, "HUE_CA_CERTS_PEM", "\n"+string(bundle))()
+
+	// The inline bundle takes precedence, the missing file is not read.
+	tlsConfig, err := NewBridgeTLSConfig("ECB5FAFFFE123456", "/nonexistent/bundle.pem")
+	require.NoError(t, err)
+
+	assert.NoError(t, tlsConfig.VerifyPeerCertificate([][]byte{oldLeaf.raw}, nil))
+	assert.NoError(t, tlsConfig.VerifyPeerCertificate([][]byte{newLeaf.raw}, nil))
+	assert.Error(t, tlsConfig.VerifyPeerCertificate([][]byte{untrustedLeaf.raw}, nil))
+}
+
+func TestNewBridgeTLSConfig_InvalidInlineCABundle(t *testing.T) {
+	defer testutils.SetEnv(t, "HUE_CA_CERTS_PEM", "not a certificate")()
+
+	_, err := NewBridgeTLSConfig("ecb5fafffe123456", "")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to append x509 certs from $HUE_CA_CERTS_PEM")
+}
+
+func TestResolveCABundlePath_InlineCABundle(t *testing.T) {
+	bundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: newTestCert(t, "Root CA", true, nil).raw})
+
+	t.Run("takes precedence over the bundle path", func(t *testing.T) {
+		defer testutils.SetEnv(t, "HUE_CA_CERTS_PEM", string(bundle))()
+		defer testutils.SetEnv(t, "HUE_CA_CERTS_PATH", "/nonexistent/bundle.pem")()
+
+		path, err := ResolveCABundlePath("")
+
+		require.NoError(t, err)
+		assert.Equal(t, InlineCABundlePath, path)
+	})
+
+	t.Run("fails without certificate", func(t *testing.T) {
+		defer testutils.SetEnv(t, "HUE_CA_CERTS_PEM", "-----BEGIN CERTIFICATE-----")()
+
+		_, err := ResolveCABundlePath("")
+
+		assert.EqualError(t, err, "$HUE_CA_CERTS_PEM contains no PEM encoded certificate")
+	})
+}
+
+func TestResolveCABundlePath(t *testing.T) {
+	existingFile := writeTestCertPEM(t, t.TempDir(), "bundle.pem", newTestCert(t, "Root CA", true, nil))
+	existingDir := t.TempDir()
+	sep := string(filepath.ListSeparator)
+
+	tests := []struct {
+		name         string
+		envPath      string
+		defaultPath  string
+		expectedPath string
+		wantErr      string
+	}{
+		{
+			name:    "resolves single file",
+			envPath: existingFile,
+		},
+		{
+			name:         "resolves default path without environment",
+			defaultPath:  existingFile,
+			expectedPath: existingFile,
+		},
+		{
+			name:         "environment takes precedence over default path",
+			envPath:      existingFile,
+			defaultPath:  "/nonexistent/bundle.pem",
+			expectedPath: existingFile,
+		},
+		{
+			name:    "resolves file and directory list",
+			envPath: existingFile + sep + existingDir,
+		},
+		{
+			name:    "fails when one list entry is missing",
+			envPath: existingFile + sep + "/nonexistent/bundle.pem",
+			wantErr: "CA bundle not found at /nonexistent/bundle.pem",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer testutils.SetEnv(t, "HUE_CA_CERTS_PATH", tt.envPath)()
+
+			path, err := ResolveCABundlePath(tt.defaultPath)
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			} else {
+				require.NoError(t, err)
+				expectedPath := tt.expectedPath
+				if expectedPath == "" {
+					expectedPath = tt.envPath
+				}
+				assert.Equal(t, expectedPath, path)
+			}
+		})
+	}
+}
+
+func TestNewBridgeTLSConfig_InsecureSkipCAVerification(t *testing.T) {
+	const bridgeID = "ECB5FAFFFE123456"
+
+	selfSignedLeaf := newTestCert(t, "ecb5fafffe123456", false, newTestCert(t, "Unknown Root CA", true, nil))
+	otherBridgeLeaf := newTestCert(t, "ecb5fafffe000000", false, newTestCert(t, "Unknown Root CA", true, nil))
+
+	t.Run("secure default rejects certificate of unknown CA", func(t *testing.T) {
+		caBundlePath := writeTestCertPEM(t, t.TempDir(), "ca.pem", newTestCert(t, "Test Root CA", true, nil))
+
+		tlsConfig, err := NewBridgeTLSConfig(bridgeID, caBundlePath)
+		require.NoError(t, err)
+
+		err = tlsConfig.VerifyPeerCertificate([][]byte{selfSignedLeaf.raw}, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "certificate verification failed")
+	})
+
+	t.Run("secure default requires CA bundle", func(t *testing.T) {
+		_, err := NewBridgeTLSConfig(bridgeID, "/nonexistent/ca-bundle.pem")
+
+		require.Error(t, err)
+	})
+
+	t.Run("insecure mode accepts certificate of unknown CA without CA bundle", func(t *testing.T) {
+		tlsConfig, err := NewBridgeTLSConfig(bridgeID, "", WithInsecureSkipCAVerification())
+		require.NoError(t, err)
+
+		assert.NoError(t, tlsConfig.VerifyPeerCertificate([][]byte{selfSignedLeaf.raw}, nil))
+	})
+
+	t.Run("insecure mode still verifies bridge ID", func(t *testing.T) {
+		tlsConfig, err := NewBridgeTLSConfig(bridgeID, "", WithInsecureSkipCAVerification())
+		require.NoError(t, err)
+
+		err = tlsConfig.VerifyPeerCertificate([][]byte{otherBridgeLeaf.raw}, nil)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrBridgeCertificateMismatch)
+		assert.Contains(t, err.Error(), "does not match expected")
+	})
+
+	t.Run("insecure mode still verifies certificate pin", func(t *testing.T) {
+		pin, err := NewCertificatePin(CertificateFingerprint(otherBridgeLeaf.cert), nil)
+		require.NoError(t, err)
+
+		tlsConfig, err := NewBridgeTLSConfig(bridgeID, "", WithInsecureSkipCAVerification(), WithCertificatePin(pin))
+		require.NoError(t, err)
+
+		assert.Error(t, tlsConfig.VerifyPeerCertificate([][]byte{selfSignedLeaf.raw}, nil))
+	})
+}
+
+func TestInsecureTLSAllowed(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected bool
+	}{
+		{name: "disabled when not set", value: "", expected: false},
+		{name: "enabled by true", value: "true", expected: true},
+		{name: "enabled by 1", value: "1", expected: true},
+		{name: "disabled by false", value: "false", expected: false},
+		{name: "disabled by invalid value", value: "yes please", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer testutils.SetEnv(t, "HUE_ALLOW_INSECURE_TLS", tt.value)()
+
+			assert.Equal(t, tt.expected, InsecureTLSAllowed())
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package hueclient
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// RequestMetrics receives the outcome of every request sent to the bridge or the
+// discovery endpoint, status is 0 if the request failed without response.
+type RequestMetrics interface {
+	ObserveRequest(method string, path string, status int, elapsed time.Duration, err error)
+}
+
+// instrumentedTransport is a http.RoundTripper which sets the User-Agent, logs and
+// times every request and reports it to the metrics, so that the cross-cutting
+// concerns stay out of the request methods of the client and the discovery.
+type instrumentedTransport struct {
+	next      http.RoundTripper
+	logger    *log.Entry
+	userAgent string
+	metrics   RequestMetrics
+	// subject names the requests in the log, e.g. "Bridge request"
+	subject string
+}
+
+type attemptKey struct{}
+
+// withAttempt numbers the attempts of a request in the log, e.g. the retry after
+// the bridge was re-discovered.
+func withAttempt(ctx context.Context, attempt int) context.Context {
+	return context.WithValue(ctx, attemptKey{}, attempt)
+}
+
+// attemptOf returns the attempt of the request, 1 if it was not numbered.
+func attemptOf(ctx context.Context) int {
+	if attempt, ok := ctx.Value(attemptKey{}).(int); ok {
+		return attempt
+	}
+	return 1
+}
+
+func newInstrumentedTransport(next http.RoundTripper, logger *log.Entry, userAgent string, metrics RequestMetrics, subject string) *instrumentedTransport {
+	return &instrumentedTransport{
+		next:      next,
+		logger:    logger,
+		userAgent: userAgent,
+		metrics:   metrics,
+		subject:   subject,
+	}
+}
+
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A RoundTripper must not modify the request of the caller.
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", userAgentOrDefault(t.userAgent))
+
+	// The path of v1 requests contains the API key.
+	path := "/" + redactAPIKeyPath(strings.TrimPrefix(req.URL.Path, "/"))
+	requestLogger := t.logger.WithFields(log.Fields{
+		"method":  req.Method,
+		"path":    path,
+		"attempt": attemptOf(req.Context()),
+	})
+	requestLogger.Debugf("Making %s request to %s://%s%s", req.Method, req.URL.Scheme, req.URL.Host, path)
+
+	// Each call is timed on its own, so that retries of a request are logged separately.
+	start := time.Now()
+	response, err := t.next.RoundTrip(req)
+	elapsed := time.Since(start)
+
+	requestLogger = requestLogger.WithField("elapsed", elapsed)
+
+	status := 0
+	if err != nil {
+		requestLogger.WithError(err).Debug(t.subject + " failed")
+	} else {
+		status = response.StatusCode
+		requestLogger.WithField("status", status).Debug(t.subject + " completed")
+	}
+
+	if t.metrics != nil {
+		t.metrics.ObserveRequest(req.Method, path, status, elapsed, err)
+	}
+
+	return response, err
+}
@@ -0,0 +1,141 @@
+package hueclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"com.github.yveskaufmann/hue-lighter/internal/testutils"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type observedRequest struct {
+	method string
+	path   string
+	status int
+	err    error
+}
+
+type fakeRequestMetrics struct {
+	mu       sync.Mutex
+	observed []observedRequest
+}
+
+func (f *fakeRequestMetrics) ObserveRequest(method string, path string, status int, elapsed time.Duration, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.observed = append(f.observed, observedRequest{method: method, path: path, status: status, err: err})
+}
+
+func (f *fakeRequestMetrics) Observed() []observedRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]observedRequest(nil), f.observed...)
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestInstrumentedTransport_RoundTrip(t *testing.T) {
+	t.Run("sets user agent, logs and records the request", func(t *testing.T) {
+		server, recorder := testutils.MockHueBridgeRecorder(http.StatusOK, map[string]interface{}{"data": []interface{}{}})
+		defer server.Close()
+
+		hookLogger, hook := test.NewNullLogger()
+		hookLogger.SetLevel(logrus.DebugLevel)
+		metrics := &fakeRequestMetrics{}
+		transport := newInstrumentedTransport(server.Client().Transport, logrus.NewEntry(hookLogger), "my-app/1.0", metrics, "Test request")
+
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/api/secret-key/config", nil)
+		require.NoError(t, err)
+		resp, err := (&http.Client{Transport: transport}).Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		require.Len(t, recorder.Requests(), 1)
+		assert.Equal(t, "my-app/1.0", recorder.Requests()[0].Header.Get("User-Agent"))
+		assert.Empty(t, req.Header.Get("User-Agent"), "request of the caller must not be modified")
+
+		assert.Equal(t, []observedRequest{{method: http.MethodGet, path: "/api/[REDACTED]/config", status: http.StatusOK}}, metrics.Observed())
+
+		entry := hook.LastEntry()
+		require.NotNil(t, entry)
+		assert.Equal(t, "Test request completed", entry.Message)
+		assert.Equal(t, "/api/[REDACTED]/config", entry.Data["path"])
+		assert.Equal(t, http.StatusOK, entry.Data["status"])
+		for _, e := range hook.AllEntries() {
+			assert.NotContains(t, e.Message, "secret-key")
+		}
+	})
+
+	t.Run("records failed request without status", func(t *testing.T) {
+		metrics := &fakeRequestMetrics{}
+		failure := errors.New("connection refused")
+		transport := newInstrumentedTransport(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			return nil, failure
+		}), logrus.NewEntry(logrus.New()), "", metrics, "Test request")
+
+		req, err := http.NewRequest(http.MethodPut, "http://bridge.local/clip/v2/resource/light/light-1", nil)
+		require.NoError(t, err)
+		_, err = transport.RoundTrip(req)
+
+		assert.ErrorIs(t, err, failure)
+		assert.Equal(t, []observedRequest{{method: http.MethodPut, path: "/clip/v2/resource/light/light-1", err: failure}}, metrics.Observed())
+	})
+}
+
+func TestClient_RecordsRequestMetrics(t *testing.T) {
+	server := testutils.MockHueBridgeResponse(http.StatusOK, map[string]interface{}{"data": []interface{}{}})
+	defer server.Close()
+
+	metrics := &fakeRequestMetrics{}
+	client := newTestClient(t, server)
+	client.client.Transport = newInstrumentedTransport(server.Client().Transport, client.logger, "", metrics, bridgeRequestSubject)
+
+	_, err := client.GetAllLights()
+
+	require.NoError(t, err)
+	assert.Equal(t, []observedRequest{{method: http.MethodGet, path: "/clip/v2/resource/light", status: http.StatusOK}}, metrics.Observed())
+}
+
+func TestNewClient_ComposesInstrumentedTransport(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "Test Root CA", true, nil)
+	caBundlePath := writeTestCertPEM(t, dir, "ca.pem", ca)
+	metrics := &fakeRequestMetrics{}
+
+	client, err := NewClient("test-device", "bridge-123", "192.168.1.100", newMockAPIKeyStore(), caBundlePath, nil,
+		WithRequestMetrics(metrics), WithUserAgent("my-app/1.0"))
+	require.NoError(t, err)
+
+	transport, ok := client.client.Transport.(*instrumentedTransport)
+	require.True(t, ok)
+	assert.Same(t, metrics, transport.metrics)
+	assert.Equal(t, "my-app/1.0", transport.userAgent)
+	assert.IsType(t, &http.Transport{}, transport.next)
+}
+
+func TestBridgeDiscoveryService_RecordsRequestMetrics(t *testing.T) {
+	server := testutils.MockHueBridgeResponse(http.StatusOK, []map[string]interface{}{
+		{"id": "ecb5fafffe123456", "internalipaddress": "192.168.1.2"},
+	})
+	defer server.Close()
+
+	metrics := &fakeRequestMetrics{}
+	service := NewBridgeDiscoveryService(nil, WithDiscoveryRequestMetrics(metrics))
+	service.endpointURL = server.URL
+
+	_, err := service.fetchBridgesByDiscoverEndpoint(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, []observedRequest{{method: http.MethodGet, path: "/", status: http.StatusOK}}, metrics.Observed())
+}
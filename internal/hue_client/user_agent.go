@@ -0,0 +1,18 @@
+package hueclient
+
+// Version of hue-lighter, it is set at build time with
+// -ldflags "-X com.github.yveskaufmann/hue-lighter/internal/hue_client.Version=<version>".
+var Version = "dev"
+
+// DefaultUserAgent returns the User-Agent sent with bridge and discovery requests,
+// e.g. "hue-lighter/1.2.0".
+func DefaultUserAgent() string {
+	return APP_NAME + "/" + Version
+}
+
+func userAgentOrDefault(userAgent string) string {
+	if userAgent == "" {
+		return DefaultUserAgent()
+	}
+	return userAgent
+}
@@ -0,0 +1,87 @@
+package hueclient
+
+import (
+	"context"
+	"testing"
+
+	"com.github.yveskaufmann/hue-lighter/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_UserAgent(t *testing.T) {
+	tests := []struct {
+		name              string
+		userAgent         string
+		expectedUserAgent string
+	}{
+		{
+			name:              "sends default user agent",
+			expectedUserAgent: "hue-lighter/dev",
+		},
+		{
+			name:              "sends overridden user agent",
+			userAgent:         "my-home-automation/2.0",
+			expectedUserAgent: "my-home-automation/2.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, recorder := testutils.MockHueBridgeRecorder(200, map[string]interface{}{
+				"data": []map[string]interface{}{{"rid": "light-1", "rtype": "light"}},
+			})
+			defer server.Close()
+
+			options := clientOptions{}
+			WithUserAgent(tt.userAgent)(&options)
+			client := newTestClient(t, server)
+			transport := newTestTransport(server, client.logger)
+			transport.userAgent = options.userAgent
+			client.client.Transport = transport
+
+			require.NoError(t, client.TurnOnLightById("light-1"))
+
+			requests := recorder.Requests()
+			require.Len(t, requests, 1)
+			assert.Equal(t, tt.expectedUserAgent, requests[0].Header.Get("User-Agent"))
+		})
+	}
+}
+
+func TestBridgeDiscoveryService_UserAgent(t *testing.T) {
+	tests := []struct {
+		name              string
+		opts              []DiscoveryOption
+		expectedUserAgent string
+	}{
+		{
+			name:              "sends default user agent",
+			expectedUserAgent: "hue-lighter/dev",
+		},
+		{
+			name:              "sends overridden user agent",
+			opts:              []DiscoveryOption{WithDiscoveryUserAgent("my-home-automation/2.0")},
+			expectedUserAgent: "my-home-automation/2.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, recorder := testutils.MockHueBridgeRecorder(200, []map[string]interface{}{
+				{"id": "ecb5fafffe123456", "internalipaddress": "192.168.1.2"},
+			})
+			defer server.Close()
+
+			service := NewBridgeDiscoveryService(nil, tt.opts...)
+			service.endpointURL = server.URL
+
+			_, err := service.fetchBridgesByDiscoverEndpoint(context.Background())
+
+			require.NoError(t, err)
+			requests := recorder.Requests()
+			require.Len(t, requests, 1)
+			assert.Equal(t, tt.expectedUserAgent, requests[0].Header.Get("User-Agent"))
+		})
+	}
+}
@@ -2,16 +2,101 @@ package logging
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
 	log "github.com/sirupsen/logrus"
 )
 
-func NewLogger() *log.Entry {
+// Option configures the logger created by NewLogger.
+type Option func(*options)
+
+type options struct {
+	level     *log.Level
+	formatter log.Formatter
+	output    io.Writer
+	hooks     []log.Hook
+	redactor  *Redactor
+}
+
+// WithLevel sets the log level instead of reading it from `LOG_LEVEL`.
+func WithLevel(level log.Level) Option {
+	return func(o *options) {
+		o.level = &level
+	}
+}
+
+// WithFormatter sets the formatter instead of selecting it by `LOG_FORMAT`.
+func WithFormatter(formatter log.Formatter) Option {
+	return func(o *options) {
+		o.formatter = formatter
+	}
+}
+
+// WithOutput sets the writer logs are written to instead of selecting it by
+// `LOG_OUTPUT`, defaults to stderr.
+func WithOutput(output io.Writer) Option {
+	return func(o *options) {
+		o.output = output
+	}
+}
+
+// WithHooks registers hooks which receive every log entry.
+func WithHooks(hooks ...log.Hook) Option {
+	return func(o *options) {
+		o.hooks = append(o.hooks, hooks...)
+	}
+}
+
+// NewLogger creates a new logger which is independent of the logrus standard logger.
+// Level, format and output are read from `LOG_LEVEL`, `LOG_FORMAT` and `LOG_OUTPUT`
+// unless provided as option.
+func NewLogger(opts ...Option) *log.Entry {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	logger := log.New()
+
+	// The syslog adds its own timestamp, it is only selected by `LOG_OUTPUT`
+	// if no output is provided as option.
+	toSyslog := o.output == nil && useSyslog()
+
+	formatter := o.formatter
+	if formatter == nil {
+		formatter = newFormatter(!toSyslog)
+	}
+	if o.redactor != nil {
+		formatter = &redactingFormatter{formatter: formatter, redactor: o.redactor}
+	}
+	logger.SetFormatter(formatter)
+
+	if o.level != nil {
+		logger.SetLevel(*o.level)
+	} else {
+		logger.SetLevel(getLogLevelByEnvironment())
+	}
+
+	if o.output != nil {
+		logger.SetOutput(o.output)
+	} else if toSyslog {
+		setupSyslog(logger, formatter)
+	}
+
+	for _, hook := range o.hooks {
+		logger.AddHook(hook)
+	}
+
+	return log.NewEntry(logger)
+}
+
+// NewDiscardLogger creates a logger which drops all entries, it is used by
+// components which are embedded without a logger.
+func NewDiscardLogger() *log.Entry {
 	logger := log.New()
-	logger.SetFormatter(newFormatter())
-	logger.SetLevel(getLogLevelByEnvironment())
+	logger.SetOutput(io.Discard)
 	return log.NewEntry(logger)
 }
 
@@ -32,7 +117,9 @@ func getLogLevelByEnvironment() log.Level {
 	return parsedLevel
 }
 
-func newFormatter() log.Formatter {
+// newFormatter selects the formatter by `LOG_FORMAT`, timestamps are left out
+// for outputs which add their own.
+func newFormatter(timestamps bool) log.Formatter {
 	defaultFormatType := "text"
 
 	formatType, ok := os.LookupEnv("LOG_FORMAT")
@@ -51,10 +138,11 @@ func newFormatter() log.Formatter {
 	}
 
 	if formatType == "json" {
-		return &log.JSONFormatter{}
+		return &log.JSONFormatter{DisableTimestamp: !timestamps}
 	}
 
 	return &log.TextFormatter{
-		FullTimestamp: true,
+		FullTimestamp:    true,
+		DisableTimestamp: !timestamps,
 	}
 }
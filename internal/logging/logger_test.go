@@ -0,0 +1,68 @@
+package logging
+
+import (
+	"bytes"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLogger_WithHooks(t *testing.T) {
+	hook := test.NewLocal(log.New())
+	output := &bytes.Buffer{}
+
+	logger := NewLogger(WithHooks(hook), WithOutput(output), WithLevel(log.DebugLevel))
+	logger.WithField("component", "test").Debug("hello")
+
+	require.Len(t, hook.AllEntries(), 1)
+	entry := hook.LastEntry()
+	assert.Equal(t, "hello", entry.Message)
+	assert.Equal(t, log.DebugLevel, entry.Level)
+	assert.Equal(t, "test", entry.Data["component"])
+	assert.Contains(t, output.String(), "hello")
+}
+
+func TestNewLogger_Options(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "debug")
+	t.Setenv("LOG_FORMAT", "json")
+
+	tests := []struct {
+		name              string
+		opts              []Option
+		expectedLevel     log.Level
+		expectedFormatter log.Formatter
+	}{
+		{
+			name:              "uses environment without options",
+			expectedLevel:     log.DebugLevel,
+			expectedFormatter: &log.JSONFormatter{},
+		},
+		{
+			name:              "options take precedence over environment",
+			opts:              []Option{WithLevel(log.WarnLevel), WithFormatter(&log.TextFormatter{DisableColors: true})},
+			expectedLevel:     log.WarnLevel,
+			expectedFormatter: &log.TextFormatter{DisableColors: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := NewLogger(tt.opts...)
+
+			assert.Equal(t, tt.expectedLevel, logger.Logger.GetLevel())
+			assert.Equal(t, tt.expectedFormatter, logger.Logger.Formatter)
+		})
+	}
+}
+
+func TestNewLogger_IsIndependentOfStandardLogger(t *testing.T) {
+	hook := test.NewLocal(log.New())
+
+	NewLogger(WithHooks(hook), WithOutput(&bytes.Buffer{}))
+
+	assert.NotSame(t, log.StandardLogger(), NewLogger().Logger)
+	assert.Empty(t, log.StandardLogger().Hooks)
+}
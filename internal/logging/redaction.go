@@ -0,0 +1,79 @@
+package logging
+
+import (
+	"bytes"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Redacted replaces secrets in log output.
+const Redacted = "[REDACTED]"
+
+// Redact returns Redacted for a non-empty secret, so that a log field tells
+// whether a secret is set without revealing it.
+func Redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return Redacted
+}
+
+// Redactor replaces registered secrets, e.g. API keys, with Redacted. It is safe
+// for concurrent use, so that secrets can be added while logging.
+type Redactor struct {
+	mu      sync.RWMutex
+	secrets map[string]bool
+}
+
+func NewRedactor(secrets ...string) *Redactor {
+	r := &Redactor{secrets: make(map[string]bool)}
+	for _, secret := range secrets {
+		r.AddSecret(secret)
+	}
+	return r
+}
+
+// AddSecret registers a secret to be redacted, empty secrets are ignored.
+func (r *Redactor) AddSecret(secret string) {
+	if secret == "" {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.secrets[secret] = true
+}
+
+// RedactBytes replaces all registered secrets in the given text.
+func (r *Redactor) RedactBytes(text []byte) []byte {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for secret := range r.secrets {
+		text = bytes.ReplaceAll(text, []byte(secret), []byte(Redacted))
+	}
+	return text
+}
+
+// WithRedaction replaces the secrets of the redactor in every formatted entry,
+// regardless of whether they are part of the message or a field. Hooks receive
+// the entries before they are formatted and must not forward them unredacted.
+func WithRedaction(redactor *Redactor) Option {
+	return func(o *options) {
+		o.redactor = redactor
+	}
+}
+
+type redactingFormatter struct {
+	formatter log.Formatter
+	redactor  *Redactor
+}
+
+func (f *redactingFormatter) Format(entry *log.Entry) ([]byte, error) {
+	formatted, err := f.formatter.Format(entry)
+	if err != nil {
+		return nil, err
+	}
+	return f.redactor.RedactBytes(formatted), nil
+}
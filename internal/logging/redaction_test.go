@@ -0,0 +1,71 @@
+package logging
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedact(t *testing.T) {
+	assert.Equal(t, Redacted, Redact("secret-api-key"))
+	assert.Empty(t, Redact(""))
+}
+
+func TestNewLogger_WithRedaction(t *testing.T) {
+	tests := []struct {
+		name      string
+		formatter log.Formatter
+	}{
+		{
+			name:      "text format",
+			formatter: &log.TextFormatter{DisableColors: true},
+		},
+		{
+			name:      "json format",
+			formatter: &log.JSONFormatter{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := &bytes.Buffer{}
+			redactor := NewRedactor("known-secret")
+			logger := NewLogger(WithOutput(output), WithFormatter(tt.formatter), WithRedaction(redactor))
+
+			redactor.AddSecret("added-secret")
+			logger.WithField("key", "added-secret").Infof("Using key known-secret")
+
+			assert.NotContains(t, output.String(), "known-secret")
+			assert.NotContains(t, output.String(), "added-secret")
+			assert.Contains(t, output.String(), Redacted)
+		})
+	}
+}
+
+func TestRedactor_AddSecretConcurrently(t *testing.T) {
+	output := &bytes.Buffer{}
+	redactor := NewRedactor()
+	logger := NewLogger(WithOutput(output), WithRedaction(redactor))
+
+	var wg sync.WaitGroup
+	for _, secret := range []string{"secret-1", "secret-2", "secret-3"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			redactor.AddSecret(secret)
+			logger.Info("Registered " + secret)
+		}()
+	}
+	wg.Wait()
+
+	assert.NotContains(t, output.String(), "secret-")
+}
+
+func TestRedactor_IgnoresEmptySecret(t *testing.T) {
+	redactor := NewRedactor("")
+
+	assert.Equal(t, "unchanged", string(redactor.RedactBytes([]byte("unchanged"))))
+}
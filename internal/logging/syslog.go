@@ -0,0 +1,107 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/syslog"
+	"os"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// syslogTag identifies the entries of hue-lighter in the syslog and journal.
+const syslogTag = "hue-lighter"
+
+// syslogWriter is the part of *syslog.Writer used by the SyslogHook.
+type syslogWriter interface {
+	Crit(m string) error
+	Err(m string) error
+	Warning(m string) error
+	Info(m string) error
+	Debug(m string) error
+}
+
+// SyslogHook sends log entries to the local syslog with the priority matching
+// their level, journald picks them up from there with its own timestamp.
+type SyslogHook struct {
+	writer    syslogWriter
+	formatter log.Formatter
+}
+
+// NewSyslogHook connects to the local syslog socket.
+func NewSyslogHook(tag string, formatter log.Formatter) (*SyslogHook, error) {
+	writer, err := syslog.New(syslog.LOG_INFO|syslog.LOG_DAEMON, tag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to syslog: %w", err)
+	}
+	return &SyslogHook{writer: writer, formatter: formatter}, nil
+}
+
+func (h *SyslogHook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+func (h *SyslogHook) Fire(entry *log.Entry) error {
+	line, err := h.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+	message := strings.TrimSuffix(string(line), "\n")
+
+	switch syslogPriority(entry.Level) {
+	case syslog.LOG_CRIT:
+		return h.writer.Crit(message)
+	case syslog.LOG_ERR:
+		return h.writer.Err(message)
+	case syslog.LOG_WARNING:
+		return h.writer.Warning(message)
+	case syslog.LOG_INFO:
+		return h.writer.Info(message)
+	default:
+		return h.writer.Debug(message)
+	}
+}
+
+// syslogPriority maps a log level to its syslog severity.
+func syslogPriority(level log.Level) syslog.Priority {
+	switch level {
+	case log.PanicLevel, log.FatalLevel:
+		return syslog.LOG_CRIT
+	case log.ErrorLevel:
+		return syslog.LOG_ERR
+	case log.WarnLevel:
+		return syslog.LOG_WARNING
+	case log.InfoLevel:
+		return syslog.LOG_INFO
+	default:
+		return syslog.LOG_DEBUG
+	}
+}
+
+// useSyslog reports whether `LOG_OUTPUT` selects the syslog output.
+func useSyslog() bool {
+	output := strings.ToLower(strings.TrimSpace(os.Getenv("LOG_OUTPUT")))
+	switch output {
+	case "", "stderr":
+		return false
+	case "syslog":
+		return true
+	default:
+		fmt.Fprintf(os.Stderr, "invalid log output '%s', defaulting to stderr\n", output)
+		return false
+	}
+}
+
+// setupSyslog sends the entries of the logger to the syslog instead of stderr,
+// it keeps stderr if the syslog is unavailable.
+func setupSyslog(logger *log.Logger, formatter log.Formatter) {
+	hook, err := NewSyslogHook(syslogTag, formatter)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v, logging to stderr\n", err)
+		return
+	}
+
+	logger.AddHook(hook)
+	logger.SetOutput(io.Discard)
+}
@@ -0,0 +1,95 @@
+package logging
+
+import (
+	"bytes"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeSyslogWriter struct {
+	messages []string
+}
+
+func (f *fakeSyslogWriter) record(priority string, m string) error {
+	f.messages = append(f.messages, priority+": "+m)
+	return nil
+}
+
+func (f *fakeSyslogWriter) Crit(m string) error    { return f.record("crit", m) }
+func (f *fakeSyslogWriter) Err(m string) error     { return f.record("err", m) }
+func (f *fakeSyslogWriter) Warning(m string) error { return f.record("warning", m) }
+func (f *fakeSyslogWriter) Info(m string) error    { return f.record("info", m) }
+func (f *fakeSyslogWriter) Debug(m string) error   { return f.record("debug", m) }
+
+func TestSyslogHook_MapsLevelsToPriorities(t *testing.T) {
+	tests := []struct {
+		level            log.Level
+		expectedPriority string
+	}{
+		{level: log.PanicLevel, expectedPriority: "crit"},
+		{level: log.FatalLevel, expectedPriority: "crit"},
+		{level: log.ErrorLevel, expectedPriority: "err"},
+		{level: log.WarnLevel, expectedPriority: "warning"},
+		{level: log.InfoLevel, expectedPriority: "info"},
+		{level: log.DebugLevel, expectedPriority: "debug"},
+		{level: log.TraceLevel, expectedPriority: "debug"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.level.String(), func(t *testing.T) {
+			writer := &fakeSyslogWriter{}
+			hook := &SyslogHook{writer: writer, formatter: &log.TextFormatter{DisableTimestamp: true}}
+
+			err := hook.Fire(&log.Entry{Logger: log.New(), Level: tt.level, Message: "hello", Data: log.Fields{}})
+
+			assert.NoError(t, err)
+			assert.Equal(t, []string{tt.expectedPriority + ": level=" + tt.level.String() + " msg=hello"}, writer.messages)
+		})
+	}
+}
+
+func TestSyslogHook_RedactsSecrets(t *testing.T) {
+	writer := &fakeSyslogWriter{}
+	hook := &SyslogHook{
+		writer:    writer,
+		formatter: &redactingFormatter{formatter: &log.TextFormatter{DisableTimestamp: true}, redactor: NewRedactor("s3cr3t")},
+	}
+
+	assert.NoError(t, hook.Fire(&log.Entry{Logger: log.New(), Level: log.InfoLevel, Message: "key s3cr3t", Data: log.Fields{}}))
+
+	assert.Equal(t, []string{`info: level=info msg="key [REDACTED]"`}, writer.messages)
+}
+
+func TestUseSyslog(t *testing.T) {
+	tests := []struct {
+		output   string
+		expected bool
+	}{
+		{output: "", expected: false},
+		{output: "stderr", expected: false},
+		{output: "syslog", expected: true},
+		{output: " SYSLOG ", expected: true},
+		{output: "file", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.output, func(t *testing.T) {
+			t.Setenv("LOG_OUTPUT", tt.output)
+
+			assert.Equal(t, tt.expected, useSyslog())
+		})
+	}
+}
+
+func TestNewLogger_OutputOptionTakesPrecedenceOverSyslog(t *testing.T) {
+	t.Setenv("LOG_OUTPUT", "syslog")
+	output := &bytes.Buffer{}
+
+	logger := NewLogger(WithOutput(output))
+	logger.Info("hello")
+
+	assert.Contains(t, output.String(), "hello")
+	assert.Contains(t, output.String(), "time=")
+}
@@ -1,6 +1,7 @@
 package device_registration
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -8,6 +9,10 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+// linkButtonTimeout is the time the user has to press the link button before the
+// device is registered.
+const linkButtonTimeout = 15 * time.Second
+
 type Service struct {
 	client      *hueclient.Client
 	apiKeyStore hueclient.APIKeyStore
@@ -22,7 +27,10 @@ func NewService(client *hueclient.Client, apiKeyStore hueclient.APIKeyStore, log
 	}
 }
 
-func (s *Service) RegisterDevice(deviceName string) error {
+// RegisterDevice registers the device at the bridge and stores its API key, a
+// registration request which did not reach the bridge is retried. The registration is
+// aborted when ctx is done, also while waiting for the link button.
+func (s *Service) RegisterDevice(ctx context.Context, deviceName string) error {
 
 	logger := s.logger.WithFields(log.Fields{
 		"device": deviceName,
@@ -37,12 +45,21 @@ func (s *Service) RegisterDevice(deviceName string) error {
 
 	// TODO: Check if device is already registered
 
+	// Fail fast instead of letting the user press the link button in vain
+	if _, err := s.client.ProbeBridge(); err != nil {
+		logger.WithError(err).Error("Hue bridge is not reachable, check the network connection and the bridge IP")
+		return fmt.Errorf("bridge is not reachable, not waiting for the link button: %w", err)
+	}
+
 	logger.Info("Registering device...")
 	logger.Info("Press the link button on your Philips Hue bridge within the next 15 seconds!")
 
-	<-time.After(15 * time.Second)
+	if err := waitForLinkButton(ctx, linkButtonTimeout); err != nil {
+		logger.Info("Device registration aborted")
+		return err
+	}
 	// TODO: The username is the API key
-	registerResponse, err := s.client.RegisterDevice(deviceName)
+	registerResponse, err := s.client.RegisterDeviceWithRetry(ctx, deviceName)
 	if err != nil {
 		logger.WithError(err).Error("Failed to invoke device registration API call")
 		return err
@@ -56,7 +73,7 @@ func (s *Service) RegisterDevice(deviceName string) error {
 		return registerResponse.ToError()
 	}
 
-	logger.WithFields(log.Fields{"ClientKey": registerResponse.Success.ClientKey}).Info("Device registered successfully")
+	logger.Info("Device registered successfully")
 
 	err = s.apiKeyStore.Set(fmt.Sprintf("%s#%s", s.client.BridgeID(), s.client.DeviceName()), registerResponse.Success.Username)
 	if err != nil {
@@ -68,3 +85,16 @@ func (s *Service) RegisterDevice(deviceName string) error {
 
 	return nil
 }
+
+// waitForLinkButton waits for the given duration, giving the user the time to press
+// the link button, or until ctx is done.
+func waitForLinkButton(ctx context.Context, wait time.Duration) error {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("device registration aborted while waiting for the link button: %w", ctx.Err())
+	}
+}
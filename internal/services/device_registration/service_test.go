@@ -0,0 +1,80 @@
+package device_registration
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	hueclient "com.github.yveskaufmann/hue-lighter/internal/hue_client"
+	"com.github.yveskaufmann/hue-lighter/internal/logging"
+	"com.github.yveskaufmann/hue-lighter/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_RegisterDevice_SkipsRegistrationWithProvidedAPIKey(t *testing.T) {
+	defer testutils.SetEnv(t, "HUE_API_KEY", "provided-key")()
+
+	server, recorder := testutils.MockHueBridgeRecorder(http.StatusOK, nil)
+	defer server.Close()
+	logger := logging.NewDiscardLogger()
+	store := hueclient.NewInMemoryAPIKeyStore(logger)
+	client, err := hueclient.NewClient("office", "ECB5FAFFFE123456", server.Listener.Addr().String(), store, "", logger,
+		hueclient.WithTLSOptions(hueclient.WithInsecureSkipCAVerification()))
+	require.NoError(t, err)
+
+	seeded, err := hueclient.SeedAPIKey(store, client.BridgeID(), client.DeviceName(), hueclient.ProvidedAPIKey(""))
+	require.NoError(t, err)
+	require.True(t, seeded)
+
+	err = NewService(client, store, logger).RegisterDevice(context.Background(), client.DeviceName())
+
+	require.NoError(t, err)
+	assert.Empty(t, recorder.Requests())
+}
+
+func TestService_RegisterDevice_AbortsIfBridgeIsUnreachable(t *testing.T) {
+	defer testutils.SetEnv(t, "HUE_API_KEY", "")()
+
+	server, recorder := testutils.MockHueBridgeRecorder(http.StatusInternalServerError, nil)
+	defer server.Close()
+	logger := logging.NewDiscardLogger()
+	store := hueclient.NewInMemoryAPIKeyStore(logger)
+	client, err := hueclient.NewClient("office", "ECB5FAFFFE123456", server.Listener.Addr().String(), store, "", logger,
+		hueclient.WithTLSOptions(hueclient.WithInsecureSkipCAVerification()))
+	require.NoError(t, err)
+
+	start := time.Now()
+	err = NewService(client, store, logger).RegisterDevice(context.Background(), client.DeviceName())
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "bridge is not reachable")
+	assert.Less(t, time.Since(start), 5*time.Second, "registration should not wait for the link button")
+	for _, request := range recorder.Requests() {
+		assert.NotEqual(t, http.MethodPost, request.Method)
+	}
+}
+
+func TestWaitForLinkButton(t *testing.T) {
+	t.Run("waits for the given duration", func(t *testing.T) {
+		start := time.Now()
+
+		err := waitForLinkButton(context.Background(), 50*time.Millisecond)
+
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	})
+
+	t.Run("aborts when the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+
+		err := waitForLinkButton(ctx, time.Minute)
+
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Contains(t, err.Error(), "device registration aborted while waiting for the link button")
+		assert.Less(t, time.Since(start), 5*time.Second)
+	})
+}
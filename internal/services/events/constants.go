@@ -2,3 +2,7 @@ package events
 
 const SOCKET_HUE_LIGHTER_EVENTS = "/tmp/hue-lighter.sock"
 const EVENT_TYPE_SHUTDOWN = "shutdown"
+const EVENT_TYPE_STATUS = "status"
+const EVENT_TYPE_RELOAD_CONFIG = "reload_config"
+const EVENT_TYPE_PAUSE = "pause"
+const EVENT_TYPE_RESUME = "resume"
@@ -1,43 +1,121 @@
 package events
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"os"
+	"sync"
+	"time"
 
+	"com.github.yveskaufmann/hue-lighter/internal/config"
 	"com.github.yveskaufmann/hue-lighter/internal/services/light_automation"
 	log "github.com/sirupsen/logrus"
 )
 
+// LightAutomation handles the events received by the ExternalEventService,
+// *light_automation.Service is the implementation used in production.
+type LightAutomation interface {
+	StopAndTurnOffLights() error
+	Status() light_automation.Status
+	Pause()
+	Resume()
+	ApplyConfig(cfg *config.Config)
+}
+
+var _ LightAutomation = (*light_automation.Service)(nil)
+
 type ExternalEventService struct {
 	logger          *log.Entry
-	lightAutomation *light_automation.Service
+	lightAutomation LightAutomation
 	listener        net.Listener
-	stopChan        chan struct{}
+	socketPath      string
+	loadConfig      func() (*config.Config, error)
+	reloadDebounce  time.Duration
+	reloads         *reloadDebouncer
+	// onShutdown is called after the lights were turned off by a shutdown event
+	onShutdown func()
+	listen     func(network, address string) (net.Listener, error)
+	// loopDone is closed when the event loop exited and the socket was removed
+	loopDone chan struct{}
+	// mu guards stopping, so that no event is started once Shutdown waits for handlers
+	mu       sync.Mutex
+	stopping bool
+	// handlers counts the events in progress, e.g. a shutdown event turning off the lights
+	handlers sync.WaitGroup
+}
+
+// EventResponse is sent back for events which do not return data of their own.
+type EventResponse struct {
+	Error string `json:"error,omitempty"`
+}
+
+// Option configures optional behaviour of the ExternalEventService.
+type Option func(*ExternalEventService)
+
+// WithConfigLoader sets how the config is loaded on a reload, defaults to
+// config.LoadConfigFromDefaultPath.
+func WithConfigLoader(loadConfig func() (*config.Config, error)) Option {
+	return func(s *ExternalEventService) {
+		s.loadConfig = loadConfig
+	}
+}
+
+// WithSocketPath sets the path of the Unix socket on which the events are
+// received and sent, defaults to SOCKET_HUE_LIGHTER_EVENTS.
+func WithSocketPath(path string) Option {
+	return func(s *ExternalEventService) {
+		s.socketPath = path
+	}
+}
+
+// WithReloadDebounce coalesces the reload events received within the window into
+// a single reload, only the final state of the config file is validated.
+func WithReloadDebounce(window time.Duration) Option {
+	return func(s *ExternalEventService) {
+		s.reloadDebounce = window
+	}
 }
 
-func NewExternalEventService(lightAutomation *light_automation.Service, logger *log.Entry, stopChan chan struct{}) *ExternalEventService {
-	return &ExternalEventService{
+func NewExternalEventService(lightAutomation LightAutomation, logger *log.Entry, onShutdown func(), opts ...Option) *ExternalEventService {
+	s := &ExternalEventService{
 		logger:          logger.WithField("component", "ExternalEventService"),
 		lightAutomation: lightAutomation,
-		stopChan:        stopChan,
+		socketPath:      SOCKET_HUE_LIGHTER_EVENTS,
+		loadConfig:      config.LoadConfigFromDefaultPath,
+		onShutdown:      onShutdown,
+		listen:          net.Listen,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.reloads = newReloadDebouncer(s.reloadDebounce, s.reloadConfig)
+	return s
+}
+
+// SocketPath returns the path of the Unix socket of the service.
+func (s *ExternalEventService) SocketPath() string {
+	return s.socketPath
 }
 
 func (s *ExternalEventService) Start() error {
 
-	listener, err := net.Listen("unix", SOCKET_HUE_LIGHTER_EVENTS)
+	listener, err := s.listen("unix", s.socketPath)
 	if err != nil {
 		return fmt.Errorf("failed to start Unix socket listener: %w", err)
 	}
 	s.listener = listener
+	s.loopDone = make(chan struct{})
 
 	go func() {
 		defer func() {
 			s.logger.Info("Closing Unix socket listener")
-			s.listener.Close()
-			os.Remove(SOCKET_HUE_LIGHTER_EVENTS)
+			listener.Close()
+			os.Remove(s.socketPath)
+			close(s.loopDone)
 		}()
 
 		for {
@@ -52,28 +130,18 @@ func (s *ExternalEventService) Start() error {
 
 			}
 
-			s.logger.Printf("Listening for events on Unix socket: %q", SOCKET_HUE_LIGHTER_EVENTS)
-
-			buf := make([]byte, 128)
-			defer conn.Close()
-			n, _ := conn.Read(buf)
-			if string(buf[:n]) == EVENT_TYPE_SHUTDOWN {
-				s.logger.Info("Received shutdown event, stopping light automation service")
-				err := s.lightAutomation.StopAndTurnOffLights()
-				if err != nil {
-					s.logger.WithError(err).Error("Failed to stop and turn off lights")
-				}
-
-				if s.stopChan != nil {
-					s.stopChan <- struct{}{}
-				}
+			s.logger.Printf("Listening for events on Unix socket: %q", s.socketPath)
 
-				if err != nil {
-					s.logger.WithError(err).Error("Failed to stop light automation service")
-				}
+			if !s.startHandler() {
+				s.logger.Info("Rejecting event, the service is shutting down")
+				conn.Close()
+				continue
+			}
+			stop := s.handleConnection(conn)
+			s.handlers.Done()
+			if stop {
 				return
 			}
-
 		}
 	}()
 
@@ -81,8 +149,89 @@ func (s *ExternalEventService) Start() error {
 	return nil
 }
 
+// handleConnection processes a single event and reports whether the event loop should stop.
+func (s *ExternalEventService) handleConnection(conn net.Conn) bool {
+	buf := make([]byte, 128)
+	n, _ := conn.Read(buf)
+	event := string(buf[:n])
+
+	// Reload events are answered once the debounced reload ran, without
+	// blocking the events received in the meantime.
+	if event == EVENT_TYPE_RELOAD_CONFIG {
+		s.logger.Info("Received reload config event")
+		s.handlers.Add(1)
+		go func() {
+			defer s.handlers.Done()
+			s.answerReload(conn, s.reloads.Request())
+		}()
+		return false
+	}
+
+	defer conn.Close()
+
+	switch event {
+	case EVENT_TYPE_SHUTDOWN:
+		s.logger.Info("Received shutdown event, stopping light automation service")
+		err := s.lightAutomation.StopAndTurnOffLights()
+		if err != nil {
+			s.logger.WithError(err).Error("Failed to stop and turn off lights")
+		}
+
+		if s.onShutdown != nil {
+			s.onShutdown()
+		}
+		return true
+	case EVENT_TYPE_STATUS:
+		s.logger.Debug("Received status event")
+		if err := json.NewEncoder(conn).Encode(s.lightAutomation.Status()); err != nil {
+			s.logger.WithError(err).Error("Failed to send status")
+		}
+	case EVENT_TYPE_PAUSE:
+		s.logger.Info("Received pause event")
+		s.lightAutomation.Pause()
+		if err := json.NewEncoder(conn).Encode(EventResponse{}); err != nil {
+			s.logger.WithError(err).Error("Failed to send pause response")
+		}
+	case EVENT_TYPE_RESUME:
+		s.logger.Info("Received resume event")
+		s.lightAutomation.Resume()
+		if err := json.NewEncoder(conn).Encode(EventResponse{}); err != nil {
+			s.logger.WithError(err).Error("Failed to send resume response")
+		}
+	}
+
+	return false
+}
+
+// answerReload sends the result of the reload and closes the connection.
+func (s *ExternalEventService) answerReload(conn net.Conn, result <-chan error) {
+	defer conn.Close()
+
+	response := EventResponse{}
+	if err := <-result; err != nil {
+		response.Error = err.Error()
+	}
+	if err := json.NewEncoder(conn).Encode(response); err != nil {
+		s.logger.WithError(err).Error("Failed to send reload config response")
+	}
+}
+
+// reloadConfig loads and validates the config file and applies it to the light
+// automation. The current config stays active if loading fails.
+func (s *ExternalEventService) reloadConfig() error {
+	cfg, err := s.loadConfig()
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to reload config, keeping current config")
+		return err
+	}
+
+	s.lightAutomation.ApplyConfig(cfg)
+	s.reloads.setWindow(cfg.Automation.ReloadDebounce)
+	return nil
+}
+
 func (s *ExternalEventService) StopAndTurnOffLights() error {
-	conn, err := net.Dial("unix", SOCKET_HUE_LIGHTER_EVENTS)
+	conn, err := net.Dial("unix", s.socketPath)
 	if err != nil {
 		return fmt.Errorf("failed to connect to Unix socket: %w", err)
 	}
@@ -96,6 +245,141 @@ func (s *ExternalEventService) StopAndTurnOffLights() error {
 	return nil
 }
 
+// RequestStatus asks the running service for a snapshot of its current state.
+func (s *ExternalEventService) RequestStatus() (*light_automation.Status, error) {
+	conn, err := net.Dial("unix", s.socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Unix socket: %w", err)
+	}
+	defer conn.Close()
+
+	_, err = conn.Write([]byte(EVENT_TYPE_STATUS))
+	if err != nil {
+		return nil, fmt.Errorf("failed to send status event: %w", err)
+	}
+
+	var status light_automation.Status
+	if err := json.NewDecoder(conn).Decode(&status); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("service closed the connection without sending a status")
+		}
+		return nil, fmt.Errorf("failed to decode status: %w", err)
+	}
+
+	return &status, nil
+}
+
+// ReloadConfig asks the running service to reload its config file and returns
+// the error reported by the service if the new config was rejected.
+func (s *ExternalEventService) ReloadConfig() error {
+	conn, err := net.Dial("unix", s.socketPath)
+	if err != nil {
+		return fmt.Errorf("failed to connect to Unix socket: %w", err)
+	}
+	defer conn.Close()
+
+	_, err = conn.Write([]byte(EVENT_TYPE_RELOAD_CONFIG))
+	if err != nil {
+		return fmt.Errorf("failed to send reload config event: %w", err)
+	}
+
+	var response EventResponse
+	if err := json.NewDecoder(conn).Decode(&response); err != nil {
+		return fmt.Errorf("failed to decode reload config response: %w", err)
+	}
+
+	if response.Error != "" {
+		return fmt.Errorf("service rejected config: %s", response.Error)
+	}
+
+	return nil
+}
+
+// PauseAutomation asks the running service to pause the light automation.
+func (s *ExternalEventService) PauseAutomation() error {
+	return s.sendEvent(EVENT_TYPE_PAUSE)
+}
+
+// ResumeAutomation asks the running service to resume the paused light automation.
+func (s *ExternalEventService) ResumeAutomation() error {
+	return s.sendEvent(EVENT_TYPE_RESUME)
+}
+
+// sendEvent sends an event which is answered with an EventResponse.
+func (s *ExternalEventService) sendEvent(eventType string) error {
+	conn, err := net.Dial("unix", s.socketPath)
+	if err != nil {
+		return fmt.Errorf("failed to connect to Unix socket: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(eventType)); err != nil {
+		return fmt.Errorf("failed to send %s event: %w", eventType, err)
+	}
+
+	var response EventResponse
+	if err := json.NewDecoder(conn).Decode(&response); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", eventType, err)
+	}
+
+	if response.Error != "" {
+		return fmt.Errorf("service rejected %s event: %s", eventType, response.Error)
+	}
+
+	return nil
+}
+
+// startHandler counts an event as in progress, it reports false once the service is shutting down.
+func (s *ExternalEventService) startHandler() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopping {
+		return false
+	}
+	s.handlers.Add(1)
+	return true
+}
+
+// Shutdown stops the service in order: it stops accepting events, waits until the
+// events in progress are handled, e.g. a shutdown event turning off the lights,
+// and closes the listener afterwards. It returns the context error if the events
+// are not handled in time, the listener is closed anyway.
+func (s *ExternalEventService) Shutdown(ctx context.Context) error {
+	s.logger.Info("Shutting down External Event Service")
+
+	s.mu.Lock()
+	s.stopping = true
+	s.mu.Unlock()
+
+	handled := make(chan struct{})
+	go func() {
+		s.handlers.Wait()
+		close(handled)
+	}()
+
+	var err error
+	select {
+	case <-handled:
+	case <-ctx.Done():
+		err = fmt.Errorf("events still in progress: %w", ctx.Err())
+	}
+
+	if s.listener == nil {
+		return err
+	}
+	s.listener.Close()
+
+	select {
+	case <-s.loopDone:
+	case <-ctx.Done():
+		if err == nil {
+			err = fmt.Errorf("event loop did not stop: %w", ctx.Err())
+		}
+	}
+	return err
+}
+
 func (s *ExternalEventService) Stop() error {
 	s.logger.Info("Stopping External Event Service")
 
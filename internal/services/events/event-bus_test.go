@@ -0,0 +1,479 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"com.github.yveskaufmann/hue-lighter/internal/config"
+	hueclient "com.github.yveskaufmann/hue-lighter/internal/hue_client"
+	"com.github.yveskaufmann/hue-lighter/internal/services/light_automation"
+	"com.github.yveskaufmann/hue-lighter/internal/testutils"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeLightClient struct{}
+
+func (fakeLightClient) BridgeID() string { return "ECB5FAFFFE123456" }
+
+func (fakeLightClient) GetOneLightById(id string) (*hueclient.LightListItem, error) {
+	return &hueclient.LightListItem{ID: id}, nil
+}
+
+func (fakeLightClient) SwitchLightById(id string, on bool) (*hueclient.ResourceIdentifier, error) {
+	return &hueclient.ResourceIdentifier{RID: id, RType: "light"}, nil
+}
+
+func (fakeLightClient) TurnOffGroupedLightById(id string) error { return nil }
+
+func (fakeLightClient) UpdateGroupedLightById(id string, update *hueclient.LightBodyUpdate) (*hueclient.ResourceIdentifier, error) {
+	return &hueclient.ResourceIdentifier{}, nil
+}
+
+func (fakeLightClient) SetColorTemperatureById(id string, mirek int) error { return nil }
+
+func (fakeLightClient) RecallSmartSceneById(id string) error { return nil }
+
+func (fakeLightClient) DeactivateSmartSceneById(id string) error { return nil }
+
+func (fakeLightClient) RecallSceneById(id string, duration time.Duration) error { return nil }
+
+func (fakeLightClient) UpdateOneLightById(id string, lightUpdate *hueclient.LightBodyUpdate) (*hueclient.ResourceIdentifier, error) {
+	return &hueclient.ResourceIdentifier{}, nil
+}
+
+func newTestEventService(t *testing.T) (*ExternalEventService, *light_automation.Service) {
+	t.Helper()
+	return newTestEventServiceWithShutdown(t, nil)
+}
+
+func newTestEventServiceWithShutdown(t *testing.T, onShutdown func()) (*ExternalEventService, *light_automation.Service) {
+	t.Helper()
+
+	lightID, lightName := "light-1", "Desk"
+	cfg := &config.Config{}
+	cfg.Location.Latitude = 52.5
+	cfg.Location.Longitude = 13.4
+	cfg.Lights = append(cfg.Lights, config.LightConfig{ID: &lightID, Name: &lightName})
+
+	logger := logrus.New().WithField("test", t.Name())
+	lightService := light_automation.NewService(fakeLightClient{}, cfg, logger)
+
+	service := NewExternalEventService(lightService, logger, onShutdown, WithSocketPath(filepath.Join(t.TempDir(), "events.sock")))
+
+	require.NoError(t, service.Start())
+	t.Cleanup(func() { service.Stop() })
+
+	return service, lightService
+}
+
+type mockLightAutomation struct {
+	mu     sync.Mutex
+	calls  []string
+	stopCh chan struct{}
+}
+
+func newMockLightAutomation() *mockLightAutomation {
+	return &mockLightAutomation{stopCh: make(chan struct{})}
+}
+
+func (m *mockLightAutomation) record(call string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, call)
+}
+
+func (m *mockLightAutomation) Calls() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.calls...)
+}
+
+func (m *mockLightAutomation) StopAndTurnOffLights() error {
+	m.record("stop")
+	close(m.stopCh)
+	return nil
+}
+
+func (m *mockLightAutomation) Status() light_automation.Status {
+	m.record("status")
+	return light_automation.Status{}
+}
+
+func (m *mockLightAutomation) Pause() { m.record("pause") }
+
+func (m *mockLightAutomation) Resume() { m.record("resume") }
+
+func (m *mockLightAutomation) ApplyConfig(cfg *config.Config) { m.record("apply config") }
+
+func newMockEventService(t *testing.T, automation LightAutomation, onShutdown func(), opts ...Option) *ExternalEventService {
+	t.Helper()
+
+	logger := logrus.New().WithField("test", t.Name())
+	opts = append([]Option{WithSocketPath(filepath.Join(t.TempDir(), "events.sock"))}, opts...)
+	service := NewExternalEventService(automation, logger, onShutdown, opts...)
+
+	require.NoError(t, service.Start())
+	t.Cleanup(func() { service.Stop() })
+
+	return service
+}
+
+func TestExternalEventService_ShutdownEventEndToEnd(t *testing.T) {
+	automation := newMockLightAutomation()
+	shutdownCalled := make(chan struct{})
+	socketPath := filepath.Join(t.TempDir(), "events.sock")
+
+	service := newMockEventService(t, automation, func() { close(shutdownCalled) }, WithSocketPath(socketPath))
+	assert.Equal(t, socketPath, service.SocketPath())
+
+	conn, err := net.Dial("unix", socketPath)
+	require.NoError(t, err)
+	_, err = conn.Write([]byte(EVENT_TYPE_SHUTDOWN))
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	select {
+	case <-shutdownCalled:
+	case <-time.After(time.Second):
+		t.Fatal("onShutdown was not called after shutdown event")
+	}
+	assert.Equal(t, []string{"stop"}, automation.Calls())
+}
+
+func TestExternalEventService_DispatchesEventsToLightAutomation(t *testing.T) {
+	validConfig := func() (*config.Config, error) { return &config.Config{}, nil }
+	invalidConfig := func() (*config.Config, error) { return nil, errors.New("invalid location coordinates") }
+
+	tests := []struct {
+		name          string
+		loadConfig    func() (*config.Config, error)
+		send          func(s *ExternalEventService) error
+		expectedErr   string
+		expectedCalls []string
+	}{
+		{
+			name:          "status",
+			send:          func(s *ExternalEventService) error { _, err := s.RequestStatus(); return err },
+			expectedCalls: []string{"status"},
+		},
+		{
+			name:          "pause",
+			send:          (*ExternalEventService).PauseAutomation,
+			expectedCalls: []string{"pause"},
+		},
+		{
+			name:          "resume",
+			send:          (*ExternalEventService).ResumeAutomation,
+			expectedCalls: []string{"resume"},
+		},
+		{
+			name:          "reload applies the loaded config",
+			loadConfig:    validConfig,
+			send:          (*ExternalEventService).ReloadConfig,
+			expectedCalls: []string{"apply config"},
+		},
+		{
+			name:        "reload keeps the current config when loading fails",
+			loadConfig:  invalidConfig,
+			send:        (*ExternalEventService).ReloadConfig,
+			expectedErr: "invalid location coordinates",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			automation := newMockLightAutomation()
+			var opts []Option
+			if tt.loadConfig != nil {
+				opts = append(opts, WithConfigLoader(tt.loadConfig))
+			}
+			service := newMockEventService(t, automation, nil, opts...)
+
+			err := tt.send(service)
+
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedCalls, automation.Calls())
+		})
+	}
+}
+
+func TestExternalEventService_RequestStatus(t *testing.T) {
+	service, lightService := newTestEventService(t)
+
+	status, err := service.RequestStatus()
+
+	require.NoError(t, err)
+	expected := lightService.Status()
+	assert.Equal(t, expected.BridgeID, status.BridgeID)
+	assert.Equal(t, expected.Night, status.Night)
+	assert.True(t, expected.NextSunrise.Equal(status.NextSunrise))
+	assert.True(t, expected.NextSunset.Equal(status.NextSunset))
+	assert.Equal(t, []light_automation.LightStatus{{ID: "light-1", Name: "Desk", On: false}}, status.Lights)
+}
+
+func TestExternalEventService_StatusJSONFields(t *testing.T) {
+	service, _ := newTestEventService(t)
+
+	conn, err := net.Dial("unix", service.SocketPath())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte(EVENT_TYPE_STATUS))
+	require.NoError(t, err)
+
+	var raw map[string]interface{}
+	require.NoError(t, json.NewDecoder(conn).Decode(&raw))
+
+	assert.Equal(t, "ECB5FAFFFE123456", raw["bridge_id"])
+	assert.Contains(t, raw, "night")
+	assert.Contains(t, raw, "next_sunrise")
+	assert.Contains(t, raw, "next_sunset")
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"id": "light-1", "name": "Desk", "on": false},
+	}, raw["lights"])
+}
+
+func TestExternalEventService_ServesMultipleStatusRequests(t *testing.T) {
+	service, _ := newTestEventService(t)
+
+	for i := 0; i < 3; i++ {
+		_, err := service.RequestStatus()
+		require.NoError(t, err)
+	}
+}
+
+func TestExternalEventService_ReloadConfig(t *testing.T) {
+	service, lightService := newTestEventService(t)
+
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	defer testutils.SetEnv(t, "CONFIG_PATH", configPath)()
+
+	t.Run("applies changed config", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(configPath, []byte(`location:
+  latitude: 48.1
+  longitude: 11.6
+lights:
+  - id: "light-2"
+    name: "Shelf"
+  - id: "light-3"
+    name: "Window"`), 0644))
+
+		require.NoError(t, service.ReloadConfig())
+
+		status := lightService.Status()
+		require.Len(t, status.Lights, 2)
+		assert.Equal(t, "light-2", status.Lights[0].ID)
+		assert.Equal(t, "light-3", status.Lights[1].ID)
+	})
+
+	t.Run("keeps current config when new config is invalid", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(configPath, []byte(testutils.InvalidHueConfigYAML("invalid-latitude")), 0644))
+
+		err := service.ReloadConfig()
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "service rejected config")
+		assert.Contains(t, err.Error(), "invalid location coordinates")
+
+		status := lightService.Status()
+		require.Len(t, status.Lights, 2)
+		assert.Equal(t, "light-2", status.Lights[0].ID)
+	})
+}
+
+func TestExternalEventService_ShutdownCallsOnShutdown(t *testing.T) {
+	shutdownCalled := make(chan struct{})
+	service, _ := newTestEventServiceWithShutdown(t, func() { close(shutdownCalled) })
+
+	require.NoError(t, service.StopAndTurnOffLights())
+
+	select {
+	case <-shutdownCalled:
+	case <-time.After(time.Second):
+		t.Fatal("onShutdown was not called after shutdown event")
+	}
+}
+
+func TestExternalEventService_PauseResume(t *testing.T) {
+	service, lightService := newTestEventService(t)
+
+	require.NoError(t, service.PauseAutomation())
+	assert.True(t, lightService.Paused())
+
+	status, err := service.RequestStatus()
+	require.NoError(t, err)
+	assert.True(t, status.Paused)
+
+	require.NoError(t, service.ResumeAutomation())
+	assert.False(t, lightService.Paused())
+}
+
+func TestExternalEventService_ReloadConfig_Debounced(t *testing.T) {
+	service, _ := newTestEventService(t)
+
+	var loads atomic.Int32
+	service.loadConfig = func() (*config.Config, error) {
+		loads.Add(1)
+		return nil, errors.New("config changed while loading")
+	}
+	service.reloads.setWindow(100 * time.Millisecond)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 3)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = service.ReloadConfig()
+		}(i)
+		time.Sleep(10 * time.Millisecond)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), loads.Load())
+	for _, err := range errs {
+		assert.EqualError(t, err, "service rejected config: config changed while loading")
+	}
+
+	// Other events are answered while a reload is pending
+	service.reloads.setWindow(time.Second)
+	result := make(chan error, 1)
+	go func() { result <- service.ReloadConfig() }()
+	time.Sleep(10 * time.Millisecond)
+	_, err := service.RequestStatus()
+	require.NoError(t, err)
+	assert.Empty(t, result, "reload must still be pending")
+	assert.Error(t, <-result)
+}
+
+// slowLightClient turns off the grouped light slowly and records it in the log.
+type slowLightClient struct {
+	fakeLightClient
+	log *eventLog
+}
+
+func (c slowLightClient) TurnOffGroupedLightById(id string) error {
+	time.Sleep(100 * time.Millisecond)
+	c.log.add("lights off")
+	return nil
+}
+
+type eventLog struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (l *eventLog) add(event string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, event)
+}
+
+func (l *eventLog) get() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.events...)
+}
+
+// recordingListener records when it is closed in the log.
+type recordingListener struct {
+	net.Listener
+	log  *eventLog
+	once sync.Once
+}
+
+func (l *recordingListener) Close() error {
+	l.once.Do(func() { l.log.add("listener closed") })
+	return l.Listener.Close()
+}
+
+func TestExternalEventService_Shutdown(t *testing.T) {
+	newService := func(t *testing.T, log *eventLog, onShutdown func()) *ExternalEventService {
+		t.Helper()
+
+		lightID, groupID := "light-1", "group-home"
+		cfg := &config.Config{}
+		cfg.Location.Latitude = 52.5
+		cfg.Location.Longitude = 13.4
+		cfg.Lights = append(cfg.Lights, config.LightConfig{ID: &lightID})
+		cfg.Shutdown.GroupedLightID = &groupID
+
+		logger := logrus.New().WithField("test", t.Name())
+		lightService := light_automation.NewService(slowLightClient{log: log}, cfg, logger)
+
+		service := NewExternalEventService(lightService, logger, onShutdown, WithSocketPath(filepath.Join(t.TempDir(), "events.sock")))
+		service.listen = func(network, address string) (net.Listener, error) {
+			listener, err := net.Listen(network, address)
+			if err != nil {
+				return nil, err
+			}
+			return &recordingListener{Listener: listener, log: log}, nil
+		}
+		require.NoError(t, service.Start())
+		return service
+	}
+
+	t.Run("turns off lights of shutdown event in progress before closing the listener", func(t *testing.T) {
+		log := &eventLog{}
+		shutdownRequested := make(chan struct{})
+		service := newService(t, log, func() { close(shutdownRequested) })
+
+		require.NoError(t, service.StopAndTurnOffLights())
+		// The termination signal arrives while the lights are turned off
+		time.Sleep(20 * time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		require.NoError(t, service.Shutdown(ctx))
+
+		assert.Equal(t, []string{"lights off", "listener closed"}, log.get())
+		assert.NoFileExists(t, service.SocketPath())
+		select {
+		case <-shutdownRequested:
+		default:
+			t.Fatal("onShutdown was not called")
+		}
+	})
+
+	t.Run("closes the listener without events in progress", func(t *testing.T) {
+		log := &eventLog{}
+		service := newService(t, log, nil)
+
+		require.NoError(t, service.Shutdown(context.Background()))
+
+		assert.Equal(t, []string{"listener closed"}, log.get())
+		_, err := net.Dial("unix", service.SocketPath())
+		assert.Error(t, err)
+	})
+
+	t.Run("gives up waiting when the context expires", func(t *testing.T) {
+		log := &eventLog{}
+		service := newService(t, log, nil)
+
+		require.NoError(t, service.StopAndTurnOffLights())
+		time.Sleep(20 * time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		err := service.Shutdown(ctx)
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, []string{"listener closed"}, log.get())
+		// Let the shutdown event finish before the temp dir is removed
+		<-service.loopDone
+	})
+}
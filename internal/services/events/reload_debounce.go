@@ -0,0 +1,68 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// reloadDebouncer coalesces the reload requests received within the debounce
+// window into a single reload, e.g. of editors writing a file several times. The
+// window restarts with every request and all requests receive the result of the reload.
+type reloadDebouncer struct {
+	mu      sync.Mutex
+	window  time.Duration
+	reload  func() error
+	timer   *time.Timer
+	waiters []chan error
+	// reloading serializes the reloads of consecutive windows
+	reloading sync.Mutex
+}
+
+func newReloadDebouncer(window time.Duration, reload func() error) *reloadDebouncer {
+	return &reloadDebouncer{window: window, reload: reload}
+}
+
+// Request schedules a reload at the end of the window, the returned channel
+// receives its result. Without window the reload runs immediately.
+func (d *reloadDebouncer) Request() <-chan error {
+	result := make(chan error, 1)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.waiters = append(d.waiters, result)
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+	d.timer = time.AfterFunc(d.window, d.run)
+
+	return result
+}
+
+// setWindow changes the window of the following requests.
+func (d *reloadDebouncer) setWindow(window time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.window = window
+}
+
+func (d *reloadDebouncer) run() {
+	d.mu.Lock()
+	waiters := d.waiters
+	d.waiters = nil
+	d.timer = nil
+	d.mu.Unlock()
+
+	// A stopped timer may still fire once the waiters were taken by its successor.
+	if len(waiters) == 0 {
+		return
+	}
+
+	d.reloading.Lock()
+	err := d.reload()
+	d.reloading.Unlock()
+
+	for _, waiter := range waiters {
+		waiter <- err
+	}
+}
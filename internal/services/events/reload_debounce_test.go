@@ -0,0 +1,45 @@
+package events
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReloadDebouncer_CoalescesRapidRequests(t *testing.T) {
+	var reloads atomic.Int32
+	debouncer := newReloadDebouncer(50*time.Millisecond, func() error {
+		reloads.Add(1)
+		return errors.New("invalid config")
+	})
+
+	var results []<-chan error
+	for i := 0; i < 3; i++ {
+		results = append(results, debouncer.Request())
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	for _, result := range results {
+		assert.EqualError(t, <-result, "invalid config")
+	}
+	assert.Equal(t, int32(1), reloads.Load())
+
+	// A request after the window reloads again
+	assert.EqualError(t, <-debouncer.Request(), "invalid config")
+	assert.Equal(t, int32(2), reloads.Load())
+}
+
+func TestReloadDebouncer_WithoutWindow(t *testing.T) {
+	var reloads atomic.Int32
+	debouncer := newReloadDebouncer(0, func() error {
+		reloads.Add(1)
+		return nil
+	})
+
+	assert.NoError(t, <-debouncer.Request())
+	assert.NoError(t, <-debouncer.Request())
+	assert.Equal(t, int32(2), reloads.Load())
+}
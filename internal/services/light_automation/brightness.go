@@ -0,0 +1,43 @@
+package light_automation
+
+import "com.github.yveskaufmann/hue-lighter/internal/config"
+
+// clampBrightness raises the requested brightness to the highest of the given floors.
+func clampBrightness(requested float32, floors ...float32) float32 {
+	brightness := requested
+	for _, floor := range floors {
+		if brightness < floor {
+			brightness = floor
+		}
+	}
+	return brightness
+}
+
+// capBrightness lowers the brightness to the configured max_brightness, which
+// wins over the floors. The caller must hold s.mu.
+func (s *Service) capBrightness(brightness float32) float32 {
+	if maxBrightness := s.config.Automation.MaxBrightness; maxBrightness != nil && brightness > *maxBrightness {
+		return *maxBrightness
+	}
+	return brightness
+}
+
+// brightnessFor returns the brightness to request for the light, clamped to the
+// configured min_brightness and the min_dim_level reported by the bulb and capped
+// to max_brightness. The caller must hold s.mu and lightCfg.Brightness must be set.
+func (s *Service) brightnessFor(lightCfg config.LightConfig) float32 {
+	var configuredFloor float32
+	if lightCfg.MinBrightness != nil {
+		configuredFloor = *lightCfg.MinBrightness
+	}
+
+	brightness := clampBrightness(*lightCfg.Brightness, configuredFloor, s.minDimLevels[*lightCfg.ID])
+	if brightness != *lightCfg.Brightness {
+		s.logger.Infof("Raised brightness of light ID: %s from %.2f%% to its minimum of %.2f%%", *lightCfg.ID, *lightCfg.Brightness, brightness)
+	}
+	if capped := s.capBrightness(brightness); capped != brightness {
+		s.logger.Infof("Lowered brightness of light ID: %s from %.2f%% to the maximum of %.2f%%", *lightCfg.ID, brightness, capped)
+		brightness = capped
+	}
+	return brightness
+}
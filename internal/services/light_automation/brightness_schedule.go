@@ -0,0 +1,104 @@
+package light_automation
+
+import (
+	"math"
+	"time"
+
+	"com.github.yveskaufmann/hue-lighter/internal/config"
+	hueclient "com.github.yveskaufmann/hue-lighter/internal/hue_client"
+	"com.github.yveskaufmann/hue-lighter/internal/sunset"
+)
+
+// interpolateBrightness evaluates the brightness schedule at the given offset to
+// the sunset, each fade follows the easing of the point it ends at. Before the
+// first and after the last point their brightness is used. The points must be
+// ordered by offset and not be empty.
+func interpolateBrightness(points []config.BrightnessPoint, offset time.Duration) float32 {
+	if offset <= points[0].Offset {
+		return points[0].Brightness
+	}
+
+	for i := 1; i < len(points); i++ {
+		from, to := points[i-1], points[i]
+		if offset < to.Offset {
+			progress := ease(to.Easing, float64(offset-from.Offset)/float64(to.Offset-from.Offset))
+			return from.Brightness + float32(progress)*(to.Brightness-from.Brightness)
+		}
+	}
+
+	return points[len(points)-1].Brightness
+}
+
+// applyBrightnessSchedule updates the brightness of all lights which are on to the
+// value of the brightness schedule at tickTime. The brightness is rounded to whole
+// percents and requests are only sent when it changed.
+func (s *Service) applyBrightnessSchedule(tickTime time.Time, sunriseTime time.Time, sunsetTime time.Time) {
+	s.bridgeMu.Lock()
+	defer s.bridgeMu.Unlock()
+
+	updates := s.brightnessScheduleUpdates(tickTime, sunriseTime, sunsetTime)
+	errs := s.sendLightUpdates(updates)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, u := range updates {
+		if errs[i] != nil {
+			s.logger.Errorf("Failed to set scheduled brightness of light ID: %s, error: %v", u.id, errs[i])
+			continue
+		}
+
+		brightness := u.update.Dimming.Brightness
+		s.logger.Infof("Set brightness of light ID: %s to %.0f%% by schedule", u.id, brightness)
+		s.appliedBrightness[u.id] = brightness
+	}
+}
+
+// brightnessScheduleUpdates returns the updates of the lights which are on and
+// whose brightness differs from the schedule at tickTime.
+func (s *Service) brightnessScheduleUpdates(tickTime time.Time, sunriseTime time.Time, sunsetTime time.Time) []lightUpdate {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if len(s.config.BrightnessSchedule) == 0 {
+		return nil
+	}
+
+	// After midnight the evening started with the sunset of the previous day
+	if tickTime.Before(sunriseTime) {
+		_, sunsetTime = sunset.CalculateSunriseSunsetAt(s.config.Location.Latitude, s.config.Location.Longitude, tickTime.AddDate(0, 0, -1))
+	}
+
+	scheduled := interpolateBrightness(s.config.BrightnessSchedule, tickTime.Sub(sunsetTime))
+	scheduled = float32(math.Round(float64(scheduled)))
+
+	var updates []lightUpdate
+	for _, lightCfg := range s.enabledLights() {
+		id := *lightCfg.ID
+		if !s.lightStates[id] {
+			continue
+		}
+
+		var configuredFloor float32
+		if lightCfg.MinBrightness != nil {
+			configuredFloor = *lightCfg.MinBrightness
+		}
+		brightness := s.capBrightness(clampBrightness(scheduled, configuredFloor, s.minDimLevels[id]))
+		if applied, ok := s.appliedBrightness[id]; ok && applied == brightness {
+			continue
+		}
+
+		updates = append(updates, lightUpdate{id: id, update: &hueclient.LightBodyUpdate{
+			Dimming: &hueclient.LightDimmingState{Brightness: brightness},
+		}})
+	}
+	return updates
+}
+
+// resetBrightnessSchedule forgets the applied brightness, so that the schedule is
+// applied again in the next evening.
+func (s *Service) resetBrightnessSchedule() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	clear(s.appliedBrightness)
+}
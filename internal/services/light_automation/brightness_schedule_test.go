@@ -0,0 +1,123 @@
+package light_automation
+
+import (
+	"testing"
+	"time"
+
+	"com.github.yveskaufmann/hue-lighter/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterpolateBrightness(t *testing.T) {
+	points := []config.BrightnessPoint{
+		{Offset: -30 * time.Minute, Brightness: 100},
+		{Offset: 2 * time.Hour, Brightness: 50},
+		{Offset: 4 * time.Hour, Brightness: 10},
+	}
+
+	tests := []struct {
+		name     string
+		offset   time.Duration
+		expected float32
+	}{
+		{name: "before first point", offset: -2 * time.Hour, expected: 100},
+		{name: "at first point", offset: -30 * time.Minute, expected: 100},
+		{name: "between first and second point", offset: 45 * time.Minute, expected: 75},
+		{name: "at inner point", offset: 2 * time.Hour, expected: 50},
+		{name: "between second and last point", offset: 3 * time.Hour, expected: 30},
+		{name: "at last point", offset: 4 * time.Hour, expected: 10},
+		{name: "after last point", offset: 8 * time.Hour, expected: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.expected, interpolateBrightness(points, tt.offset), 0.001)
+		})
+	}
+
+	t.Run("follows the easing of the point the fade ends at", func(t *testing.T) {
+		eased := []config.BrightnessPoint{
+			{Offset: 0, Brightness: 100},
+			{Offset: 4 * time.Hour, Brightness: 20, Easing: config.EasingEaseIn},
+		}
+
+		assert.InDelta(t, 80, interpolateBrightness(eased, 2*time.Hour), 0.001)
+	})
+
+	t.Run("single point", func(t *testing.T) {
+		single := []config.BrightnessPoint{{Offset: time.Hour, Brightness: 40}}
+
+		assert.Equal(t, float32(40), interpolateBrightness(single, 0))
+		assert.Equal(t, float32(40), interpolateBrightness(single, 2*time.Hour))
+	})
+}
+
+func TestService_ApplyBrightnessSchedule(t *testing.T) {
+	sunriseTime := time.Date(2025, 1, 10, 7, 0, 0, 0, time.UTC)
+	sunsetTime := time.Date(2025, 1, 10, 17, 0, 0, 0, time.UTC)
+	minBrightness := float32(20)
+
+	client := newFakeLightClient()
+	cfg := newTestConfig("light-1", "light-2", "light-3")
+	cfg.Lights[2].MinBrightness = &minBrightness
+	cfg.BrightnessSchedule = []config.BrightnessPoint{
+		{Offset: 0, Brightness: 80},
+		{Offset: 4 * time.Hour, Brightness: 10},
+	}
+
+	service := newTestService(t, client, cfg)
+	service.lightStates["light-1"] = true
+	service.lightStates["light-2"] = false
+	service.lightStates["light-3"] = true
+
+	service.applyBrightnessSchedule(sunsetTime.Add(2*time.Hour), sunriseTime, sunsetTime)
+	assert.Equal(t, []string{"update light-1", "update light-3"}, client.Calls())
+	require.NotNil(t, client.Update("light-1").Dimming)
+	assert.Equal(t, float32(45), client.Update("light-1").Dimming.Brightness)
+
+	// No request while the rounded brightness is unchanged
+	service.applyBrightnessSchedule(sunsetTime.Add(2*time.Hour+time.Second), sunriseTime, sunsetTime)
+	assert.Len(t, client.Calls(), 2)
+
+	// The end of the schedule is clamped to the min brightness of light-3
+	service.applyBrightnessSchedule(sunsetTime.Add(5*time.Hour), sunriseTime, sunsetTime)
+	assert.Len(t, client.Calls(), 4)
+	assert.Equal(t, float32(10), client.Update("light-1").Dimming.Brightness)
+	assert.Equal(t, float32(20), client.Update("light-3").Dimming.Brightness)
+}
+
+func TestService_ApplyBrightnessScheduleCapsToMaxBrightness(t *testing.T) {
+	sunriseTime := time.Date(2025, 1, 10, 7, 0, 0, 0, time.UTC)
+	sunsetTime := time.Date(2025, 1, 10, 17, 0, 0, 0, time.UTC)
+	maxBrightness := float32(70)
+
+	client := newFakeLightClient()
+	cfg := newTestConfig("light-1")
+	cfg.Automation.MaxBrightness = &maxBrightness
+	cfg.BrightnessSchedule = []config.BrightnessPoint{
+		{Offset: 0, Brightness: 100},
+		{Offset: 4 * time.Hour, Brightness: 10},
+	}
+
+	service := newTestService(t, client, cfg)
+	service.lightStates["light-1"] = true
+
+	service.applyBrightnessSchedule(sunsetTime, sunriseTime, sunsetTime)
+	require.NotNil(t, client.Update("light-1").Dimming)
+	assert.Equal(t, float32(70), client.Update("light-1").Dimming.Brightness)
+
+	service.applyBrightnessSchedule(sunsetTime.Add(4*time.Hour), sunriseTime, sunsetTime)
+	assert.Equal(t, float32(10), client.Update("light-1").Dimming.Brightness)
+}
+
+func TestService_ApplyBrightnessScheduleDisabled(t *testing.T) {
+	client := newFakeLightClient()
+	service := newTestService(t, client, newTestConfig("light-1"))
+	service.lightStates["light-1"] = true
+
+	now := time.Date(2025, 1, 10, 20, 0, 0, 0, time.UTC)
+	service.applyBrightnessSchedule(now, now.Add(-12*time.Hour), now.Add(-3*time.Hour))
+
+	assert.Empty(t, client.Calls())
+}
@@ -0,0 +1,210 @@
+package light_automation
+
+import (
+	"encoding/json"
+	"testing"
+
+	"com.github.yveskaufmann/hue-lighter/internal/config"
+	hueclient "com.github.yveskaufmann/hue-lighter/internal/hue_client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func float32Ptr(v float32) *float32 {
+	return &v
+}
+
+func TestClampBrightness(t *testing.T) {
+	assert.Equal(t, float32(10), clampBrightness(1, 10))
+	assert.Equal(t, float32(50), clampBrightness(50, 10))
+	assert.Equal(t, float32(12.5), clampBrightness(1, 10, 12.5))
+	assert.Equal(t, float32(1), clampBrightness(1))
+}
+
+func TestService_TurnOnClampsToMinBrightness(t *testing.T) {
+	tests := []struct {
+		name               string
+		brightness         float32
+		minBrightness      *float32
+		reportedMinDimming float32
+		expectedBrightness float32
+	}{
+		{
+			name:               "requested 1% is raised to configured floor",
+			brightness:         1,
+			minBrightness:      float32Ptr(10),
+			expectedBrightness: 10,
+		},
+		{
+			name:               "requested brightness above floor is kept",
+			brightness:         40,
+			minBrightness:      float32Ptr(10),
+			expectedBrightness: 40,
+		},
+		{
+			name:               "reported min_dim_level is used when higher than configured floor",
+			brightness:         1,
+			minBrightness:      float32Ptr(2),
+			reportedMinDimming: 5,
+			expectedBrightness: 5,
+		},
+		{
+			name:               "reported min_dim_level is used without configured floor",
+			brightness:         1,
+			reportedMinDimming: 3,
+			expectedBrightness: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newFakeLightClient()
+			if tt.reportedMinDimming > 0 {
+				client.dimming["light-1"] = &hueclient.LightDimmingState{MinDimLevel: tt.reportedMinDimming}
+			}
+
+			cfg := newTestConfig("light-1")
+			cfg.Lights[0].Brightness = float32Ptr(tt.brightness)
+			cfg.Lights[0].MinBrightness = tt.minBrightness
+
+			service := newTestService(t, client, cfg)
+			service.refreshLightStates()
+			service.setLightsState(true, 0)
+
+			update := client.Update("light-1")
+			require.NotNil(t, update)
+			require.NotNil(t, update.On)
+			assert.True(t, update.On.On)
+			require.NotNil(t, update.Dimming)
+			assert.Equal(t, tt.expectedBrightness, update.Dimming.Brightness)
+		})
+	}
+}
+
+func TestService_TurnOnCapsToMaxBrightness(t *testing.T) {
+	tests := []struct {
+		name               string
+		brightness         float32
+		minBrightness      *float32
+		maxBrightness      *float32
+		expectedBrightness float32
+	}{
+		{
+			name:               "requested 100% is capped to max brightness",
+			brightness:         100,
+			maxBrightness:      float32Ptr(80),
+			expectedBrightness: 80,
+		},
+		{
+			name:               "requested brightness below max brightness is kept",
+			brightness:         40,
+			maxBrightness:      float32Ptr(80),
+			expectedBrightness: 40,
+		},
+		{
+			name:               "max brightness wins over min brightness",
+			brightness:         10,
+			minBrightness:      float32Ptr(90),
+			maxBrightness:      float32Ptr(80),
+			expectedBrightness: 80,
+		},
+		{
+			name:               "requested 100% is kept without max brightness",
+			brightness:         100,
+			expectedBrightness: 100,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newFakeLightClient()
+			cfg := newTestConfig("light-1")
+			cfg.Lights[0].Brightness = float32Ptr(tt.brightness)
+			cfg.Lights[0].MinBrightness = tt.minBrightness
+			cfg.Automation.MaxBrightness = tt.maxBrightness
+
+			service := newTestService(t, client, cfg)
+			service.setLightsState(true, 0)
+
+			update := client.Update("light-1")
+			require.NotNil(t, update)
+			require.NotNil(t, update.Dimming)
+			assert.Equal(t, tt.expectedBrightness, update.Dimming.Brightness)
+		})
+	}
+}
+
+func TestService_TurnOnWithoutBrightnessKeepsCurrentBrightness(t *testing.T) {
+	client := newFakeLightClient()
+	cfg := newTestConfig("light-1")
+	cfg.Lights[0].MinBrightness = float32Ptr(10)
+
+	service := newTestService(t, client, cfg)
+	service.setLightsState(true, 0)
+
+	assert.Equal(t, []string{"on light-1"}, client.Calls())
+}
+
+func TestService_TurnOnWithoutBrightnessCapsToMaxBrightness(t *testing.T) {
+	client := newFakeLightClient()
+	cfg := newTestConfig("light-1")
+	cfg.Automation.MaxBrightness = float32Ptr(80)
+
+	service := newTestService(t, client, cfg)
+	service.setLightsState(true, 0)
+
+	assert.Equal(t, []string{"update light-1"}, client.Calls())
+	body, err := json.Marshal(client.Update("light-1"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"on":{"on":true},"dimming":{"brightness":80}}`, string(body))
+}
+
+func TestService_TurnOnComposesTargetState(t *testing.T) {
+	mirek := 366
+
+	tests := []struct {
+		name         string
+		light        func(lightCfg *config.LightConfig)
+		expectedBody string
+	}{
+		{
+			name: "brightness and color temperature",
+			light: func(lightCfg *config.LightConfig) {
+				lightCfg.Brightness = float32Ptr(40)
+				lightCfg.ColorTemperature = &mirek
+			},
+			expectedBody: `{"on":{"on":true},"dimming":{"brightness":40},"color_temperature":{"mirek":366}}`,
+		},
+		{
+			name: "brightness and color",
+			light: func(lightCfg *config.LightConfig) {
+				lightCfg.Brightness = float32Ptr(60)
+				lightCfg.Color = &config.ColorXY{X: 0.5, Y: 0.4}
+			},
+			expectedBody: `{"on":{"on":true},"dimming":{"brightness":60},"color":{"xy":{"x":0.5,"y":0.4}}}`,
+		},
+		{
+			name: "color only keeps the current brightness",
+			light: func(lightCfg *config.LightConfig) {
+				lightCfg.Color = &config.ColorXY{X: 0.5, Y: 0.4}
+			},
+			expectedBody: `{"on":{"on":true},"color":{"xy":{"x":0.5,"y":0.4}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newFakeLightClient()
+			cfg := newTestConfig("light-1")
+			tt.light(&cfg.Lights[0])
+
+			service := newTestService(t, client, cfg)
+			service.setLightsState(true, 0)
+
+			assert.Equal(t, []string{"update light-1"}, client.Calls())
+			body, err := json.Marshal(client.Update("light-1"))
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expectedBody, string(body))
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package light_automation
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"com.github.yveskaufmann/hue-lighter/internal/config"
+	hueclient "com.github.yveskaufmann/hue-lighter/internal/hue_client"
+	"com.github.yveskaufmann/hue-lighter/internal/sunset"
+)
+
+// interpolateMirek linearly interpolates the color temperature between start at
+// windowStart and end at windowEnd. Outside of the window the nearest end is used.
+func interpolateMirek(start int, end int, windowStart time.Time, windowEnd time.Time, t time.Time) int {
+	if !t.After(windowStart) {
+		return start
+	}
+	if !t.Before(windowEnd) {
+		return end
+	}
+
+	progress := float64(t.Sub(windowStart)) / float64(windowEnd.Sub(windowStart))
+	return int(math.Round(float64(start) + progress*float64(end-start)))
+}
+
+// colorTemperatureWindow returns the evening window which starts at the given
+// sunset and ends at the next occurrence of endTime after it.
+func colorTemperatureWindow(endTime config.ClockTime, sunsetTime time.Time, loc *time.Location) (time.Time, time.Time) {
+	windowStart := sunsetTime.In(loc)
+	windowEnd := endTime.On(windowStart)
+	if !windowEnd.After(windowStart) {
+		windowEnd = windowEnd.AddDate(0, 0, 1)
+	}
+	return windowStart, windowEnd
+}
+
+// applyColorTemperature updates the color temperature of all lights which are on
+// and have no color of their own to the value interpolated for tickTime. Requests are only sent when the value changed.
+func (s *Service) applyColorTemperature(tickTime time.Time, sunriseTime time.Time, sunsetTime time.Time) {
+	s.bridgeMu.Lock()
+	defer s.bridgeMu.Unlock()
+
+	mirek, ids := s.colorTemperatureTargets(tickTime, sunriseTime, sunsetTime)
+	errs := make([]error, len(ids))
+	for i, id := range ids {
+		errs[i] = s.client.SetColorTemperatureById(id, mirek)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, id := range ids {
+		if err := errs[i]; err != nil {
+			if errors.Is(err, hueclient.ErrUnsupportedCapability) {
+				// Tried again once the color temperature changes, e.g. after the bulb was replaced
+				s.logger.Debugf("Light ID: %s does not support color temperatures, skipping it", id)
+				s.appliedMirek[id] = mirek
+				continue
+			}
+			s.logger.Errorf("Failed to set color temperature of light ID: %s, error: %v", id, err)
+			continue
+		}
+
+		s.logger.Infof("Set color temperature of light ID: %s to %d mirek", id, mirek)
+		s.appliedMirek[id] = mirek
+	}
+}
+
+// colorTemperatureTargets returns the color temperature interpolated for tickTime
+// and the IDs of the lights to update to it, none if the gradient is disabled.
+func (s *Service) colorTemperatureTargets(tickTime time.Time, sunriseTime time.Time, sunsetTime time.Time) (int, []string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if !s.config.ColorTemperatureEnabled() {
+		return 0, nil
+	}
+
+	// After midnight the evening started with the sunset of the previous day
+	if tickTime.Before(sunriseTime) {
+		_, sunsetTime = sunset.CalculateSunriseSunsetAt(s.config.Location.Latitude, s.config.Location.Longitude, tickTime.AddDate(0, 0, -1))
+	}
+
+	ct := s.config.ColorTemperature
+	windowStart, windowEnd := colorTemperatureWindow(*ct.EndTime, sunsetTime, tickTime.Location())
+	mirek := interpolateMirek(*ct.StartMirek, *ct.EndMirek, windowStart, windowEnd, tickTime)
+
+	var ids []string
+	for _, lightCfg := range s.enabledLights() {
+		id := *lightCfg.ID
+		if s.lightStates[id] && !lightCfg.HasColor() && s.appliedMirek[id] != mirek {
+			ids = append(ids, id)
+		}
+	}
+	return mirek, ids
+}
+
+// resetColorTemperature forgets the applied color temperatures, so that they are
+// applied again in the next evening.
+func (s *Service) resetColorTemperature() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	clear(s.appliedMirek)
+}
@@ -0,0 +1,132 @@
+package light_automation
+
+import (
+	"testing"
+	"time"
+
+	"com.github.yveskaufmann/hue-lighter/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterpolateMirek(t *testing.T) {
+	windowStart := time.Date(2025, 1, 10, 17, 0, 0, 0, time.UTC)
+	windowEnd := time.Date(2025, 1, 10, 23, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		at       time.Time
+		expected int
+	}{
+		{name: "before window", at: windowStart.Add(-time.Hour), expected: 250},
+		{name: "at window start", at: windowStart, expected: 250},
+		{name: "quarter of window", at: windowStart.Add(90 * time.Minute), expected: 300},
+		{name: "half of window", at: windowStart.Add(3 * time.Hour), expected: 350},
+		{name: "three quarters of window", at: windowStart.Add(270 * time.Minute), expected: 400},
+		{name: "at window end", at: windowEnd, expected: 450},
+		{name: "after window", at: windowEnd.Add(time.Hour), expected: 450},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, interpolateMirek(250, 450, windowStart, windowEnd, tt.at))
+		})
+	}
+}
+
+func TestColorTemperatureWindow(t *testing.T) {
+	sunsetTime := time.Date(2025, 1, 10, 16, 30, 0, 0, time.UTC)
+
+	t.Run("end time on the same evening", func(t *testing.T) {
+		start, end := colorTemperatureWindow(config.ClockTime{Hour: 23}, sunsetTime, time.UTC)
+
+		assert.Equal(t, sunsetTime, start)
+		assert.Equal(t, time.Date(2025, 1, 10, 23, 0, 0, 0, time.UTC), end)
+	})
+
+	t.Run("end time after midnight", func(t *testing.T) {
+		_, end := colorTemperatureWindow(config.ClockTime{Hour: 1, Minute: 30}, sunsetTime, time.UTC)
+
+		assert.Equal(t, time.Date(2025, 1, 11, 1, 30, 0, 0, time.UTC), end)
+	})
+}
+
+func TestService_ApplyColorTemperature(t *testing.T) {
+	startMirek, endMirek := 250, 450
+	sunriseTime := time.Date(2025, 1, 10, 7, 0, 0, 0, time.UTC)
+	sunsetTime := time.Date(2025, 1, 10, 17, 0, 0, 0, time.UTC)
+
+	client := newFakeLightClient()
+	cfg := newTestConfig("light-1", "light-2")
+	cfg.ColorTemperature.StartMirek = &startMirek
+	cfg.ColorTemperature.EndMirek = &endMirek
+	cfg.ColorTemperature.EndTime = &config.ClockTime{Hour: 23}
+
+	service := newTestService(t, client, cfg)
+	service.lightStates["light-1"] = true
+	service.lightStates["light-2"] = false
+
+	service.applyColorTemperature(sunsetTime.Add(3*time.Hour), sunriseTime, sunsetTime)
+	assert.Equal(t, []string{"mirek light-1 350"}, client.Calls())
+
+	// No request while the interpolated value is unchanged
+	service.applyColorTemperature(sunsetTime.Add(3*time.Hour+time.Second), sunriseTime, sunsetTime)
+	assert.Equal(t, []string{"mirek light-1 350"}, client.Calls())
+
+	service.applyColorTemperature(sunsetTime.Add(6*time.Hour), sunriseTime, sunsetTime)
+	assert.Equal(t, []string{"mirek light-1 350", "mirek light-1 450"}, client.Calls())
+}
+
+func TestService_ApplyColorTemperatureSkipsLightsWithOwnColor(t *testing.T) {
+	startMirek, endMirek, ownMirek := 250, 450, 200
+	sunriseTime := time.Date(2025, 1, 10, 7, 0, 0, 0, time.UTC)
+	sunsetTime := time.Date(2025, 1, 10, 17, 0, 0, 0, time.UTC)
+
+	client := newFakeLightClient()
+	cfg := newTestConfig("light-1", "light-2", "light-3")
+	cfg.ColorTemperature.StartMirek = &startMirek
+	cfg.ColorTemperature.EndMirek = &endMirek
+	cfg.ColorTemperature.EndTime = &config.ClockTime{Hour: 23}
+	cfg.Lights[1].ColorTemperature = &ownMirek
+	cfg.Lights[2].Color = &config.ColorXY{X: 0.5, Y: 0.4}
+
+	service := newTestService(t, client, cfg)
+	for _, id := range []string{"light-1", "light-2", "light-3"} {
+		service.lightStates[id] = true
+	}
+
+	service.applyColorTemperature(sunsetTime.Add(3*time.Hour), sunriseTime, sunsetTime)
+
+	assert.Equal(t, []string{"mirek light-1 350"}, client.Calls())
+}
+
+func TestService_ApplyColorTemperatureSkipsLightsWithoutColorTemperature(t *testing.T) {
+	startMirek, endMirek := 250, 450
+	sunriseTime := time.Date(2025, 1, 10, 7, 0, 0, 0, time.UTC)
+	sunsetTime := time.Date(2025, 1, 10, 17, 0, 0, 0, time.UTC)
+
+	client := newFakeLightClient()
+	client.withoutColorTemperature["light-1"] = true
+	cfg := newTestConfig("light-1")
+	cfg.ColorTemperature.StartMirek = &startMirek
+	cfg.ColorTemperature.EndMirek = &endMirek
+	cfg.ColorTemperature.EndTime = &config.ClockTime{Hour: 23}
+
+	service := newTestService(t, client, cfg)
+	service.lightStates["light-1"] = true
+
+	service.applyColorTemperature(sunsetTime.Add(3*time.Hour), sunriseTime, sunsetTime)
+	service.applyColorTemperature(sunsetTime.Add(3*time.Hour+time.Second), sunriseTime, sunsetTime)
+
+	assert.Equal(t, []string{"mirek light-1 350"}, client.Calls(), "not retried every tick")
+}
+
+func TestService_ApplyColorTemperatureDisabled(t *testing.T) {
+	client := newFakeLightClient()
+	service := newTestService(t, client, newTestConfig("light-1"))
+	service.lightStates["light-1"] = true
+
+	now := time.Date(2025, 1, 10, 20, 0, 0, 0, time.UTC)
+	service.applyColorTemperature(now, now.Add(-12*time.Hour), now.Add(-3*time.Hour))
+
+	assert.Empty(t, client.Calls())
+}
@@ -0,0 +1,28 @@
+package light_automation
+
+import "sync"
+
+// maxConcurrentLightRequests bounds the requests sent to the bridge at once, the
+// bridge processes only a few requests in parallel and rejects them when overloaded.
+const maxConcurrentLightRequests = 5
+
+// forEachConcurrently calls fn for every index below n with at most limit calls
+// running at once and returns after all calls returned.
+func forEachConcurrently(n, limit int, fn func(i int)) {
+	if limit < 1 {
+		limit = 1
+	}
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, limit)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,137 @@
+package light_automation
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	hueclient "com.github.yveskaufmann/hue-lighter/internal/hue_client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForEachConcurrently(t *testing.T) {
+	tests := []struct {
+		name  string
+		n     int
+		limit int
+	}{
+		{name: "more calls than the limit", n: 20, limit: 3},
+		{name: "fewer calls than the limit", n: 2, limit: 5},
+		{name: "no calls", n: 0, limit: 5},
+		{name: "limit below one runs sequentially", n: 4, limit: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var running, maxRunning atomic.Int32
+			var mu sync.Mutex
+			called := map[int]bool{}
+
+			forEachConcurrently(tt.n, tt.limit, func(i int) {
+				current := running.Add(1)
+				defer running.Add(-1)
+				for {
+					seen := maxRunning.Load()
+					if current <= seen || maxRunning.CompareAndSwap(seen, current) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond)
+
+				mu.Lock()
+				defer mu.Unlock()
+				called[i] = true
+			})
+
+			assert.Len(t, called, tt.n)
+			assert.LessOrEqual(t, int(maxRunning.Load()), max(tt.limit, 1))
+		})
+	}
+}
+
+func TestService_RefreshLightStates_ManyLights(t *testing.T) {
+	client := newFakeLightClient()
+	var ids []string
+	for i := 0; i < 50; i++ {
+		id := fmt.Sprintf("light-%d", i)
+		ids = append(ids, id)
+		client.states[id] = i%2 == 0
+		client.dimming[id] = &hueclient.LightDimmingState{Brightness: 50, MinDimLevel: float32(i)}
+	}
+	client.failing["get light-7"] = true
+
+	service := newTestService(t, client, newTestConfig(ids...))
+
+	service.refreshLightStates()
+
+	assert.Len(t, client.Calls(), len(ids))
+	for i, id := range ids {
+		assert.Contains(t, client.Calls(), "get "+id)
+		if id == "light-7" {
+			assert.NotContains(t, service.lightStates, id)
+			continue
+		}
+		assert.Equal(t, i%2 == 0, service.lightStates[id], id)
+		assert.Equal(t, float32(i), service.minDimLevels[id], id)
+	}
+	assert.False(t, service.lastLightStateRefresh.IsZero())
+}
+
+func TestService_RefreshLightStates_DoesNotBlockStatus(t *testing.T) {
+	client := newFakeLightClient()
+	client.getBlocked = make(chan struct{})
+	client.states["light-1"] = true
+	service := newTestService(t, client, newTestConfig("light-1"))
+
+	refreshed := make(chan struct{})
+	go func() {
+		defer close(refreshed)
+		service.refreshLightStates()
+	}()
+	assert.Eventually(t, func() bool { return len(client.Calls()) == 1 }, time.Second, time.Millisecond)
+
+	status := make(chan struct{})
+	go func() {
+		defer close(status)
+		service.Status()
+	}()
+	select {
+	case <-status:
+	case <-time.After(time.Second):
+		t.Fatal("Status was blocked by the light state refresh")
+	}
+
+	close(client.getBlocked)
+	<-refreshed
+	assert.True(t, service.lightStates["light-1"])
+}
+
+func TestService_SetLightsState_DoesNotBlockStatus(t *testing.T) {
+	client := newFakeLightClient()
+	client.switchBlocked = make(chan struct{})
+	service := newTestService(t, client, newTestConfig("light-1"))
+
+	switched := make(chan struct{})
+	go func() {
+		defer close(switched)
+		service.setLightsState(true, 0)
+	}()
+	assert.Eventually(t, func() bool { return len(client.Calls()) == 1 }, time.Second, time.Millisecond)
+
+	status := make(chan Status)
+	go func() {
+		status <- service.Status()
+	}()
+	select {
+	case s := <-status:
+		assert.False(t, s.Lights[0].On, "the light is on once the bridge confirmed it")
+	case <-time.After(time.Second):
+		t.Fatal("Status was blocked by switching the lights")
+	}
+
+	close(client.switchBlocked)
+	<-switched
+	assert.True(t, service.Status().Lights[0].On)
+}
@@ -0,0 +1,21 @@
+package light_automation
+
+import "com.github.yveskaufmann/hue-lighter/internal/config"
+
+// ease maps the linear progress of a fade in [0, 1] onto the easing curve, the
+// ease curves are quadratic. Unknown easings are treated as linear.
+func ease(easing config.Easing, progress float64) float64 {
+	switch easing {
+	case config.EasingEaseIn:
+		return progress * progress
+	case config.EasingEaseOut:
+		return 1 - (1-progress)*(1-progress)
+	case config.EasingEaseInOut:
+		if progress < 0.5 {
+			return 2 * progress * progress
+		}
+		return 1 - 2*(1-progress)*(1-progress)
+	default:
+		return progress
+	}
+}
@@ -0,0 +1,32 @@
+package light_automation
+
+import (
+	"testing"
+
+	"com.github.yveskaufmann/hue-lighter/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEase(t *testing.T) {
+	tests := []struct {
+		name     string
+		easing   config.Easing
+		expected [3]float64
+	}{
+		{name: "linear", easing: config.EasingLinear, expected: [3]float64{0.25, 0.5, 0.75}},
+		{name: "unset is linear", easing: "", expected: [3]float64{0.25, 0.5, 0.75}},
+		{name: "ease-in", easing: config.EasingEaseIn, expected: [3]float64{0.0625, 0.25, 0.5625}},
+		{name: "ease-out", easing: config.EasingEaseOut, expected: [3]float64{0.4375, 0.75, 0.9375}},
+		{name: "ease-in-out", easing: config.EasingEaseInOut, expected: [3]float64{0.125, 0.5, 0.875}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i, progress := range []float64{0.25, 0.5, 0.75} {
+				assert.InDelta(t, tt.expected[i], ease(tt.easing, progress), 1e-9, "progress %.2f", progress)
+			}
+			assert.InDelta(t, 0, ease(tt.easing, 0), 1e-9)
+			assert.InDelta(t, 1, ease(tt.easing, 1), 1e-9)
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package light_automation
+
+import hueclient "com.github.yveskaufmann/hue-lighter/internal/hue_client"
+
+// ApplyStreamEvent updates the light states from an event of the bridge event
+// stream, so that lights switched by other apps are noticed without polling.
+// Events of other resources and of lights which are not enabled are ignored.
+func (s *Service) ApplyStreamEvent(event *hueclient.StreamEvent) {
+	if event.Type != hueclient.EventTypeUpdate {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	enabled := make(map[string]bool)
+	for _, lightCfg := range s.enabledLights() {
+		enabled[*lightCfg.ID] = true
+	}
+
+	for _, resource := range event.Data {
+		if resource.Type != "light" || resource.On == nil || !enabled[resource.ID] {
+			continue
+		}
+
+		if s.lightStates[resource.ID] != resource.On.On {
+			s.logger.Debugf("Light ID: %s was switched by another app, on: %t", resource.ID, resource.On.On)
+		}
+		s.lightStates[resource.ID] = resource.On.On
+		if !resource.On.On {
+			// Turned off by someone else, if it is turned on again it is not ours.
+			delete(s.ownedLights, resource.ID)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package light_automation
+
+import (
+	"testing"
+	"time"
+
+	hueclient "com.github.yveskaufmann/hue-lighter/internal/hue_client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestService_ApplyStreamEvent(t *testing.T) {
+	lightEvent := func(eventType, resourceType, id string, on *bool) *hueclient.StreamEvent {
+		resource := hueclient.StreamEventResource{ID: id, Type: resourceType}
+		if on != nil {
+			resource.On = &hueclient.LightOnState{On: *on}
+		}
+		return &hueclient.StreamEvent{Type: eventType, Data: []hueclient.StreamEventResource{resource}}
+	}
+	on, off := true, false
+
+	tests := []struct {
+		name          string
+		event         *hueclient.StreamEvent
+		expectedOn    bool
+		expectedOwned bool
+	}{
+		{
+			name:          "light turned off by another app is no longer owned",
+			event:         lightEvent(hueclient.EventTypeUpdate, "light", "light-1", &off),
+			expectedOn:    false,
+			expectedOwned: false,
+		},
+		{
+			name:          "light turned on again keeps ownership",
+			event:         lightEvent(hueclient.EventTypeUpdate, "light", "light-1", &on),
+			expectedOn:    true,
+			expectedOwned: true,
+		},
+		{
+			name:          "ignores updates without on state",
+			event:         lightEvent(hueclient.EventTypeUpdate, "light", "light-1", nil),
+			expectedOn:    true,
+			expectedOwned: true,
+		},
+		{
+			name:          "ignores other resources",
+			event:         lightEvent(hueclient.EventTypeUpdate, "grouped_light", "light-1", &off),
+			expectedOn:    true,
+			expectedOwned: true,
+		},
+		{
+			name:          "ignores other event types",
+			event:         lightEvent("delete", "light", "light-1", &off),
+			expectedOn:    true,
+			expectedOwned: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newFakeLightClient()
+			service := newTestService(t, client, newTestConfig("light-1"))
+			service.setLightsState(true, 0)
+			client.calls = nil
+
+			service.ApplyStreamEvent(tt.event)
+
+			assert.Equal(t, tt.expectedOn, service.lightStates["light-1"])
+			assert.Equal(t, tt.expectedOwned, service.ownedLights["light-1"])
+			assert.Empty(t, client.Calls())
+		})
+	}
+
+	t.Run("ignores lights which are not configured", func(t *testing.T) {
+		service := newTestService(t, newFakeLightClient(), newTestConfig("light-1"))
+
+		service.ApplyStreamEvent(lightEvent(hueclient.EventTypeUpdate, "light", "light-2", &on))
+
+		assert.NotContains(t, service.lightStates, "light-2")
+	})
+}
+
+func TestService_RunAutomation_LightStateRefreshInterval(t *testing.T) {
+	tests := []struct {
+		name            string
+		refreshInterval time.Duration
+		lastRefresh     time.Duration
+		expectedCalls   []string
+	}{
+		{
+			name:            "refreshes after the configured interval",
+			refreshInterval: time.Minute,
+			lastRefresh:     2 * time.Minute,
+			expectedCalls:   []string{"get light-1"},
+		},
+		{
+			name:            "does not refresh within the configured interval",
+			refreshInterval: 10 * time.Minute,
+			lastRefresh:     6 * time.Minute,
+		},
+		{
+			name:          "refreshes after the default interval without configured interval",
+			lastRefresh:   6 * time.Minute,
+			expectedCalls: []string{"get light-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newFakeLightClient()
+			cfg := newTestConfig("light-1")
+			cfg.Automation.LightStateRefreshInterval = tt.refreshInterval
+
+			service := newTestService(t, client, cfg)
+			service.lightStates["light-1"] = false
+			service.lastLightStateRefresh = time.Now().Add(-tt.lastRefresh)
+			// Noon, the light stays off
+			service.clock = fixedClock(time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC))
+
+			service.runAutomation()
+
+			assert.Equal(t, tt.expectedCalls, client.Calls())
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package light_automation
+
+import (
+	"time"
+
+	"com.github.yveskaufmann/hue-lighter/internal/config"
+	"com.github.yveskaufmann/hue-lighter/internal/sunset"
+)
+
+// switchLights turns every light on or off according to its trigger. Lights with
+// the "sun" trigger are on at night, lights with the "time" trigger from their on
+// time until the sunrise. No light is on once the off time of the night is reached.
+// An active smart scene turns on the lights with the "sun" trigger instead. Lights
+// turned on during the wake-up window start at the brightness of the ramp.
+func (s *Service) switchLights(tickTime time.Time, sunriseTime time.Time, night bool, offTimeReached bool) {
+	s.bridgeMu.Lock()
+	defer s.bridgeMu.Unlock()
+
+	s.mu.Lock()
+	var wakeUp *wakeUpRamp
+	if ramp, active := wakeUpRampAt(s.config, sunriseTime, tickTime); active && s.activeSmartScene == "" {
+		wakeUp = &ramp
+	}
+
+	var commands []lightCommand
+	for _, lightCfg := range s.enabledLights() {
+		turnOn := night
+		if lightCfg.Trigger == config.LightTriggerTime {
+			turnOn = s.onTimeReached(*lightCfg.OnTime, tickTime)
+		} else if turnOn && s.activeSmartScene != "" && !offTimeReached {
+			s.markSwitchedBySmartScene(lightCfg)
+			continue
+		}
+		if command, ok := s.lightStateCommand(lightCfg, turnOn && !offTimeReached, 0, wakeUp); ok {
+			commands = append(commands, command)
+		}
+	}
+	s.mu.Unlock()
+
+	s.sendLightCommands(commands)
+}
+
+// onTimeReached reports whether tickTime is between the last occurrence of onTime
+// and the sunrise following it. The caller must hold s.mu.
+func (s *Service) onTimeReached(onTime config.ClockTime, tickTime time.Time) bool {
+	start := onTime.On(tickTime)
+	if start.After(tickTime) {
+		start = start.AddDate(0, 0, -1)
+	}
+
+	latitude, longitude := s.config.Location.Latitude, s.config.Location.Longitude
+	sunriseTime, _ := sunset.CalculateSunriseSunsetAt(latitude, longitude, start)
+	if !sunriseTime.After(start) {
+		sunriseTime, _ = sunset.CalculateSunriseSunsetAt(latitude, longitude, start.AddDate(0, 0, 1))
+	}
+	return tickTime.Before(sunriseTime)
+}
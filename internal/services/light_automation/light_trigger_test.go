@@ -0,0 +1,106 @@
+package light_automation
+
+import (
+	"testing"
+	"time"
+
+	"com.github.yveskaufmann/hue-lighter/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var cest = time.FixedZone("CEST", 2*60*60)
+
+// newMixedTriggerConfig returns a config in which light-1 follows the sunset and
+// light-2 is turned on at 20:00.
+func newMixedTriggerConfig(t *testing.T) *config.Config {
+	t.Helper()
+
+	cfg := newTestConfig("light-1", "light-2")
+	onTime, err := config.ParseClockTime("20:00")
+	require.NoError(t, err)
+	cfg.Lights[1].Trigger = config.LightTriggerTime
+	cfg.Lights[1].OnTime = &onTime
+	return cfg
+}
+
+func TestService_RunAutomation_MixedTriggers(t *testing.T) {
+	client := newFakeLightClient()
+	service := newTestService(t, client, newMixedTriggerConfig(t))
+	service.lastLightStateRefresh = time.Now()
+
+	// The sun sets at 21:33 CEST on the 21st of June and rises at 04:43 CEST.
+	ticks := []struct {
+		tickTime      time.Time
+		expectedCalls []string
+	}{
+		{tickTime: time.Date(2024, 6, 21, 19, 0, 0, 0, cest)},
+		{tickTime: time.Date(2024, 6, 21, 20, 30, 0, 0, cest), expectedCalls: []string{"on light-2"}},
+		{tickTime: time.Date(2024, 6, 21, 22, 0, 0, 0, cest), expectedCalls: []string{"on light-2", "on light-1"}},
+		{tickTime: time.Date(2024, 6, 22, 3, 0, 0, 0, cest), expectedCalls: []string{"on light-2", "on light-1"}},
+		{tickTime: time.Date(2024, 6, 22, 5, 0, 0, 0, cest), expectedCalls: []string{"on light-2", "on light-1", "off light-1", "off light-2"}},
+	}
+
+	for _, tick := range ticks {
+		service.clock = fixedClock(tick.tickTime)
+		service.runAutomation()
+		assert.Equal(t, tick.expectedCalls, client.Calls(), tick.tickTime.String())
+	}
+}
+
+func TestService_RunAutomation_OffTimeAppliesToTimeTrigger(t *testing.T) {
+	client := newFakeLightClient()
+	cfg := newMixedTriggerConfig(t)
+	offTime, err := config.ParseClockTime("23:00")
+	require.NoError(t, err)
+	cfg.Automation.OffTime = &offTime
+	service := newTestService(t, client, cfg)
+	service.lastLightStateRefresh = time.Now()
+
+	service.clock = fixedClock(time.Date(2024, 6, 21, 22, 0, 0, 0, cest))
+	service.runAutomation()
+	service.clock = fixedClock(time.Date(2024, 6, 21, 23, 30, 0, 0, cest))
+	service.runAutomation()
+
+	assert.Equal(t, []string{"on light-1", "on light-2", "off light-1", "off light-2"}, client.Calls())
+}
+
+func TestService_RunAutomation_OnTimeInConfiguredTimezone(t *testing.T) {
+	client := newFakeLightClient()
+	cfg := newMixedTriggerConfig(t)
+	cfg.Location.Timezone = "Europe/Berlin"
+	service := newTestService(t, client, cfg)
+	service.lastLightStateRefresh = time.Now()
+
+	// 18:30 UTC is 20:30 CEST, after the on time of light-2
+	service.clock = fixedClock(time.Date(2024, 6, 21, 18, 30, 0, 0, time.UTC))
+	service.runAutomation()
+
+	assert.Equal(t, []string{"on light-2"}, client.Calls())
+}
+
+func TestService_OnTimeReached(t *testing.T) {
+	tests := []struct {
+		name     string
+		onTime   string
+		tickTime time.Time
+		expected bool
+	}{
+		{name: "before on time", onTime: "20:00", tickTime: time.Date(2024, 6, 21, 19, 59, 0, 0, cest)},
+		{name: "at on time", onTime: "20:00", tickTime: time.Date(2024, 6, 21, 20, 0, 0, 0, cest), expected: true},
+		{name: "past midnight before sunrise", onTime: "20:00", tickTime: time.Date(2024, 6, 22, 4, 0, 0, 0, cest), expected: true},
+		{name: "after sunrise", onTime: "20:00", tickTime: time.Date(2024, 6, 22, 5, 0, 0, 0, cest)},
+		{name: "on time past midnight", onTime: "01:00", tickTime: time.Date(2024, 6, 22, 1, 30, 0, 0, cest), expected: true},
+		{name: "before on time past midnight", onTime: "01:00", tickTime: time.Date(2024, 6, 21, 23, 0, 0, 0, cest)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newTestService(t, newFakeLightClient(), newTestConfig())
+			onTime, err := config.ParseClockTime(tt.onTime)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expected, service.onTimeReached(onTime, tt.tickTime))
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package light_automation
+
+import (
+	"time"
+
+	"com.github.yveskaufmann/hue-lighter/internal/config"
+	"com.github.yveskaufmann/hue-lighter/internal/sunset"
+)
+
+// offTimeWindow returns the window of the night in which the lights stay off
+// because of the off time. It starts at the first occurrence of offTime after the
+// evening sunset and ends at the next sunrise, or at the start of the wake-up
+// window before it. The window is empty if offTime is not before its end.
+func offTimeWindow(offTime config.ClockTime, eveningSunset time.Time, nextSunrise time.Time, wakeUp time.Duration, loc *time.Location) (time.Time, time.Time) {
+	windowStart := offTime.On(eveningSunset.In(loc))
+	if !windowStart.After(eveningSunset) {
+		windowStart = windowStart.AddDate(0, 0, 1)
+	}
+	return windowStart, nextSunrise.Add(-wakeUp)
+}
+
+// offTimeReached reports whether the configured off time has passed in the
+// current night, tickTime must be at night.
+func (s *Service) offTimeReached(tickTime time.Time, sunriseTime time.Time, sunsetTime time.Time) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	offTime := s.config.Automation.OffTime
+	if offTime == nil {
+		return false
+	}
+
+	latitude, longitude := s.config.Location.Latitude, s.config.Location.Longitude
+	eveningSunset, nextSunrise := sunsetTime, sunriseTime
+	if tickTime.Before(sunriseTime) {
+		// After midnight the evening started with the sunset of the previous day
+		_, eveningSunset = sunset.CalculateSunriseSunsetAt(latitude, longitude, tickTime.AddDate(0, 0, -1))
+	} else {
+		nextSunrise, _ = sunset.CalculateSunriseSunsetAt(latitude, longitude, tickTime.AddDate(0, 0, 1))
+	}
+
+	var wakeUp time.Duration
+	if s.config.WakeUpEnabled() {
+		wakeUp = s.config.WakeUp.Duration
+	}
+
+	windowStart, windowEnd := offTimeWindow(*offTime, eveningSunset, nextSunrise, wakeUp, tickTime.Location())
+	return !tickTime.Before(windowStart) && tickTime.Before(windowEnd)
+}
+
+// trackOffTime logs once when the off time is reached and when it ends.
+func (s *Service) trackOffTime(reached bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if reached == s.offTimeActive {
+		return
+	}
+	s.offTimeActive = reached
+
+	if reached {
+		s.logger.Infof("Off time %s reached, turning lights off for the rest of the night", s.config.Automation.OffTime)
+	} else {
+		s.logger.Info("Off time ended")
+	}
+}
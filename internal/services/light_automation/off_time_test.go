@@ -0,0 +1,88 @@
+package light_automation
+
+import (
+	"testing"
+	"time"
+
+	"com.github.yveskaufmann/hue-lighter/internal/config"
+	"com.github.yveskaufmann/hue-lighter/internal/sunset"
+	"github.com/stretchr/testify/assert"
+)
+
+func newOffTimeConfig(offTime string, lightIDs ...string) *config.Config {
+	cfg := newTestConfig(lightIDs...)
+	parsed, err := config.ParseClockTime(offTime)
+	if err != nil {
+		panic(err)
+	}
+	cfg.Automation.OffTime = &parsed
+	return cfg
+}
+
+func TestService_OffTimeReached(t *testing.T) {
+	day := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		offTime        string
+		wakeUp         time.Duration
+		tickTime       time.Time
+		expectedActive bool
+	}{
+		{name: "before off time in the evening", offTime: "01:00", tickTime: time.Date(2025, 1, 10, 22, 0, 0, 0, time.UTC)},
+		{name: "after off time past midnight", offTime: "01:00", tickTime: time.Date(2025, 1, 11, 1, 0, 0, 0, time.UTC), expectedActive: true},
+		{name: "before off time past midnight", offTime: "01:00", tickTime: time.Date(2025, 1, 11, 0, 59, 0, 0, time.UTC)},
+		{name: "after off time before midnight", offTime: "23:00", tickTime: time.Date(2025, 1, 10, 23, 30, 0, 0, time.UTC), expectedActive: true},
+		{name: "off time before midnight still applies past midnight", offTime: "23:00", tickTime: time.Date(2025, 1, 11, 3, 0, 0, 0, time.UTC), expectedActive: true},
+		{name: "ends with wake-up window", offTime: "01:00", wakeUp: 8 * time.Hour, tickTime: time.Date(2025, 1, 11, 1, 0, 0, 0, time.UTC)},
+		{name: "off time at day is moved to next day", offTime: "12:00", tickTime: time.Date(2025, 1, 10, 23, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newOffTimeConfig(tt.offTime)
+			cfg.WakeUp.Duration = tt.wakeUp
+			service := newTestService(t, newFakeLightClient(), cfg)
+
+			sunriseTime, sunsetTime := sunset.CalculateSunriseSunsetAt(cfg.Location.Latitude, cfg.Location.Longitude, tt.tickTime)
+			assert.True(t, isNight(tt.tickTime, sunriseTime, sunsetTime), "test tick must be at night")
+
+			assert.Equal(t, tt.expectedActive, service.offTimeReached(tt.tickTime, sunriseTime, sunsetTime))
+		})
+	}
+
+	t.Run("disabled without off time", func(t *testing.T) {
+		cfg := newTestConfig()
+		service := newTestService(t, newFakeLightClient(), cfg)
+		tickTime := time.Date(2025, 1, 11, 3, 0, 0, 0, time.UTC)
+		sunriseTime, sunsetTime := sunset.CalculateSunriseSunsetAt(cfg.Location.Latitude, cfg.Location.Longitude, day)
+
+		assert.False(t, service.offTimeReached(tickTime, sunriseTime, sunsetTime))
+	})
+}
+
+func TestService_RunAutomation_OffTimeTurnsLightsOff(t *testing.T) {
+	client := newFakeLightClient()
+	service := newTestService(t, client, newOffTimeConfig("01:00", "light-1"))
+	service.lastLightStateRefresh = time.Now()
+
+	service.clock = fixedClock(time.Date(2025, 1, 10, 23, 0, 0, 0, time.UTC))
+	service.runAutomation()
+	assert.Equal(t, []string{"on light-1"}, client.Calls())
+
+	service.clock = fixedClock(time.Date(2025, 1, 11, 1, 0, 0, 0, time.UTC))
+	service.runAutomation()
+	assert.Equal(t, []string{"on light-1", "off light-1"}, client.Calls())
+	assert.True(t, service.offTimeActive)
+
+	// The lights are not turned on again for the rest of the night
+	service.clock = fixedClock(time.Date(2025, 1, 11, 4, 0, 0, 0, time.UTC))
+	service.runAutomation()
+	assert.Equal(t, []string{"on light-1", "off light-1"}, client.Calls())
+
+	// but in the next evening
+	service.clock = fixedClock(time.Date(2025, 1, 11, 20, 0, 0, 0, time.UTC))
+	service.runAutomation()
+	assert.Equal(t, []string{"on light-1", "off light-1", "on light-1"}, client.Calls())
+	assert.False(t, service.offTimeActive)
+}
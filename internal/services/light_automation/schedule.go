@@ -0,0 +1,130 @@
+package light_automation
+
+import (
+	"time"
+
+	"com.github.yveskaufmann/hue-lighter/internal/config"
+	"com.github.yveskaufmann/hue-lighter/internal/sunset"
+)
+
+// Schedule is the computed schedule of a day, from its sunrise until the sunrise
+// of the next day, so that it covers the night which starts at its sunset. The
+// times are in the configured time zone.
+type Schedule struct {
+	// Date of the day in the format YYYY-MM-DD
+	Date        string    `json:"date"`
+	Sunrise     time.Time `json:"sunrise"`
+	Sunset      time.Time `json:"sunset"`
+	NextSunrise time.Time `json:"next_sunrise"`
+	// OffTime at which the lights are turned off for the rest of the night, nil if
+	// no off time is configured or it does not fall into the night.
+	OffTime *time.Time `json:"off_time,omitempty"`
+	// WakeUp is the start of the wake-up window before the next sunrise, nil if disabled.
+	WakeUp *time.Time      `json:"wake_up,omitempty"`
+	Lights []LightSchedule `json:"lights"`
+}
+
+// LightSchedule lists the periods in which a configured light is on. The off time
+// window splits the night into a period before it and one after it, when the
+// wake-up window starts.
+type LightSchedule struct {
+	ID       string              `json:"id,omitempty"`
+	Name     string              `json:"name,omitempty"`
+	Trigger  config.LightTrigger `json:"trigger"`
+	Periods  []OnPeriod          `json:"periods"`
+	Disabled bool                `json:"disabled,omitempty"`
+}
+
+// OnPeriod is a period in which a light is on.
+type OnPeriod struct {
+	On  time.Time `json:"on"`
+	Off time.Time `json:"off"`
+}
+
+// ComputeSchedule returns the schedule of the calendar day of now, or of the
+// previous day before sunrise, when the night of the previous day is still in
+// progress. The clock times of the config are interpreted in the configured time
+// zone, or in the one of now if unset.
+func ComputeSchedule(cfg *config.Config, now time.Time) Schedule {
+	if cfg.Location.Timezone != "" {
+		now = now.In(cfg.Location.TimeZone())
+	}
+	loc := now.Location()
+	latitude, longitude := cfg.Location.Latitude, cfg.Location.Longitude
+
+	sunriseTime, sunsetTime := sunset.CalculateSunriseSunsetAt(latitude, longitude, now)
+	if now.Before(sunriseTime) {
+		// After midnight the night started with the sunset of the previous day
+		now = now.AddDate(0, 0, -1)
+		sunriseTime, sunsetTime = sunset.CalculateSunriseSunsetAt(latitude, longitude, now)
+	}
+	nextSunrise, _ := sunset.CalculateSunriseSunsetAt(latitude, longitude, now.AddDate(0, 0, 1))
+	sunriseTime, sunsetTime, nextSunrise = sunriseTime.In(loc), sunsetTime.In(loc), nextSunrise.In(loc)
+
+	schedule := Schedule{
+		Date:        now.Format(time.DateOnly),
+		Sunrise:     sunriseTime,
+		Sunset:      sunsetTime,
+		NextSunrise: nextSunrise,
+		Lights:      make([]LightSchedule, 0, len(cfg.Lights)),
+	}
+
+	var wakeUp time.Duration
+	if cfg.WakeUpEnabled() {
+		wakeUp = cfg.WakeUp.Duration
+		wakeUpStart := nextSunrise.Add(-wakeUp)
+		schedule.WakeUp = &wakeUpStart
+	}
+
+	// The lights are on until the next sunrise unless the off time window of the night starts before.
+	offStart, offEnd := nextSunrise, nextSunrise
+	if cfg.Automation.OffTime != nil {
+		if start, end := offTimeWindow(*cfg.Automation.OffTime, sunsetTime, nextSunrise, wakeUp, loc); start.Before(end) {
+			offStart, offEnd = start, end
+			schedule.OffTime = &start
+		}
+	}
+
+	for _, lightCfg := range cfg.Lights {
+		light := LightSchedule{Trigger: lightCfg.Trigger, Periods: []OnPeriod{}, Disabled: !lightCfg.IsEnabled()}
+		if lightCfg.ID != nil {
+			light.ID = *lightCfg.ID
+		}
+		if lightCfg.Name != nil {
+			light.Name = *lightCfg.Name
+		}
+		if light.Trigger == "" {
+			light.Trigger = config.LightTriggerSun
+		}
+
+		if !light.Disabled {
+			on := sunsetTime
+			if light.Trigger == config.LightTriggerTime && lightCfg.OnTime != nil {
+				on = lightCfg.OnTime.On(sunriseTime)
+				if on.Before(sunriseTime) {
+					on = on.AddDate(0, 0, 1)
+				}
+			}
+			light.Periods = onPeriods(on, nextSunrise, offStart, offEnd)
+		}
+
+		schedule.Lights = append(schedule.Lights, light)
+	}
+
+	return schedule
+}
+
+// onPeriods returns the periods between on and the sunrise, without the off time window.
+func onPeriods(on time.Time, sunriseTime time.Time, offStart time.Time, offEnd time.Time) []OnPeriod {
+	periods := []OnPeriod{}
+	if on.Before(offStart) {
+		periods = append(periods, OnPeriod{On: on, Off: offStart})
+	}
+	if on.Before(offEnd) {
+		on = offEnd
+	}
+	if on.Before(sunriseTime) {
+		periods = append(periods, OnPeriod{On: on, Off: sunriseTime})
+	}
+	return periods
+}
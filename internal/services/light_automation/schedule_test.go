@@ -0,0 +1,159 @@
+package light_automation
+
+import (
+	"testing"
+	"time"
+
+	"com.github.yveskaufmann/hue-lighter/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeSchedule(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	at := func(day int, hour int, minute int, second int) time.Time {
+		return time.Date(2024, 6, day, hour, minute, second, 0, berlin)
+	}
+
+	sunriseTime, sunsetTime, nextSunrise := at(21, 4, 43, 19), at(21, 21, 33, 7), at(22, 4, 43, 33)
+	offTime, wakeUpStart := at(22, 1, 0, 0), at(22, 4, 13, 33)
+
+	tests := []struct {
+		name     string
+		config   func(cfg *config.Config)
+		expected Schedule
+	}{
+		{
+			name: "lights are on from sunset until the next sunrise",
+			expected: Schedule{
+				Date:        "2024-06-21",
+				Sunrise:     sunriseTime,
+				Sunset:      sunsetTime,
+				NextSunrise: nextSunrise,
+				Lights: []LightSchedule{
+					{ID: "light-1", Name: "Light light-1", Trigger: config.LightTriggerSun, Periods: []OnPeriod{{On: sunsetTime, Off: nextSunrise}}},
+					{ID: "light-2", Name: "Light light-2", Trigger: config.LightTriggerSun, Periods: []OnPeriod{{On: sunsetTime, Off: nextSunrise}}},
+				},
+			},
+		},
+		{
+			name: "off time and wake-up split the night, time trigger turns on before sunset",
+			config: func(cfg *config.Config) {
+				cfg.Automation.OffTime = &config.ClockTime{Hour: 1}
+				cfg.WakeUp.Duration = 30 * time.Minute
+				cfg.Lights[1].Trigger = config.LightTriggerTime
+				cfg.Lights[1].OnTime = &config.ClockTime{Hour: 20}
+			},
+			expected: Schedule{
+				Date:        "2024-06-21",
+				Sunrise:     sunriseTime,
+				Sunset:      sunsetTime,
+				NextSunrise: nextSunrise,
+				OffTime:     &offTime,
+				WakeUp:      &wakeUpStart,
+				Lights: []LightSchedule{
+					{ID: "light-1", Name: "Light light-1", Trigger: config.LightTriggerSun, Periods: []OnPeriod{
+						{On: sunsetTime, Off: offTime},
+						{On: wakeUpStart, Off: nextSunrise},
+					}},
+					{ID: "light-2", Name: "Light light-2", Trigger: config.LightTriggerTime, Periods: []OnPeriod{
+						{On: at(21, 20, 0, 0), Off: offTime},
+						{On: wakeUpStart, Off: nextSunrise},
+					}},
+				},
+			},
+		},
+		{
+			name: "time trigger after midnight and disabled light",
+			config: func(cfg *config.Config) {
+				cfg.Lights[0].Trigger = config.LightTriggerTime
+				cfg.Lights[0].OnTime = &config.ClockTime{Hour: 3, Minute: 30}
+				cfg.Lights[1].Enabled = new(bool)
+			},
+			expected: Schedule{
+				Date:        "2024-06-21",
+				Sunrise:     sunriseTime,
+				Sunset:      sunsetTime,
+				NextSunrise: nextSunrise,
+				Lights: []LightSchedule{
+					{ID: "light-1", Name: "Light light-1", Trigger: config.LightTriggerTime, Periods: []OnPeriod{{On: at(22, 3, 30, 0), Off: nextSunrise}}},
+					{ID: "light-2", Name: "Light light-2", Trigger: config.LightTriggerSun, Periods: []OnPeriod{}, Disabled: true},
+				},
+			},
+		},
+		{
+			name: "off time during the day does not apply",
+			config: func(cfg *config.Config) {
+				cfg.Automation.OffTime = &config.ClockTime{Hour: 12}
+			},
+			expected: Schedule{
+				Date:        "2024-06-21",
+				Sunrise:     sunriseTime,
+				Sunset:      sunsetTime,
+				NextSunrise: nextSunrise,
+				Lights: []LightSchedule{
+					{ID: "light-1", Name: "Light light-1", Trigger: config.LightTriggerSun, Periods: []OnPeriod{{On: sunsetTime, Off: nextSunrise}}},
+					{ID: "light-2", Name: "Light light-2", Trigger: config.LightTriggerSun, Periods: []OnPeriod{{On: sunsetTime, Off: nextSunrise}}},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig("light-1", "light-2")
+			cfg.Location.Timezone = "Europe/Berlin"
+			if tt.config != nil {
+				tt.config(cfg)
+			}
+
+			schedule := ComputeSchedule(cfg, time.Date(2024, 6, 21, 10, 0, 0, 0, time.UTC))
+
+			assert.Equal(t, tt.expected, schedule)
+		})
+	}
+}
+
+func TestComputeSchedule_BeforeSunrise(t *testing.T) {
+	cfg := newTestConfig("light-1")
+	cfg.Location.Timezone = "Europe/Berlin"
+	offTime := config.ClockTime{Hour: 1}
+	cfg.Automation.OffTime = &offTime
+
+	evening := ComputeSchedule(cfg, time.Date(2024, 6, 21, 20, 0, 0, 0, time.UTC))
+	night := ComputeSchedule(cfg, time.Date(2024, 6, 22, 0, 30, 0, 0, time.UTC))
+
+	assert.Equal(t, "2024-06-21", night.Date, "the night in progress started on the previous day")
+	assert.Equal(t, evening, night)
+	require.NotNil(t, night.OffTime)
+	assert.Equal(t, time.Date(2024, 6, 21, 23, 0, 0, 0, time.UTC), night.OffTime.UTC())
+}
+
+func TestService_Status_Schedule(t *testing.T) {
+	cfg := newTestConfig("light-1")
+	service := newTestService(t, newFakeLightClient(), cfg)
+	now := time.Date(2024, 12, 22, 3, 0, 0, 0, time.UTC)
+	service.clock = fixedClock(now)
+
+	schedule := service.Status().Schedule
+
+	assert.Equal(t, "2024-12-21", schedule.Date)
+	assert.Equal(t, ComputeSchedule(cfg, now), schedule)
+}
+
+func TestService_Status_InConfiguredTimezone(t *testing.T) {
+	cfg := newTestConfig("light-1")
+	cfg.Location.Latitude, cfg.Location.Longitude = -36.85, 174.76
+	cfg.Location.Timezone = "Pacific/Auckland"
+	service := newTestService(t, newFakeLightClient(), cfg)
+	// 11:00 on the 22nd in Auckland, but still the 21st in UTC
+	now := time.Date(2024, 6, 21, 23, 0, 0, 0, time.UTC)
+	service.clock = fixedClock(now)
+
+	status := service.Status()
+
+	assert.False(t, status.Night)
+	assert.True(t, status.NextSunrise.After(now), "next sunrise %s is not after %s", status.NextSunrise, now)
+	assert.True(t, status.NextSunset.After(now), "next sunset %s is not after %s", status.NextSunset, now)
+}
@@ -1,6 +1,8 @@
 package light_automation
 
 import (
+	"math/rand/v2"
+	"sync"
 	"time"
 
 	"com.github.yveskaufmann/hue-lighter/internal/config"
@@ -10,24 +12,82 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+// LightClient covers the bridge operations used by the light automation,
+// it is implemented by *hueclient.Client.
+type LightClient interface {
+	BridgeID() string
+	GetOneLightById(id string) (*hueclient.LightListItem, error)
+	SwitchLightById(id string, on bool) (*hueclient.ResourceIdentifier, error)
+	TurnOffGroupedLightById(id string) error
+	UpdateGroupedLightById(id string, update *hueclient.LightBodyUpdate) (*hueclient.ResourceIdentifier, error)
+	UpdateOneLightById(id string, lightUpdate *hueclient.LightBodyUpdate) (*hueclient.ResourceIdentifier, error)
+	SetColorTemperatureById(id string, mirek int) error
+	RecallSmartSceneById(id string) error
+	DeactivateSmartSceneById(id string) error
+	RecallSceneById(id string, duration time.Duration) error
+}
+
+// TimeProvider provides the current time, it allows tests to use a fixed time.
+type TimeProvider interface {
+	Now() time.Time
+}
+
+type systemTimeProvider struct{}
+
+func (systemTimeProvider) Now() time.Time {
+	return time.Now()
+}
+
 type Service struct {
-	logger                *log.Entry
-	client                *hueclient.Client
-	config                *config.Config
-	ticker                *time.Ticker
-	tickerStop            chan struct{}
-	lightStates           map[string]bool
+	logger *log.Entry
+	client LightClient
+	clock  TimeProvider
+	sleep  func(time.Duration)
+	// random picks the startup jitter, tests seed it deterministically
+	random     *rand.Rand
+	ticker     *time.Ticker
+	tickerStop chan struct{}
+	// bridgeMu serializes the operations which send requests to the bridge, e.g. a
+	// tick and a shutdown. They hold mu only to read and record the light states,
+	// not while waiting for the bridge, so that status requests are not blocked by it.
+	bridgeMu sync.Mutex
+	// mu guards the config and the cached light states, which may be accessed
+	// by the event service while the automation is running.
+	mu          sync.RWMutex
+	config      *config.Config
+	lightStates map[string]bool
+	// ownedLights are the lights turned on by the automation and not turned off since
+	ownedLights           map[string]bool
+	minDimLevels          map[string]float32
+	appliedMirek          map[string]int
+	appliedBrightness     map[string]float32
 	lastLightStateRefresh time.Time
+	// paused makes runAutomation a no-op while the ticker keeps running
+	paused bool
+	// night is the period of the last tick, nil before the first tick
+	night *bool
+	// offTimeActive is set while the lights are off because of the off time
+	offTimeActive bool
+	// activeSmartScene is the ID of the smart scene recalled by the automation, it
+	// is written while holding bridgeMu and mu and may be read holding either.
+	activeSmartScene string
 }
 
-func NewService(client *hueclient.Client, config *config.Config, logger *log.Entry) *Service {
+func NewService(client LightClient, config *config.Config, logger *log.Entry) *Service {
 	return &Service{
-		logger:      logger.WithField("component", "LightAutomationService"),
-		client:      client,
-		config:      config,
-		ticker:      nil,
-		tickerStop:  make(chan struct{}),
-		lightStates: make(map[string]bool),
+		logger:            logger.WithField("component", "LightAutomationService"),
+		client:            client,
+		config:            config,
+		clock:             systemTimeProvider{},
+		sleep:             time.Sleep,
+		random:            rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())),
+		ticker:            nil,
+		tickerStop:        make(chan struct{}),
+		lightStates:       make(map[string]bool),
+		ownedLights:       make(map[string]bool),
+		minDimLevels:      make(map[string]float32),
+		appliedMirek:      make(map[string]int),
+		appliedBrightness: make(map[string]float32),
 	}
 }
 
@@ -38,23 +98,58 @@ func (s *Service) Start() error {
 		return nil
 	}
 
+	s.mu.RLock()
+	tickInterval := s.config.Automation.TickInterval
+	startupJitter := s.config.Automation.StartupJitter
+	s.mu.RUnlock()
+	if tickInterval <= 0 {
+		tickInterval = config.DefaultTickInterval
+	}
+
 	s.logger.Info("Starting Light Automation Service")
-	s.ticker = time.NewTicker(1 * time.Second)
-	go s.runAutomationTickerLoop()
+	s.ticker = time.NewTicker(tickInterval)
+	go s.runAutomationTickerLoop(s.ticker, tickInterval, s.startupDelay(startupJitter))
 	return nil
 
 }
 
-func (s *Service) runAutomationTickerLoop() {
-	s.logger.Info("Running automation ticker loop")
+// startupDelay returns a random delay in [0, jitter), zero if jitter is not positive.
+func (s *Service) startupDelay(jitter time.Duration) time.Duration {
+	if jitter <= 0 {
+		return 0
+	}
+	return time.Duration(s.random.Int64N(int64(jitter)))
+}
+
+func (s *Service) runAutomationTickerLoop(ticker *time.Ticker, tickInterval time.Duration, startupDelay time.Duration) {
+	if startupDelay > 0 {
+		s.logger.Infof("Delaying start of the automation by %s", startupDelay)
+		timer := time.NewTimer(startupDelay)
+		select {
+		case <-timer.C:
+		case <-s.tickerStop:
+			timer.Stop()
+			return
+		}
+		// The first tick follows one tick interval after the delay.
+		ticker.Reset(tickInterval)
+	}
 
-	defer s.Stop()
+	s.logger.Info("Running automation ticker loop")
 
 	s.refreshLightStates()
 
+	s.mu.RLock()
+	syncOnStartup := s.config.Automation.SyncOnStartup
+	s.mu.RUnlock()
+	if syncOnStartup {
+		s.logger.Info("Syncing lights to the current sun state")
+		s.runAutomation()
+	}
+
 	for {
 		select {
-		case <-s.ticker.C:
+		case <-ticker.C:
 			s.runAutomation()
 		case <-s.tickerStop:
 			s.logger.Info("Stopping periodic tasks.")
@@ -65,81 +160,492 @@ func (s *Service) runAutomationTickerLoop() {
 	// Example: Turn off all lights at midnight
 }
 
+// Pause suspends the automation, no light commands are sent until Resume is called.
+// The service keeps running, e.g. to answer status requests.
+func (s *Service) Pause() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.paused = true
+	s.logger.Info("Paused light automation")
+}
+
+// Resume continues a paused automation with the next tick.
+func (s *Service) Resume() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.paused = false
+	// Lights may have been switched manually while paused.
+	s.lastLightStateRefresh = time.Time{}
+	s.logger.Info("Resumed light automation")
+}
+
+// Paused reports whether the automation is paused.
+func (s *Service) Paused() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.paused
+}
+
 func (s *Service) runAutomation() {
-	tickTime := time.Now()
+	if s.Paused() {
+		s.logger.Debug("Light automation is paused, skipping tick")
+		return
+	}
+
+	tickTime := s.clock.Now()
 
-	s.logger.Infof("Tick at %v", tickTime)
+	s.logger.Debugf("Tick at %v", tickTime)
 
-	if time.Since(s.lastLightStateRefresh) > 5*time.Minute {
+	s.mu.RLock()
+	lastLightStateRefresh := s.lastLightStateRefresh
+	latitude, longitude := s.config.Location.Latitude, s.config.Location.Longitude
+	refreshInterval := s.config.Automation.LightStateRefreshInterval
+	if s.config.Location.Timezone != "" {
+		// The clock times of the config are interpreted in the time zone of tickTime
+		tickTime = tickTime.In(s.config.Location.TimeZone())
+	}
+	s.mu.RUnlock()
+
+	if refreshInterval <= 0 {
+		refreshInterval = config.DefaultLightStateRefreshInterval
+	}
+
+	if time.Since(lastLightStateRefresh) > refreshInterval {
 		s.refreshLightStates()
 	}
 
-	sunriseTime, sunsetTime := sunset.CalculateSunriseSunset(s.config.Location.Latitude, s.config.Location.Longitude)
+	sunriseTime, sunsetTime := sunset.CalculateSunriseSunsetAt(latitude, longitude, tickTime)
 
-	s.logger.Infof("Sunrise at %v, Sunset at %v", sunriseTime, sunsetTime)
-	isNight := tickTime.Before(sunriseTime) || tickTime.After(sunsetTime)
-	// Only attempt to enable lights when both conditions are met:
-	//  - tickTime is at night between sunset and next day's sunrise
-	if isNight {
-		s.setLightsState(true)
+	s.logger.Debugf("Sunrise at %v, Sunset at %v", sunriseTime, sunsetTime)
+	night := isNight(tickTime, sunriseTime, sunsetTime)
+	s.trackPeriod(night, sunriseTime, sunsetTime)
 
+	offTimeReached := night && s.offTimeReached(tickTime, sunriseTime, sunsetTime)
+	s.trackOffTime(offTimeReached)
+	sceneActive := s.applySmartScene(night && !offTimeReached)
+	s.switchLights(tickTime, sunriseTime, night, offTimeReached)
+
+	// The gradients apply when all conditions are met:
+	//  - tickTime is at night between sunset and next day's sunrise
+	//  - the off time of the night has not been reached
+	//  - no smart scene controls the lights
+	if night && !offTimeReached && !sceneActive {
+		// The wake-up before sunrise takes precedence over the evening gradients.
+		if !s.applyWakeUp(tickTime, sunriseTime) {
+			s.applyColorTemperature(tickTime, sunriseTime, sunsetTime)
+			s.applyBrightnessSchedule(tickTime, sunriseTime, sunsetTime)
+		}
 	} else {
-		s.setLightsState(false)
+		s.resetColorTemperature()
+		s.resetBrightnessSchedule()
 	}
 }
 
-func (s *Service) setLightsState(turnOn bool) {
-	for _, lightCfg := range s.config.Lights {
-		if turnOn {
-			s.logger.Info("It's nighttime and we've reached lights on time, turning on lights")
+// trackPeriod logs once when the period changes between day and night, instead of on every tick.
+func (s *Service) trackPeriod(night bool, sunriseTime time.Time, sunsetTime time.Time) {
+	s.mu.Lock()
+	previous := s.night
+	s.night = &night
+	s.mu.Unlock()
+
+	logger := s.logger.WithFields(log.Fields{"sunrise": sunriseTime, "sunset": sunsetTime})
+	switch {
+	case previous == nil:
+		logger.Infof("Automation starts at %s", periodName(night))
+	case *previous != night:
+		logger.Infof("Transition from %s to %s", periodName(*previous), periodName(night))
+	}
+}
+
+func periodName(night bool) string {
+	if night {
+		return "night"
+	}
+	return "day"
+}
+
+func isNight(t time.Time, sunriseTime time.Time, sunsetTime time.Time) bool {
+	return t.Before(sunriseTime) || t.After(sunsetTime)
+}
+
+// lightCommand switches a light, it is composed while holding s.mu and sent to
+// the bridge without it.
+type lightCommand struct {
+	id string
+	on bool
+	// update is sent with UpdateOneLightById, nil switches the light with SwitchLightById
+	update *hueclient.LightBodyUpdate
+	// wakeUp is set for lights turned on at the state of the wake-up ramp
+	wakeUp *wakeUpRamp
+}
+
+// setLightsState turns the configured lights on or off, lights are turned off
+// with a transition of the given fade duration. The state of a light is only
+// updated if the bridge accepted the command, failed lights are retried on the next tick.
+func (s *Service) setLightsState(turnOn bool, fade time.Duration) {
+	s.bridgeMu.Lock()
+	defer s.bridgeMu.Unlock()
+
+	s.mu.RLock()
+	var commands []lightCommand
+	for _, lightCfg := range s.enabledLights() {
+		if command, ok := s.lightStateCommand(lightCfg, turnOn, fade, nil); ok {
+			commands = append(commands, command)
+		}
+	}
+	s.mu.RUnlock()
+
+	s.sendLightCommands(commands)
+}
+
+// lightStateCommand composes the command which turns a single light on or off, see
+// setLightsState. It reports false if the light is already in that state. A light
+// turned on during the wake-up window starts at the given ramp, if any. The caller
+// must hold s.mu.
+func (s *Service) lightStateCommand(lightCfg config.LightConfig, turnOn bool, fade time.Duration, wakeUp *wakeUpRamp) (lightCommand, bool) {
+	if turnOn {
+		s.logger.Debug("It's nighttime and we've reached lights on time, turning on lights")
+
+		if s.lightStates[*lightCfg.ID] {
+			s.logger.Debugf("Light ID: %s is already on, skipping", *lightCfg.ID)
+			return lightCommand{}, false
+		}
+
+		return s.turnOnCommand(lightCfg, wakeUp), true
+	}
+
+	s.logger.Debug("It's daytime, lights should remain off")
+
+	if !s.lightStates[*lightCfg.ID] {
+		s.logger.Debugf("Light ID: %s is already off, skipping", *lightCfg.ID)
+		return lightCommand{}, false
+	}
+
+	return turnOffCommand(*lightCfg.ID, fade), true
+}
+
+// turnOnCommand turns on the light with its configured target state, if any. During
+// the wake-up window the light is turned on at the state of the ramp, so that it
+// does not flash at full brightness before the ramp applies. The caller must hold s.mu.
+func (s *Service) turnOnCommand(lightCfg config.LightConfig, wakeUp *wakeUpRamp) lightCommand {
+	command := lightCommand{id: *lightCfg.ID, on: true}
+	if wakeUp != nil {
+		command.update = s.wakeUpOnUpdate(lightCfg, *wakeUp)
+		command.wakeUp = wakeUp
+		return command
+	}
+
+	if lightCfg.Brightness != nil || lightCfg.HasColor() || s.config.Automation.MaxBrightness != nil {
+		command.update = s.onUpdate(lightCfg)
+	}
+	return command
+}
+
+// onUpdate composes the configured brightness, color temperature and color of
+// the light into a single update which turns it on. Without a configured brightness
+// the light is turned on at max_brightness, if set, as it would keep its last
+// brightness otherwise, possibly 100%. The caller must hold s.mu.
+func (s *Service) onUpdate(lightCfg config.LightConfig) *hueclient.LightBodyUpdate {
+	update := &hueclient.LightBodyUpdate{On: &hueclient.LightOnState{On: true}}
+	if lightCfg.Brightness != nil {
+		update.Dimming = &hueclient.LightDimmingState{Brightness: s.brightnessFor(lightCfg)}
+	} else if maxBrightness := s.config.Automation.MaxBrightness; maxBrightness != nil {
+		update.Dimming = &hueclient.LightDimmingState{Brightness: *maxBrightness}
+	}
+	if lightCfg.ColorTemperature != nil {
+		mirek := *lightCfg.ColorTemperature
+		update.ColorTemperature = &hueclient.LightColorTemperature{Mirek: &mirek}
+	}
+	if lightCfg.Color != nil {
+		color := hueclient.NewLightColorXY(lightCfg.Color.X, lightCfg.Color.Y)
+		update.Color = &color
+	}
+	return update
+}
+
+// turnOffCommand turns off the light, dimming it down within the fade duration if
+// it is positive.
+func turnOffCommand(id string, fade time.Duration) lightCommand {
+	command := lightCommand{id: id}
+	if fade > 0 {
+		command.update = offWithFade(fade)
+	}
+	return command
+}
 
-			if s.lightStates[*lightCfg.ID] {
-				s.logger.Infof("Light ID: %s is already on, skipping", *lightCfg.ID)
-				continue
-			}
+// sendLightCommands sends the commands to the bridge one after another without
+// holding s.mu and records the states of the lights the bridge switched. The
+// caller must hold s.bridgeMu.
+func (s *Service) sendLightCommands(commands []lightCommand) {
+	resources := make([]*hueclient.ResourceIdentifier, len(commands))
+	errs := make([]error, len(commands))
+	for i, command := range commands {
+		if command.update != nil {
+			resources[i], errs[i] = s.client.UpdateOneLightById(command.id, command.update)
+		} else {
+			resources[i], errs[i] = s.client.SwitchLightById(command.id, command.on)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, command := range commands {
+		s.recordLightCommand(command, resources[i], errs[i])
+	}
+}
+
+// lightUpdate changes the state of a light which is on, e.g. the brightness of a
+// gradient. It is composed while holding s.mu and sent to the bridge without it.
+type lightUpdate struct {
+	id     string
+	update *hueclient.LightBodyUpdate
+}
 
-			err := s.client.TurnOnLightById(*lightCfg.ID)
-			if err != nil {
-				s.logger.Errorf("Failed to turn on light ID: %s, error: %v", *lightCfg.ID, err)
-			}
+// sendLightUpdates sends the updates to the bridge one after another and returns
+// their errors in the same order. The caller must hold s.bridgeMu but not s.mu.
+func (s *Service) sendLightUpdates(updates []lightUpdate) []error {
+	errs := make([]error, len(updates))
+	for i, u := range updates {
+		_, errs[i] = s.client.UpdateOneLightById(u.id, u.update)
+	}
+	return errs
+}
 
-			s.lightStates[*lightCfg.ID] = true
+// recordLightCommand updates the state of the light if the bridge accepted the
+// command, failed lights keep their state. The caller must hold s.mu.
+func (s *Service) recordLightCommand(command lightCommand, resource *hueclient.ResourceIdentifier, err error) {
+	if err != nil {
+		if command.on {
+			s.logger.Errorf("Failed to turn on light ID: %s, error: %v", command.id, err)
 		} else {
-			s.logger.Info("It's daytime, lights should remain off")
-
-			if !s.lightStates[*lightCfg.ID] {
-				s.logger.Infof("Light ID: %s is already off, skipping", *lightCfg.ID)
-				continue
-			}
-
-			err := s.client.TurnOffLightById(*lightCfg.ID)
-			if err != nil {
-				s.logger.Errorf("Failed to turn off light ID: %s, error: %v", *lightCfg.ID, err)
-			}
-			s.lightStates[*lightCfg.ID] = false
+			s.logger.Errorf("Failed to turn off light ID: %s, error: %v", command.id, err)
+		}
+		return
+	}
+
+	s.logSwitched(command.id, resource, command.on)
+	s.lightStates[command.id] = command.on
+	if !command.on {
+		delete(s.ownedLights, command.id)
+		return
+	}
+
+	s.ownedLights[command.id] = true
+	if command.wakeUp != nil {
+		s.appliedBrightness[command.id] = command.update.Dimming.Brightness
+		if command.wakeUp.mirek != nil {
+			s.appliedMirek[command.id] = *command.wakeUp.mirek
 		}
 	}
 }
 
-func (s *Service) refreshLightStates() {
+// logSwitched logs the switched light together with the resource confirmed by the bridge.
+func (s *Service) logSwitched(id string, resource *hueclient.ResourceIdentifier, on bool) {
+	logger := s.logger.WithField("light", id)
+	if resource != nil {
+		logger = logger.WithFields(log.Fields{"rid": resource.RID, "rtype": resource.RType})
+	}
+
+	if on {
+		logger.Debug("Turned on light")
+	} else {
+		logger.Debug("Turned off light")
+	}
+}
+
+// enabledLights returns the configured lights which take part in the automation.
+// The caller must hold s.mu.
+func (s *Service) enabledLights() []config.LightConfig {
+	lights := make([]config.LightConfig, 0, len(s.config.Lights))
 	for _, lightCfg := range s.config.Lights {
-		state, err := s.client.GetOneLightById(*lightCfg.ID)
-		if err == nil {
-			s.lightStates[*lightCfg.ID] = state.On.On
-		} else {
-			s.logger.Warnf("Could not refresh state for light %s: %v", *lightCfg.ID, err)
+		if lightCfg.IsEnabled() {
+			lights = append(lights, lightCfg)
+		}
+	}
+	return lights
+}
+
+func offWithFade(fade time.Duration) *hueclient.LightBodyUpdate {
+	duration := int(fade.Milliseconds())
+	return &hueclient.LightBodyUpdate{
+		On:       &hueclient.LightOnState{On: false},
+		Dynamics: &hueclient.Dynamics{Duration: &duration},
+	}
+}
+
+// refreshLightStates reads the states of the enabled lights concurrently and
+// applies them in the order of the config once all reads returned. s.mu is not
+// held while the states are read, so that status requests are not blocked by the bridge.
+func (s *Service) refreshLightStates() {
+	s.bridgeMu.Lock()
+	defer s.bridgeMu.Unlock()
+
+	s.mu.RLock()
+	lights := s.enabledLights()
+	attempts := s.lightReadAttempts()
+	s.mu.RUnlock()
+
+	states := make([]*hueclient.LightListItem, len(lights))
+	errs := make([]error, len(lights))
+	forEachConcurrently(len(lights), maxConcurrentLightRequests, func(i int) {
+		states[i], errs[i] = s.readLightState(*lights[i].ID, attempts)
+	})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, lightCfg := range lights {
+		state, err := states[i], errs[i]
+		if err != nil {
+			s.handleUnreachableLight(*lightCfg.ID, err)
+			continue
+		}
+
+		s.lightStates[*lightCfg.ID] = state.On.On
+		if !state.On.On {
+			// Turned off by someone else, if it is turned on again it is not ours.
+			delete(s.ownedLights, *lightCfg.ID)
+		}
+		if minDimLevel := state.MinDimLevel(); minDimLevel > 0 {
+			s.minDimLevels[*lightCfg.ID] = minDimLevel
 		}
 	}
 
 	s.lastLightStateRefresh = time.Now()
 }
 
+// ApplyConfig replaces the configuration used by the running automation. The
+// light states are refreshed on the next tick to pick up newly added lights.
+func (s *Service) ApplyConfig(cfg *config.Config) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.config = cfg
+	s.lastLightStateRefresh = time.Time{}
+	s.logger.Info("Applied new configuration")
+}
+
+// StopAndTurnOffLights stops the automation and turns off the lights after the
+// configured shutdown delay, fading them out if a fade duration is configured.
+// If a shutdown scene is configured, it is recalled instead.
 func (s *Service) StopAndTurnOffLights() error {
 	s.Stop()
-	s.setLightsState(false)
+	// A running smart scene would turn the lights on again.
+	s.applySmartScene(false)
+
+	s.mu.RLock()
+	delay, fade := s.config.Shutdown.Delay, s.config.Shutdown.FadeDuration
+	policy := s.config.Shutdown.TurnOff
+	s.mu.RUnlock()
+
+	if delay > 0 {
+		s.logger.Infof("Turning off lights in %s", delay)
+		s.sleep(delay)
+	}
+
+	if s.recallShutdownScene(fade) {
+		return nil
+	}
+
+	if policy == config.ShutdownTurnOffOwned {
+		// The grouped light would turn off lights which are not ours as well.
+		s.turnOffOwnedLights(fade)
+		return nil
+	}
+
+	if s.turnOffGroupedLight(fade) {
+		return nil
+	}
+
+	s.setLightsState(false, fade)
 	return nil
 }
 
+// turnOffOwnedLights only turns off the lights which were turned on by the automation.
+func (s *Service) turnOffOwnedLights(fade time.Duration) {
+	s.bridgeMu.Lock()
+	defer s.bridgeMu.Unlock()
+
+	s.mu.RLock()
+	var commands []lightCommand
+	for _, lightCfg := range s.enabledLights() {
+		if !s.ownedLights[*lightCfg.ID] {
+			s.logger.Infof("Light ID: %s was not turned on by the automation, keeping its state", *lightCfg.ID)
+			continue
+		}
+		commands = append(commands, turnOffCommand(*lightCfg.ID, fade))
+	}
+	s.mu.RUnlock()
+
+	s.sendLightCommands(commands)
+}
+
+// recallShutdownScene recalls the shutdown scene if one is configured and reports
+// whether this succeeded, the lights of the scene are not owned by the automation.
+func (s *Service) recallShutdownScene(fade time.Duration) bool {
+	s.bridgeMu.Lock()
+	defer s.bridgeMu.Unlock()
+
+	s.mu.RLock()
+	sceneID := s.config.ShutdownScene
+	s.mu.RUnlock()
+	if sceneID == nil || *sceneID == "" {
+		return false
+	}
+
+	s.logger.Infof("Recalling shutdown scene ID: %s", *sceneID)
+	if err := s.client.RecallSceneById(*sceneID, fade); err != nil {
+		s.logger.Errorf("Failed to recall shutdown scene ID: %s, falling back to turning off the lights, error: %v", *sceneID, err)
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, lightCfg := range s.config.Lights {
+		delete(s.ownedLights, *lightCfg.ID)
+	}
+	// The scene may switch any of the lights, they are read again on the next refresh.
+	s.lastLightStateRefresh = time.Time{}
+
+	return true
+}
+
+// turnOffGroupedLight turns off all lights with a single request if a grouped light
+// is configured and reports whether this succeeded.
+func (s *Service) turnOffGroupedLight(fade time.Duration) bool {
+	s.bridgeMu.Lock()
+	defer s.bridgeMu.Unlock()
+
+	s.mu.RLock()
+	groupID := s.config.Shutdown.GroupedLightID
+	s.mu.RUnlock()
+	if groupID == nil || *groupID == "" {
+		return false
+	}
+
+	s.logger.Infof("Turning off all lights via grouped light ID: %s", *groupID)
+	var err error
+	if fade > 0 {
+		_, err = s.client.UpdateGroupedLightById(*groupID, offWithFade(fade))
+	} else {
+		err = s.client.TurnOffGroupedLightById(*groupID)
+	}
+	if err != nil {
+		s.logger.Errorf("Failed to turn off grouped light ID: %s, falling back to turning off lights one by one, error: %v", *groupID, err)
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, lightCfg := range s.config.Lights {
+		s.lightStates[*lightCfg.ID] = false
+		delete(s.ownedLights, *lightCfg.ID)
+	}
+
+	return true
+}
+
 func (s *Service) Stop() {
 	if s.ticker == nil {
 		s.logger.Warn("Light Automation Service is not running")
@@ -0,0 +1,574 @@
+package light_automation
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"com.github.yveskaufmann/hue-lighter/internal/config"
+	hueclient "com.github.yveskaufmann/hue-lighter/internal/hue_client"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeLightClient records all commands and fails those listed in failing.
+type fakeLightClient struct {
+	mu      sync.Mutex
+	calls   []string
+	failing map[string]bool
+	// failures lets the next n calls fail, e.g. of a flaky light
+	failures map[string]int
+	states   map[string]bool
+	// dimming reported by GetOneLightById per light
+	dimming map[string]*hueclient.LightDimmingState
+	updates map[string]*hueclient.LightBodyUpdate
+	// withoutColorTemperature lists the lights which reject color temperatures
+	withoutColorTemperature map[string]bool
+	// getBlocked, if set, blocks GetOneLightById until it is closed
+	getBlocked chan struct{}
+	// switchBlocked, if set, blocks SwitchLightById until it is closed
+	switchBlocked chan struct{}
+}
+
+func newFakeLightClient() *fakeLightClient {
+	return &fakeLightClient{
+		failing:  make(map[string]bool),
+		failures: make(map[string]int),
+		states:   make(map[string]bool),
+		dimming:  make(map[string]*hueclient.LightDimmingState),
+		updates:  make(map[string]*hueclient.LightBodyUpdate),
+
+		withoutColorTemperature: make(map[string]bool),
+	}
+}
+
+func (f *fakeLightClient) record(call string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls = append(f.calls, call)
+	if f.failing[call] {
+		return errors.New("bridge unavailable")
+	}
+	if f.failures[call] > 0 {
+		f.failures[call]--
+		return errors.New("light unreachable")
+	}
+	return nil
+}
+
+func (f *fakeLightClient) Calls() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.calls...)
+}
+
+func (f *fakeLightClient) BridgeID() string { return "bridge-123" }
+
+func (f *fakeLightClient) GetOneLightById(id string) (*hueclient.LightListItem, error) {
+	if err := f.record("get " + id); err != nil {
+		return nil, err
+	}
+	if f.getBlocked != nil {
+		<-f.getBlocked
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return &hueclient.LightListItem{ID: id, On: hueclient.LightOnState{On: f.states[id]}, Dimming: f.dimming[id]}, nil
+}
+
+func (f *fakeLightClient) SwitchLightById(id string, on bool) (*hueclient.ResourceIdentifier, error) {
+	call := "off " + id
+	if on {
+		call = "on " + id
+	}
+	if err := f.record(call); err != nil {
+		return nil, err
+	}
+	if f.switchBlocked != nil {
+		<-f.switchBlocked
+	}
+	return &hueclient.ResourceIdentifier{RID: id, RType: "light"}, nil
+}
+
+func (f *fakeLightClient) TurnOffGroupedLightById(id string) error {
+	return f.record("group off " + id)
+}
+
+func (f *fakeLightClient) UpdateGroupedLightById(id string, update *hueclient.LightBodyUpdate) (*hueclient.ResourceIdentifier, error) {
+	if err := f.record("group update " + id); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.updates[id] = update
+	return &hueclient.ResourceIdentifier{}, nil
+}
+
+func (f *fakeLightClient) UpdateOneLightById(id string, lightUpdate *hueclient.LightBodyUpdate) (*hueclient.ResourceIdentifier, error) {
+	if err := f.record("update " + id); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.updates[id] = lightUpdate
+	return &hueclient.ResourceIdentifier{}, nil
+}
+
+func (f *fakeLightClient) SetColorTemperatureById(id string, mirek int) error {
+	if err := f.record(fmt.Sprintf("mirek %s %d", id, mirek)); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.withoutColorTemperature[id] {
+		return fmt.Errorf("%w %s", hueclient.ErrUnsupportedCapability, hueclient.CapabilityColorTemperature)
+	}
+	return nil
+}
+
+func (f *fakeLightClient) RecallSmartSceneById(id string) error {
+	return f.record("scene activate " + id)
+}
+
+func (f *fakeLightClient) DeactivateSmartSceneById(id string) error {
+	return f.record("scene deactivate " + id)
+}
+
+func (f *fakeLightClient) RecallSceneById(id string, duration time.Duration) error {
+	return f.record(fmt.Sprintf("scene recall %s %s", id, duration))
+}
+
+func (f *fakeLightClient) Update(id string) *hueclient.LightBodyUpdate {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.updates[id]
+}
+
+func newTestConfig(lightIDs ...string) *config.Config {
+	cfg := &config.Config{}
+	cfg.Location.Latitude = 52.5
+	cfg.Location.Longitude = 13.4
+	for _, id := range lightIDs {
+		id := id
+		name := fmt.Sprintf("Light %s", id)
+		cfg.Lights = append(cfg.Lights, config.LightConfig{ID: &id, Name: &name})
+	}
+	return cfg
+}
+
+func newTestService(t *testing.T, client LightClient, cfg *config.Config) *Service {
+	t.Helper()
+	return NewService(client, cfg, logrus.New().WithField("test", t.Name()))
+}
+
+func TestService_StopAndTurnOffLights(t *testing.T) {
+	groupID := "group-home"
+
+	tests := []struct {
+		name          string
+		groupedLight  *string
+		failing       []string
+		expectedCalls []string
+	}{
+		{
+			name:          "turns off lights one by one without grouped light",
+			groupedLight:  nil,
+			expectedCalls: []string{"off light-1", "off light-2"},
+		},
+		{
+			name:          "turns off all lights with a single grouped light request",
+			groupedLight:  &groupID,
+			expectedCalls: []string{"group off group-home"},
+		},
+		{
+			name:          "falls back to one by one when grouped light request fails",
+			groupedLight:  &groupID,
+			failing:       []string{"group off group-home"},
+			expectedCalls: []string{"group off group-home", "off light-1", "off light-2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newFakeLightClient()
+			for _, call := range tt.failing {
+				client.failing[call] = true
+			}
+
+			cfg := newTestConfig("light-1", "light-2")
+			cfg.Shutdown.GroupedLightID = tt.groupedLight
+
+			service := newTestService(t, client, cfg)
+			service.lightStates["light-1"] = true
+			service.lightStates["light-2"] = true
+
+			err := service.StopAndTurnOffLights()
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCalls, client.Calls())
+			assert.False(t, service.lightStates["light-1"])
+			assert.False(t, service.lightStates["light-2"])
+		})
+	}
+}
+
+func TestService_StopAndTurnOffLights_GracePeriod(t *testing.T) {
+	groupID := "group-home"
+
+	tests := []struct {
+		name          string
+		groupedLight  *string
+		failing       []string
+		expectedCalls []string
+		expectedFaded []string
+	}{
+		{
+			name:          "fades out lights one by one",
+			expectedCalls: []string{"update light-1", "update light-2"},
+			expectedFaded: []string{"light-1", "light-2"},
+		},
+		{
+			name:          "fades out grouped light",
+			groupedLight:  &groupID,
+			expectedCalls: []string{"group update group-home"},
+			expectedFaded: []string{"group-home"},
+		},
+		{
+			name:          "fades out one by one when grouped light request fails",
+			groupedLight:  &groupID,
+			failing:       []string{"group update group-home"},
+			expectedCalls: []string{"group update group-home", "update light-1", "update light-2"},
+			expectedFaded: []string{"light-1", "light-2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newFakeLightClient()
+			for _, call := range tt.failing {
+				client.failing[call] = true
+			}
+
+			cfg := newTestConfig("light-1", "light-2")
+			cfg.Shutdown.GroupedLightID = tt.groupedLight
+			cfg.Shutdown.Delay = 10 * time.Second
+			cfg.Shutdown.FadeDuration = 3 * time.Second
+
+			service := newTestService(t, client, cfg)
+			var slept []time.Duration
+			service.sleep = func(d time.Duration) { slept = append(slept, d) }
+			service.lightStates["light-1"] = true
+			service.lightStates["light-2"] = true
+
+			err := service.StopAndTurnOffLights()
+
+			assert.NoError(t, err)
+			assert.Equal(t, []time.Duration{10 * time.Second}, slept)
+			assert.Equal(t, tt.expectedCalls, client.Calls())
+			for _, id := range tt.expectedFaded {
+				update := client.Update(id)
+				require.NotNil(t, update, id)
+				require.NotNil(t, update.Dynamics, id)
+				assert.Equal(t, 3000, *update.Dynamics.Duration)
+				assert.False(t, update.On.On)
+			}
+			assert.False(t, service.lightStates["light-1"])
+			assert.False(t, service.lightStates["light-2"])
+		})
+	}
+}
+
+func TestService_StopAndTurnOffLights_TurnOffPolicy(t *testing.T) {
+	groupID := "group-home"
+
+	tests := []struct {
+		name          string
+		policy        config.ShutdownTurnOffPolicy
+		expectedCalls []string
+		expectedOn    bool
+	}{
+		{
+			name:          "turns off all configured lights",
+			policy:        config.ShutdownTurnOffAll,
+			expectedCalls: []string{"group off group-home"},
+			expectedOn:    false,
+		},
+		{
+			name:          "turns off only lights turned on by the automation",
+			policy:        config.ShutdownTurnOffOwned,
+			expectedCalls: []string{"off light-1"},
+			expectedOn:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newFakeLightClient()
+			// light-2 was turned on manually
+			client.states["light-2"] = true
+
+			cfg := newTestConfig("light-1", "light-2")
+			cfg.Shutdown.GroupedLightID = &groupID
+			cfg.Shutdown.TurnOff = tt.policy
+
+			service := newTestService(t, client, cfg)
+			service.refreshLightStates()
+			service.setLightsState(true, 0)
+			client.calls = nil
+
+			err := service.StopAndTurnOffLights()
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCalls, client.Calls())
+			assert.False(t, service.lightStates["light-1"])
+			assert.Equal(t, tt.expectedOn, service.lightStates["light-2"])
+		})
+	}
+
+	t.Run("light turned off manually is no longer owned", func(t *testing.T) {
+		client := newFakeLightClient()
+		cfg := newTestConfig("light-1")
+		cfg.Shutdown.TurnOff = config.ShutdownTurnOffOwned
+
+		service := newTestService(t, client, cfg)
+		service.setLightsState(true, 0)
+		// turned off and on again manually
+		client.states["light-1"] = false
+		service.refreshLightStates()
+		client.states["light-1"] = true
+		service.refreshLightStates()
+		client.calls = nil
+
+		err := service.StopAndTurnOffLights()
+
+		assert.NoError(t, err)
+		assert.Empty(t, client.Calls())
+	})
+
+	t.Run("light failing to turn off is still owned", func(t *testing.T) {
+		client := newFakeLightClient()
+		client.failing["off light-1"] = true
+		cfg := newTestConfig("light-1")
+		cfg.Shutdown.TurnOff = config.ShutdownTurnOffOwned
+
+		service := newTestService(t, client, cfg)
+		service.setLightsState(true, 0)
+
+		err := service.StopAndTurnOffLights()
+
+		assert.NoError(t, err)
+		assert.True(t, service.lightStates["light-1"])
+		assert.True(t, service.ownedLights["light-1"])
+	})
+}
+
+func TestService_startupDelay(t *testing.T) {
+	service := newTestService(t, newFakeLightClient(), newTestConfig("light-1"))
+	service.random = rand.New(rand.NewPCG(1, 2))
+
+	assert.Zero(t, service.startupDelay(0))
+
+	for range 100 {
+		delay := service.startupDelay(time.Second)
+		assert.GreaterOrEqual(t, delay, time.Duration(0))
+		assert.Less(t, delay, time.Second)
+	}
+}
+
+func TestService_Start_DelaysFirstTickByJitter(t *testing.T) {
+	const jitter = 200 * time.Millisecond
+
+	client := newFakeLightClient()
+	cfg := newTestConfig("light-1")
+	cfg.Automation.TickInterval = 10 * time.Millisecond
+	cfg.Automation.StartupJitter = jitter
+
+	service := newTestService(t, client, cfg)
+	service.random = rand.New(rand.NewPCG(1, 2))
+	expectedDelay := rand.New(rand.NewPCG(1, 2)).Int64N(int64(jitter))
+	require.Positive(t, expectedDelay)
+
+	start := time.Now()
+	require.NoError(t, service.Start())
+	defer service.Stop()
+
+	require.Eventually(t, func() bool { return len(client.Calls()) > 0 }, 2*time.Second, time.Millisecond)
+	elapsed := time.Since(start)
+
+	assert.GreaterOrEqual(t, elapsed, time.Duration(expectedDelay))
+	assert.Less(t, elapsed, jitter+time.Second)
+}
+
+func TestService_Start_SyncOnStartup(t *testing.T) {
+	tests := []struct {
+		name          string
+		syncOnStartup bool
+		expectedCalls []string
+	}{
+		{name: "sets lights right after start", syncOnStartup: true, expectedCalls: []string{"get light-1", "on light-1"}},
+		{name: "waits for the first tick", syncOnStartup: false, expectedCalls: []string{"get light-1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newFakeLightClient()
+			cfg := newTestConfig("light-1")
+			cfg.Automation.TickInterval = time.Hour
+			cfg.Automation.SyncOnStartup = tt.syncOnStartup
+			service := newTestService(t, client, cfg)
+			// night in Berlin, the automation turns the lights on
+			service.clock = fixedClock(time.Date(2024, 6, 21, 23, 0, 0, 0, time.UTC))
+
+			require.NoError(t, service.Start())
+			defer service.Stop()
+
+			require.Eventually(t, func() bool { return len(client.Calls()) >= len(tt.expectedCalls) }, 2*time.Second, time.Millisecond)
+			// Give an unexpected sync the chance to show up
+			time.Sleep(20 * time.Millisecond)
+			assert.Equal(t, tt.expectedCalls, client.Calls())
+		})
+	}
+}
+
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time {
+	return time.Time(c)
+}
+
+func TestService_PauseResume(t *testing.T) {
+	client := newFakeLightClient()
+	service := newTestService(t, client, newTestConfig("light-1", "light-2"))
+	// night in Berlin, the automation turns the lights on
+	service.clock = fixedClock(time.Date(2024, 6, 21, 23, 0, 0, 0, time.UTC))
+
+	service.Pause()
+	service.runAutomation()
+
+	assert.True(t, service.Paused())
+	assert.True(t, service.Status().Paused)
+	assert.Empty(t, client.Calls(), "no light commands while paused")
+
+	service.Resume()
+	service.runAutomation()
+
+	assert.False(t, service.Paused())
+	assert.Contains(t, client.Calls(), "on light-1")
+	assert.Contains(t, client.Calls(), "on light-2")
+}
+
+func TestService_LogsDayNightTransitionOnce(t *testing.T) {
+	night := fixedClock(time.Date(2024, 6, 21, 23, 0, 0, 0, time.UTC))
+	day := fixedClock(time.Date(2024, 6, 22, 12, 0, 0, 0, time.UTC))
+
+	logger, hook := test.NewNullLogger()
+	service := newTestService(t, newFakeLightClient(), newTestConfig("light-1"))
+	service.logger = logger.WithField("test", t.Name())
+
+	var transitions []string
+	for _, clock := range []fixedClock{night, night, day, day, day, night} {
+		service.clock = clock
+		service.runAutomation()
+	}
+	for _, entry := range hook.AllEntries() {
+		if strings.HasPrefix(entry.Message, "Automation starts at") || strings.HasPrefix(entry.Message, "Transition from") {
+			transitions = append(transitions, entry.Message)
+		}
+	}
+
+	assert.Equal(t, []string{
+		"Automation starts at night",
+		"Transition from night to day",
+		"Transition from day to night",
+	}, transitions)
+}
+
+func TestService_LogsSwitchedResource(t *testing.T) {
+	logger, hook := test.NewNullLogger()
+	logger.SetLevel(logrus.DebugLevel)
+	service := newTestService(t, newFakeLightClient(), newTestConfig("light-1"))
+	service.logger = logger.WithField("test", t.Name())
+
+	service.setLightsState(true, 0)
+	service.setLightsState(false, 0)
+
+	var switched []logrus.Fields
+	for _, entry := range hook.AllEntries() {
+		if entry.Message == "Turned on light" || entry.Message == "Turned off light" {
+			switched = append(switched, logrus.Fields{
+				"message": entry.Message,
+				"light":   entry.Data["light"],
+				"rid":     entry.Data["rid"],
+				"rtype":   entry.Data["rtype"],
+			})
+		}
+	}
+
+	assert.Equal(t, []logrus.Fields{
+		{"message": "Turned on light", "light": "light-1", "rid": "light-1", "rtype": "light"},
+		{"message": "Turned off light", "light": "light-1", "rid": "light-1", "rtype": "light"},
+	}, switched)
+}
+
+func TestService_SkipsDisabledLights(t *testing.T) {
+	disabled := false
+	cfg := newTestConfig("light-1", "light-2")
+	cfg.Lights[1].Enabled = &disabled
+
+	client := newFakeLightClient()
+	client.states["light-2"] = true
+	service := newTestService(t, client, cfg)
+
+	service.refreshLightStates()
+	service.setLightsState(true, 0)
+	service.setLightsState(false, 0)
+	service.turnOffOwnedLights(0)
+
+	for _, call := range client.Calls() {
+		assert.NotContains(t, call, "light-2")
+	}
+	assert.Equal(t, []string{"get light-1", "on light-1", "off light-1"}, client.Calls())
+
+	status := service.Status()
+	require.Len(t, status.Lights, 2)
+	assert.False(t, status.Lights[0].Disabled)
+	assert.True(t, status.Lights[1].Disabled)
+}
+
+func TestService_SetLightsState_RetriesFailedLights(t *testing.T) {
+	client := newFakeLightClient()
+	client.failing["on light-1"] = true
+	service := newTestService(t, client, newTestConfig("light-1", "light-2"))
+
+	service.setLightsState(true, 0)
+
+	assert.False(t, service.lightStates["light-1"])
+	assert.False(t, service.ownedLights["light-1"])
+	assert.True(t, service.lightStates["light-2"])
+
+	// The failed light is retried on the next tick, the switched one is skipped
+	delete(client.failing, "on light-1")
+	service.setLightsState(true, 0)
+
+	assert.Equal(t, []string{"on light-1", "on light-2", "on light-1"}, client.Calls())
+	assert.True(t, service.lightStates["light-1"])
+	assert.True(t, service.ownedLights["light-1"])
+
+	client.failing["off light-2"] = true
+	service.setLightsState(false, 0)
+
+	assert.False(t, service.lightStates["light-1"])
+	assert.True(t, service.lightStates["light-2"])
+	assert.True(t, service.ownedLights["light-2"])
+}
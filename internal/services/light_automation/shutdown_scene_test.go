@@ -0,0 +1,73 @@
+package light_automation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestService_StopAndTurnOffLights_ShutdownScene(t *testing.T) {
+	sceneID := "scene-night"
+
+	tests := []struct {
+		name          string
+		sceneID       *string
+		fade          time.Duration
+		failing       []string
+		expectedCalls []string
+		expectedOn    bool
+	}{
+		{
+			name:          "turns off lights without shutdown scene",
+			expectedCalls: []string{"off light-1", "off light-2"},
+			expectedOn:    false,
+		},
+		{
+			name:          "recalls shutdown scene instead of turning off lights",
+			sceneID:       &sceneID,
+			expectedCalls: []string{"scene recall scene-night 0s"},
+			expectedOn:    true,
+		},
+		{
+			name:          "recalls shutdown scene with the fade duration",
+			sceneID:       &sceneID,
+			fade:          3 * time.Second,
+			expectedCalls: []string{"scene recall scene-night 3s"},
+			expectedOn:    true,
+		},
+		{
+			name:          "turns off lights when shutdown scene recall fails",
+			sceneID:       &sceneID,
+			failing:       []string{"scene recall scene-night 0s"},
+			expectedCalls: []string{"scene recall scene-night 0s", "off light-1", "off light-2"},
+			expectedOn:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newFakeLightClient()
+			for _, call := range tt.failing {
+				client.failing[call] = true
+			}
+
+			cfg := newTestConfig("light-1", "light-2")
+			cfg.ShutdownScene = tt.sceneID
+			cfg.Shutdown.FadeDuration = tt.fade
+
+			service := newTestService(t, client, cfg)
+			service.setLightsState(true, 0)
+			client.calls = nil
+
+			err := service.StopAndTurnOffLights()
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCalls, client.Calls())
+			assert.Equal(t, tt.expectedOn, service.lightStates["light-1"])
+			assert.Equal(t, tt.expectedOn, service.lightStates["light-2"])
+			assert.False(t, service.ownedLights["light-1"])
+			assert.False(t, service.ownedLights["light-2"])
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package light_automation
+
+import "com.github.yveskaufmann/hue-lighter/internal/config"
+
+// applySmartScene recalls the configured smart scene when active is set and
+// deactivates the recalled scene otherwise. It reports whether a smart scene is
+// active, the lights are turned on one by one if the recall failed.
+func (s *Service) applySmartScene(active bool) bool {
+	s.bridgeMu.Lock()
+	defer s.bridgeMu.Unlock()
+
+	s.mu.RLock()
+	sceneID := s.config.SmartScene.ID
+	s.mu.RUnlock()
+
+	if !active || sceneID == "" {
+		s.deactivateSmartScene()
+		return s.activeSmartScene != ""
+	}
+
+	if s.activeSmartScene == sceneID {
+		return true
+	}
+	// The scene was replaced by a config reload
+	s.deactivateSmartScene()
+	if s.activeSmartScene != "" {
+		return true
+	}
+
+	if err := s.client.RecallSmartSceneById(sceneID); err != nil {
+		s.logger.WithError(err).Errorf("Failed to recall smart scene ID: %s, turning on lights instead", sceneID)
+		return false
+	}
+	s.logger.Infof("Recalled smart scene ID: %s", sceneID)
+	s.setActiveSmartScene(sceneID)
+	return true
+}
+
+// deactivateSmartScene stops the smart scene recalled by the automation, if any.
+// A failed deactivation is retried on the next tick. The caller must hold s.bridgeMu.
+func (s *Service) deactivateSmartScene() {
+	if s.activeSmartScene == "" {
+		return
+	}
+
+	if err := s.client.DeactivateSmartSceneById(s.activeSmartScene); err != nil {
+		s.logger.WithError(err).Errorf("Failed to deactivate smart scene ID: %s", s.activeSmartScene)
+		return
+	}
+	s.logger.Infof("Deactivated smart scene ID: %s", s.activeSmartScene)
+	s.setActiveSmartScene("")
+}
+
+// setActiveSmartScene records the smart scene recalled by the automation. The
+// caller must hold s.bridgeMu.
+func (s *Service) setActiveSmartScene(sceneID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.activeSmartScene = sceneID
+}
+
+// markSwitchedBySmartScene records the light as turned on by the automation, so
+// that it is turned off at sunrise and on shutdown. The caller must hold s.mu.
+func (s *Service) markSwitchedBySmartScene(lightCfg config.LightConfig) {
+	s.lightStates[*lightCfg.ID] = true
+	s.ownedLights[*lightCfg.ID] = true
+}
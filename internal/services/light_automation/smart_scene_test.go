@@ -0,0 +1,63 @@
+package light_automation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_RunAutomation_SmartScene(t *testing.T) {
+	client := newFakeLightClient()
+	cfg := newMixedTriggerConfig(t)
+	cfg.SmartScene.ID = "scene-1"
+	service := newTestService(t, client, cfg)
+	service.lastLightStateRefresh = time.Now()
+
+	// The sun sets at 21:33 CEST on the 21st of June and rises at 04:43 CEST.
+	ticks := []struct {
+		tickTime      time.Time
+		expectedCalls []string
+	}{
+		{tickTime: time.Date(2024, 6, 21, 20, 30, 0, 0, cest), expectedCalls: []string{"on light-2"}},
+		{tickTime: time.Date(2024, 6, 21, 22, 0, 0, 0, cest), expectedCalls: []string{"on light-2", "scene activate scene-1"}},
+		{tickTime: time.Date(2024, 6, 22, 3, 0, 0, 0, cest), expectedCalls: []string{"on light-2", "scene activate scene-1"}},
+		{tickTime: time.Date(2024, 6, 22, 5, 0, 0, 0, cest), expectedCalls: []string{"on light-2", "scene activate scene-1", "scene deactivate scene-1", "off light-1", "off light-2"}},
+	}
+
+	for _, tick := range ticks {
+		service.clock = fixedClock(tick.tickTime)
+		service.runAutomation()
+		assert.Equal(t, tick.expectedCalls, client.Calls(), tick.tickTime.String())
+	}
+}
+
+func TestService_RunAutomation_SmartSceneRecallFails(t *testing.T) {
+	client := newFakeLightClient()
+	client.failing["scene activate scene-1"] = true
+	cfg := newTestConfig("light-1")
+	cfg.SmartScene.ID = "scene-1"
+	service := newTestService(t, client, cfg)
+	service.lastLightStateRefresh = time.Now()
+
+	service.clock = fixedClock(time.Date(2024, 6, 21, 22, 0, 0, 0, cest))
+	service.runAutomation()
+
+	assert.Equal(t, []string{"scene activate scene-1", "on light-1"}, client.Calls())
+	assert.Empty(t, service.activeSmartScene)
+}
+
+func TestService_StopAndTurnOffLights_DeactivatesSmartScene(t *testing.T) {
+	client := newFakeLightClient()
+	cfg := newTestConfig("light-1")
+	cfg.SmartScene.ID = "scene-1"
+	service := newTestService(t, client, cfg)
+	service.lastLightStateRefresh = time.Now()
+
+	service.clock = fixedClock(time.Date(2024, 6, 21, 22, 0, 0, 0, cest))
+	service.runAutomation()
+	require.NoError(t, service.StopAndTurnOffLights())
+
+	assert.Equal(t, []string{"scene activate scene-1", "scene deactivate scene-1", "off light-1"}, client.Calls())
+}
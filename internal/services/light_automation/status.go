@@ -0,0 +1,69 @@
+package light_automation
+
+import (
+	"time"
+
+	"com.github.yveskaufmann/hue-lighter/internal/sunset"
+)
+
+// Status is a snapshot of the automation state as reported by the status command.
+type Status struct {
+	BridgeID    string        `json:"bridge_id"`
+	Night       bool          `json:"night"`
+	Paused      bool          `json:"paused"`
+	NextSunrise time.Time     `json:"next_sunrise"`
+	NextSunset  time.Time     `json:"next_sunset"`
+	Lights      []LightStatus `json:"lights"`
+	// Schedule of the current day, or of the previous day while its night is in progress
+	Schedule Schedule `json:"schedule"`
+}
+
+// LightStatus is the last known state of a configured light.
+type LightStatus struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+	On   bool   `json:"on"`
+	// Disabled lights are excluded from the automation, see config.LightConfig.Enabled
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// Status returns a snapshot of the current automation state. Light states are
+// taken from the service cache and do not trigger requests to the bridge.
+func (s *Service) Status() Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := s.clock.Now()
+	if s.config.Location.Timezone != "" {
+		// The sunrise and sunset are computed for the calendar day in the configured time zone
+		now = now.In(s.config.Location.TimeZone())
+	}
+	latitude, longitude := s.config.Location.Latitude, s.config.Location.Longitude
+
+	sunriseTime, sunsetTime := sunset.CalculateSunriseSunsetAt(latitude, longitude, now)
+	nextSunrise, nextSunset := sunset.NextSunriseSunset(latitude, longitude, now)
+
+	status := Status{
+		BridgeID:    s.client.BridgeID(),
+		Night:       isNight(now, sunriseTime, sunsetTime),
+		Paused:      s.paused,
+		NextSunrise: nextSunrise,
+		NextSunset:  nextSunset,
+		Lights:      make([]LightStatus, 0, len(s.config.Lights)),
+		Schedule:    ComputeSchedule(s.config, now),
+	}
+
+	for _, lightCfg := range s.config.Lights {
+		light := LightStatus{Disabled: !lightCfg.IsEnabled()}
+		if lightCfg.ID != nil {
+			light.ID = *lightCfg.ID
+			light.On = s.lightStates[*lightCfg.ID]
+		}
+		if lightCfg.Name != nil {
+			light.Name = *lightCfg.Name
+		}
+		status.Lights = append(status.Lights, light)
+	}
+
+	return status
+}
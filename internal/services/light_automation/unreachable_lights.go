@@ -0,0 +1,45 @@
+package light_automation
+
+import (
+	"com.github.yveskaufmann/hue-lighter/internal/config"
+	hueclient "com.github.yveskaufmann/hue-lighter/internal/hue_client"
+)
+
+// lightReadAttempts returns how often the state of a light is read before it is
+// considered unreachable, the caller must hold s.mu.
+func (s *Service) lightReadAttempts() int {
+	attempts := 1
+	if s.config.Automation.UnreachableLights == config.UnreachableLightRetry && s.config.Automation.UnreachableLightRetries != nil {
+		attempts += *s.config.Automation.UnreachableLightRetries
+	}
+	return attempts
+}
+
+// readLightState reads the state of the light, failed reads are repeated
+// immediately up to the given number of attempts.
+func (s *Service) readLightState(id string, attempts int) (*hueclient.LightListItem, error) {
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		var state *hueclient.LightListItem
+		if state, err = s.client.GetOneLightById(id); err == nil {
+			return state, nil
+		}
+		if attempt < attempts {
+			s.logger.Debugf("Reading state of light %s failed (attempt %d/%d): %v", id, attempt, attempts, err)
+		}
+	}
+	return nil, err
+}
+
+// handleUnreachableLight applies the unreachable light policy to a light whose
+// state could not be read.
+func (s *Service) handleUnreachableLight(id string, err error) {
+	if s.config.Automation.UnreachableLights == config.UnreachableLightAssumeOff {
+		s.logger.Warnf("Could not refresh state for light %s, assuming it is off: %v", id, err)
+		s.lightStates[id] = false
+		delete(s.ownedLights, id)
+		return
+	}
+
+	s.logger.Warnf("Could not refresh state for light %s: %v", id, err)
+}
@@ -0,0 +1,88 @@
+package light_automation
+
+import (
+	"testing"
+
+	"com.github.yveskaufmann/hue-lighter/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestService_RefreshLightStates_UnreachableLights(t *testing.T) {
+	tests := []struct {
+		name          string
+		policy        config.UnreachableLightPolicy
+		retries       int
+		failing       bool
+		failures      int
+		expectedCalls []string
+		expectedOn    bool
+		expectedOwned bool
+	}{
+		{
+			name:          "skip keeps the last known state",
+			policy:        config.UnreachableLightSkip,
+			failing:       true,
+			expectedCalls: []string{"get light-1"},
+			expectedOn:    true,
+			expectedOwned: true,
+		},
+		{
+			name:          "assume_off treats the light as off",
+			policy:        config.UnreachableLightAssumeOff,
+			failing:       true,
+			expectedCalls: []string{"get light-1"},
+			expectedOn:    false,
+			expectedOwned: false,
+		},
+		{
+			name:          "retry reads the state of a flaky light again",
+			policy:        config.UnreachableLightRetry,
+			retries:       2,
+			failures:      2,
+			expectedCalls: []string{"get light-1", "get light-1", "get light-1"},
+			expectedOn:    true,
+			expectedOwned: true,
+		},
+		{
+			name:          "retry keeps the last known state when all reads fail",
+			policy:        config.UnreachableLightRetry,
+			retries:       2,
+			failing:       true,
+			expectedCalls: []string{"get light-1", "get light-1", "get light-1"},
+			expectedOn:    true,
+			expectedOwned: true,
+		},
+		{
+			name:          "retry without retries reads the state once",
+			policy:        config.UnreachableLightRetry,
+			retries:       0,
+			failing:       true,
+			expectedCalls: []string{"get light-1"},
+			expectedOn:    true,
+			expectedOwned: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newFakeLightClient()
+			client.states["light-1"] = true
+
+			cfg := newTestConfig("light-1")
+			cfg.Automation.UnreachableLights = tt.policy
+			cfg.Automation.UnreachableLightRetries = &tt.retries
+
+			service := newTestService(t, client, cfg)
+			service.setLightsState(true, 0)
+			client.calls = nil
+			client.failing["get light-1"] = tt.failing
+			client.failures["get light-1"] = tt.failures
+
+			service.refreshLightStates()
+
+			assert.Equal(t, tt.expectedCalls, client.Calls())
+			assert.Equal(t, tt.expectedOn, service.lightStates["light-1"])
+			assert.Equal(t, tt.expectedOwned, service.ownedLights["light-1"])
+		})
+	}
+}
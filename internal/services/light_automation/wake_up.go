@@ -0,0 +1,139 @@
+package light_automation
+
+import (
+	"math"
+	"time"
+
+	"com.github.yveskaufmann/hue-lighter/internal/config"
+	hueclient "com.github.yveskaufmann/hue-lighter/internal/hue_client"
+)
+
+// wakeUpStartBrightness is the brightness in percent at the start of the wake-up window.
+const wakeUpStartBrightness = 1
+
+// wakeUpRamp is the state of the lights at a point in the wake-up window.
+type wakeUpRamp struct {
+	brightness float32
+	// mirek is nil if the wake-up does not change the color temperature
+	mirek *int
+}
+
+// wakeUpRampAt returns the wake-up state at t, the window ends at sunriseTime. It
+// reports false outside of the window, i.e. before the window and from sunrise on.
+func wakeUpRampAt(cfg *config.Config, sunriseTime time.Time, t time.Time) (wakeUpRamp, bool) {
+	windowStart := sunriseTime.Add(-cfg.WakeUp.Duration)
+	if !cfg.WakeUpEnabled() || t.Before(windowStart) || !t.Before(sunriseTime) {
+		return wakeUpRamp{}, false
+	}
+
+	target := cfg.WakeUp.Brightness
+	if target <= 0 {
+		target = config.DefaultWakeUpBrightness
+	}
+
+	progress := ease(cfg.WakeUp.Easing, float64(t.Sub(windowStart))/float64(cfg.WakeUp.Duration))
+	ramp := wakeUpRamp{
+		brightness: float32(math.Round(wakeUpStartBrightness + progress*float64(target-wakeUpStartBrightness))),
+	}
+
+	if cfg.WakeUp.StartMirek != nil && cfg.WakeUp.EndMirek != nil {
+		mirek := interpolateMirek(*cfg.WakeUp.StartMirek, *cfg.WakeUp.EndMirek, windowStart, sunriseTime, t)
+		ramp.mirek = &mirek
+	}
+
+	return ramp, true
+}
+
+// applyWakeUp ramps up the lights which are on during the wake-up window before
+// sunrise and reports whether the window is active. The brightness is rounded to
+// whole percents and requests are only sent when brightness or color temperature changed.
+func (s *Service) applyWakeUp(tickTime time.Time, sunriseTime time.Time) bool {
+	s.bridgeMu.Lock()
+	defer s.bridgeMu.Unlock()
+
+	updates, active := s.wakeUpUpdates(tickTime, sunriseTime)
+	if !active {
+		return false
+	}
+	errs := s.sendLightUpdates(updates)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, u := range updates {
+		if errs[i] != nil {
+			s.logger.Errorf("Failed to wake up light ID: %s, error: %v", u.id, errs[i])
+			continue
+		}
+
+		if u.update.Dimming != nil {
+			s.logger.Debugf("Set wake-up brightness of light ID: %s to %.0f%%", u.id, u.update.Dimming.Brightness)
+			s.appliedBrightness[u.id] = u.update.Dimming.Brightness
+		}
+		if u.update.ColorTemperature != nil {
+			s.appliedMirek[u.id] = *u.update.ColorTemperature.Mirek
+		}
+	}
+
+	return true
+}
+
+// wakeUpUpdates returns the updates of the lights which are on to the wake-up
+// ramp at tickTime, only brightness or color temperature which changed are
+// included. It reports false outside of the wake-up window.
+func (s *Service) wakeUpUpdates(tickTime time.Time, sunriseTime time.Time) ([]lightUpdate, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ramp, active := wakeUpRampAt(s.config, sunriseTime, tickTime)
+	if !active {
+		return nil, false
+	}
+
+	var updates []lightUpdate
+	for _, lightCfg := range s.enabledLights() {
+		id := *lightCfg.ID
+		if !s.lightStates[id] {
+			continue
+		}
+
+		brightness := s.wakeUpBrightness(lightCfg, ramp)
+		update := &hueclient.LightBodyUpdate{}
+		if applied, ok := s.appliedBrightness[id]; !ok || applied != brightness {
+			update.Dimming = &hueclient.LightDimmingState{Brightness: brightness}
+		}
+		if ramp.mirek != nil && s.appliedMirek[id] != *ramp.mirek {
+			update.ColorTemperature = &hueclient.LightColorTemperature{Mirek: ramp.mirek}
+		}
+		if update.Dimming == nil && update.ColorTemperature == nil {
+			continue
+		}
+		updates = append(updates, lightUpdate{id: id, update: update})
+	}
+
+	return updates, true
+}
+
+// wakeUpBrightness returns the brightness of the ramp for the light, clamped to its
+// floors and capped to max_brightness. The caller must hold s.mu.
+func (s *Service) wakeUpBrightness(lightCfg config.LightConfig, ramp wakeUpRamp) float32 {
+	var configuredFloor float32
+	if lightCfg.MinBrightness != nil {
+		configuredFloor = *lightCfg.MinBrightness
+	}
+	return s.capBrightness(clampBrightness(ramp.brightness, configuredFloor, s.minDimLevels[*lightCfg.ID]))
+}
+
+// wakeUpOnUpdate composes the update which turns on the light during the wake-up
+// window, it starts at the brightness of the ramp instead of the configured one.
+// The caller must hold s.mu.
+func (s *Service) wakeUpOnUpdate(lightCfg config.LightConfig, ramp wakeUpRamp) *hueclient.LightBodyUpdate {
+	update := s.onUpdate(lightCfg)
+	update.Dimming = &hueclient.LightDimmingState{Brightness: s.wakeUpBrightness(lightCfg, ramp)}
+	if ramp.mirek != nil {
+		// The color temperature of the ramp replaces a configured color
+		mirek := *ramp.mirek
+		update.ColorTemperature = &hueclient.LightColorTemperature{Mirek: &mirek}
+		update.Color = nil
+	}
+	return update
+}
@@ -0,0 +1,179 @@
+package light_automation
+
+import (
+	"testing"
+	"time"
+
+	"com.github.yveskaufmann/hue-lighter/internal/config"
+	"com.github.yveskaufmann/hue-lighter/internal/sunset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newWakeUpConfig(lightIDs ...string) *config.Config {
+	startMirek, endMirek := 454, 250
+	cfg := newTestConfig(lightIDs...)
+	cfg.WakeUp.Duration = 30 * time.Minute
+	cfg.WakeUp.Brightness = 81
+	cfg.WakeUp.StartMirek = &startMirek
+	cfg.WakeUp.EndMirek = &endMirek
+	return cfg
+}
+
+func TestWakeUpRampAt(t *testing.T) {
+	sunriseTime := time.Date(2025, 1, 10, 7, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name               string
+		t                  time.Time
+		expectedActive     bool
+		expectedBrightness float32
+		expectedMirek      int
+	}{
+		{name: "before window", t: sunriseTime.Add(-31 * time.Minute)},
+		{name: "start of window", t: sunriseTime.Add(-30 * time.Minute), expectedActive: true, expectedBrightness: 1, expectedMirek: 454},
+		{name: "middle of window", t: sunriseTime.Add(-15 * time.Minute), expectedActive: true, expectedBrightness: 41, expectedMirek: 352},
+		{name: "end of window", t: sunriseTime.Add(-time.Second), expectedActive: true, expectedBrightness: 81, expectedMirek: 250},
+		{name: "at sunrise", t: sunriseTime},
+		{name: "after sunrise", t: sunriseTime.Add(time.Hour)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ramp, active := wakeUpRampAt(newWakeUpConfig(), sunriseTime, tt.t)
+
+			assert.Equal(t, tt.expectedActive, active)
+			if !tt.expectedActive {
+				return
+			}
+			assert.Equal(t, tt.expectedBrightness, ramp.brightness)
+			require.NotNil(t, ramp.mirek)
+			assert.Equal(t, tt.expectedMirek, *ramp.mirek)
+		})
+	}
+
+	t.Run("eases the brightness but not the color temperature", func(t *testing.T) {
+		cfg := newWakeUpConfig()
+		cfg.WakeUp.Easing = config.EasingEaseOut
+
+		ramp, active := wakeUpRampAt(cfg, sunriseTime, sunriseTime.Add(-15*time.Minute))
+
+		assert.True(t, active)
+		assert.Equal(t, float32(61), ramp.brightness)
+		require.NotNil(t, ramp.mirek)
+		assert.Equal(t, 352, *ramp.mirek)
+	})
+
+	t.Run("disabled without duration", func(t *testing.T) {
+		_, active := wakeUpRampAt(newTestConfig(), sunriseTime, sunriseTime.Add(-time.Minute))
+
+		assert.False(t, active)
+	})
+
+	t.Run("keeps color temperature without mirek", func(t *testing.T) {
+		cfg := newWakeUpConfig()
+		cfg.WakeUp.StartMirek, cfg.WakeUp.EndMirek = nil, nil
+
+		ramp, active := wakeUpRampAt(cfg, sunriseTime, sunriseTime.Add(-time.Minute))
+
+		assert.True(t, active)
+		assert.Nil(t, ramp.mirek)
+	})
+}
+
+func TestService_ApplyWakeUp(t *testing.T) {
+	sunriseTime := time.Date(2025, 1, 10, 7, 0, 0, 0, time.UTC)
+	minBrightness := float32(20)
+
+	client := newFakeLightClient()
+	cfg := newWakeUpConfig("light-1", "light-2", "light-3")
+	cfg.Lights[2].MinBrightness = &minBrightness
+
+	service := newTestService(t, client, cfg)
+	service.lightStates["light-1"] = true
+	service.lightStates["light-2"] = false
+	service.lightStates["light-3"] = true
+
+	assert.False(t, service.applyWakeUp(sunriseTime.Add(-time.Hour), sunriseTime))
+	assert.Empty(t, client.Calls())
+
+	assert.True(t, service.applyWakeUp(sunriseTime.Add(-15*time.Minute), sunriseTime))
+	assert.Equal(t, []string{"update light-1", "update light-3"}, client.Calls())
+	update := client.Update("light-1")
+	require.NotNil(t, update.Dimming)
+	require.NotNil(t, update.ColorTemperature)
+	assert.Equal(t, float32(41), update.Dimming.Brightness)
+	assert.Equal(t, 352, *update.ColorTemperature.Mirek)
+
+	// No request while brightness and color temperature are unchanged
+	service.applyWakeUp(sunriseTime.Add(-15*time.Minute+time.Second), sunriseTime)
+	assert.Len(t, client.Calls(), 2)
+
+	// Early in the window the brightness is clamped to the min brightness of light-3
+	client = newFakeLightClient()
+	service = newTestService(t, client, cfg)
+	service.lightStates["light-3"] = true
+	service.applyWakeUp(sunriseTime.Add(-30*time.Minute), sunriseTime)
+	assert.Equal(t, float32(20), client.Update("light-3").Dimming.Brightness)
+
+	assert.False(t, service.applyWakeUp(sunriseTime, sunriseTime))
+}
+
+func TestService_ApplyWakeUpCapsToMaxBrightness(t *testing.T) {
+	sunriseTime := time.Date(2025, 1, 10, 7, 0, 0, 0, time.UTC)
+	maxBrightness := float32(50)
+
+	client := newFakeLightClient()
+	cfg := newWakeUpConfig("light-1")
+	cfg.WakeUp.Brightness = 100
+	cfg.Automation.MaxBrightness = &maxBrightness
+
+	service := newTestService(t, client, cfg)
+	service.lightStates["light-1"] = true
+
+	assert.True(t, service.applyWakeUp(sunriseTime.Add(-time.Second), sunriseTime))
+	require.NotNil(t, client.Update("light-1").Dimming)
+	assert.Equal(t, float32(50), client.Update("light-1").Dimming.Brightness)
+}
+
+func TestService_RunAutomation_WakeUpTakesPrecedence(t *testing.T) {
+	client := newFakeLightClient()
+	cfg := newWakeUpConfig("light-1")
+	cfg.BrightnessSchedule = []config.BrightnessPoint{{Offset: 0, Brightness: 10}}
+	service := newTestService(t, client, cfg)
+	service.lastLightStateRefresh = time.Now()
+
+	sunriseTime, _ := sunset.CalculateSunriseSunsetAt(cfg.Location.Latitude, cfg.Location.Longitude, time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC))
+	service.clock = fixedClock(sunriseTime.Add(-10 * time.Minute))
+	service.runAutomation()
+
+	update := client.Update("light-1")
+	require.NotNil(t, update)
+	require.NotNil(t, update.Dimming)
+	assert.Greater(t, update.Dimming.Brightness, float32(10))
+	assert.NotNil(t, update.ColorTemperature)
+}
+
+func TestService_RunAutomation_TurnsOnLightsAtTheWakeUpBrightness(t *testing.T) {
+	brightness := float32(100)
+	client := newFakeLightClient()
+	cfg := newWakeUpConfig("light-1")
+	cfg.Lights[0].Brightness = &brightness
+	service := newTestService(t, client, cfg)
+	service.lastLightStateRefresh = time.Now()
+
+	sunriseTime, _ := sunset.CalculateSunriseSunsetAt(cfg.Location.Latitude, cfg.Location.Longitude, time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC))
+	service.clock = fixedClock(sunriseTime.Add(-15 * time.Minute))
+	service.runAutomation()
+
+	// A single update turns on the light at the ramp, instead of at full brightness first
+	assert.Equal(t, []string{"update light-1"}, client.Calls())
+	update := client.Update("light-1")
+	require.NotNil(t, update.On)
+	assert.True(t, update.On.On)
+	require.NotNil(t, update.Dimming)
+	assert.Equal(t, float32(41), update.Dimming.Brightness)
+	require.NotNil(t, update.ColorTemperature)
+	assert.Equal(t, 352, *update.ColorTemperature.Mirek)
+	assert.True(t, service.lightStates["light-1"])
+}
@@ -7,15 +7,50 @@ import (
 )
 
 func CalculateSunriseSunset(latitude float64, longitude float64) (time.Time, time.Time) {
-	now := time.Now()
+	return CalculateSunriseSunsetAt(latitude, longitude, time.Now())
+}
 
+// CalculateSunriseSunsetAt returns sunrise and sunset for the calendar day of t.
+func CalculateSunriseSunsetAt(latitude float64, longitude float64, t time.Time) (time.Time, time.Time) {
 	sunriseTime, sunsetTime := sunrise.SunriseSunset(
 		latitude,
 		longitude,
-		now.Year(),
-		now.Month(),
-		now.Day(),
+		t.Year(),
+		t.Month(),
+		t.Day(),
 	)
 
 	return sunriseTime, sunsetTime
 }
+
+// NextSunriseSunset returns the first sunrise and the first sunset after t,
+// looking at the following day if they already passed today.
+func NextSunriseSunset(latitude float64, longitude float64, t time.Time) (time.Time, time.Time) {
+	sunriseTime, sunsetTime := CalculateSunriseSunsetAt(latitude, longitude, t)
+	tomorrowSunrise, tomorrowSunset := CalculateSunriseSunsetAt(latitude, longitude, t.AddDate(0, 0, 1))
+
+	if !sunriseTime.After(t) {
+		sunriseTime = tomorrowSunrise
+	}
+	if !sunsetTime.After(t) {
+		sunsetTime = tomorrowSunset
+	}
+
+	return sunriseTime, sunsetTime
+}
+
+// DayLength returns the time between sunrise and sunset on the calendar day of t,
+// 24 hours during the polar day and zero during the polar night.
+func DayLength(latitude float64, longitude float64, t time.Time) time.Duration {
+	sunriseTime, sunsetTime := CalculateSunriseSunsetAt(latitude, longitude, t)
+	if !sunriseTime.IsZero() {
+		return sunsetTime.Sub(sunriseTime)
+	}
+
+	// The sun neither rises nor sets, it is above the horizon all day during the polar day.
+	solarNoon := time.Date(t.Year(), t.Month(), t.Day(), 12, 0, 0, 0, time.UTC).Add(-time.Duration(longitude / 15 * float64(time.Hour)))
+	if sunrise.Elevation(latitude, longitude, solarNoon) > 0 {
+		return 24 * time.Hour
+	}
+	return 0
+}
@@ -2,9 +2,11 @@ package testutils
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"sync"
 	"testing"
 	"time"
 
@@ -50,6 +52,54 @@ func MockHueBridgeResponse(statusCode int, data interface{}) *httptest.Server {
 	}))
 }
 
+// RecordedRequest is a request received by a mock server
+type RecordedRequest struct {
+	Method string
+	Path   string
+	Header http.Header
+	Body   []byte
+}
+
+// RequestRecorder records all requests received by a mock server
+type RequestRecorder struct {
+	mu       sync.Mutex
+	requests []RecordedRequest
+}
+
+// Requests returns a copy of all requests recorded so far
+func (r *RequestRecorder) Requests() []RecordedRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]RecordedRequest(nil), r.requests...)
+}
+
+func (r *RequestRecorder) record(req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, RecordedRequest{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Header: req.Header.Clone(),
+		Body:   body,
+	})
+}
+
+// MockHueBridgeRecorder creates a mock Hue Bridge API response and records the received requests
+func MockHueBridgeRecorder(statusCode int, data interface{}) (*httptest.Server, *RequestRecorder) {
+	recorder := &RequestRecorder{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorder.record(r)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusCode)
+		if data != nil {
+			json.NewEncoder(w).Encode(data)
+		}
+	}))
+	return server, recorder
+}
+
 // MockHueErrorResponse creates a mock Hue Bridge error response
 func MockHueErrorResponse(errorType, description string) *httptest.Server {
 	errorResponse := []map[string]interface{}{